@@ -1,6 +1,7 @@
 package modemmanager
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/godbus/dbus/v5"
@@ -41,10 +42,16 @@ type Bearer interface {
 	// valid and may contain IP configuration information for the data interface associated with this bearer.
 	Connect() error
 
+	// Same as Connect, but the call is canceled when ctx is done
+	ConnectWithContext(ctx context.Context) error
+
 	// Disconnect and deactivate this packet data connection.
 	// Any ongoing data session will be terminated and IP addresses become invalid when this method is called.
 	Disconnect() error
 
+	// Same as Disconnect, but the call is canceled when ctx is done
+	DisconnectWithContext(ctx context.Context) error
+
 	MarshalJSON() ([]byte, error)
 
 	/* PROPERTIES */
@@ -120,9 +127,14 @@ func NewBearer(objectPath dbus.ObjectPath) (Bearer, error) {
 	return &be, be.init(ModemManagerInterface, objectPath)
 }
 
+// NewBearerWithConn returns new Bearer Interface using the given dbus connection
+func NewBearerWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (Bearer, error) {
+	var be bearer
+	return &be, be.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type bearer struct {
 	dbusBase
-	sigChan chan *dbus.Signal
 }
 
 // BearerIpConfig represents all available ip configuration properties
@@ -203,34 +215,66 @@ func (bp BearerProperty) String() string {
 
 // BearerStats represents all stats according to the bearer
 type BearerStats struct {
-	RxBytes  uint64 `json:"rx-bytes"` // Number of bytes received without error, given as an unsigned 64-bit integer value (signature "t").
-	TxBytes  uint64 `json:"tx-bytes"` // Number bytes transmitted without error, given as an unsigned 64-bit integer value (signature "t").
-	Duration uint32 `json:"duration"` // Duration of the connection, in seconds, given as an unsigned integer value (signature "u").
+	RxBytes        uint64 `json:"rx-bytes"`        // Number of bytes received without error, given as an unsigned 64-bit integer value (signature "t").
+	TxBytes        uint64 `json:"tx-bytes"`        // Number bytes transmitted without error, given as an unsigned 64-bit integer value (signature "t").
+	Duration       uint32 `json:"duration"`        // Duration of the connection, in seconds, given as an unsigned integer value (signature "u").
+	StartDate      uint64 `json:"start-date"`      // Timestamp indicating when the ongoing data connection was established, in seconds since the epoch, given as an unsigned 64-bit integer value (signature "t").
+	UplinkSpeed    uint64 `json:"uplink-speed"`    // Uplink bit rate negotiated with the network, in bits per second, given as an unsigned 64-bit integer value (signature "t").
+	DownlinkSpeed  uint64 `json:"downlink-speed"`  // Downlink bit rate negotiated with the network, in bits per second, given as an unsigned 64-bit integer value (signature "t").
+	Attempts       uint32 `json:"attempts"`        // Total number of connection attempts done with this bearer, given as an unsigned integer value (signature "u").
+	FailedAttempts uint32 `json:"failed-attempts"` // Number of failed connection attempts done with this bearer, given as an unsigned integer value (signature "u").
+	TotalDuration  uint32 `json:"total-duration"`  // Total duration of all the connections of this bearer, in seconds, given as an unsigned integer value (signature "u").
+	TotalRxBytes   uint64 `json:"total-rx-bytes"`  // Total number of bytes received without error in all the successful connection establishments of this bearer, given as an unsigned 64-bit integer value (signature "t").
+	TotalTxBytes   uint64 `json:"total-tx-bytes"`  // Total number of bytes transmitted without error in all the successful connection establishments of this bearer, given as an unsigned 64-bit integer value (signature "t").
 }
 
 // MarshalJSON returns a byte array
 func (bs BearerStats) MarshalJSON() ([]byte, error) {
 	return json.Marshal(map[string]interface{}{
-		"RxBytes":  bs.RxBytes,
-		"TxBytes":  bs.TxBytes,
-		"Duration": bs.Duration,
+		"RxBytes":        bs.RxBytes,
+		"TxBytes":        bs.TxBytes,
+		"Duration":       bs.Duration,
+		"StartDate":      bs.StartDate,
+		"UplinkSpeed":    bs.UplinkSpeed,
+		"DownlinkSpeed":  bs.DownlinkSpeed,
+		"Attempts":       bs.Attempts,
+		"FailedAttempts": bs.FailedAttempts,
+		"TotalDuration":  bs.TotalDuration,
+		"TotalRxBytes":   bs.TotalRxBytes,
+		"TotalTxBytes":   bs.TotalTxBytes,
 	})
 }
 func (bs BearerStats) String() string {
 	return "RxBytes: " + fmt.Sprint(bs.RxBytes) +
 		", TxBytes: " + fmt.Sprint(bs.TxBytes) +
-		", Duration: " + fmt.Sprint(bs.Duration)
+		", Duration: " + fmt.Sprint(bs.Duration) +
+		", StartDate: " + fmt.Sprint(bs.StartDate) +
+		", UplinkSpeed: " + fmt.Sprint(bs.UplinkSpeed) +
+		", DownlinkSpeed: " + fmt.Sprint(bs.DownlinkSpeed) +
+		", Attempts: " + fmt.Sprint(bs.Attempts) +
+		", FailedAttempts: " + fmt.Sprint(bs.FailedAttempts) +
+		", TotalDuration: " + fmt.Sprint(bs.TotalDuration) +
+		", TotalRxBytes: " + fmt.Sprint(bs.TotalRxBytes) +
+		", TotalTxBytes: " + fmt.Sprint(bs.TotalTxBytes)
 }
 func (be bearer) GetObjectPath() dbus.ObjectPath {
 	return be.obj.Path()
 }
 
 func (be bearer) Connect() error {
-	return be.call(BearerConnect)
+	return be.ConnectWithContext(context.Background())
+}
+
+func (be bearer) ConnectWithContext(ctx context.Context) error {
+	return be.callContext(ctx, BearerConnect)
 }
 
 func (be bearer) Disconnect() error {
-	return be.call(BearerDisconnect)
+	return be.DisconnectWithContext(context.Background())
+}
+
+func (be bearer) DisconnectWithContext(ctx context.Context) error {
+	return be.callContext(ctx, BearerDisconnect)
 }
 
 func (be bearer) GetInterface() (string, error) {
@@ -359,6 +403,11 @@ func (be bearer) GetStats() (br BearerStats, err error) {
 	if err != nil {
 		return br, err
 	}
+	return parseBearerStats(tmpMap), nil
+}
+
+// parseBearerStats parses the Stats property, e.g. of a PropertiesChanged signal
+func parseBearerStats(tmpMap map[string]dbus.Variant) (br BearerStats) {
 	for key, element := range tmpMap {
 		switch key {
 		case "duration":
@@ -376,7 +425,46 @@ func (be bearer) GetStats() (br BearerStats, err error) {
 			if ok {
 				br.TxBytes = tmpValue
 			}
-
+		case "start-date":
+			tmpValue, ok := element.Value().(uint64)
+			if ok {
+				br.StartDate = tmpValue
+			}
+		case "uplink-speed":
+			tmpValue, ok := element.Value().(uint64)
+			if ok {
+				br.UplinkSpeed = tmpValue
+			}
+		case "downlink-speed":
+			tmpValue, ok := element.Value().(uint64)
+			if ok {
+				br.DownlinkSpeed = tmpValue
+			}
+		case "attempts":
+			tmpValue, ok := element.Value().(uint32)
+			if ok {
+				br.Attempts = tmpValue
+			}
+		case "failed-attempts":
+			tmpValue, ok := element.Value().(uint32)
+			if ok {
+				br.FailedAttempts = tmpValue
+			}
+		case "total-duration":
+			tmpValue, ok := element.Value().(uint32)
+			if ok {
+				br.TotalDuration = tmpValue
+			}
+		case "total-rx-bytes":
+			tmpValue, ok := element.Value().(uint64)
+			if ok {
+				br.TotalRxBytes = tmpValue
+			}
+		case "total-tx-bytes":
+			tmpValue, ok := element.Value().(uint64)
+			if ok {
+				br.TotalTxBytes = tmpValue
+			}
 		}
 	}
 	return
@@ -447,22 +535,14 @@ func (be bearer) GetProperties() (bp BearerProperty, err error) {
 }
 
 func (be bearer) SubscribePropertiesChanged() <-chan *dbus.Signal {
-	if be.sigChan != nil {
-		return be.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", dbusPropertiesChanged, fmt.Sprint(be.GetObjectPath()))
-	be.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	be.sigChan = make(chan *dbus.Signal, 10)
-	be.conn.Signal(be.sigChan)
-	return be.sigChan
+	return be.subscribeSignal(dbusPropertiesInterface, dbusPropertiesChanged, be.GetObjectPath())
 }
 func (be bearer) ParsePropertiesChanged(v *dbus.Signal) (interfaceName string, changedProperties map[string]dbus.Variant, invalidatedProperties []string, err error) {
 	return be.parsePropertiesChanged(v)
 }
 
 func (be bearer) Unsubscribe() {
-	be.conn.RemoveSignal(be.sigChan)
-	be.sigChan = nil
+	be.unsubscribeSignals()
 }
 
 func (be bearer) MarshalJSON() ([]byte, error) {
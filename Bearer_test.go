@@ -0,0 +1,151 @@
+package modemmanager_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+func TestBearerConnect(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	if err := modem.Enable(); err != nil {
+		t.Fatal(err)
+	}
+	bearer, err := modem.CreateBearer(mm.BearerProperty{APN: "internet", IPType: mm.MmBearerIpFamilyIpv4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	properties, err := bearer.GetProperties()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if properties.APN != "internet" || properties.IPType != mm.MmBearerIpFamilyIpv4 {
+		t.Errorf("properties = %s, want apn internet and ip type ipv4", properties)
+	}
+	if err := bearer.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	connected, err := bearer.GetConnected()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !connected {
+		t.Fatal("bearer not connected")
+	}
+	iface, err := bearer.GetInterface()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if iface != "wwan0" {
+		t.Errorf("interface = %s, want wwan0", iface)
+	}
+	config, err := bearer.GetIp4Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Method != mm.MmBearerIpMethodStatic || config.Address != "10.64.64.64" || config.Prefix != 30 ||
+		config.Gateway != "10.64.64.65" || config.Dns1 != "10.11.12.13" || config.Mtu != 1500 {
+		t.Errorf("ip4 config = %s, want the default config of the fake", config)
+	}
+
+	fake.Bearers()[0].AddTraffic(1000, 200)
+	stats, err := bearer.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.RxBytes != 1000 || stats.TxBytes != 200 || stats.Attempts != 1 {
+		t.Errorf("stats = %s, want 1000 bytes received, 200 bytes transmitted and 1 attempt", stats)
+	}
+
+	if err := bearer.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+	connected, err = bearer.GetConnected()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if connected {
+		t.Error("bearer still connected")
+	}
+	stats, err = bearer.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalRxBytes != 1000 || stats.TotalTxBytes != 200 {
+		t.Errorf("stats = %s, want 1000 bytes received and 200 bytes transmitted in total", stats)
+	}
+
+	if err := modem.DeleteBearer(bearer); err != nil {
+		t.Fatal(err)
+	}
+	bearers, err := modem.GetBearers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bearers) != 0 {
+		t.Errorf("got %d bearers after delete, want none", len(bearers))
+	}
+}
+
+func TestBearerConnectUnregistered(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	bearer, err := modem.CreateBearer(mm.BearerProperty{APN: "internet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = bearer.Connect()
+	if !errors.Is(err, mm.MmCoreErrorWrongState) {
+		t.Errorf("connect of a disabled modem returned %v, want MmCoreErrorWrongState", err)
+	}
+	stats, err := bearer.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.FailedAttempts != 1 {
+		t.Errorf("failed attempts = %d, want 1", stats.FailedAttempts)
+	}
+}
+
+func TestBearerDrop(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	simple, err := modem.GetSimpleModem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bearer, err := simple.Connect(mm.SimpleProperties{Apn: "internet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := bearer.SubscribePropertiesChanged()
+	defer bearer.Unsubscribe()
+	fake.Bearers()[0].Drop()
+	for {
+		sig, ok := <-changed
+		if !ok {
+			t.Fatal("signal channel closed")
+		}
+		_, properties, _, err := bearer.ParsePropertiesChanged(sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v, ok := properties["Connected"]; ok && v == dbus.MakeVariant(false) {
+			break
+		}
+	}
+	state, err := modem.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != mm.MmModemStateRegistered {
+		t.Errorf("state after drop = %s, want registered", state)
+	}
+}
@@ -1,9 +1,9 @@
 package modemmanager
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"github.com/godbus/dbus/v5"
 )
 
@@ -45,10 +45,16 @@ type Call interface {
 	// Applicable only if state is MM_CALL_STATE_UNKNOWN and direction is MM_CALL_DIRECTION_OUTGOING.
 	Start() error
 
+	// Same as Start, but the call is canceled when ctx is done
+	StartWithContext(ctx context.Context) error
+
 	// Accept incoming call (answer).
 	// Applicable only if state is MM_CALL_STATE_RINGING_IN and direction is MM_CALL_DIRECTION_INCOMING.
 	Accept() error
 
+	// Same as Accept, but the call is canceled when ctx is done
+	AcceptWithContext(ctx context.Context) error
+
 	// Deflect an incoming or waiting call to a new number. This call will be considered terminated once the
 	// deflection is performed.
 	// Applicable only if state is MM_CALL_STATE_RINGING_IN or MM_CALL_STATE_WAITING and direction is
@@ -56,25 +62,40 @@ type Call interface {
 	// number: new number where the call will be deflected.
 	Deflect(number string) error
 
+	// Same as Deflect, but the call is canceled when ctx is done
+	DeflectWithContext(ctx context.Context, number string) error
+
 	// Join the currently held call into a single multiparty call with another already active call.
 	// The calls will be flagged with the 'Multiparty' property while they are part of the multiparty call.
 	// Applicable only if state is MM_CALL_STATE_HELD.
 	JoinMultiparty() error
 
+	// Same as JoinMultiparty, but the call is canceled when ctx is done
+	JoinMultipartyWithContext(ctx context.Context) error
+
 	// If this call is part of an ongoing multiparty call, detach it from the multiparty call, put the multiparty
 	// call on hold, and activate this one alone. This operation makes this call private again between both ends of the call.
 	// Applicable only if state is MM_CALL_STATE_ACTIVE or MM_CALL_STATE_HELD and the call is a multiparty call.
 	LeaveMultiparty() error
 
+	// Same as LeaveMultiparty, but the call is canceled when ctx is done
+	LeaveMultipartyWithContext(ctx context.Context) error
+
 	// Hangup the active call.
 	// Applicable only if state is MM_CALL_STATE_UNKNOWN.
 	Hangup() error
 
+	// Same as Hangup, but the call is canceled when ctx is done
+	HangupWithContext(ctx context.Context) error
+
 	// Send a DTMF tone (Dual Tone Multi-Frequency) (only on supported modem).
 	// Applicable only if state is MM_CALL_STATE_ACTIVE.
 	//		IN s dtmf: DTMF tone identifier [0-9A-D*#].
 	SendDtmf(dtmf string) error
 
+	// Same as SendDtmf, but the call is canceled when ctx is done
+	SendDtmfWithContext(ctx context.Context, dtmf string) error
+
 	/* PROPERTIES */
 	MarshalJSON() ([]byte, error)
 
@@ -141,9 +162,14 @@ func NewCall(objectPath dbus.ObjectPath) (Call, error) {
 	return &ca, ca.init(ModemManagerInterface, objectPath)
 }
 
+// NewCallWithConn returns new Call Interface using the given dbus connection
+func NewCallWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (Call, error) {
+	var ca call
+	return &ca, ca.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type call struct {
 	dbusBase
-	sigChan chan *dbus.Signal
 }
 
 type AudioFormat struct {
@@ -170,31 +196,59 @@ func (ca call) GetObjectPath() dbus.ObjectPath {
 }
 
 func (ca call) Start() error {
-	return ca.call(CallStart)
+	return ca.StartWithContext(context.Background())
+}
+
+func (ca call) StartWithContext(ctx context.Context) error {
+	return ca.callContext(ctx, CallStart)
 }
 
 func (ca call) Accept() error {
-	return ca.call(CallAccept)
+	return ca.AcceptWithContext(context.Background())
+}
+
+func (ca call) AcceptWithContext(ctx context.Context) error {
+	return ca.callContext(ctx, CallAccept)
 }
 
 func (ca call) Deflect(number string) error {
-	return ca.call(CallDeflect, &number)
+	return ca.DeflectWithContext(context.Background(), number)
+}
+
+func (ca call) DeflectWithContext(ctx context.Context, number string) error {
+	return ca.callContext(ctx, CallDeflect, &number)
 }
 
 func (ca call) JoinMultiparty() error {
-	return ca.call(CallJoinMultiparty)
+	return ca.JoinMultipartyWithContext(context.Background())
+}
+
+func (ca call) JoinMultipartyWithContext(ctx context.Context) error {
+	return ca.callContext(ctx, CallJoinMultiparty)
 }
 
 func (ca call) LeaveMultiparty() error {
-	return ca.call(CallLeaveMultiparty)
+	return ca.LeaveMultipartyWithContext(context.Background())
+}
+
+func (ca call) LeaveMultipartyWithContext(ctx context.Context) error {
+	return ca.callContext(ctx, CallLeaveMultiparty)
 }
 
 func (ca call) Hangup() error {
-	return ca.call(CallHangup)
+	return ca.HangupWithContext(context.Background())
+}
+
+func (ca call) HangupWithContext(ctx context.Context) error {
+	return ca.callContext(ctx, CallHangup)
 }
 
 func (ca call) SendDtmf(dtmf string) error {
-	return ca.call(CallSendDtmf, &dtmf)
+	return ca.SendDtmfWithContext(context.Background(), dtmf)
+}
+
+func (ca call) SendDtmfWithContext(ctx context.Context, dtmf string) error {
+	return ca.callContext(ctx, CallSendDtmf, &dtmf)
 }
 
 func (ca call) GetState() (MMCallState, error) {
@@ -262,14 +316,7 @@ func (ca call) GetAudioFormat() (af AudioFormat, err error) {
 }
 
 func (ca call) SubscribeDtmfReceived() <-chan *dbus.Signal {
-	if ca.sigChan != nil {
-		return ca.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", CallSignalStateChanged, fmt.Sprint(ca.GetObjectPath()))
-	ca.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	ca.sigChan = make(chan *dbus.Signal, 10)
-	ca.conn.Signal(ca.sigChan)
-	return ca.sigChan
+	return ca.subscribeSignal(CallInterface, CallSignalDtmfReceived, ca.GetObjectPath())
 }
 
 func (ca call) ParseDtmfReceived(v *dbus.Signal) (dtmf string, err error) {
@@ -287,14 +334,7 @@ func (ca call) ParseDtmfReceived(v *dbus.Signal) (dtmf string, err error) {
 }
 
 func (ca call) SubscribeStateChanged() <-chan *dbus.Signal {
-	if ca.sigChan != nil {
-		return ca.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", CallSignalStateChanged, fmt.Sprint(ca.GetObjectPath()))
-	ca.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	ca.sigChan = make(chan *dbus.Signal, 10)
-	ca.conn.Signal(ca.sigChan)
-	return ca.sigChan
+	return ca.subscribeSignal(CallInterface, CallSignalStateChanged, ca.GetObjectPath())
 }
 
 func (ca call) ParseStateChanged(v *dbus.Signal) (oldState MMCallState, newState MMCallState, reason MMCallStateReason, err error) {
@@ -326,14 +366,7 @@ func (ca call) ParseStateChanged(v *dbus.Signal) (oldState MMCallState, newState
 }
 
 func (ca call) SubscribePropertiesChanged() <-chan *dbus.Signal {
-	if ca.sigChan != nil {
-		return ca.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", dbusPropertiesChanged, fmt.Sprint(ca.GetObjectPath()))
-	ca.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	ca.sigChan = make(chan *dbus.Signal, 10)
-	ca.conn.Signal(ca.sigChan)
-	return ca.sigChan
+	return ca.subscribeSignal(dbusPropertiesInterface, dbusPropertiesChanged, ca.GetObjectPath())
 }
 
 func (ca call) ParsePropertiesChanged(v *dbus.Signal) (interfaceName string, changedProperties map[string]dbus.Variant, invalidatedProperties []string, err error) {
@@ -341,8 +374,7 @@ func (ca call) ParsePropertiesChanged(v *dbus.Signal) (interfaceName string, cha
 }
 
 func (ca call) Unsubscribe() {
-	ca.conn.RemoveSignal(ca.sigChan)
-	ca.sigChan = nil
+	ca.unsubscribeSignals()
 }
 
 func (ca call) MarshalJSON() ([]byte, error) {
@@ -0,0 +1,405 @@
+package modemmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/godbus/dbus/v5"
+	"reflect"
+)
+
+// CellInfo represents a serving or neighboring cell as reported by Modem.GetCellInfo. Use a type switch on
+// *CellInfoCdma, *CellInfoGsm, *CellInfoUmts, *CellInfoTdscdma, *CellInfoLte or *CellInfoNr5g to access the
+// fields specific to the cell type.
+type CellInfo interface {
+	// The type of the cell
+	GetCellType() MMCellType
+
+	// Whether the cell is the serving cell or a neighboring cell
+	IsServing() bool
+
+	// The access technology of the cell
+	GetAccessTechnology() MMModemAccessTechnology
+
+	MarshalJSON() ([]byte, error)
+
+	String() string
+}
+
+// CellInfoCdma represents a CDMA cell
+type CellInfoCdma struct {
+	Serving       bool   `json:"serving"`         // Whether the cell is a serving cell, given as a boolean value (signature "b").
+	Nid           string `json:"nid"`             // Network id, given as a hexadecimal string (signature "s").
+	Sid           string `json:"sid"`             // System id, given as a hexadecimal string (signature "s").
+	BaseStationId string `json:"base-station-id"` // Base station id, given as a hexadecimal string (signature "s").
+	RefPn         string `json:"ref-pn"`          // Reference PN, given as a hexadecimal string (signature "s").
+	PilotStrength uint32 `json:"pilot-strength"`  // Pilot strength, given as an unsigned integer value (signature "u").
+}
+
+// CellInfoGsm represents a GSM cell
+type CellInfoGsm struct {
+	Serving       bool   `json:"serving"`         // Whether the cell is a serving cell, given as a boolean value (signature "b").
+	OperatorId    string `json:"operator-id"`     // PLMN of the cell in the format "MCCMNC", given as a string value (signature "s").
+	Lac           string `json:"lac"`             // Location area code, given as a hexadecimal string (signature "s").
+	Ci            string `json:"ci"`              // Cell id, given as a hexadecimal string (signature "s").
+	TimingAdvance uint32 `json:"timing-advance"`  // Timing advance, given as an unsigned integer value (signature "u").
+	Arfcn         uint32 `json:"arfcn"`           // Absolute radio frequency channel number, given as an unsigned integer value (signature "u").
+	BaseStationId string `json:"base-station-id"` // Base station identity code, given as a hexadecimal string (signature "s").
+	RxLevel       uint32 `json:"rx-level"`        // Received signal level, given as an unsigned integer value (signature "u").
+}
+
+// CellInfoUmts represents an UMTS cell
+type CellInfoUmts struct {
+	Serving        bool    `json:"serving"`          // Whether the cell is a serving cell, given as a boolean value (signature "b").
+	OperatorId     string  `json:"operator-id"`      // PLMN of the cell in the format "MCCMNC", given as a string value (signature "s").
+	Lac            string  `json:"lac"`              // Location area code, given as a hexadecimal string (signature "s").
+	Ci             string  `json:"ci"`               // Cell id, given as a hexadecimal string (signature "s").
+	FrequencyFddUl uint32  `json:"frequency-fdd-ul"` // Uplink frequency in FDD mode, given as an unsigned integer value (signature "u").
+	FrequencyFddDl uint32  `json:"frequency-fdd-dl"` // Downlink frequency in FDD mode, given as an unsigned integer value (signature "u").
+	FrequencyTdd   uint32  `json:"frequency-tdd"`    // Frequency in TDD mode, given as an unsigned integer value (signature "u").
+	Uarfcn         uint32  `json:"uarfcn"`           // UTRA absolute radio frequency channel number, given as an unsigned integer value (signature "u").
+	Psc            uint32  `json:"psc"`              // Primary scrambling code, given as an unsigned integer value (signature "u").
+	Rscp           float64 `json:"rscp"`             // Received signal code power in dBm, given as a floating point value (signature "d").
+	Ecio           float64 `json:"ecio"`             // Energy per chip to interference ratio in dB, given as a floating point value (signature "d").
+	PathLoss       uint32  `json:"path-loss"`        // Path loss in dB, given as an unsigned integer value (signature "u").
+}
+
+// CellInfoTdscdma represents a TD-SCDMA cell
+type CellInfoTdscdma struct {
+	Serving         bool    `json:"serving"`           // Whether the cell is a serving cell, given as a boolean value (signature "b").
+	OperatorId      string  `json:"operator-id"`       // PLMN of the cell in the format "MCCMNC", given as a string value (signature "s").
+	Lac             string  `json:"lac"`               // Location area code, given as a hexadecimal string (signature "s").
+	Ci              string  `json:"ci"`                // Cell id, given as a hexadecimal string (signature "s").
+	Uarfcn          uint32  `json:"uarfcn"`            // UTRA absolute radio frequency channel number, given as an unsigned integer value (signature "u").
+	CellParameterId uint32  `json:"cell-parameter-id"` // Cell parameter id, given as an unsigned integer value (signature "u").
+	TimingAdvance   uint32  `json:"timing-advance"`    // Timing advance, given as an unsigned integer value (signature "u").
+	Rscp            float64 `json:"rscp"`              // Received signal code power in dBm, given as a floating point value (signature "d").
+	PathLoss        uint32  `json:"path-loss"`         // Path loss in dB, given as an unsigned integer value (signature "u").
+}
+
+// CellInfoLte represents a LTE cell
+type CellInfoLte struct {
+	Serving         bool              `json:"serving"`           // Whether the cell is a serving cell, given as a boolean value (signature "b").
+	OperatorId      string            `json:"operator-id"`       // PLMN of the cell in the format "MCCMNC", given as a string value (signature "s").
+	Tac             string            `json:"tac"`               // Tracking area code, given as a hexadecimal string (signature "s").
+	Ci              string            `json:"ci"`                // Cell id, given as a hexadecimal string (signature "s").
+	PhysicalCi      string            `json:"physical-ci"`       // Physical cell id, given as a hexadecimal string (signature "s").
+	Earfcn          uint32            `json:"earfcn"`            // E-UTRA absolute radio frequency channel number, given as an unsigned integer value (signature "u").
+	Rsrp            float64           `json:"rsrp"`              // Reference signal received power in dBm, given as a floating point value (signature "d").
+	Rsrq            float64           `json:"rsrq"`              // Reference signal received quality in dB, given as a floating point value (signature "d").
+	TimingAdvance   uint32            `json:"timing-advance"`    // Timing advance, given as an unsigned integer value (signature "u").
+	Bandwidth       uint32            `json:"bandwidth"`         // Bandwidth in kHz, given as an unsigned integer value (signature "u").
+	ServingCellType MMServingCellType `json:"serving-cell-type"` // Type of the serving cell, given as a MMServingCellType value (signature "u").
+}
+
+// CellInfoNr5g represents a 5GNR cell
+type CellInfoNr5g struct {
+	Serving         bool              `json:"serving"`           // Whether the cell is a serving cell, given as a boolean value (signature "b").
+	OperatorId      string            `json:"operator-id"`       // PLMN of the cell in the format "MCCMNC", given as a string value (signature "s").
+	Tac             string            `json:"tac"`               // Tracking area code, given as a hexadecimal string (signature "s").
+	Ci              string            `json:"ci"`                // Cell id, given as a hexadecimal string (signature "s").
+	PhysicalCi      string            `json:"physical-ci"`       // Physical cell id, given as a hexadecimal string (signature "s").
+	NrArfcn         uint32            `json:"nrarfcn"`           // NR absolute radio frequency channel number, given as an unsigned integer value (signature "u").
+	Rsrp            float64           `json:"rsrp"`              // Reference signal received power in dBm, given as a floating point value (signature "d").
+	Rsrq            float64           `json:"rsrq"`              // Reference signal received quality in dB, given as a floating point value (signature "d").
+	Sinr            float64           `json:"sinr"`              // Signal to interference plus noise ratio in dB, given as a floating point value (signature "d").
+	TimingAdvance   uint32            `json:"timing-advance"`    // Timing advance, given as an unsigned integer value (signature "u").
+	Bandwidth       uint32            `json:"bandwidth"`         // Bandwidth in kHz, given as an unsigned integer value (signature "u").
+	ServingCellType MMServingCellType `json:"serving-cell-type"` // Type of the serving cell, given as a MMServingCellType value (signature "u").
+}
+
+// parseCellInfo returns the typed cell of the given cell info dictionary, or nil if the cell type is unknown
+func parseCellInfo(tmpMap map[string]dbus.Variant) CellInfo {
+	var cellType MMCellType
+	if element, ok := tmpMap["cell-type"]; ok {
+		tmpValue, ok := element.Value().(uint32)
+		if ok {
+			cellType = MMCellType(tmpValue)
+		}
+	}
+	var cell CellInfo
+	switch cellType {
+	case MmCellTypeCdma:
+		cell = &CellInfoCdma{}
+	case MmCellTypeGsm:
+		cell = &CellInfoGsm{}
+	case MmCellTypeUmts:
+		cell = &CellInfoUmts{}
+	case MmCellTypeTdscdma:
+		cell = &CellInfoTdscdma{}
+	case MmCellTypeLte:
+		cell = &CellInfoLte{}
+	case MmCellType5gnr:
+		cell = &CellInfoNr5g{}
+	default:
+		return nil
+	}
+	// the keys of the dictionary match the json tags of the struct fields
+	v := reflect.ValueOf(cell).Elem()
+	st := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		element, ok := tmpMap[st.Field(i).Tag.Get("json")]
+		if !ok {
+			continue
+		}
+		value := reflect.ValueOf(element.Value())
+		if value.Kind() == v.Field(i).Kind() {
+			v.Field(i).Set(value.Convert(v.Field(i).Type()))
+		}
+	}
+	return cell
+}
+
+func (c CellInfoCdma) GetCellType() MMCellType {
+	return MmCellTypeCdma
+}
+
+func (c CellInfoCdma) IsServing() bool {
+	return c.Serving
+}
+
+func (c CellInfoCdma) GetAccessTechnology() MMModemAccessTechnology {
+	return MmModemAccessTechnology1xrtt
+}
+
+// MarshalJSON returns a byte array
+func (c CellInfoCdma) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"CellType":      fmt.Sprint(c.GetCellType()),
+		"Serving":       c.Serving,
+		"Nid":           c.Nid,
+		"Sid":           c.Sid,
+		"BaseStationId": c.BaseStationId,
+		"RefPn":         c.RefPn,
+		"PilotStrength": c.PilotStrength,
+	})
+}
+
+func (c CellInfoCdma) String() string {
+	return "CellType: " + fmt.Sprint(c.GetCellType()) +
+		", Serving: " + fmt.Sprint(c.Serving) +
+		", Nid: " + c.Nid +
+		", Sid: " + c.Sid +
+		", BaseStationId: " + c.BaseStationId +
+		", RefPn: " + c.RefPn +
+		", PilotStrength: " + fmt.Sprint(c.PilotStrength)
+}
+
+func (c CellInfoGsm) GetCellType() MMCellType {
+	return MmCellTypeGsm
+}
+
+func (c CellInfoGsm) IsServing() bool {
+	return c.Serving
+}
+
+func (c CellInfoGsm) GetAccessTechnology() MMModemAccessTechnology {
+	return MmModemAccessTechnologyGsm
+}
+
+// MarshalJSON returns a byte array
+func (c CellInfoGsm) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"CellType":      fmt.Sprint(c.GetCellType()),
+		"Serving":       c.Serving,
+		"OperatorId":    c.OperatorId,
+		"Lac":           c.Lac,
+		"Ci":            c.Ci,
+		"TimingAdvance": c.TimingAdvance,
+		"Arfcn":         c.Arfcn,
+		"BaseStationId": c.BaseStationId,
+		"RxLevel":       c.RxLevel,
+	})
+}
+
+func (c CellInfoGsm) String() string {
+	return "CellType: " + fmt.Sprint(c.GetCellType()) +
+		", Serving: " + fmt.Sprint(c.Serving) +
+		", OperatorId: " + c.OperatorId +
+		", Lac: " + c.Lac +
+		", Ci: " + c.Ci +
+		", TimingAdvance: " + fmt.Sprint(c.TimingAdvance) +
+		", Arfcn: " + fmt.Sprint(c.Arfcn) +
+		", BaseStationId: " + c.BaseStationId +
+		", RxLevel: " + fmt.Sprint(c.RxLevel)
+}
+
+func (c CellInfoUmts) GetCellType() MMCellType {
+	return MmCellTypeUmts
+}
+
+func (c CellInfoUmts) IsServing() bool {
+	return c.Serving
+}
+
+func (c CellInfoUmts) GetAccessTechnology() MMModemAccessTechnology {
+	return MmModemAccessTechnologyUmts
+}
+
+// MarshalJSON returns a byte array
+func (c CellInfoUmts) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"CellType":       fmt.Sprint(c.GetCellType()),
+		"Serving":        c.Serving,
+		"OperatorId":     c.OperatorId,
+		"Lac":            c.Lac,
+		"Ci":             c.Ci,
+		"FrequencyFddUl": c.FrequencyFddUl,
+		"FrequencyFddDl": c.FrequencyFddDl,
+		"FrequencyTdd":   c.FrequencyTdd,
+		"Uarfcn":         c.Uarfcn,
+		"Psc":            c.Psc,
+		"Rscp":           c.Rscp,
+		"Ecio":           c.Ecio,
+		"PathLoss":       c.PathLoss,
+	})
+}
+
+func (c CellInfoUmts) String() string {
+	return "CellType: " + fmt.Sprint(c.GetCellType()) +
+		", Serving: " + fmt.Sprint(c.Serving) +
+		", OperatorId: " + c.OperatorId +
+		", Lac: " + c.Lac +
+		", Ci: " + c.Ci +
+		", FrequencyFddUl: " + fmt.Sprint(c.FrequencyFddUl) +
+		", FrequencyFddDl: " + fmt.Sprint(c.FrequencyFddDl) +
+		", FrequencyTdd: " + fmt.Sprint(c.FrequencyTdd) +
+		", Uarfcn: " + fmt.Sprint(c.Uarfcn) +
+		", Psc: " + fmt.Sprint(c.Psc) +
+		", Rscp: " + fmt.Sprint(c.Rscp) +
+		", Ecio: " + fmt.Sprint(c.Ecio) +
+		", PathLoss: " + fmt.Sprint(c.PathLoss)
+}
+
+func (c CellInfoTdscdma) GetCellType() MMCellType {
+	return MmCellTypeTdscdma
+}
+
+func (c CellInfoTdscdma) IsServing() bool {
+	return c.Serving
+}
+
+// GetAccessTechnology returns MmModemAccessTechnologyUnknown, as ModemManager has no access technology for TD-SCDMA
+func (c CellInfoTdscdma) GetAccessTechnology() MMModemAccessTechnology {
+	return MmModemAccessTechnologyUnknown
+}
+
+// MarshalJSON returns a byte array
+func (c CellInfoTdscdma) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"CellType":        fmt.Sprint(c.GetCellType()),
+		"Serving":         c.Serving,
+		"OperatorId":      c.OperatorId,
+		"Lac":             c.Lac,
+		"Ci":              c.Ci,
+		"Uarfcn":          c.Uarfcn,
+		"CellParameterId": c.CellParameterId,
+		"TimingAdvance":   c.TimingAdvance,
+		"Rscp":            c.Rscp,
+		"PathLoss":        c.PathLoss,
+	})
+}
+
+func (c CellInfoTdscdma) String() string {
+	return "CellType: " + fmt.Sprint(c.GetCellType()) +
+		", Serving: " + fmt.Sprint(c.Serving) +
+		", OperatorId: " + c.OperatorId +
+		", Lac: " + c.Lac +
+		", Ci: " + c.Ci +
+		", Uarfcn: " + fmt.Sprint(c.Uarfcn) +
+		", CellParameterId: " + fmt.Sprint(c.CellParameterId) +
+		", TimingAdvance: " + fmt.Sprint(c.TimingAdvance) +
+		", Rscp: " + fmt.Sprint(c.Rscp) +
+		", PathLoss: " + fmt.Sprint(c.PathLoss)
+}
+
+func (c CellInfoLte) GetCellType() MMCellType {
+	return MmCellTypeLte
+}
+
+func (c CellInfoLte) IsServing() bool {
+	return c.Serving
+}
+
+func (c CellInfoLte) GetAccessTechnology() MMModemAccessTechnology {
+	return MmModemAccessTechnologyLte
+}
+
+// MarshalJSON returns a byte array
+func (c CellInfoLte) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"CellType":        fmt.Sprint(c.GetCellType()),
+		"Serving":         c.Serving,
+		"OperatorId":      c.OperatorId,
+		"Tac":             c.Tac,
+		"Ci":              c.Ci,
+		"PhysicalCi":      c.PhysicalCi,
+		"Earfcn":          c.Earfcn,
+		"Rsrp":            c.Rsrp,
+		"Rsrq":            c.Rsrq,
+		"TimingAdvance":   c.TimingAdvance,
+		"Bandwidth":       c.Bandwidth,
+		"ServingCellType": fmt.Sprint(c.ServingCellType),
+	})
+}
+
+func (c CellInfoLte) String() string {
+	return "CellType: " + fmt.Sprint(c.GetCellType()) +
+		", Serving: " + fmt.Sprint(c.Serving) +
+		", OperatorId: " + c.OperatorId +
+		", Tac: " + c.Tac +
+		", Ci: " + c.Ci +
+		", PhysicalCi: " + c.PhysicalCi +
+		", Earfcn: " + fmt.Sprint(c.Earfcn) +
+		", Rsrp: " + fmt.Sprint(c.Rsrp) +
+		", Rsrq: " + fmt.Sprint(c.Rsrq) +
+		", TimingAdvance: " + fmt.Sprint(c.TimingAdvance) +
+		", Bandwidth: " + fmt.Sprint(c.Bandwidth) +
+		", ServingCellType: " + fmt.Sprint(c.ServingCellType)
+}
+
+func (c CellInfoNr5g) GetCellType() MMCellType {
+	return MmCellType5gnr
+}
+
+func (c CellInfoNr5g) IsServing() bool {
+	return c.Serving
+}
+
+func (c CellInfoNr5g) GetAccessTechnology() MMModemAccessTechnology {
+	return MmModemAccessTechnology5gnr
+}
+
+// MarshalJSON returns a byte array
+func (c CellInfoNr5g) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"CellType":        fmt.Sprint(c.GetCellType()),
+		"Serving":         c.Serving,
+		"OperatorId":      c.OperatorId,
+		"Tac":             c.Tac,
+		"Ci":              c.Ci,
+		"PhysicalCi":      c.PhysicalCi,
+		"NrArfcn":         c.NrArfcn,
+		"Rsrp":            c.Rsrp,
+		"Rsrq":            c.Rsrq,
+		"Sinr":            c.Sinr,
+		"TimingAdvance":   c.TimingAdvance,
+		"Bandwidth":       c.Bandwidth,
+		"ServingCellType": fmt.Sprint(c.ServingCellType),
+	})
+}
+
+func (c CellInfoNr5g) String() string {
+	return "CellType: " + fmt.Sprint(c.GetCellType()) +
+		", Serving: " + fmt.Sprint(c.Serving) +
+		", OperatorId: " + c.OperatorId +
+		", Tac: " + c.Tac +
+		", Ci: " + c.Ci +
+		", PhysicalCi: " + c.PhysicalCi +
+		", NrArfcn: " + fmt.Sprint(c.NrArfcn) +
+		", Rsrp: " + fmt.Sprint(c.Rsrp) +
+		", Rsrq: " + fmt.Sprint(c.Rsrq) +
+		", Sinr: " + fmt.Sprint(c.Sinr) +
+		", TimingAdvance: " + fmt.Sprint(c.TimingAdvance) +
+		", Bandwidth: " + fmt.Sprint(c.Bandwidth) +
+		", ServingCellType: " + fmt.Sprint(c.ServingCellType)
+}
@@ -0,0 +1,94 @@
+package modemmanager
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestParseCellInfo(t *testing.T) {
+	v := dbus.MakeVariant
+	tests := []struct {
+		name       string
+		values     map[string]dbus.Variant
+		want       CellInfo
+		technology MMModemAccessTechnology
+	}{
+		{"cdma", map[string]dbus.Variant{
+			"cell-type": v(uint32(MmCellTypeCdma)), "serving": v(true), "nid": v("1"), "sid": v("3a5"),
+			"base-station-id": v("1f4"), "ref-pn": v("64"), "pilot-strength": v(uint32(45)),
+		}, &CellInfoCdma{Serving: true, Nid: "1", Sid: "3a5", BaseStationId: "1f4", RefPn: "64", PilotStrength: 45},
+			MmModemAccessTechnology1xrtt},
+		{"gsm", map[string]dbus.Variant{
+			"cell-type": v(uint32(MmCellTypeGsm)), "serving": v(false), "operator-id": v("26201"), "lac": v("1a2b"),
+			"ci": v("3c4d"), "timing-advance": v(uint32(2)), "arfcn": v(uint32(62)), "base-station-id": v("2f"),
+			"rx-level": v(uint32(40)),
+		}, &CellInfoGsm{OperatorId: "26201", Lac: "1a2b", Ci: "3c4d", TimingAdvance: 2, Arfcn: 62, BaseStationId: "2f",
+			RxLevel: 40}, MmModemAccessTechnologyGsm},
+		{"umts", map[string]dbus.Variant{
+			"cell-type": v(uint32(MmCellTypeUmts)), "serving": v(true), "operator-id": v("26202"), "lac": v("5e"),
+			"ci": v("1929601"), "frequency-fdd-ul": v(uint32(9613)), "frequency-fdd-dl": v(uint32(10563)),
+			"uarfcn": v(uint32(10563)), "psc": v(uint32(312)), "rscp": v(-87.5), "ecio": v(-6.5), "path-loss": v(uint32(110)),
+		}, &CellInfoUmts{Serving: true, OperatorId: "26202", Lac: "5e", Ci: "1929601", FrequencyFddUl: 9613,
+			FrequencyFddDl: 10563, Uarfcn: 10563, Psc: 312, Rscp: -87.5, Ecio: -6.5, PathLoss: 110},
+			MmModemAccessTechnologyUmts},
+		{"tdscdma", map[string]dbus.Variant{
+			"cell-type": v(uint32(MmCellTypeTdscdma)), "serving": v(true), "operator-id": v("46000"), "lac": v("2541"),
+			"ci": v("a1b2"), "uarfcn": v(uint32(10088)), "cell-parameter-id": v(uint32(77)),
+			"timing-advance": v(uint32(5)), "rscp": v(-90.0), "path-loss": v(uint32(100)),
+		}, &CellInfoTdscdma{Serving: true, OperatorId: "46000", Lac: "2541", Ci: "a1b2", Uarfcn: 10088,
+			CellParameterId: 77, TimingAdvance: 5, Rscp: -90, PathLoss: 100}, MmModemAccessTechnologyUnknown},
+		{"lte", map[string]dbus.Variant{
+			"cell-type": v(uint32(MmCellTypeLte)), "serving": v(true), "operator-id": v("26201"), "tac": v("b3c4"),
+			"ci": v("1a2d005"), "physical-ci": v("f1"), "earfcn": v(uint32(6300)), "rsrp": v(-95.5), "rsrq": v(-10.0),
+			"timing-advance": v(uint32(3)), "bandwidth": v(uint32(20000)),
+			"serving-cell-type": v(uint32(MmServingCellTypePcell)),
+		}, &CellInfoLte{Serving: true, OperatorId: "26201", Tac: "b3c4", Ci: "1a2d005", PhysicalCi: "f1",
+			Earfcn: 6300, Rsrp: -95.5, Rsrq: -10, TimingAdvance: 3, Bandwidth: 20000,
+			ServingCellType: MmServingCellTypePcell}, MmModemAccessTechnologyLte},
+		{"5gnr", map[string]dbus.Variant{
+			"cell-type": v(uint32(MmCellType5gnr)), "serving": v(true), "operator-id": v("26203"), "tac": v("00c4d5"),
+			"ci": v("abcdef012"), "physical-ci": v("1f7"), "nrarfcn": v(uint32(636768)), "rsrp": v(-88.0),
+			"rsrq": v(-11.5), "sinr": v(0.0), "timing-advance": v(uint32(1)), "bandwidth": v(uint32(100000)),
+			"serving-cell-type": v(uint32(MmServingCellTypePscell)),
+		}, &CellInfoNr5g{Serving: true, OperatorId: "26203", Tac: "00c4d5", Ci: "abcdef012", PhysicalCi: "1f7",
+			NrArfcn: 636768, Rsrp: -88, Rsrq: -11.5, TimingAdvance: 1, Bandwidth: 100000,
+			ServingCellType: MmServingCellTypePscell}, MmModemAccessTechnology5gnr},
+		{"neighbor with values of wrong type", map[string]dbus.Variant{
+			"cell-type": v(uint32(MmCellTypeLte)), "physical-ci": v(uint32(241)), "earfcn": v(int32(6300)),
+			"rsrp": v(-101.0),
+		}, &CellInfoLte{Rsrp: -101}, MmModemAccessTechnologyLte},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cell := parseCellInfo(tt.values)
+			if !reflect.DeepEqual(cell, tt.want) {
+				t.Fatalf("got cell %+v, want %+v", cell, tt.want)
+			}
+			if cell.GetCellType() != MMCellType(tt.values["cell-type"].Value().(uint32)) {
+				t.Errorf("got cell type %s", cell.GetCellType())
+			}
+			if cell.IsServing() != (tt.values["serving"].Value() == true) {
+				t.Errorf("got serving %t", cell.IsServing())
+			}
+			if cell.GetAccessTechnology() != tt.technology {
+				t.Errorf("got access technology %s, want %s", cell.GetAccessTechnology(), tt.technology)
+			}
+		})
+	}
+}
+
+func TestParseCellInfoUnknownType(t *testing.T) {
+	v := dbus.MakeVariant
+	for _, values := range []map[string]dbus.Variant{
+		{"serving": v(true), "ci": v("1a2d005")},
+		{"cell-type": v(uint32(MmCellTypeUnknown)), "ci": v("1a2d005")},
+		{"cell-type": v(uint32(42)), "ci": v("1a2d005")},
+		{"cell-type": v("lte"), "ci": v("1a2d005")},
+	} {
+		if cell := parseCellInfo(values); cell != nil {
+			t.Errorf("got cell %+v of %v, want nil", cell, values)
+		}
+	}
+}
@@ -1,9 +1,9 @@
 package modemmanager
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"github.com/godbus/dbus/v5"
 	"reflect"
 )
@@ -25,6 +25,8 @@ const (
 	ModemSetCurrentModes        = ModemInterface + ".SetCurrentModes"
 	ModemSetCurrentBands        = ModemInterface + ".SetCurrentBands"
 	ModemCommand                = ModemInterface + ".Command"
+	ModemSetPrimarySimSlot      = ModemInterface + ".SetPrimarySimSlot"
+	ModemGetCellInfo            = ModemInterface + ".GetCellInfo"
 
 	/* Property */
 
@@ -60,6 +62,8 @@ const (
 	ModemPropertySupportedBands               = ModemInterface + ".SupportedBands"               //           readable   au
 	ModemPropertyCurrentBands                 = ModemInterface + ".CurrentBands"                 //          readable   au
 	ModemPropertySupportedIpFamilies          = ModemInterface + ".SupportedIpFamilies"          //         readable   u
+	ModemPropertySimSlots                     = ModemInterface + ".SimSlots"                     //         readable   ao
+	ModemPropertyPrimarySimSlot               = ModemInterface + ".PrimarySimSlot"               //         readable   u
 
 	/* Signal */
 	ModemSignalStateChanged = "StateChanged"
@@ -94,6 +98,9 @@ type Modem interface {
 	// Return ModemSignal Interface
 	GetOma() (ModemOma, error)
 
+	// Return ModemSar Interface
+	GetSar() (ModemSar, error)
+
 	// Return ModemLocation Interface
 	GetLocation() (ModemLocation, error)
 
@@ -106,8 +113,12 @@ type Modem interface {
 	// Enables the Modem: When enabled, the modem's radio is powered on and data sessions, voice calls,
 	// location services, and Short Message Service may be available.
 	Enable() error
+	// Same as Enable, but the call is canceled when ctx is done
+	EnableWithContext(ctx context.Context) error
 	// Disable the Modem: When disabled, the modem enters low-power state and no network-related operations are available.
 	Disable() error
+	// Same as Disable, but the call is canceled when ctx is done
+	DisableWithContext(ctx context.Context) error
 	// Deprecated: List configured packet data bearers (EPS Bearers, PDP Contexts, or CDMA2000 Packet Data Sessions).
 	// ListBearers() ([]Bearer, error)
 
@@ -120,42 +131,93 @@ type Modem interface {
 	// not applicable to CDMA2000 Packet Data Session bearers.
 	CreateBearer(BearerProperty) (Bearer, error)
 
+	// Same as CreateBearer, but the call is canceled when ctx is done
+	CreateBearerWithContext(ctx context.Context, property BearerProperty) (Bearer, error)
+
 	// If the bearer is currently active and providing packet data server, it will be disconnected and that packet data service will terminate.
 	DeleteBearer(bearer Bearer) error
 
+	// Same as DeleteBearer, but the call is canceled when ctx is done
+	DeleteBearerWithContext(ctx context.Context, bearer Bearer) error
+
 	// Clear non-persistent configuration and state, and return the device to a newly-powered-on state.
 	// This command may power-cycle the device.
 	Reset() error
 
+	// Same as Reset, but the call is canceled when ctx is done
+	ResetWithContext(ctx context.Context) error
+
 	// Clear the modem's configuration (including persistent configuration and state), and return the device to a
 	// factory-default state.
 	// If not required by the modem, code may be ignored. This command may or may not power-cycle the device.
 	FactoryReset(code string) error
 
+	// Same as FactoryReset, but the call is canceled when ctx is done
+	FactoryResetWithContext(ctx context.Context, code string) error
+
 	// Set the power state of the modem. This action can only be run when the modem is in MM_MODEM_STATE_DISABLED state.
 	SetPowerState(MMModemPowerState) error
 
+	// Same as SetPowerState, but the call is canceled when ctx is done
+	SetPowerStateWithContext(ctx context.Context, state MMModemPowerState) error
+
 	// Set the capabilities of the device. A restart of the modem may be required. Bitmask of MMModemCapability values, to specify the capabilities to use.
 	SetCurrentCapabilities([]MMModemCapability) error
 
+	// Same as SetCurrentCapabilities, but the call is canceled when ctx is done
+	SetCurrentCapabilitiesWithContext(ctx context.Context, capabilities []MMModemCapability) error
+
 	// Set the access technologies (e.g. 2G/3G/4G preference) the device is currently allowed to use when connecting to a network.
 	// The given combination should be supported by the modem, as specified in the "SupportedModes" property.
 	// A pair of MMModemMode values, where the first one is a bitmask of allowed modes, and the second one the preferred mode, if any.
 	SetCurrentModes(Mode) error
 
+	// Same as SetCurrentModes, but the call is canceled when ctx is done
+	SetCurrentModesWithContext(ctx context.Context, property Mode) error
+
 	// Set the radio frequency and technology bands the device is currently allowed to use when connecting to a network.
 	// List of MMModemBand values, to specify the bands to be used.
 	SetCurrentBands([]MMModemBand) error
 
+	// Same as SetCurrentBands, but the call is canceled when ctx is done
+	SetCurrentBandsWithContext(ctx context.Context, bands []MMModemBand) error
+
 	// AT command for the Modem:
 	// to enable either start mm in debug mode (ModemManager --debug) or with ModemManager --with-at-command-via-dbus
 	Command(cmd string, timeout uint32) (string, error)
 
+	// Same as Command, but the call is canceled when ctx is done
+	CommandWithContext(ctx context.Context, cmd string, timeout uint32) (string, error)
+
+	// Selects which SIM slot to be considered as primary, on devices that expose multiple slots in the "SimSlots"
+	// property. The given slot index is 1-based. The modem will be reprobed and exposed with a new object path.
+	SetPrimarySimSlot(slot uint32) error
+
+	// Same as SetPrimarySimSlot, but the call is canceled when ctx is done
+	SetPrimarySimSlotWithContext(ctx context.Context, slot uint32) error
+
+	// Retrieve the list of serving and neighboring cells, as reported by the device. The cells are typed by
+	// cell type, see CellInfo. Cells of an unknown type are skipped.
+	// 		OUT aa{sv} cell_info: an array of dictionaries with the information of each cell.
+	GetCellInfo() ([]CellInfo, error)
+
+	// Same as GetCellInfo, but the call is canceled when ctx is done
+	GetCellInfoWithContext(ctx context.Context) ([]CellInfo, error)
+
 	/* PROPERTIES */
 
 	// The path of the SIM object available in this device, if any.
 	GetSim() (Sim, error)
 
+	// The list of SIM slots available in the system, including the SIM object paths if the cards are present.
+	// If a given SIM slot at a given index doesn't have a SIM card available, the entry is nil.
+	// The list is empty if the modem doesn't support multiple SIM slots.
+	GetSimSlots() ([]Sim, error)
+
+	// The index of the primary active SIM slot in the "SimSlots" array, given in the [1,N] range.
+	// If multiple SIM slots aren't supported, this property will report value 0.
+	GetPrimarySimSlot() (uint32, error)
+
 	// The list of bearer object paths (EPS Bearers, PDP Contexts, or CDMA2000 Packet Data Sessions) as requested by the user.
 	// This list does not include the initial EPS bearer details (see "InitialEpsBearer").
 	GetBearers() ([]Bearer, error)
@@ -312,9 +374,14 @@ func NewModem(objectPath dbus.ObjectPath) (Modem, error) {
 	return &m, m.init(ModemManagerInterface, objectPath)
 }
 
+// NewModemWithConn returns new Modem Interface using the given dbus connection
+func NewModemWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (Modem, error) {
+	var m modem
+	return &m, m.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type modem struct {
 	dbusBase
-	sigChan chan *dbus.Signal
 }
 
 // Represents the modem port (name and type)
@@ -349,52 +416,68 @@ func (m modem) GetObjectPath() dbus.ObjectPath {
 	return m.obj.Path()
 }
 func (m modem) GetSimpleModem() (ModemSimple, error) {
-	return NewModemSimple(m.obj.Path())
+	return NewModemSimpleWithConn(m.conn, m.obj.Path())
 }
 func (m modem) Get3gpp() (Modem3gpp, error) {
-	return NewModem3gpp(m.obj.Path())
+	return NewModem3gppWithConn(m.conn, m.obj.Path())
 }
 func (m modem) GetCdma() (ModemCdma, error) {
-	return NewModemCdma(m.obj.Path())
+	return NewModemCdmaWithConn(m.conn, m.obj.Path())
 }
 
 func (m modem) GetTime() (ModemTime, error) {
-	return NewModemTime(m.obj.Path())
+	return NewModemTimeWithConn(m.conn, m.obj.Path())
 }
 
 func (m modem) GetFirmware() (ModemFirmware, error) {
-	return NewModemFirmware(m.obj.Path())
+	return NewModemFirmwareWithConn(m.conn, m.obj.Path())
 }
 
 func (m modem) GetSignal() (ModemSignal, error) {
-	return NewModemSignal(m.obj.Path())
+	return NewModemSignalWithConn(m.conn, m.obj.Path())
 }
 
 func (m modem) GetOma() (ModemOma, error) {
-	return NewModemOma(m.obj.Path())
+	return NewModemOmaWithConn(m.conn, m.obj.Path())
+}
+
+func (m modem) GetSar() (ModemSar, error) {
+	return NewModemSarWithConn(m.conn, m.obj.Path())
 }
 
 func (m modem) GetLocation() (ModemLocation, error) {
-	return NewModemLocation(m.obj.Path())
+	return NewModemLocationWithConn(m.conn, m.obj.Path())
 }
 func (m modem) GetMessaging() (ModemMessaging, error) {
-	return NewModemMessaging(m.obj.Path())
+	return NewModemMessagingWithConn(m.conn, m.obj.Path())
 }
 func (m modem) GetVoice() (ModemVoice, error) {
-	return NewModemVoice(m.obj.Path(), m)
+	return NewModemVoiceWithConn(m.conn, m.obj.Path(), m)
 }
 
 func (m modem) Enable() error {
-	err := m.call(ModemEnable, true)
+	return m.EnableWithContext(context.Background())
+}
+
+func (m modem) EnableWithContext(ctx context.Context) error {
+	err := m.callContext(ctx, ModemEnable, true)
 	return err
 }
 
 func (m modem) Disable() error {
-	err := m.call(ModemDisable, false)
+	return m.DisableWithContext(context.Background())
+}
+
+func (m modem) DisableWithContext(ctx context.Context) error {
+	err := m.callContext(ctx, ModemDisable, false)
 	return err
 }
 
 func (m modem) CreateBearer(property BearerProperty) (Bearer, error) {
+	return m.CreateBearerWithContext(context.Background(), property)
+}
+
+func (m modem) CreateBearerWithContext(ctx context.Context, property BearerProperty) (Bearer, error) {
 	// todo: untested
 	v := reflect.ValueOf(property)
 	st := reflect.TypeOf(property)
@@ -410,54 +493,113 @@ func (m modem) CreateBearer(property BearerProperty) (Bearer, error) {
 		myMap[tag] = value
 	}
 	var path dbus.ObjectPath
-	err := m.callWithReturn(&path, ModemCreateBearer, &myMap)
+	err := m.callWithReturnContext(ctx, &path, ModemCreateBearer, &myMap)
 	if err != nil {
 		return nil, err
 	}
-	return NewBearer(path)
+	return NewBearerWithConn(m.conn, path)
 }
 
 func (m modem) DeleteBearer(bearer Bearer) error {
+	return m.DeleteBearerWithContext(context.Background(), bearer)
+}
+
+func (m modem) DeleteBearerWithContext(ctx context.Context, bearer Bearer) error {
 	// todo: untested
-	return m.call(ModemDeleteBearer, bearer.GetObjectPath())
+	return m.callContext(ctx, ModemDeleteBearer, bearer.GetObjectPath())
 }
 
 func (m modem) Reset() error {
-	return m.call(ModemReset)
+	return m.ResetWithContext(context.Background())
+}
+
+func (m modem) ResetWithContext(ctx context.Context) error {
+	return m.callContext(ctx, ModemReset)
 }
 
 func (m modem) FactoryReset(code string) error {
+	return m.FactoryResetWithContext(context.Background(), code)
+}
+
+func (m modem) FactoryResetWithContext(ctx context.Context, code string) error {
 	// todo: untested
-	return m.call(ModemFactoryReset, code)
+	return m.callContext(ctx, ModemFactoryReset, code)
 }
 
 func (m modem) SetPowerState(state MMModemPowerState) error {
+	return m.SetPowerStateWithContext(context.Background(), state)
+}
+
+func (m modem) SetPowerStateWithContext(ctx context.Context, state MMModemPowerState) error {
 	// handle with care ...
-	return m.call(ModemSetPowerState, state)
+	return m.callContext(ctx, ModemSetPowerState, state)
 }
 
 func (m modem) SetCurrentCapabilities(capabilities []MMModemCapability) error {
+	return m.SetCurrentCapabilitiesWithContext(context.Background(), capabilities)
+}
+
+func (m modem) SetCurrentCapabilitiesWithContext(ctx context.Context, capabilities []MMModemCapability) error {
 	// todo: untested
 	var caps MMModemCapability
-	err := m.call(ModemSetCurrentCapabilities, caps.SliceToBitmask(capabilities))
+	err := m.callContext(ctx, ModemSetCurrentCapabilities, caps.SliceToBitmask(capabilities))
 	return err
 }
 
 func (m modem) SetCurrentModes(property Mode) error {
+	return m.SetCurrentModesWithContext(context.Background(), property)
+}
+
+func (m modem) SetCurrentModesWithContext(ctx context.Context, property Mode) error {
 	// todo: untested
 	var mode MMModemMode
 	var resSlice = []uint32{mode.SliceToBitmask(property.AllowedModes),
 		mode.SliceToBitmask([]MMModemMode{property.PreferredMode})}
-	return m.call(ModemSetCurrentModes, resSlice)
+	return m.callContext(ctx, ModemSetCurrentModes, resSlice)
 }
 
 func (m modem) SetCurrentBands(bands []MMModemBand) error {
+	return m.SetCurrentBandsWithContext(context.Background(), bands)
+}
+
+func (m modem) SetCurrentBandsWithContext(ctx context.Context, bands []MMModemBand) error {
 	// todo: untested
-	return m.call(ModemSetCurrentBands, bands)
+	return m.callContext(ctx, ModemSetCurrentBands, bands)
 }
 
 func (m modem) Command(cmd string, timeout uint32) (response string, err error) {
-	err = m.callWithReturn(&response, ModemCommand, cmd, timeout)
+	return m.CommandWithContext(context.Background(), cmd, timeout)
+}
+
+func (m modem) CommandWithContext(ctx context.Context, cmd string, timeout uint32) (response string, err error) {
+	err = m.callWithReturnContext(ctx, &response, ModemCommand, cmd, timeout)
+	return
+}
+
+func (m modem) SetPrimarySimSlot(slot uint32) error {
+	return m.SetPrimarySimSlotWithContext(context.Background(), slot)
+}
+
+func (m modem) SetPrimarySimSlotWithContext(ctx context.Context, slot uint32) error {
+	return m.callContext(ctx, ModemSetPrimarySimSlot, slot)
+}
+
+func (m modem) GetCellInfo() ([]CellInfo, error) {
+	return m.GetCellInfoWithContext(context.Background())
+}
+
+func (m modem) GetCellInfoWithContext(ctx context.Context) (cells []CellInfo, err error) {
+	var res []map[string]dbus.Variant
+	err = m.callWithReturnContext(ctx, &res, ModemGetCellInfo)
+	if err != nil {
+		return nil, err
+	}
+	for _, tmpMap := range res {
+		cell := parseCellInfo(tmpMap)
+		if cell != nil {
+			cells = append(cells, cell)
+		}
+	}
 	return
 }
 
@@ -466,7 +608,31 @@ func (m modem) GetSim() (Sim, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewSim(simPath)
+	return NewSimWithConn(m.conn, simPath)
+}
+
+func (m modem) GetSimSlots() ([]Sim, error) {
+	simPaths, err := m.getSliceObjectProperty(ModemPropertySimSlots)
+	if err != nil {
+		return nil, err
+	}
+	var sims []Sim
+	for _, simPath := range simPaths {
+		if simPath == "/" {
+			sims = append(sims, nil)
+			continue
+		}
+		sim, err := NewSimWithConn(m.conn, simPath)
+		if err != nil {
+			return nil, err
+		}
+		sims = append(sims, sim)
+	}
+	return sims, nil
+}
+
+func (m modem) GetPrimarySimSlot() (uint32, error) {
+	return m.getUint32Property(ModemPropertyPrimarySimSlot)
 }
 
 func (m modem) GetBearers() ([]Bearer, error) {
@@ -476,7 +642,7 @@ func (m modem) GetBearers() ([]Bearer, error) {
 	}
 	var bearers []Bearer
 	for idx := range bearerPaths {
-		bearer, err := NewBearer(bearerPaths[idx])
+		bearer, err := NewBearerWithConn(m.conn, bearerPaths[idx])
 		if err != nil {
 			return nil, err
 		}
@@ -717,14 +883,7 @@ func (m modem) GetSupportedIpFamilies() ([]MMBearerIpFamily, error) {
 }
 
 func (m modem) SubscribeStateChanged() <-chan *dbus.Signal {
-	if m.sigChan != nil {
-		return m.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", ModemSignalStateChanged, fmt.Sprint(m.GetObjectPath()))
-	m.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	m.sigChan = make(chan *dbus.Signal, 10)
-	m.conn.Signal(m.sigChan)
-	return m.sigChan
+	return m.subscribeSignal(ModemInterface, ModemSignalStateChanged, m.GetObjectPath())
 }
 func (m modem) ParseStateChanged(v *dbus.Signal) (oldState MMModemState, newState MMModemState, reason MMModemStateChangeReason, err error) {
 	if len(v.Body) != 3 {
@@ -755,22 +914,14 @@ func (m modem) ParseStateChanged(v *dbus.Signal) (oldState MMModemState, newStat
 	return
 }
 func (m modem) SubscribePropertiesChanged() <-chan *dbus.Signal {
-	if m.sigChan != nil {
-		return m.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", dbusPropertiesChanged, fmt.Sprint(m.GetObjectPath()))
-	m.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	m.sigChan = make(chan *dbus.Signal, 10)
-	m.conn.Signal(m.sigChan)
-	return m.sigChan
+	return m.subscribeSignal(dbusPropertiesInterface, dbusPropertiesChanged, m.GetObjectPath())
 }
 func (m modem) ParsePropertiesChanged(v *dbus.Signal) (interfaceName string, changedProperties map[string]dbus.Variant, invalidatedProperties []string, err error) {
 	return m.parsePropertiesChanged(v)
 }
 
 func (m modem) Unsubscribe() {
-	m.conn.RemoveSignal(m.sigChan)
-	m.sigChan = nil
+	m.unsubscribeSignals()
 }
 
 func (m modem) MarshalJSON() ([]byte, error) {
@@ -1,11 +1,13 @@
 package modemmanager
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/godbus/dbus/v5"
 	"reflect"
+	"sync"
 	"time"
 )
 
@@ -18,17 +20,24 @@ const (
 	Modem3gppScan                        = Modem3gppInterface + ".Scan"
 	Modem3gppSetEpsUeModeOperation       = Modem3gppInterface + ".SetEpsUeModeOperation"
 	Modem3gppSetInitialEpsBearerSettings = Modem3gppInterface + ".SetInitialEpsBearerSettings"
+	Modem3gppSetNr5gRegistrationSettings = Modem3gppInterface + ".SetNr5gRegistrationSettings"
+	Modem3gppSetPacketServiceState       = Modem3gppInterface + ".SetPacketServiceState"
+	Modem3gppDisableFacilityLock         = Modem3gppInterface + ".DisableFacilityLock"
+	Modem3gppSetCarrierLock              = Modem3gppInterface + ".SetCarrierLock"
 	/* Property */
-	Modem3gppPropertyImei                 = Modem3gppInterface + ".Imei"                 // readable   s
-	Modem3gppPropertyRegistrationState    = Modem3gppInterface + ".RegistrationState"    // readable   u
-	Modem3gppPropertyOperatorCode         = Modem3gppInterface + ".OperatorCode"         // readable   s
-	Modem3gppPropertyOperatorName         = Modem3gppInterface + ".OperatorName"         // readable   s
-	Modem3gppPropertyEnabledFacilityLocks = Modem3gppInterface + ".EnabledFacilityLocks" // readable   u
-	// Deprecated Modem3gppPropertySubscriptionState        = Modem3gppInterface + ".SubscriptionState "       // readable   u
+	Modem3gppPropertyImei                     = Modem3gppInterface + ".Imei"                     // readable   s
+	Modem3gppPropertyRegistrationState        = Modem3gppInterface + ".RegistrationState"        // readable   u
+	Modem3gppPropertyOperatorCode             = Modem3gppInterface + ".OperatorCode"             // readable   s
+	Modem3gppPropertyOperatorName             = Modem3gppInterface + ".OperatorName"             // readable   s
+	Modem3gppPropertyEnabledFacilityLocks     = Modem3gppInterface + ".EnabledFacilityLocks"     // readable   u
+	Modem3gppPropertySubscriptionState        = Modem3gppInterface + ".SubscriptionState"        // readable   u
 	Modem3gppPropertyEpsUeModeOperation       = Modem3gppInterface + ".EpsUeModeOperation"       // readable   u
 	Modem3gppPropertyPco                      = Modem3gppInterface + ".Pco"                      // readable   a(ubay)
 	Modem3gppPropertyInitialEpsBearer         = Modem3gppInterface + ".InitialEpsBearer"         // readable   o
 	Modem3gppPropertyInitialEpsBearerSettings = Modem3gppInterface + ".InitialEpsBearerSettings" // readable   a{sv}
+	Modem3gppPropertyNr5gRegistrationSettings = Modem3gppInterface + ".Nr5gRegistrationSettings" // readable   a{sv}
+	Modem3gppPropertyPacketServiceState       = Modem3gppInterface + ".PacketServiceState"       // readable   u
+	Modem3gppPropertyNetworkRejection         = Modem3gppInterface + ".NetworkRejection"         // readable   a{sv}
 )
 
 // Modem3gpp interface provides access to specific actions that may be performed in modems with 3GPP capabilities.
@@ -43,25 +52,75 @@ type Modem3gpp interface {
 	// Returns the Ussd Interface
 	GetUssd() (Ussd, error)
 
+	// Returns the ProfileManager Interface
+	GetProfileManager() (ProfileManager, error)
+
 	// The operator ID (ie, "MCCMNC", like "310260") to register. An empty string can be used to register to the home network.
 	Register(operatorId string) error
 
+	// Same as Register, but the call is canceled when ctx is done
+	RegisterWithContext(ctx context.Context, operatorId string) error
+
 	// results is an array of dictionaries with each array element describing a mobile network found in the scan.
 	// takes up to 1 min
 	Scan() (networks []Network3Gpp, err error)
 
-	// Request a network scan (async)
-	RequestScan()
+	// Same as Scan, but the call is canceled when ctx is done
+	ScanWithContext(ctx context.Context) (networks []Network3Gpp, err error)
+
+	// Request a network scan (async). The returned handle delivers the result of the scan or the error.
+	// If a scan of the modem is still running, the request waits for its result instead of starting a new scan.
+	RequestScan() *ScanRequest
 
-	// Get latest scan result
+	// Same as RequestScan, but the request is canceled when ctx is done
+	RequestScanWithContext(ctx context.Context) *ScanRequest
+
+	// Get latest scan result of the modem, the results are updated by Scan and RequestScan.
+	// The results are kept by the Modem3gpp object and shared by all Modem3gpp objects returned by Modem.Get3gpp
+	// of the same Modem object.
 	GetScanResults() (NetworkScanResult, error)
 
 	// Sets the UE mode of operation for EPS.
 	SetEpsUeModeOperation(mode MMModem3gppEpsUeModeOperation) error
 
+	// Same as SetEpsUeModeOperation, but the call is canceled when ctx is done
+	SetEpsUeModeOperationWithContext(ctx context.Context, mode MMModem3gppEpsUeModeOperation) error
+
 	// Updates the default settings to be used in the initial default EPS bearer when registering to the LTE network.
 	SetInitialEpsBearerSettings(property BearerProperty) error
 
+	// Same as SetInitialEpsBearerSettings, but the call is canceled when ctx is done
+	SetInitialEpsBearerSettingsWithContext(ctx context.Context, property BearerProperty) error
+
+	// Updates the 5GNR specific registration settings configured in the device.
+	// Settings with an unknown value are not changed.
+	SetNr5gRegistrationSettings(settings Nr5gRegistrationSettings) error
+
+	// Same as SetNr5gRegistrationSettings, but the call is canceled when ctx is done
+	SetNr5gRegistrationSettingsWithContext(ctx context.Context, settings Nr5gRegistrationSettings) error
+
+	// Explicitly attach or detach packet service on the current registered network.
+	// 		IN u state: a MMModem3gppPacketServiceState, either attached or detached.
+	SetPacketServiceState(state MMModem3gppPacketServiceState) error
+
+	// Same as SetPacketServiceState, but the call is canceled when ctx is done
+	SetPacketServiceStateWithContext(ctx context.Context, state MMModem3gppPacketServiceState) error
+
+	// Sends control key to modem to disable selected facility lock, e.g. to remove a PH-SIM or network personalization lock.
+	// 		IN (us) properties: a MMModem3gppFacility value representing the type of the facility lock,
+	//		and the control key string used to disable it.
+	DisableFacilityLock(facility MMModem3gppFacility, controlKey string) error
+
+	// Same as DisableFacilityLock, but the call is canceled when ctx is done
+	DisableFacilityLockWithContext(ctx context.Context, facility MMModem3gppFacility, controlKey string) error
+
+	// Sends the carrier lock information to the modem.
+	// 		IN ay data: the serialized carrier lock configuration, which is specific to the device.
+	SetCarrierLock(data []byte) error
+
+	// Same as SetCarrierLock, but the call is canceled when ctx is done
+	SetCarrierLockWithContext(ctx context.Context, data []byte) error
+
 	/* PROPERTIES */
 
 	// The IMEI of the device.
@@ -86,6 +145,10 @@ type Modem3gpp interface {
 	// Bitmask of MMModem3gppFacility values for which PIN locking is enabled.
 	GetEnabledFacilityLocks() ([]MMModem3gppFacility, error)
 
+	// A MMModem3gppSubscriptionState value representing the subscription status of the account and whether there is any data remaining, given as an unsigned integer (signature "u").
+	// Deprecated: only available in a few devices (e.g. Altair LTE), MMModem3gppRegistrationState should be used instead.
+	GetSubscriptionState() (MMModem3gppSubscriptionState, error)
+
 	// A MMModem3gppEpsUeModeOperation value representing the UE mode of operation for EPS, given as an unsigned integer (signature "u").
 	GetEpsUeModeOperation() (MMModem3gppEpsUeModeOperation, error)
 
@@ -105,16 +168,32 @@ type Modem3gpp interface {
 	// This is a read-only property, updating these settings should be done using the SetInitialEpsBearerSettings() method.
 	GetInitialEpsBearerSettings() (property BearerProperty, err error)
 
+	// The 5GNR specific registration settings configured in the device, i.e. the MICO mode and the DRX cycle.
+	// This is a read-only property, updating these settings should be done using the SetNr5gRegistrationSettings() method.
+	GetNr5gRegistrationSettings() (Nr5gRegistrationSettings, error)
+
+	// A MMModem3gppPacketServiceState value specifying the packet domain service state.
+	GetPacketServiceState() (MMModem3gppPacketServiceState, error)
+
+	// Detailed information about the last network rejection of the registration attempt, i.e. the network error
+	// cause and the operator that rejected it.
+	GetNetworkRejection() (NetworkRejection, error)
+
 	MarshalJSON() ([]byte, error)
 }
 
 // NewModem3gpp returns new Modem3gppInterface
 func NewModem3gpp(objectPath dbus.ObjectPath) (Modem3gpp, error) {
 	var m3gpp modem3gpp
-	scanResults = NetworkScanResult{Recent: false}
 	return &m3gpp, m3gpp.init(ModemManagerInterface, objectPath)
 }
 
+// NewModem3gppWithConn returns new Modem3gppInterface using the given dbus connection
+func NewModem3gppWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (Modem3gpp, error) {
+	var m3gpp modem3gpp
+	return &m3gpp, m3gpp.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type modem3gpp struct {
 	dbusBase
 }
@@ -144,6 +223,88 @@ func (nsr NetworkScanResult) String() string {
 		", Recent: " + fmt.Sprint(nsr.Recent)
 }
 
+// ScanRequest is the handle of an asynchronous network scan. Concurrent requests of a modem share the running scan,
+// but each request is canceled on its own.
+type ScanRequest struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	result NetworkScanResult
+	err    error
+}
+
+// Done returns a channel which is closed when the scan has finished, failed or the request was canceled
+func (r *ScanRequest) Done() <-chan struct{} {
+	return r.done
+}
+
+// Cancel stops waiting for the scan results, Result returns context.Canceled then. The call of the scan is canceled
+// once all requests waiting for it are canceled, the scan of the modem itself keeps running until completion.
+func (r *ScanRequest) Cancel() {
+	r.cancel()
+}
+
+// Result waits until the scan has finished and returns its result
+func (r *ScanRequest) Result() (NetworkScanResult, error) {
+	<-r.done
+	return r.result, r.err
+}
+
+// scanState holds the latest result and the running scan of a modem
+type scanState struct {
+	mu      sync.Mutex
+	result  NetworkScanResult
+	scanned bool
+	running *scanRun
+}
+
+// scanKey identifies a modem by its connection and object path
+type scanKey struct {
+	conn *dbus.Conn
+	path dbus.ObjectPath
+}
+
+// scanStates holds the scan state of each modem, shared by all Modem3gpp objects of the modem
+var scanStates = struct {
+	sync.Mutex
+	states map[scanKey]*scanState
+}{states: make(map[scanKey]*scanState)}
+
+// scans returns the scan state of the modem
+func (m modem3gpp) scans() *scanState {
+	key := scanKey{conn: m.conn, path: m.obj.Path()}
+	scanStates.Lock()
+	defer scanStates.Unlock()
+	s, ok := scanStates.states[key]
+	if !ok {
+		s = &scanState{}
+		scanStates.states[key] = s
+	}
+	return s
+}
+
+// scanRun is a running scan, which is shared by all requests waiting for it
+type scanRun struct {
+	cancel  context.CancelFunc
+	done    chan struct{}
+	result  NetworkScanResult
+	err     error
+	waiters int
+}
+
+// leave removes a canceled request from the run, the run is canceled if no request is left
+func (s *scanState) leave(run *scanRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run.waiters--
+	if run.waiters > 0 {
+		return
+	}
+	run.cancel()
+	if s.running == run {
+		s.running = nil
+	}
+}
+
 // Network3Gpp describes a mobile network found in the scan
 type Network3Gpp struct {
 	Status           MMModem3gppNetworkAvailability `json:"status"`         // A MMModem3gppNetworkAvailability value representing network availability status, given as an unsigned integer (signature "u"). This key will always be present.
@@ -193,27 +354,91 @@ func (r RawPcoData) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// Nr5gRegistrationSettings represents the 5GNR specific registration settings
+type Nr5gRegistrationSettings struct {
+	MicoMode MMModem3gppMicoMode `json:"mico-mode"` // Mobile initiated connection only mode, given as a MMModem3gppMicoMode value (signature "u").
+	DrxCycle MMModem3gppDrxCycle `json:"drx-cycle"` // Discontinuous reception cycle, given as a MMModem3gppDrxCycle value (signature "u").
+}
+
+// MarshalJSON returns a byte array
+func (n Nr5gRegistrationSettings) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"MicoMode": fmt.Sprint(n.MicoMode),
+		"DrxCycle": fmt.Sprint(n.DrxCycle),
+	})
+}
+
+func (n Nr5gRegistrationSettings) String() string {
+	return "MicoMode: " + fmt.Sprint(n.MicoMode) +
+		", DrxCycle: " + fmt.Sprint(n.DrxCycle)
+}
+
+// NetworkRejection describes the last registration rejection received from the network
+type NetworkRejection struct {
+	Error            MMNetworkError          `json:"error"`             // The network error cause of the rejection, given as a MMNetworkError value (signature "u").
+	OperatorId       string                  `json:"operator-id"`       // Operator id ("MCCMNC") of the network that rejected the registration, given as a string value (signature "s").
+	OperatorName     string                  `json:"operator-name"`     // Name of the operator that rejected the registration, given as a string value (signature "s").
+	AccessTechnology MMModemAccessTechnology `json:"access-technology"` // The access technology used in the rejected attempt, given as a MMModemAccessTechnology value (signature "u").
+}
+
+// MarshalJSON returns a byte array
+func (n NetworkRejection) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"Error":            fmt.Sprint(n.Error),
+		"OperatorId":       n.OperatorId,
+		"OperatorName":     n.OperatorName,
+		"AccessTechnology": fmt.Sprint(n.AccessTechnology),
+	})
+}
+
+func (n NetworkRejection) String() string {
+	return "Error: " + fmt.Sprint(n.Error) +
+		", OperatorId: " + n.OperatorId +
+		", OperatorName: " + n.OperatorName +
+		", AccessTechnology: " + fmt.Sprint(n.AccessTechnology)
+}
+
 func (m modem3gpp) GetObjectPath() dbus.ObjectPath {
 	return m.obj.Path()
 }
 
 func (m modem3gpp) GetUssd() (Ussd, error) {
-	return NewUssd(m.obj.Path())
+	return NewUssdWithConn(m.conn, m.obj.Path())
+}
+
+func (m modem3gpp) GetProfileManager() (ProfileManager, error) {
+	return NewProfileManagerWithConn(m.conn, m.obj.Path())
 }
 
 func (m modem3gpp) Register(operatorId string) error {
-	return m.call(Modem3gppRegister, operatorId)
+	return m.RegisterWithContext(context.Background(), operatorId)
 }
 
-var scanResults NetworkScanResult
+func (m modem3gpp) RegisterWithContext(ctx context.Context, operatorId string) error {
+	return m.callContext(ctx, Modem3gppRegister, operatorId)
+}
 
 func (m modem3gpp) Scan() (networks []Network3Gpp, err error) {
+	return m.ScanWithContext(context.Background())
+}
+
+func (m modem3gpp) ScanWithContext(ctx context.Context) (networks []Network3Gpp, err error) {
+	res, err := m.scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Networks, nil
+}
+
+// scan runs a network scan and stores the result as latest result of the modem
+func (m modem3gpp) scan(ctx context.Context) (res NetworkScanResult, err error) {
 	// takes < 1min
 	start := time.Now()
+	var networks []Network3Gpp
 	var tmpRes interface{}
-	err = m.callWithReturn(&tmpRes, Modem3gppScan)
+	err = m.callWithReturnContext(ctx, &tmpRes, Modem3gppScan)
 	if err != nil {
-		return nil, err
+		return res, err
 	}
 	scanResMap, ok := tmpRes.([]map[string]dbus.Variant)
 	if ok {
@@ -260,37 +485,80 @@ func (m modem3gpp) Scan() (networks []Network3Gpp, err error) {
 		}
 	}
 	duration := time.Since(start).Seconds()
-	scanResults = NetworkScanResult{Recent: true, LastScan: time.Now(), ScanDuration: duration, Networks: networks}
+	res = NetworkScanResult{Recent: true, LastScan: time.Now(), ScanDuration: duration, Networks: networks}
+	s := m.scans()
+	s.mu.Lock()
+	s.result = res
+	s.scanned = true
+	s.mu.Unlock()
+	return res, nil
+}
 
-	return networks, nil
+func (m modem3gpp) RequestScan() *ScanRequest {
+	return m.RequestScanWithContext(context.Background())
 }
 
-func (m modem3gpp) RequestScan() {
+func (m modem3gpp) RequestScanWithContext(ctx context.Context) *ScanRequest {
+	ctx, cancel := context.WithCancel(ctx)
+	req := &ScanRequest{cancel: cancel, done: make(chan struct{})}
+	s := m.scans()
+	s.mu.Lock()
+	run := s.running
+	if run == nil {
+		runCtx, runCancel := context.WithCancel(context.Background())
+		run = &scanRun{cancel: runCancel, done: make(chan struct{})}
+		s.running = run
+		go func() {
+			defer runCancel()
+			run.result, run.err = m.scan(runCtx)
+			s.mu.Lock()
+			if s.running == run {
+				s.running = nil
+			}
+			s.mu.Unlock()
+			close(run.done)
+		}()
+	}
+	run.waiters++
+	s.mu.Unlock()
 	go func() {
-		res, err := m.Scan()
-		if err == nil {
-			fmt.Println("scan done, found ", len(res), " networks.")
-		} else {
-			fmt.Println("error during scanning, ", err.Error())
+		defer cancel()
+		select {
+		case <-run.done:
+			req.result, req.err = run.result, run.err
+		case <-ctx.Done():
+			req.err = ctx.Err()
+			s.leave(run)
 		}
-
+		close(req.done)
 	}()
+	return req
 }
 
 func (m modem3gpp) GetScanResults() (res NetworkScanResult, err error) {
-	if scanResults.Recent {
-		return scanResults, nil
+	s := m.scans()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.scanned {
+		return res, errors.New("no recent scans")
 	}
-	return res, errors.New("no recent scans")
-
+	return s.result, nil
 }
 
 func (m modem3gpp) SetEpsUeModeOperation(mode MMModem3gppEpsUeModeOperation) error {
+	return m.SetEpsUeModeOperationWithContext(context.Background(), mode)
+}
+
+func (m modem3gpp) SetEpsUeModeOperationWithContext(ctx context.Context, mode MMModem3gppEpsUeModeOperation) error {
 	// todo untested
-	return m.call(Modem3gppSetEpsUeModeOperation, mode)
+	return m.callContext(ctx, Modem3gppSetEpsUeModeOperation, mode)
 }
 
 func (m modem3gpp) SetInitialEpsBearerSettings(property BearerProperty) error {
+	return m.SetInitialEpsBearerSettingsWithContext(context.Background(), property)
+}
+
+func (m modem3gpp) SetInitialEpsBearerSettingsWithContext(ctx context.Context, property BearerProperty) error {
 	// todo untested
 	v := reflect.ValueOf(property)
 	st := reflect.TypeOf(property)
@@ -307,10 +575,53 @@ func (m modem3gpp) SetInitialEpsBearerSettings(property BearerProperty) error {
 		}
 		myMap[tag] = value
 	}
-	return m.call(Modem3gppSetInitialEpsBearerSettings, &myMap)
+	return m.callContext(ctx, Modem3gppSetInitialEpsBearerSettings, &myMap)
 
 }
 
+func (m modem3gpp) SetNr5gRegistrationSettings(settings Nr5gRegistrationSettings) error {
+	return m.SetNr5gRegistrationSettingsWithContext(context.Background(), settings)
+}
+
+func (m modem3gpp) SetNr5gRegistrationSettingsWithContext(ctx context.Context, settings Nr5gRegistrationSettings) error {
+	myMap := make(map[string]interface{})
+	if settings.MicoMode != MmModem3gppMicoModeUnknown {
+		myMap["mico-mode"] = settings.MicoMode
+	}
+	if settings.DrxCycle != MmModem3gppDrxCycleUnknown {
+		myMap["drx-cycle"] = settings.DrxCycle
+	}
+	return m.callContext(ctx, Modem3gppSetNr5gRegistrationSettings, &myMap)
+}
+
+func (m modem3gpp) SetPacketServiceState(state MMModem3gppPacketServiceState) error {
+	return m.SetPacketServiceStateWithContext(context.Background(), state)
+}
+
+func (m modem3gpp) SetPacketServiceStateWithContext(ctx context.Context, state MMModem3gppPacketServiceState) error {
+	return m.callContext(ctx, Modem3gppSetPacketServiceState, state)
+}
+
+func (m modem3gpp) DisableFacilityLock(facility MMModem3gppFacility, controlKey string) error {
+	return m.DisableFacilityLockWithContext(context.Background(), facility, controlKey)
+}
+
+func (m modem3gpp) DisableFacilityLockWithContext(ctx context.Context, facility MMModem3gppFacility, controlKey string) error {
+	properties := struct {
+		Facility   uint32
+		ControlKey string
+	}{uint32(facility), controlKey}
+	return m.callContext(ctx, Modem3gppDisableFacilityLock, properties)
+}
+
+func (m modem3gpp) SetCarrierLock(data []byte) error {
+	return m.SetCarrierLockWithContext(context.Background(), data)
+}
+
+func (m modem3gpp) SetCarrierLockWithContext(ctx context.Context, data []byte) error {
+	return m.callContext(ctx, Modem3gppSetCarrierLock, data)
+}
+
 func (m modem3gpp) GetImei() (string, error) {
 	return m.getStringProperty(Modem3gppPropertyImei)
 }
@@ -367,6 +678,14 @@ func (m modem3gpp) GetEnabledFacilityLocks() ([]MMModem3gppFacility, error) {
 	return fac.BitmaskToSlice(res), nil
 }
 
+func (m modem3gpp) GetSubscriptionState() (MMModem3gppSubscriptionState, error) {
+	res, err := m.getUint32Property(Modem3gppPropertySubscriptionState)
+	if err != nil {
+		return MmModem3gppSubscriptionStateUnknown, err
+	}
+	return MMModem3gppSubscriptionState(res), nil
+}
+
 func (m modem3gpp) GetEpsUeModeOperation() (MMModem3gppEpsUeModeOperation, error) {
 	res, err := m.getUint32Property(Modem3gppPropertyEpsUeModeOperation)
 	if err != nil {
@@ -412,7 +731,7 @@ func (m modem3gpp) GetInitialEpsBearer() (Bearer, error) {
 	if fmt.Sprint(path) == "/" {
 		return nil, errors.New("no initial bearer")
 	}
-	return NewBearer(path)
+	return NewBearerWithConn(m.conn, path)
 }
 
 func (m modem3gpp) GetInitialEpsBearerSettings() (property BearerProperty, err error) {
@@ -457,6 +776,68 @@ func (m modem3gpp) GetInitialEpsBearerSettings() (property BearerProperty, err e
 	return property, nil
 }
 
+func (m modem3gpp) GetNr5gRegistrationSettings() (settings Nr5gRegistrationSettings, err error) {
+	tmpRes, err := m.getMapStringVariantProperty(Modem3gppPropertyNr5gRegistrationSettings)
+	if err != nil {
+		return settings, err
+	}
+	for key, element := range tmpRes {
+		switch key {
+		case "mico-mode":
+			tmpValue, ok := element.Value().(uint32)
+			if ok {
+				settings.MicoMode = MMModem3gppMicoMode(tmpValue)
+			}
+		case "drx-cycle":
+			tmpValue, ok := element.Value().(uint32)
+			if ok {
+				settings.DrxCycle = MMModem3gppDrxCycle(tmpValue)
+			}
+		}
+	}
+	return settings, nil
+}
+
+func (m modem3gpp) GetPacketServiceState() (MMModem3gppPacketServiceState, error) {
+	res, err := m.getUint32Property(Modem3gppPropertyPacketServiceState)
+	if err != nil {
+		return MmModem3gppPacketServiceStateUnknown, err
+	}
+	return MMModem3gppPacketServiceState(res), nil
+}
+
+func (m modem3gpp) GetNetworkRejection() (rejection NetworkRejection, err error) {
+	tmpRes, err := m.getMapStringVariantProperty(Modem3gppPropertyNetworkRejection)
+	if err != nil {
+		return rejection, err
+	}
+	for key, element := range tmpRes {
+		switch key {
+		case "error":
+			tmpValue, ok := element.Value().(uint32)
+			if ok {
+				rejection.Error = MMNetworkError(tmpValue)
+			}
+		case "operator-id":
+			tmpValue, ok := element.Value().(string)
+			if ok {
+				rejection.OperatorId = tmpValue
+			}
+		case "operator-name":
+			tmpValue, ok := element.Value().(string)
+			if ok {
+				rejection.OperatorName = tmpValue
+			}
+		case "access-technology":
+			tmpValue, ok := element.Value().(uint32)
+			if ok {
+				rejection.AccessTechnology = MMModemAccessTechnology(tmpValue)
+			}
+		}
+	}
+	return rejection, nil
+}
+
 func (m modem3gpp) MarshalJSON() ([]byte, error) {
 	imei, err := m.GetImei()
 	if err != nil {
@@ -482,24 +863,21 @@ func (m modem3gpp) MarshalJSON() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	// the initial eps bearer and its settings are optional, they are left empty if not available
 	initialEpsBearerJson := []byte("")
 	initialEpsBearer, err := m.GetInitialEpsBearer()
-	if err != nil {
-		fmt.Println(err.Error())
-	} else {
-		initialEpsBearerJson, err = initialEpsBearer.MarshalJSON()
-		if err != nil {
-			fmt.Println(err.Error())
+	if err == nil {
+		tmpJson, err := initialEpsBearer.MarshalJSON()
+		if err == nil {
+			initialEpsBearerJson = tmpJson
 		}
 	}
 	initialEpsBearerSettingsJson := []byte("")
 	initialEpsBearerSettings, err := m.GetInitialEpsBearerSettings()
-	if err != nil {
-		fmt.Println(err.Error())
-	} else {
-		initialEpsBearerSettingsJson, err = initialEpsBearerSettings.MarshalJSON()
-		if err != nil {
-			fmt.Println(err.Error())
+	if err == nil {
+		tmpJson, err := initialEpsBearerSettings.MarshalJSON()
+		if err == nil {
+			initialEpsBearerSettingsJson = tmpJson
 		}
 	}
 
@@ -0,0 +1,350 @@
+package modemmanager_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+// blockScan lets the scans of the fake wait until the returned function is called
+func blockScan(srv *mmtest.Server, fake *mmtest.Modem) (release func()) {
+	released := make(chan struct{})
+	srv.OnCall(fake.GetObjectPath(), mm.Modem3gppScan, func(args ...interface{}) error {
+		<-released
+		return nil
+	})
+	var once sync.Once
+	return func() { once.Do(func() { close(released) }) }
+}
+
+// waitForCalls waits until the method was called n times
+func waitForCalls(t *testing.T, srv *mmtest.Server, method string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for countCalls(srv, method) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d calls of %s, want %d", countCalls(srv, method), method, n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func enabled3gpp(t *testing.T, modem mm.Modem) mm.Modem3gpp {
+	t.Helper()
+	if err := modem.Enable(); err != nil {
+		t.Fatal(err)
+	}
+	modem3gpp, err := modem.Get3gpp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return modem3gpp
+}
+
+var scanNetworks = []mm.Network3Gpp{
+	{Status: mm.MmModem3gppNetworkAvailabilityCurrent, OperatorLong: "Telekom.de", OperatorCode: "26201", AccessTechnology: mm.MmModemAccessTechnologyLte},
+	{Status: mm.MmModem3gppNetworkAvailabilityForbidden, OperatorLong: "Vodafone.de", OperatorCode: "26202", AccessTechnology: mm.MmModemAccessTechnologyUmts},
+}
+
+func TestModem3gppRequestScanShared(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{Networks: scanNetworks})
+	modem3gpp := enabled3gpp(t, modem)
+	release := blockScan(srv, fake)
+
+	first := modem3gpp.RequestScan()
+	waitForCalls(t, srv, mm.Modem3gppScan, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	second := modem3gpp.RequestScanWithContext(ctx)
+	third := modem3gpp.RequestScan()
+
+	// canceling one request does not affect the others waiting for the same scan
+	cancel()
+	if _, err := second.Result(); err != context.Canceled {
+		t.Errorf("canceled request returned %v, want context.Canceled", err)
+	}
+	third.Cancel()
+	if _, err := third.Result(); err != context.Canceled {
+		t.Errorf("canceled request returned %v, want context.Canceled", err)
+	}
+	select {
+	case <-first.Done():
+		t.Fatal("request done before the scan finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	res, err := first.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Networks) != 2 || res.Networks[1].OperatorCode != "26202" || res.Networks[1].Mnc != "02" {
+		t.Errorf("got networks %v, want the networks of the fake", res.Networks)
+	}
+	if n := countCalls(srv, mm.Modem3gppScan); n != 1 {
+		t.Errorf("got %d scans, want 1 shared scan", n)
+	}
+
+	// the results are kept for the modem, also for objects created later
+	again, err := mm.NewModem3gppWithConn(conn, fake.GetObjectPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := again.GetScanResults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(latest.Networks) != 2 || !latest.Recent {
+		t.Errorf("got latest results %s, want the results of the scan", latest)
+	}
+}
+
+func TestModem3gppScanSharedByModemObjects(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, _ := srv.AddModemT(t, conn, mmtest.ModemConfig{Networks: scanNetworks})
+	_, otherModem := srv.AddModemT(t, conn, mmtest.ModemConfig{Networks: scanNetworks})
+	var objects []mm.Modem3gpp
+	for i := 0; i < 2; i++ {
+		modem, err := mm.NewModemWithConn(conn, fake.GetObjectPath())
+		if err != nil {
+			t.Fatal(err)
+		}
+		objects = append(objects, enabled3gpp(t, modem))
+	}
+	release := blockScan(srv, fake)
+	defer release()
+
+	// the request of the second object joins the scan of the first one
+	first := objects[0].RequestScan()
+	waitForCalls(t, srv, mm.Modem3gppScan, 1)
+	second := objects[1].RequestScan()
+	release()
+	for _, req := range []*mm.ScanRequest{first, second} {
+		if res, err := req.Result(); err != nil || len(res.Networks) != 2 {
+			t.Errorf("got result %s, %v, want the networks of the fake", res, err)
+		}
+	}
+	if n := countCalls(srv, mm.Modem3gppScan); n != 1 {
+		t.Errorf("got %d scans, want 1 shared scan", n)
+	}
+	if latest, err := objects[1].GetScanResults(); err != nil || len(latest.Networks) != 2 {
+		t.Errorf("got latest results %s, %v, want the results of the shared scan", latest, err)
+	}
+
+	// the results of another modem are kept apart
+	other, err := otherModem.Get3gpp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.GetScanResults(); err == nil {
+		t.Error("got results of another modem")
+	}
+}
+
+func TestModem3gppRequestScanCanceled(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{Networks: scanNetworks})
+	modem3gpp := enabled3gpp(t, modem)
+	release := blockScan(srv, fake)
+	defer release()
+
+	canceled := modem3gpp.RequestScan()
+	waitForCalls(t, srv, mm.Modem3gppScan, 1)
+	canceled.Cancel()
+	if _, err := canceled.Result(); err != context.Canceled {
+		t.Errorf("canceled request returned %v, want context.Canceled", err)
+	}
+	// a scan without any waiting request is not joined by new requests
+	next := modem3gpp.RequestScan()
+	waitForCalls(t, srv, mm.Modem3gppScan, 2)
+	release()
+	if _, err := next.Result(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := modem3gpp.GetScanResults(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestModem3gppScanError(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	modem3gpp, err := modem.Get3gpp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the fake requires an enabled modem
+	if _, err := modem3gpp.RequestScan().Result(); err == nil {
+		t.Error("scan of a disabled modem succeeded")
+	}
+	if _, err := modem3gpp.GetScanResults(); err == nil {
+		t.Error("got results of a failed scan")
+	}
+}
+
+func TestModem3gppNr5gRegistrationSettings(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	modem3gpp, err := modem.Get3gpp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings, err := modem3gpp.GetNr5gRegistrationSettings(); err != nil || settings != (mm.Nr5gRegistrationSettings{}) {
+		t.Errorf("got settings %s, %v, want none", settings, err)
+	}
+	want := mm.Nr5gRegistrationSettings{MicoMode: mm.MmModem3gppMicoModeEnabled, DrxCycle: mm.MmModem3gppDrxCycle64}
+	if err := modem3gpp.SetNr5gRegistrationSettings(want); err != nil {
+		t.Fatal(err)
+	}
+	if settings, err := modem3gpp.GetNr5gRegistrationSettings(); err != nil || settings != want {
+		t.Errorf("got settings %s, %v, want %s", settings, err, want)
+	}
+
+	// settings with an unknown value are not sent and keep their value
+	srv.ResetCalls()
+	if err := modem3gpp.SetNr5gRegistrationSettings(mm.Nr5gRegistrationSettings{DrxCycle: mm.MmModem3gppDrxCycle256}); err != nil {
+		t.Fatal(err)
+	}
+	calls := srv.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("got calls %v, want 1", calls)
+	}
+	sent := calls[0].Args[0].(map[string]dbus.Variant)
+	if len(sent) != 1 || sent["drx-cycle"] != dbus.MakeVariant(uint32(mm.MmModem3gppDrxCycle256)) {
+		t.Errorf("got settings %v, want only the drx cycle", sent)
+	}
+	want.DrxCycle = mm.MmModem3gppDrxCycle256
+	if settings, err := modem3gpp.GetNr5gRegistrationSettings(); err != nil || settings != want {
+		t.Errorf("got settings %s, %v, want %s", settings, err, want)
+	}
+}
+
+func TestModem3gppPacketServiceState(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	modem3gpp, err := modem.Get3gpp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state, err := modem3gpp.GetPacketServiceState(); err != nil || state != mm.MmModem3gppPacketServiceStateAttached {
+		t.Errorf("got packet service state %s, %v, want attached", state, err)
+	}
+	if err := modem3gpp.SetPacketServiceState(mm.MmModem3gppPacketServiceStateDetached); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := modem3gpp.GetPacketServiceState(); err != nil || state != mm.MmModem3gppPacketServiceStateDetached {
+		t.Errorf("got packet service state %s, %v, want detached", state, err)
+	}
+	if err := modem3gpp.SetPacketServiceState(mm.MmModem3gppPacketServiceStateUnknown); !errors.Is(err, mm.MmCoreErrorInvalidArgs) {
+		t.Errorf("got error %v, want MmCoreErrorInvalidArgs", err)
+	}
+}
+
+func TestModem3gppDisableFacilityLock(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{
+		State: mm.MmModemStateRegistered,
+		FacilityLocks: map[mm.MMModem3gppFacility]string{
+			mm.MmModem3gppFacilityNetPers: "12345678",
+			mm.MmModem3gppFacilityPhSim:   "0000",
+		},
+	})
+	modem3gpp, err := modem.Get3gpp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := modem3gpp.DisableFacilityLock(mm.MmModem3gppFacilityNetPers, "87654321"); !errors.Is(err, mm.MmCoreErrorInvalidArgs) {
+		t.Errorf("got error %v of a wrong control key, want MmCoreErrorInvalidArgs", err)
+	}
+	if err := modem3gpp.DisableFacilityLock(mm.MmModem3gppFacilityNetPers, "12345678"); err != nil {
+		t.Fatal(err)
+	}
+	// the facility and the control key are sent as (us) struct
+	calls := srv.Calls()
+	arg := reflect.ValueOf(calls[len(calls)-1].Args[0])
+	if arg.Kind() != reflect.Struct || arg.NumField() != 2 ||
+		arg.Field(0).Uint() != uint64(mm.MmModem3gppFacilityNetPers) || arg.Field(1).String() != "12345678" {
+		t.Errorf("got argument %v, want the facility and the control key", arg)
+	}
+	locks, err := modem3gpp.GetEnabledFacilityLocks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locks) != 1 || locks[0] != mm.MmModem3gppFacilityPhSim {
+		t.Errorf("got enabled facility locks %v, want the sim lock only", locks)
+	}
+	if err := modem3gpp.DisableFacilityLock(mm.MmModem3gppFacilityNetPers, "12345678"); !errors.Is(err, mm.MmCoreErrorWrongState) {
+		t.Errorf("got error %v of a disabled lock, want MmCoreErrorWrongState", err)
+	}
+}
+
+func TestModem3gppSetCarrierLock(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	modem3gpp, err := modem.Get3gpp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte{0x01, 0x02, 0x00, 0xff}
+	if err := modem3gpp.SetCarrierLock(data); err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.CarrierLock(); !bytes.Equal(got, data) {
+		t.Errorf("got carrier lock %x, want %x", got, data)
+	}
+	srv.SetError(fake.GetObjectPath(), mm.Modem3gppSetCarrierLock, mm.MmCoreErrorUnsupported)
+	if err := modem3gpp.SetCarrierLock(data); !errors.Is(err, mm.MmCoreErrorUnsupported) {
+		t.Errorf("got error %v, want MmCoreErrorUnsupported", err)
+	}
+}
+
+func TestModem3gppNetworkRejection(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	modem3gpp, err := modem.Get3gpp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rejection, err := modem3gpp.GetNetworkRejection(); err != nil || rejection != (mm.NetworkRejection{}) {
+		t.Errorf("got rejection %v, %v, want none", rejection, err)
+	}
+	want := mm.NetworkRejection{Error: mm.MmNetworkErrorGprsNotAllowed, OperatorId: "26202", OperatorName: "Vodafone.de",
+		AccessTechnology: mm.MmModemAccessTechnologyLte}
+	fake.SetNetworkRejection(want)
+	if rejection, err := modem3gpp.GetNetworkRejection(); err != nil || rejection != want {
+		t.Errorf("got rejection %v, %v, want %v", rejection, err, want)
+	}
+}
+
+func TestModem3gppSubscriptionState(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	modem3gpp, err := modem.Get3gpp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state, err := modem3gpp.GetSubscriptionState(); err != nil || state != mm.MmModem3gppSubscriptionStateUnknown {
+		t.Errorf("got subscription state %s, %v, want unknown", state, err)
+	}
+	fake.SetProperty(mm.Modem3gppInterface, "SubscriptionState", uint32(mm.MmModem3gppSubscriptionStateOutOfData))
+	if state, err := modem3gpp.GetSubscriptionState(); err != nil || state != mm.MmModem3gppSubscriptionStateOutOfData {
+		t.Errorf("got subscription state %s, %v, want out of data", state, err)
+	}
+}
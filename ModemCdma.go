@@ -3,7 +3,6 @@ package modemmanager
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"github.com/godbus/dbus/v5"
 	"reflect"
 )
@@ -92,9 +91,14 @@ func NewModemCdma(objectPath dbus.ObjectPath) (ModemCdma, error) {
 	return &mc, mc.init(ModemManagerInterface, objectPath)
 }
 
+// NewModemCdmaWithConn returns new ModemCdma Interface using the given dbus connection
+func NewModemCdmaWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (ModemCdma, error) {
+	var mc modemCdma
+	return &mc, mc.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type modemCdma struct {
 	dbusBase
-	sigChan chan *dbus.Signal
 }
 
 // CdmaProperty describes the parameters for activating manually the modem
@@ -193,14 +197,7 @@ func (mc modemCdma) GetEvdoRegistrationState() (MMModemCdmaRegistrationState, er
 }
 
 func (mc modemCdma) SubscribeActivationStateChanged() <-chan *dbus.Signal {
-	if mc.sigChan != nil {
-		return mc.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", ModemCdmaSignalActivationStateChanged, fmt.Sprint(mc.GetObjectPath()))
-	mc.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	mc.sigChan = make(chan *dbus.Signal, 10)
-	mc.conn.Signal(mc.sigChan)
-	return mc.sigChan
+	return mc.subscribeSignal(ModemCdmaInterface, ModemCdmaSignalActivationStateChanged, mc.GetObjectPath())
 }
 
 func (mc modemCdma) ParseActivationStateChanged(v *dbus.Signal) (activationState MMModemCdmaActivationState, activationError MMCdmaActivationError, changedProperties map[string]dbus.Variant, err error) {
@@ -231,8 +228,7 @@ func (mc modemCdma) ParseActivationStateChanged(v *dbus.Signal) (activationState
 }
 
 func (mc modemCdma) Unsubscribe() {
-	mc.conn.RemoveSignal(mc.sigChan)
-	mc.sigChan = nil
+	mc.unsubscribeSignals()
 }
 
 func (mc modemCdma) MarshalJSON() ([]byte, error) {
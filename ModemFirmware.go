@@ -57,6 +57,12 @@ func NewModemFirmware(objectPath dbus.ObjectPath) (ModemFirmware, error) {
 	return &fi, fi.init(ModemManagerInterface, objectPath)
 }
 
+// NewModemFirmwareWithConn returns new ModemFirmware Interface using the given dbus connection
+func NewModemFirmwareWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (ModemFirmware, error) {
+	var fi modemFirmware
+	return &fi, fi.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type modemFirmware struct {
 	dbusBase
 }
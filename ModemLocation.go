@@ -112,6 +112,20 @@ type ModemLocation interface {
 
 	// Rate of refresh of the GPS information in the interface.
 	GetGpsRefreshRate() (uint32, error)
+
+	// Listen to changed properties, e.g. the Location property if location signals are enabled by Setup
+	// returns []interface
+	// index 0 = name of the interface on which the properties are defined
+	// index 1 = changed properties with new values as map[string]dbus.Variant
+	// index 2 = invalidated properties: changed properties but the new values are not send with them
+	SubscribePropertiesChanged() <-chan *dbus.Signal
+
+	// ParsePropertiesChanged parses the dbus signal
+	ParsePropertiesChanged(v *dbus.Signal) (interfaceName string, changedProperties map[string]dbus.Variant, invalidatedProperties []string, err error)
+
+	// ParseLocation parses the value of a changed Location property
+	ParseLocation(v dbus.Variant) (CurrentLocation, error)
+	Unsubscribe()
 }
 
 // NewModemLocation returns new ModemLocation Interface
@@ -120,6 +134,12 @@ func NewModemLocation(objectPath dbus.ObjectPath) (ModemLocation, error) {
 	return &lo, lo.init(ModemManagerInterface, objectPath)
 }
 
+// NewModemLocationWithConn returns new ModemLocation Interface using the given dbus connection
+func NewModemLocationWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (ModemLocation, error) {
+	var lo modemLocation
+	return &lo, lo.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type modemLocation struct {
 	dbusBase
 }
@@ -429,6 +449,24 @@ func (lo modemLocation) GetAssistanceDataServers() ([]string, error) {
 func (lo modemLocation) GetGpsRefreshRate() (uint32, error) {
 	return lo.getUint32Property(ModemLocationPropertyGpsRefreshRate)
 }
+func (lo modemLocation) SubscribePropertiesChanged() <-chan *dbus.Signal {
+	return lo.subscribeSignal(dbusPropertiesInterface, dbusPropertiesChanged, lo.GetObjectPath())
+}
+func (lo modemLocation) ParsePropertiesChanged(v *dbus.Signal) (interfaceName string, changedProperties map[string]dbus.Variant, invalidatedProperties []string, err error) {
+	return lo.parsePropertiesChanged(v)
+}
+func (lo modemLocation) ParseLocation(v dbus.Variant) (CurrentLocation, error) {
+	res, ok := v.Value().(map[uint32]dbus.Variant)
+	if !ok {
+		return CurrentLocation{}, errors.New("error by parsing location")
+	}
+	return lo.createLocation(res)
+}
+
+func (lo modemLocation) Unsubscribe() {
+	lo.unsubscribeSignals()
+}
+
 func (lo modemLocation) MarshalJSON() ([]byte, error) {
 	capabilities, err := lo.GetCapabilities()
 	if err != nil {
@@ -2,7 +2,6 @@ package modemmanager
 
 import (
 	"encoding/json"
-	"fmt"
 	"github.com/godbus/dbus/v5"
 	"reflect"
 )
@@ -56,6 +55,10 @@ type ModemManager interface {
 	// The runtime version of the ModemManager daemon.
 	GetVersion() (string, error)
 
+	// WatchModems returns a ModemWatcher on the connection of the ModemManager, which follows added and removed
+	// modems by the InterfacesAdded and InterfacesRemoved signals of org.freedesktop.DBus.ObjectManager
+	WatchModems() (*ModemWatcher, error)
+
 	MarshalJSON() ([]byte, error)
 
 	/* SIGNALS */
@@ -78,9 +81,15 @@ func NewModemManager() (ModemManager, error) {
 	return &mm, mm.init(ModemManagerInterface, ModemManagerObjectPath)
 }
 
+// NewModemManagerWithConn returns new ModemManager Interface using the given dbus connection, e.g. a private
+// or session bus. All objects retrieved from it (modems, bearers, sims, ...) share this connection.
+func NewModemManagerWithConn(conn *dbus.Conn) (ModemManager, error) {
+	var mm modemManager
+	return &mm, mm.initWithConn(conn, ModemManagerInterface, ModemManagerObjectPath)
+}
+
 type modemManager struct {
 	dbusBase
-	sigChan chan *dbus.Signal
 }
 
 // EventProperties  defines the properties which should be reported to the kernel
@@ -107,7 +116,7 @@ func (mm modemManager) GetModems() (modems []Modem, err error) {
 		return nil, err
 	}
 	for idx := range devPaths {
-		modem, err := NewModem(devPaths[idx])
+		modem, err := NewModemWithConn(mm.conn, devPaths[idx])
 		if err != nil {
 			return nil, err
 		}
@@ -155,23 +164,19 @@ func (mm modemManager) GetVersion() (string, error) {
 	v, err := mm.getStringProperty(ModemManagerPropertyVersion)
 	return v, err
 }
+func (mm modemManager) WatchModems() (*ModemWatcher, error) {
+	return NewModemWatcherWithConn(mm.conn)
+}
+
 func (mm modemManager) SubscribePropertiesChanged() <-chan *dbus.Signal {
-	if mm.sigChan != nil {
-		return mm.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", dbusPropertiesChanged, ModemManagerObjectPath)
-	mm.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	mm.sigChan = make(chan *dbus.Signal, 10)
-	mm.conn.Signal(mm.sigChan)
-	return mm.sigChan
+	return mm.subscribeSignalNamespace(dbusPropertiesInterface, dbusPropertiesChanged, ModemManagerObjectPath)
 }
 func (mm modemManager) ParsePropertiesChanged(v *dbus.Signal) (interfaceName string, changedProperties map[string]dbus.Variant, invalidatedProperties []string, err error) {
 	return mm.parsePropertiesChanged(v)
 }
 
 func (mm modemManager) Unsubscribe() {
-	mm.conn.RemoveSignal(mm.sigChan)
-	mm.sigChan = nil
+	mm.unsubscribeSignals()
 }
 
 func (mm modemManager) MarshalJSON() ([]byte, error) {
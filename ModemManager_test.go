@@ -0,0 +1,53 @@
+package modemmanager_test
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+func TestModemManagerGetModems(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	mmgr, err := mm.NewModemManagerWithConn(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := mmgr.GetVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != mmtest.DefaultVersion {
+		t.Errorf("version = %q, want %q", version, mmtest.DefaultVersion)
+	}
+	modems, err := mmgr.GetModems()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modems) != 0 {
+		t.Fatalf("got %d modems, want none", len(modems))
+	}
+	first, _ := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	second, _ := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	modems, err = mmgr.GetModems()
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make(map[dbus.ObjectPath]bool)
+	for _, modem := range modems {
+		paths[modem.GetObjectPath()] = true
+	}
+	if len(paths) != 2 || !paths[first.GetObjectPath()] || !paths[second.GetObjectPath()] {
+		t.Errorf("got modems %v, want %s and %s", paths, first.GetObjectPath(), second.GetObjectPath())
+	}
+	second.Remove()
+	modems, err = mmgr.GetModems()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modems) != 1 || modems[0].GetObjectPath() != first.GetObjectPath() {
+		t.Errorf("got %d modems after removal, want %s", len(modems), first.GetObjectPath())
+	}
+}
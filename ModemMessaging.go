@@ -1,6 +1,7 @@
 package modemmanager
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -41,9 +42,15 @@ type ModemMessaging interface {
 	// by listening for the "Added" signal, or by querying the specific SMS object of interest.
 	List() ([]Sms, error)
 
+	// Same as List, but the call is canceled when ctx is done
+	ListWithContext(ctx context.Context) ([]Sms, error)
+
 	// Delete an SMS message.
 	Delete(Sms) error
 
+	// Same as Delete, but the call is canceled when ctx is done
+	DeleteWithContext(ctx context.Context, sms Sms) error
+
 	// Creates a new message object.
 	// The 'Number' and either 'Text' or 'Data' properties are mandatory, others are optional.
 	// If the SMSC is not specified and one is required, the default SMSC is used.
@@ -51,8 +58,13 @@ type ModemMessaging interface {
 	// When sending, if the text/data is larger than the limit of the technology or modem, the message will be broken into multiple parts or messages.
 
 	CreateSms(number string, text string, optionalParameters ...Pair) (Sms, error)
+	// Same as CreateSms, but the call is canceled when ctx is done
+	CreateSmsWithContext(ctx context.Context, number string, text string, optionalParameters ...Pair) (Sms, error)
 	CreateMms(number string, data []byte, optionalParameters ...Pair) (Sms, error)
 
+	// Same as CreateMms, but the call is canceled when ctx is done
+	CreateMmsWithContext(ctx context.Context, number string, data []byte, optionalParameters ...Pair) (Sms, error)
+
 	/* PROPERTIES */
 
 	// The list of SMS object paths.
@@ -92,9 +104,14 @@ func NewModemMessaging(objectPath dbus.ObjectPath) (ModemMessaging, error) {
 	return &me, me.init(ModemManagerInterface, objectPath)
 }
 
+// NewModemMessagingWithConn returns new ModemMessagingInterface using the given dbus connection
+func NewModemMessagingWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (ModemMessaging, error) {
+	var me modemMessaging
+	return &me, me.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type modemMessaging struct {
 	dbusBase
-	sigChan chan *dbus.Signal
 }
 
 func (me modemMessaging) GetObjectPath() dbus.ObjectPath {
@@ -102,14 +119,18 @@ func (me modemMessaging) GetObjectPath() dbus.ObjectPath {
 }
 
 func (me modemMessaging) List() (sms []Sms, err error) {
+	return me.ListWithContext(context.Background())
+}
+
+func (me modemMessaging) ListWithContext(ctx context.Context) (sms []Sms, err error) {
 	var smsPaths []dbus.ObjectPath
-	err = me.callWithReturn(&smsPaths, ModemMessagingList)
+	err = me.callWithReturnContext(ctx, &smsPaths, ModemMessagingList)
 	if err != nil {
 		return
 	}
 
 	for idx := range smsPaths {
-		singleSms, err := NewSms(smsPaths[idx])
+		singleSms, err := NewSmsWithConn(me.conn, smsPaths[idx])
 		if err != nil {
 			return nil, err
 		}
@@ -119,11 +140,19 @@ func (me modemMessaging) List() (sms []Sms, err error) {
 }
 
 func (me modemMessaging) Delete(sms Sms) error {
+	return me.DeleteWithContext(context.Background(), sms)
+}
+
+func (me modemMessaging) DeleteWithContext(ctx context.Context, sms Sms) error {
 	objPath := sms.GetObjectPath()
-	return me.call(ModemMessagingDelete, &objPath)
+	return me.callContext(ctx, ModemMessagingDelete, &objPath)
 }
 
 func (me modemMessaging) CreateSms(number string, text string, optionalParameters ...Pair) (Sms, error) {
+	return me.CreateSmsWithContext(context.Background(), number, text, optionalParameters...)
+}
+
+func (me modemMessaging) CreateSmsWithContext(ctx context.Context, number string, text string, optionalParameters ...Pair) (Sms, error) {
 	type dynMap interface{}
 	var myMap map[string]dynMap
 	myMap = make(map[string]dynMap)
@@ -133,11 +162,11 @@ func (me modemMessaging) CreateSms(number string, text string, optionalParameter
 		myMap[fmt.Sprint(pair.GetLeft())] = fmt.Sprint(pair.GetRight())
 	}
 	var path dbus.ObjectPath
-	err := me.callWithReturn(&path, ModemMessagingCreate, &myMap)
+	err := me.callWithReturnContext(ctx, &path, ModemMessagingCreate, &myMap)
 	if err != nil {
 		return nil, err
 	}
-	singleSms, err := NewSms(path)
+	singleSms, err := NewSmsWithConn(me.conn, path)
 	if err != nil {
 		return nil, err
 	}
@@ -145,6 +174,10 @@ func (me modemMessaging) CreateSms(number string, text string, optionalParameter
 }
 
 func (me modemMessaging) CreateMms(number string, data []byte, optionalParameters ...Pair) (Sms, error) {
+	return me.CreateMmsWithContext(context.Background(), number, data, optionalParameters...)
+}
+
+func (me modemMessaging) CreateMmsWithContext(ctx context.Context, number string, data []byte, optionalParameters ...Pair) (Sms, error) {
 	// todo: untested
 	type dynMap interface{}
 	var myMap map[string]dynMap
@@ -155,11 +188,11 @@ func (me modemMessaging) CreateMms(number string, data []byte, optionalParameter
 		myMap[fmt.Sprint(pair.GetLeft())] = fmt.Sprint(pair.GetRight())
 	}
 	var path dbus.ObjectPath
-	err := me.callWithReturn(&path, ModemMessagingCreate, &myMap)
+	err := me.callWithReturnContext(ctx, &path, ModemMessagingCreate, &myMap)
 	if err != nil {
 		return nil, err
 	}
-	singleSms, err := NewSms(path)
+	singleSms, err := NewSmsWithConn(me.conn, path)
 	if err != nil {
 		return nil, err
 	}
@@ -172,7 +205,7 @@ func (me modemMessaging) GetMessages() (sms []Sms, err error) {
 		return
 	}
 	for idx := range smsPaths {
-		singleSms, err := NewSms(smsPaths[idx])
+		singleSms, err := NewSmsWithConn(me.conn, smsPaths[idx])
 		if err != nil {
 			return nil, err
 		}
@@ -201,14 +234,7 @@ func (me modemMessaging) GetDefaultStorage() (storage MMSmsStorage, err error) {
 }
 
 func (me modemMessaging) SubscribeAdded() <-chan *dbus.Signal {
-	if me.sigChan != nil {
-		return me.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", ModemMessagingSignalAdded, fmt.Sprint(me.GetObjectPath()))
-	me.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	me.sigChan = make(chan *dbus.Signal, 10)
-	me.conn.Signal(me.sigChan)
-	return me.sigChan
+	return me.subscribeSignal(ModemMessagingInterface, ModemMessagingSignalAdded, me.GetObjectPath())
 }
 
 func (me modemMessaging) ParseAdded(v *dbus.Signal) (sms Sms, received bool, err error) {
@@ -222,7 +248,7 @@ func (me modemMessaging) ParseAdded(v *dbus.Signal) (sms Sms, received bool, err
 		err = errors.New("error by parsing object path")
 		return
 	}
-	sms, err = NewSms(path)
+	sms, err = NewSmsWithConn(me.conn, path)
 	if err != nil {
 		return
 	}
@@ -235,19 +261,11 @@ func (me modemMessaging) ParseAdded(v *dbus.Signal) (sms Sms, received bool, err
 }
 
 func (me modemMessaging) SubscribeDeleted() <-chan *dbus.Signal {
-	if me.sigChan != nil {
-		return me.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", ModemMessagingSignalDeleted, fmt.Sprint(me.GetObjectPath()))
-	me.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	me.sigChan = make(chan *dbus.Signal, 10)
-	me.conn.Signal(me.sigChan)
-	return me.sigChan
+	return me.subscribeSignal(ModemMessagingInterface, ModemMessagingSignalDeleted, me.GetObjectPath())
 }
 
 func (me modemMessaging) Unsubscribe() {
-	me.conn.RemoveSignal(me.sigChan)
-	me.sigChan = nil
+	me.unsubscribeSignals()
 }
 
 func (me modemMessaging) MarshalJSON() ([]byte, error) {
@@ -0,0 +1,100 @@
+package modemmanager_test
+
+import (
+	"testing"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+func TestMessagingSend(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	if err := modem.Enable(); err != nil {
+		t.Fatal(err)
+	}
+	messaging, err := modem.GetMessaging()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sms, err := messaging.CreateSms("+491701234567", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sms.Send(); err != nil {
+		t.Fatal(err)
+	}
+	state, err := sms.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != mm.MmSmsStateSent {
+		t.Errorf("state = %s, want sent", state)
+	}
+	pduType, err := sms.GetPduType()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pduType != mm.MmSmsPduTypeSubmit {
+		t.Errorf("pdu type = %s, want submit", pduType)
+	}
+	messages, err := messaging.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].GetObjectPath() != sms.GetObjectPath() {
+		t.Fatalf("got %d messages, want %s", len(messages), sms.GetObjectPath())
+	}
+	if err := messaging.Delete(sms); err != nil {
+		t.Fatal(err)
+	}
+	messages, err = messaging.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 0 {
+		t.Errorf("got %d messages after delete, want none", len(messages))
+	}
+}
+
+func TestMessagingReceive(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	messaging, err := modem.GetMessaging()
+	if err != nil {
+		t.Fatal(err)
+	}
+	added := messaging.SubscribeAdded()
+	defer messaging.Unsubscribe()
+	received, err := fake.ReceiveSms("+491701234567", "hi there")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sms, isReceived, err := messaging.ParseAdded(<-added)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isReceived || sms.GetObjectPath() != received.GetObjectPath() {
+		t.Fatalf("added %s received %v, want %s received", sms.GetObjectPath(), isReceived, received.GetObjectPath())
+	}
+	number, err := sms.GetNumber()
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := sms.GetText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if number != "+491701234567" || text != "hi there" {
+		t.Errorf("got %q from %s, want %q from +491701234567", text, number, "hi there")
+	}
+	state, err := sms.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != mm.MmSmsStateReceived {
+		t.Errorf("state = %s, want received", state)
+	}
+}
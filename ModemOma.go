@@ -87,9 +87,14 @@ func NewModemOma(objectPath dbus.ObjectPath) (ModemOma, error) {
 	return &om, om.init(ModemManagerInterface, objectPath)
 }
 
+// NewModemOmaWithConn returns new ModemOma Interface using the given dbus connection
+func NewModemOmaWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (ModemOma, error) {
+	var om modemOma
+	return &om, om.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type modemOma struct {
 	dbusBase
-	sigChan chan *dbus.Signal
 }
 
 type ModemOmaInitiatedSession struct {
@@ -182,14 +187,7 @@ func (om modemOma) GetSessionState() (MMOmaSessionState, error) {
 }
 
 func (om modemOma) SubscribeSessionStateChanged() <-chan *dbus.Signal {
-	if om.sigChan != nil {
-		return om.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", ModemOmaSignalSessionStateChanged, fmt.Sprint(om.GetObjectPath()))
-	om.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	om.sigChan = make(chan *dbus.Signal, 10)
-	om.conn.Signal(om.sigChan)
-	return om.sigChan
+	return om.subscribeSignal(ModemOmaInterface, ModemOmaSignalSessionStateChanged, om.GetObjectPath())
 }
 
 func (om modemOma) ParseSessionStateChanged(v *dbus.Signal) (oldState MMOmaSessionState, newState MMOmaSessionState, failureReason MMOmaSessionStateFailedReason, err error) {
@@ -223,8 +221,7 @@ func (om modemOma) ParseSessionStateChanged(v *dbus.Signal) (oldState MMOmaSessi
 }
 
 func (om modemOma) Unsubscribe() {
-	om.conn.RemoveSignal(om.sigChan)
-	om.sigChan = nil
+	om.unsubscribeSignals()
 }
 
 func (om modemOma) MarshalJSON() ([]byte, error) {
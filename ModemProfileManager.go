@@ -0,0 +1,322 @@
+package modemmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/godbus/dbus/v5"
+)
+
+// Paths of methods and properties
+const (
+	Modem3gppProfileManagerInterface = Modem3gppInterface + ".ProfileManager"
+
+	/* Methods */
+	Modem3gppProfileManagerList   = Modem3gppProfileManagerInterface + ".List"
+	Modem3gppProfileManagerSet    = Modem3gppProfileManagerInterface + ".Set"
+	Modem3gppProfileManagerDelete = Modem3gppProfileManagerInterface + ".Delete"
+	/* Property */
+	Modem3gppProfileManagerPropertyIndexField = Modem3gppProfileManagerInterface + ".IndexField" // readable   s
+	/* Signal */
+	Modem3gppProfileManagerSignalUpdated = "Updated"
+)
+
+// ProfileManager allows clients to manage the connection profiles (APN settings) stored in the modem.
+// This interface will only be available once the modem is ready to be registered in the cellular network.
+// 3GPP devices will require a valid unlocked SIM card before any of the features in the interface can be used.
+type ProfileManager interface {
+	/* METHODS */
+
+	// Returns object path
+	GetObjectPath() dbus.ObjectPath
+
+	MarshalJSON() ([]byte, error)
+
+	// Lists the available profiles.
+	// 		OUT aa{sv} profiles: An array of dictionaries containing the properties of the provisioned profiles.
+	List() ([]Profile, error)
+
+	// Creates or updates a connection profile in the device.
+	// If the profile id is not given, a new profile will be created, otherwise the profile with the given id is updated.
+	// Only the given fields are sent, e.g. to disable a profile by ProfileFieldEnabled. Without fields, all fields
+	// with a non-zero value are sent.
+	// 		IN a{sv} requested_properties: the requested profile properties, see Profile struct
+	// 		OUT a{sv} stored_properties: the profile properties as stored in the device
+	Set(profile Profile, fields ...ProfileField) (Profile, error)
+
+	// Deletes the profile with the profile id or the apn type of the given profile, depending on the IndexField.
+	// Returns an error without calling ModemManager if neither is set.
+	// 		IN a{sv} properties: the profile properties, only the index field is relevant
+	Delete(profile Profile) error
+
+	/* PROPERTIES */
+
+	// The field that is used as index of the profiles in the device, either "profile-id" or "apn-type".
+	GetIndexField() (string, error)
+
+	/* SIGNALS */
+
+	// Emitted when the profiles are updated by the network or by some other means.
+	SubscribeUpdated() <-chan *dbus.Signal
+
+	Unsubscribe()
+}
+
+// NewProfileManager returns new ProfileManager Interface
+func NewProfileManager(objectPath dbus.ObjectPath) (ProfileManager, error) {
+	var pm profileManager
+	return &pm, pm.init(ModemManagerInterface, objectPath)
+}
+
+// NewProfileManagerWithConn returns new ProfileManager Interface using the given dbus connection
+func NewProfileManagerWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (ProfileManager, error) {
+	var pm profileManager
+	return &pm, pm.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
+type profileManager struct {
+	dbusBase
+}
+
+// ProfileField is the key of a property of a connection profile
+type ProfileField string
+
+// The properties of a connection profile
+const (
+	ProfileFieldProfileId            ProfileField = "profile-id"
+	ProfileFieldProfileName          ProfileField = "profile-name"
+	ProfileFieldApn                  ProfileField = "apn"
+	ProfileFieldApnType              ProfileField = "apn-type"
+	ProfileFieldIpType               ProfileField = "ip-type"
+	ProfileFieldAllowedAuth          ProfileField = "allowed-auth"
+	ProfileFieldUser                 ProfileField = "user"
+	ProfileFieldPassword             ProfileField = "password"
+	ProfileFieldAccessTypePreference ProfileField = "access-type-preference"
+	ProfileFieldEnabled              ProfileField = "enabled"
+)
+
+var profileFields = []ProfileField{ProfileFieldProfileId, ProfileFieldProfileName, ProfileFieldApn, ProfileFieldApnType,
+	ProfileFieldIpType, ProfileFieldAllowedAuth, ProfileFieldUser, ProfileFieldPassword,
+	ProfileFieldAccessTypePreference, ProfileFieldEnabled}
+
+// Profile represents a connection profile stored in the modem. The APN settings are given by the embedded
+// BearerProperty, of which APN, IPType, AllowedAuth, User and Password are part of a profile.
+type Profile struct {
+	ProfileId   int32  `json:"profile-id"`   // Numeric index of the profile in the device, given as an integer value (signature "i"). Profile ids start at 1.
+	ProfileName string `json:"profile-name"` // Name of the profile, given as a string value (signature "s").
+	BearerProperty
+	ApnType              MMBearerApnType              `json:"apn-type"`               // Purposes of the APN, given as a MMBearerApnType value (signature "u").
+	AccessTypePreference MMBearerAccessTypePreference `json:"access-type-preference"` // 5G access type preference, given as a MMBearerAccessTypePreference value (signature "u").
+	Enabled              bool                         `json:"enabled"`                // Flag to tell whether the profile is enabled, given as a boolean value (signature "b").
+}
+
+// MarshalJSON returns a byte array
+func (p Profile) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"ProfileId":            p.ProfileId,
+		"ProfileName":          p.ProfileName,
+		"APN":                  p.APN,
+		"ApnType":              fmt.Sprint(p.ApnType.BitmaskToSlice(uint32(p.ApnType))),
+		"IPType":               fmt.Sprint(p.IPType),
+		"AllowedAuth":          fmt.Sprint(p.AllowedAuth),
+		"User":                 p.User,
+		"Password":             p.Password,
+		"AccessTypePreference": fmt.Sprint(p.AccessTypePreference),
+		"Enabled":              p.Enabled,
+	})
+}
+
+func (p Profile) String() string {
+	return "ProfileId: " + fmt.Sprint(p.ProfileId) +
+		", ProfileName: " + p.ProfileName +
+		", APN: " + p.APN +
+		", ApnType: " + fmt.Sprint(p.ApnType.BitmaskToSlice(uint32(p.ApnType))) +
+		", IPType: " + fmt.Sprint(p.IPType) +
+		", AllowedAuth: " + fmt.Sprint(p.AllowedAuth) +
+		", User: " + p.User +
+		", Password: " + p.Password +
+		", AccessTypePreference: " + fmt.Sprint(p.AccessTypePreference) +
+		", Enabled: " + fmt.Sprint(p.Enabled)
+}
+
+// value returns the dbus value of the field
+func (p Profile) value(field ProfileField) (interface{}, error) {
+	switch field {
+	case ProfileFieldProfileId:
+		return p.ProfileId, nil
+	case ProfileFieldProfileName:
+		return p.ProfileName, nil
+	case ProfileFieldApn:
+		return p.APN, nil
+	case ProfileFieldApnType:
+		return uint32(p.ApnType), nil
+	case ProfileFieldIpType:
+		return uint32(p.IPType), nil
+	case ProfileFieldAllowedAuth:
+		return uint32(p.AllowedAuth), nil
+	case ProfileFieldUser:
+		return p.User, nil
+	case ProfileFieldPassword:
+		return p.Password, nil
+	case ProfileFieldAccessTypePreference:
+		return uint32(p.AccessTypePreference), nil
+	case ProfileFieldEnabled:
+		return p.Enabled, nil
+	}
+	return nil, fmt.Errorf("unknown profile field: %s", field)
+}
+
+// toMap returns the given fields of the profile as dictionary, or all non-zero fields if none are given
+func (p Profile) toMap(fields []ProfileField) (map[string]interface{}, error) {
+	res := make(map[string]interface{})
+	explicit := len(fields) > 0
+	if !explicit {
+		fields = profileFields
+	}
+	for _, field := range fields {
+		value, err := p.value(field)
+		if err != nil {
+			return nil, err
+		}
+		if explicit || !isZero(value) {
+			res[string(field)] = value
+		}
+	}
+	return res, nil
+}
+
+func isZero(value interface{}) bool {
+	switch v := value.(type) {
+	case int32:
+		return v == 0
+	case uint32:
+		return v == 0
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	}
+	return false
+}
+
+func (pm profileManager) parseProfile(tmpMap map[string]dbus.Variant) (p Profile) {
+	for key, element := range tmpMap {
+		switch key {
+		case "profile-id":
+			tmpValue, ok := element.Value().(int32)
+			if ok {
+				p.ProfileId = tmpValue
+			}
+		case "profile-name":
+			tmpValue, ok := element.Value().(string)
+			if ok {
+				p.ProfileName = tmpValue
+			}
+		case "apn":
+			tmpValue, ok := element.Value().(string)
+			if ok {
+				p.APN = tmpValue
+			}
+		case "apn-type":
+			tmpValue, ok := element.Value().(uint32)
+			if ok {
+				p.ApnType = MMBearerApnType(tmpValue)
+			}
+		case "ip-type":
+			tmpValue, ok := element.Value().(uint32)
+			if ok {
+				p.IPType = MMBearerIpFamily(tmpValue)
+			}
+		case "allowed-auth":
+			tmpValue, ok := element.Value().(uint32)
+			if ok {
+				p.AllowedAuth = MMBearerAllowedAuth(tmpValue)
+			}
+		case "user":
+			tmpValue, ok := element.Value().(string)
+			if ok {
+				p.User = tmpValue
+			}
+		case "password":
+			tmpValue, ok := element.Value().(string)
+			if ok {
+				p.Password = tmpValue
+			}
+		case "access-type-preference":
+			tmpValue, ok := element.Value().(uint32)
+			if ok {
+				p.AccessTypePreference = MMBearerAccessTypePreference(tmpValue)
+			}
+		case "enabled":
+			tmpValue, ok := element.Value().(bool)
+			if ok {
+				p.Enabled = tmpValue
+			}
+		}
+	}
+	return
+}
+
+func (pm profileManager) GetObjectPath() dbus.ObjectPath {
+	return pm.obj.Path()
+}
+
+func (pm profileManager) List() (profiles []Profile, err error) {
+	var res []map[string]dbus.Variant
+	err = pm.callWithReturn(&res, Modem3gppProfileManagerList)
+	if err != nil {
+		return nil, err
+	}
+	for _, tmpMap := range res {
+		profiles = append(profiles, pm.parseProfile(tmpMap))
+	}
+	return
+}
+
+func (pm profileManager) Set(profile Profile, fields ...ProfileField) (Profile, error) {
+	requested, err := profile.toMap(fields)
+	if err != nil {
+		return Profile{}, err
+	}
+	var res map[string]dbus.Variant
+	err = pm.callWithReturn(&res, Modem3gppProfileManagerSet, requested)
+	if err != nil {
+		return Profile{}, err
+	}
+	return pm.parseProfile(res), nil
+}
+
+func (pm profileManager) Delete(profile Profile) error {
+	properties := make(map[string]interface{})
+	if profile.ProfileId != 0 {
+		properties[string(ProfileFieldProfileId)] = profile.ProfileId
+	}
+	if profile.ApnType != MmBearerApnTypeNone {
+		properties[string(ProfileFieldApnType)] = uint32(profile.ApnType)
+	}
+	if len(properties) == 0 {
+		return fmt.Errorf("profile without %s and %s", ProfileFieldProfileId, ProfileFieldApnType)
+	}
+	return pm.call(Modem3gppProfileManagerDelete, properties)
+}
+
+func (pm profileManager) GetIndexField() (string, error) {
+	return pm.getStringProperty(Modem3gppProfileManagerPropertyIndexField)
+}
+
+func (pm profileManager) SubscribeUpdated() <-chan *dbus.Signal {
+	return pm.subscribeSignal(Modem3gppProfileManagerInterface, Modem3gppProfileManagerSignalUpdated, pm.GetObjectPath())
+}
+
+func (pm profileManager) Unsubscribe() {
+	pm.unsubscribeSignals()
+}
+
+func (pm profileManager) MarshalJSON() ([]byte, error) {
+	profiles, err := pm.List()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]interface{}{
+		"Profiles": profiles,
+	})
+}
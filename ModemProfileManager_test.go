@@ -0,0 +1,86 @@
+package modemmanager_test
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+func TestProfileManagerSet(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	modem3gpp, err := modem.Get3gpp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pm, err := modem3gpp.GetProfileManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var profile mm.Profile
+	profile.ProfileName = "internet"
+	profile.APN = "internet.example"
+	profile.IPType = mm.MmBearerIpFamilyIpv4v6
+	profile.ApnType = mm.MmBearerApnTypeDefault | mm.MmBearerApnTypeInitial
+	stored, err := pm.Set(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ProfileId != 1 || stored.APN != "internet.example" || stored.IPType != mm.MmBearerIpFamilyIpv4v6 ||
+		stored.ApnType != mm.MmBearerApnTypeDefault|mm.MmBearerApnTypeInitial || !stored.Enabled {
+		t.Errorf("stored %s, want the requested profile with id 1", stored)
+	}
+
+	// disabling requires to send the zero value of Enabled
+	srv.ResetCalls()
+	var disable mm.Profile
+	disable.ProfileId = stored.ProfileId
+	stored, err = pm.Set(disable, mm.ProfileFieldProfileId, mm.ProfileFieldEnabled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Enabled || stored.APN != "internet.example" {
+		t.Errorf("stored %s, want the disabled profile with the previous apn", stored)
+	}
+	calls := srv.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("got calls %v, want one call of Set", calls)
+	}
+	requested := calls[0].Args[0].(map[string]dbus.Variant)
+	if len(requested) != 2 || requested["enabled"].Value() != false || requested["profile-id"].Value() != int32(1) {
+		t.Errorf("requested %v, want only profile-id and enabled", requested)
+	}
+
+	if _, err := pm.Set(disable, mm.ProfileField("roaming")); err == nil {
+		t.Error("unknown profile field sent")
+	}
+
+	profiles, err := pm.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 1 || profiles[0].ProfileName != "internet" || profiles[0].Enabled {
+		t.Fatalf("got profiles %v, want the disabled profile", profiles)
+	}
+	if err := pm.Delete(mm.Profile{ProfileName: "internet"}); err == nil {
+		t.Error("deleted a profile without profile id and apn type")
+	}
+	for _, call := range srv.Calls() {
+		if call.Method == mm.Modem3gppProfileManagerDelete {
+			t.Errorf("got call %v of a profile without profile id and apn type", call)
+		}
+	}
+	if err := pm.Delete(profiles[0]); err != nil {
+		t.Fatal(err)
+	}
+	profiles, err = pm.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 0 {
+		t.Errorf("got %d profiles after delete, want none", len(profiles))
+	}
+}
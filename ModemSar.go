@@ -0,0 +1,123 @@
+package modemmanager
+
+import (
+	"encoding/json"
+	"github.com/godbus/dbus/v5"
+)
+
+// Paths of methods and properties
+const (
+	ModemSarInterface = ModemInterface + ".Sar"
+
+	/* Methods */
+	ModemSarEnable        = ModemSarInterface + ".Enable"
+	ModemSarSetPowerLevel = ModemSarInterface + ".SetPowerLevel"
+	/* Property */
+	ModemSarPropertyState      = ModemSarInterface + ".State"      // readable   b
+	ModemSarPropertyPowerLevel = ModemSarInterface + ".PowerLevel" // readable   u
+)
+
+// ModemSar allows clients to control the Specific Absorption Rate (SAR) of the modem, i.e. the limits of the
+// transmission power.
+// This interface will only be available once the modem is ready to be registered in the cellular network.
+type ModemSar interface {
+	/* METHODS */
+
+	// Returns object path
+	GetObjectPath() dbus.ObjectPath
+
+	MarshalJSON() ([]byte, error)
+
+	// Enable or disable dynamic SAR.
+	// 		IN b enable: TRUE to enable dynamic SAR, FALSE to disable it.
+	Enable(enable bool) error
+
+	// Set the dynamic SAR power level.
+	// 		IN u level: Index of the SAR power level mapping table, which is specific to the device.
+	SetPowerLevel(level uint32) error
+
+	/* PROPERTIES */
+
+	// Boolean indicating whether dynamic SAR is enabled.
+	GetState() (bool, error)
+
+	// Index of the SAR power level mapping table. Only meaningful if dynamic SAR is enabled.
+	GetPowerLevel() (uint32, error)
+
+	/* SIGNALS */
+
+	// Listen to changed properties
+	// returns []interface
+	// index 0 = name of the interface on which the properties are defined
+	// index 1 = changed properties with new values as map[string]dbus.Variant
+	// index 2 = invalidated properties: changed properties but the new values are not send with them
+	SubscribePropertiesChanged() <-chan *dbus.Signal
+
+	// ParsePropertiesChanged parses the dbus signal
+	ParsePropertiesChanged(v *dbus.Signal) (interfaceName string, changedProperties map[string]dbus.Variant, invalidatedProperties []string, err error)
+
+	Unsubscribe()
+}
+
+// NewModemSar returns new ModemSar Interface
+func NewModemSar(objectPath dbus.ObjectPath) (ModemSar, error) {
+	var ms modemSar
+	return &ms, ms.init(ModemManagerInterface, objectPath)
+}
+
+// NewModemSarWithConn returns new ModemSar Interface using the given dbus connection
+func NewModemSarWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (ModemSar, error) {
+	var ms modemSar
+	return &ms, ms.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
+type modemSar struct {
+	dbusBase
+}
+
+func (ms modemSar) GetObjectPath() dbus.ObjectPath {
+	return ms.obj.Path()
+}
+
+func (ms modemSar) Enable(enable bool) error {
+	return ms.call(ModemSarEnable, enable)
+}
+
+func (ms modemSar) SetPowerLevel(level uint32) error {
+	return ms.call(ModemSarSetPowerLevel, level)
+}
+
+func (ms modemSar) GetState() (bool, error) {
+	return ms.getBoolProperty(ModemSarPropertyState)
+}
+
+func (ms modemSar) GetPowerLevel() (uint32, error) {
+	return ms.getUint32Property(ModemSarPropertyPowerLevel)
+}
+
+func (ms modemSar) SubscribePropertiesChanged() <-chan *dbus.Signal {
+	return ms.subscribeSignal(dbusPropertiesInterface, dbusPropertiesChanged, ms.GetObjectPath())
+}
+
+func (ms modemSar) ParsePropertiesChanged(v *dbus.Signal) (interfaceName string, changedProperties map[string]dbus.Variant, invalidatedProperties []string, err error) {
+	return ms.parsePropertiesChanged(v)
+}
+
+func (ms modemSar) Unsubscribe() {
+	ms.unsubscribeSignals()
+}
+
+func (ms modemSar) MarshalJSON() ([]byte, error) {
+	state, err := ms.GetState()
+	if err != nil {
+		return nil, err
+	}
+	powerLevel, err := ms.GetPowerLevel()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]interface{}{
+		"State":      state,
+		"PowerLevel": powerLevel,
+	})
+}
@@ -0,0 +1,85 @@
+package modemmanager_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+func TestModemSar(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	sar, err := modem.GetSar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state, err := sar.GetState(); err != nil || state {
+		t.Errorf("got state %t, %v, want dynamic sar disabled", state, err)
+	}
+	changed := sar.SubscribePropertiesChanged()
+	defer sar.Unsubscribe()
+
+	if err := sar.Enable(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := sar.SetPowerLevel(2); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := sar.GetState(); err != nil || !state {
+		t.Errorf("got state %t, %v, want dynamic sar enabled", state, err)
+	}
+	if level, err := sar.GetPowerLevel(); err != nil || level != 2 {
+		t.Errorf("got power level %d, %v, want 2", level, err)
+	}
+	var args []interface{}
+	for _, call := range srv.Calls() {
+		if call.Method == mm.ModemSarEnable || call.Method == mm.ModemSarSetPowerLevel {
+			args = append(args, call.Args...)
+		}
+	}
+	if len(args) != 2 || args[0] != true || args[1] != uint32(2) {
+		t.Errorf("got arguments %v, want true and 2", args)
+	}
+
+	// both changes are signaled on the sar interface
+	want := map[string]dbus.Variant{"State": dbus.MakeVariant(true), "PowerLevel": dbus.MakeVariant(uint32(2))}
+	timeout := time.After(5 * time.Second)
+	for len(want) > 0 {
+		select {
+		case sig := <-changed:
+			iface, properties, _, err := sar.ParsePropertiesChanged(sig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if iface != mm.ModemSarInterface {
+				continue
+			}
+			for name, value := range properties {
+				if want[name] == value {
+					delete(want, name)
+				}
+			}
+		case <-timeout:
+			t.Fatalf("changes of %v not signaled", want)
+		}
+	}
+
+	if err := sar.Enable(false); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := sar.GetState(); err != nil || state {
+		t.Errorf("got state %t, %v, want dynamic sar disabled", state, err)
+	}
+	srv.SetError(fake.GetObjectPath(), mm.ModemSarSetPowerLevel, mm.MmCoreErrorInvalidArgs)
+	if err := sar.SetPowerLevel(9); !errors.Is(err, mm.MmCoreErrorInvalidArgs) {
+		t.Errorf("got error %v, want MmCoreErrorInvalidArgs", err)
+	}
+	if level, err := sar.GetPowerLevel(); err != nil || level != 2 {
+		t.Errorf("got power level %d, %v after the failed call, want 2", level, err)
+	}
+}
@@ -12,14 +12,18 @@ const (
 	ModemSignalInterface = ModemInterface + ".Signal"
 
 	/* Methods */
-	ModemSignalSetup = ModemSignalInterface + ".Setup"
+	ModemSignalSetup           = ModemSignalInterface + ".Setup"
+	ModemSignalSetupThresholds = ModemSignalInterface + ".SetupThresholds"
 	/* Property */
-	ModemSignalPropertyRate = ModemSignalInterface + ".Rate"
-	ModemSignalPropertyCdma = ModemSignalInterface + ".Cdma"
-	ModemSignalPropertyEvdo = ModemSignalInterface + ".Evdo"
-	ModemSignalPropertyGsm  = ModemSignalInterface + ".Gsm"
-	ModemSignalPropertyUmts = ModemSignalInterface + ".Umts"
-	ModemSignalPropertyLte  = ModemSignalInterface + ".Lte"
+	ModemSignalPropertyRate               = ModemSignalInterface + ".Rate"
+	ModemSignalPropertyRssiThreshold      = ModemSignalInterface + ".RssiThreshold"
+	ModemSignalPropertyErrorRateThreshold = ModemSignalInterface + ".ErrorRateThreshold"
+	ModemSignalPropertyCdma               = ModemSignalInterface + ".Cdma"
+	ModemSignalPropertyEvdo               = ModemSignalInterface + ".Evdo"
+	ModemSignalPropertyGsm                = ModemSignalInterface + ".Gsm"
+	ModemSignalPropertyUmts               = ModemSignalInterface + ".Umts"
+	ModemSignalPropertyLte                = ModemSignalInterface + ".Lte"
+	ModemSignalPropertyNr5g               = ModemSignalInterface + ".Nr5g"
 )
 
 // ModemSignal provides access to extended signal quality information.
@@ -37,11 +41,23 @@ type ModemSignal interface {
 	// refresh rate to set, in seconds. 0 to disable retrieval.
 	Setup(rate uint32) error
 
+	// Setup thresholds so that the device itself decides when to report the extended signal quality information
+	// updates. The thresholds are given by SignalThresholds, a rssi threshold of 0 and a disabled error rate threshold
+	// disable the threshold based reporting.
+	SetupThresholds(thresholds SignalThresholds) error
+
 	/* PROPERTIES */
 	//Refresh rate for the extended signal quality information updates, in seconds. A value of 0 disables the retrieval of the values.
 	GetRate() (rate uint32, err error)
 
-	// Returns all available cmda,evdo, gsm,umts or lte signal properties objects where rssi is set
+	// Threshold of the RSSI change, in dBm, which triggers an update of the signal quality information.
+	// A value of 0 disables the RSSI threshold.
+	GetRssiThreshold() (uint32, error)
+
+	// Whether a change of the error rate triggers an update of the signal quality information.
+	GetErrorRateThreshold() (bool, error)
+
+	// Returns all available cmda,evdo, gsm,umts, lte or 5g nr signal properties objects where rssi (any value for 5g nr) is set
 	GetCurrentSignals() (sp []SignalProperty, err error)
 
 	// The CDMA1x access technology.
@@ -58,6 +74,9 @@ type ModemSignal interface {
 
 	// The LTE access technology.
 	GetLte() (SignalProperty, error)
+
+	// The 5G NR access technology.
+	GetNr5g() (SignalProperty, error)
 }
 
 // NewModemSignal returns new ModemSignal Interface
@@ -66,35 +85,43 @@ func NewModemSignal(objectPath dbus.ObjectPath) (ModemSignal, error) {
 	return &si, si.init(ModemManagerInterface, objectPath)
 }
 
+// NewModemSignalWithConn returns new ModemSignal Interface using the given dbus connection
+func NewModemSignalWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (ModemSignal, error) {
+	var si modemSignal
+	return &si, si.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type modemSignal struct {
 	dbusBase
 }
 
 // SignalProperty represents all available signal properties
 type SignalProperty struct {
-	Type MMSignalPropertyType `json:"property-type"` // define the Signal Property Type
-	Rssi float64              `json:"rssi"`          // The CDMA1x / CDMA EV-DO / GSM / UMTS / LTE RSSI (Received Signal Strength Indication), in dBm, given as a floating point value (Applicable for all types).
-	Ecio float64              `json:"ecio"`          // The CDMA1x Ec/Io / CDMA EV-DO Ec/Io / UMTS Ec/Io level in dBm, given as a floating point value (Only applicable for type Cdma, Evdo, Umts).
-	Sinr float64              `json:"sinr"`          // CDMA EV-DO SINR level, in dB, given as a floating point value (Only applicable for type Evdo).
-	Io   float64              `json:"io"`            // The CDMA EV-DO Io, in dBm, given as a floating point value (Only applicable for type Evdo).
-	Rscp float64              `json:"rscp"`          // The UMTS RSCP (Received Signal Code Power), in dBm, given as a floating point value (Only applicable for type Umts).
-	Rsrq float64              `json:"rsrq"`          // The LTE RSRP (Reference Signal Received Power), in dBm, given as a floating point value (Only applicable for type LTE).
-	Rsrp float64              `json:"rsrp"`          // The LTE RSRP (Reference Signal Received Power), in dBm, given as a floating point value (Only applicable for type LTE).
-	Snr  float64              `json:"snr"`           // The LTE S/R ratio, in dB, given as a floating point value (Only applicable for type LTE).
+	Type      MMSignalPropertyType `json:"property-type"` // define the Signal Property Type
+	Rssi      float64              `json:"rssi"`          // The CDMA1x / CDMA EV-DO / GSM / UMTS / LTE RSSI (Received Signal Strength Indication), in dBm, given as a floating point value (Applicable for all types except Nr5g).
+	Ecio      float64              `json:"ecio"`          // The CDMA1x Ec/Io / CDMA EV-DO Ec/Io / UMTS Ec/Io level in dBm, given as a floating point value (Only applicable for type Cdma, Evdo, Umts).
+	Sinr      float64              `json:"sinr"`          // CDMA EV-DO SINR level, in dB, given as a floating point value (Only applicable for type Evdo).
+	Io        float64              `json:"io"`            // The CDMA EV-DO Io, in dBm, given as a floating point value (Only applicable for type Evdo).
+	Rscp      float64              `json:"rscp"`          // The UMTS RSCP (Received Signal Code Power), in dBm, given as a floating point value (Only applicable for type Umts).
+	Rsrq      float64              `json:"rsrq"`          // The LTE / 5G NR RSRQ (Reference Signal Received Quality), in dB, given as a floating point value (Only applicable for type LTE, Nr5g).
+	Rsrp      float64              `json:"rsrp"`          // The LTE / 5G NR RSRP (Reference Signal Received Power), in dBm, given as a floating point value (Only applicable for type LTE, Nr5g).
+	Snr       float64              `json:"snr"`           // The LTE / 5G NR S/R ratio, in dB, given as a floating point value (Only applicable for type LTE, Nr5g).
+	ErrorRate float64              `json:"error-rate"`    // Error rate, in percentage, given as a floating point value (Only applicable for type Gsm, Umts, LTE, Nr5g).
 }
 
 // MarshalJSON returns a byte array
 func (sp SignalProperty) MarshalJSON() ([]byte, error) {
 	return json.Marshal(map[string]interface{}{
-		"Type": fmt.Sprint(sp.Type),
-		"Rssi": sp.Rssi,
-		"Ecio": sp.Ecio,
-		"Sinr": sp.Sinr,
-		"Io":   sp.Io,
-		"Rscp": sp.Rscp,
-		"Rsrq": sp.Rsrq,
-		"Rsrp": sp.Rsrp,
-		"Snr":  sp.Snr,
+		"Type":      fmt.Sprint(sp.Type),
+		"Rssi":      sp.Rssi,
+		"Ecio":      sp.Ecio,
+		"Sinr":      sp.Sinr,
+		"Io":        sp.Io,
+		"Rscp":      sp.Rscp,
+		"Rsrq":      sp.Rsrq,
+		"Rsrp":      sp.Rsrp,
+		"Snr":       sp.Snr,
+		"ErrorRate": sp.ErrorRate,
 	})
 }
 
@@ -107,7 +134,48 @@ func (sp SignalProperty) String() string {
 		", Rscp: " + fmt.Sprint(sp.Rscp) +
 		", Rsrq: " + fmt.Sprint(sp.Rsrq) +
 		", Rsrp: " + fmt.Sprint(sp.Rsrp) +
-		", Snr: " + fmt.Sprint(sp.Snr)
+		", Snr: " + fmt.Sprint(sp.Snr) +
+		", ErrorRate: " + fmt.Sprint(sp.ErrorRate)
+}
+
+// signalPropertyValues are the json names of the values applicable to each signal type
+var signalPropertyValues = map[MMSignalPropertyType][]string{
+	MMSignalPropertyTypeCdma: {"rssi", "ecio"},
+	MMSignalPropertyTypeEvdo: {"rssi", "ecio", "sinr", "io"},
+	MMSignalPropertyTypeGsm:  {"rssi", "error-rate"},
+	MMSignalPropertyTypeUmts: {"rssi", "ecio", "rscp", "error-rate"},
+	MMSignalPropertyTypeLte:  {"rssi", "rsrq", "rsrp", "snr", "error-rate"},
+	MMSignalPropertyTypeNr5g: {"rsrq", "rsrp", "snr", "error-rate"},
+}
+
+// IsApplicable returns true if the value of the given json name, e.g. snr, is applicable to the signal type.
+// Applicable values of 0 are valid readings, e.g. a SNR of 0 dB.
+func (sp SignalProperty) IsApplicable(name string) bool {
+	for _, n := range signalPropertyValues[sp.Type] {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SignalThresholds represents the settings of the threshold based signal quality reporting
+type SignalThresholds struct {
+	RssiThreshold      uint32 `json:"rssi-threshold"`       // RSSI threshold, in dBm, given as an unsigned integer value (signature "u"). 0 disables the threshold.
+	ErrorRateThreshold bool   `json:"error-rate-threshold"` // Enables the error rate threshold, given as a boolean value (signature "b").
+}
+
+// MarshalJSON returns a byte array
+func (st SignalThresholds) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"RssiThreshold":      st.RssiThreshold,
+		"ErrorRateThreshold": st.ErrorRateThreshold,
+	})
+}
+
+func (st SignalThresholds) String() string {
+	return "RssiThreshold: " + fmt.Sprint(st.RssiThreshold) +
+		", ErrorRateThreshold: " + fmt.Sprint(st.ErrorRateThreshold)
 }
 func convertMapToSignalProperty(inputMap map[string]dbus.Variant, signalType MMSignalPropertyType) (sp SignalProperty) {
 	sp.Type = signalType
@@ -162,6 +230,12 @@ func convertMapToSignalProperty(inputMap map[string]dbus.Variant, signalType MMS
 				sp.Snr = tmpValue
 			}
 
+		case "error-rate":
+			tmpValue, ok := element.Value().(float64)
+			if ok {
+				sp.ErrorRate = tmpValue
+			}
+
 		}
 	}
 	return
@@ -175,10 +249,26 @@ func (si modemSignal) Setup(rate uint32) error {
 	return si.call(ModemSignalSetup, &rate)
 }
 
+func (si modemSignal) SetupThresholds(thresholds SignalThresholds) error {
+	settings := map[string]interface{}{
+		"rssi-threshold":       thresholds.RssiThreshold,
+		"error-rate-threshold": thresholds.ErrorRateThreshold,
+	}
+	return si.call(ModemSignalSetupThresholds, settings)
+}
+
 func (si modemSignal) GetRate() (rate uint32, err error) {
 	return si.getUint32Property(ModemSignalPropertyRate)
 }
 
+func (si modemSignal) GetRssiThreshold() (uint32, error) {
+	return si.getUint32Property(ModemSignalPropertyRssiThreshold)
+}
+
+func (si modemSignal) GetErrorRateThreshold() (bool, error) {
+	return si.getBoolProperty(ModemSignalPropertyErrorRateThreshold)
+}
+
 func (si modemSignal) GetCdma() (sp SignalProperty, err error) {
 	res, err := si.getMapStringVariantProperty(ModemSignalPropertyCdma)
 	if err != nil {
@@ -224,6 +314,15 @@ func (si modemSignal) GetLte() (sp SignalProperty, err error) {
 	sp = convertMapToSignalProperty(res, MMSignalPropertyTypeLte)
 	return
 }
+
+func (si modemSignal) GetNr5g() (sp SignalProperty, err error) {
+	res, err := si.getMapStringVariantProperty(ModemSignalPropertyNr5g)
+	if err != nil {
+		return
+	}
+	sp = convertMapToSignalProperty(res, MMSignalPropertyTypeNr5g)
+	return
+}
 func (si modemSignal) isRssiSet(sp SignalProperty) bool {
 	v := reflect.ValueOf(sp)
 	st := reflect.TypeOf(sp)
@@ -279,6 +378,12 @@ func (si modemSignal) GetCurrentSignals() (sp []SignalProperty, err error) {
 	if si.isRssiSet(mSignalLte) {
 		sp = append(sp, mSignalLte)
 	}
+
+	// not available before ModemManager 1.16, and 5G NR reports no rssi, so any value of the dictionary counts
+	nr5g, nrErr := si.getMapStringVariantProperty(ModemSignalPropertyNr5g)
+	if nrErr == nil && len(nr5g) > 0 {
+		sp = append(sp, convertMapToSignalProperty(nr5g, MMSignalPropertyTypeNr5g))
+	}
 	return
 
 }
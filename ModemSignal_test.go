@@ -0,0 +1,98 @@
+package modemmanager_test
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+func TestModemSignalNr5g(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	signal, err := modem.GetSignal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signals, err := signal.GetCurrentSignals(); err != nil || len(signals) != 0 {
+		t.Errorf("got signals %v, %v, want none", signals, err)
+	}
+	lte := mm.SignalProperty{Type: mm.MMSignalPropertyTypeLte, Rssi: -65, Rsrq: -10, Rsrp: -95.5, Snr: 12, ErrorRate: 0.5}
+	fake.SetExtendedSignal(lte)
+	// a 5G NR cell without rsrp is reported by its other values
+	nr5g := mm.SignalProperty{Type: mm.MMSignalPropertyTypeNr5g, Rsrq: -11.5, Snr: 4.5, ErrorRate: 2.5}
+	fake.SetExtendedSignal(nr5g)
+	if got, err := signal.GetNr5g(); err != nil || got != nr5g {
+		t.Errorf("got 5G NR signal %s, %v, want %s", got, err, nr5g)
+	}
+	if got, err := signal.GetLte(); err != nil || got != lte {
+		t.Errorf("got LTE signal %s, %v, want %s", got, err, lte)
+	}
+	signals, err := signal.GetCurrentSignals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signals) != 2 || signals[0] != lte || signals[1] != nr5g {
+		t.Errorf("got signals %v, want LTE and 5G NR", signals)
+	}
+}
+
+func TestModemSignalSetupThresholds(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	signal, err := modem.GetSignal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signal.SetupThresholds(mm.SignalThresholds{RssiThreshold: 5, ErrorRateThreshold: true}); err != nil {
+		t.Fatal(err)
+	}
+	var settings map[string]dbus.Variant
+	for _, call := range srv.Calls() {
+		if call.Method == mm.ModemSignalSetupThresholds {
+			settings = call.Args[0].(map[string]dbus.Variant)
+		}
+	}
+	want := map[string]dbus.Variant{
+		"rssi-threshold":       dbus.MakeVariant(uint32(5)),
+		"error-rate-threshold": dbus.MakeVariant(true),
+	}
+	if len(settings) != len(want) || settings["rssi-threshold"] != want["rssi-threshold"] ||
+		settings["error-rate-threshold"] != want["error-rate-threshold"] {
+		t.Errorf("got settings %v, want %v", settings, want)
+	}
+	if rssi, err := signal.GetRssiThreshold(); err != nil || rssi != 5 {
+		t.Errorf("got rssi threshold %d, %v, want 5", rssi, err)
+	}
+	if errorRate, err := signal.GetErrorRateThreshold(); err != nil || !errorRate {
+		t.Errorf("got error rate threshold %t, %v, want true", errorRate, err)
+	}
+}
+
+func TestSignalPropertyIsApplicable(t *testing.T) {
+	tests := []struct {
+		signalType mm.MMSignalPropertyType
+		name       string
+		want       bool
+	}{
+		{mm.MMSignalPropertyTypeCdma, "ecio", true},
+		{mm.MMSignalPropertyTypeCdma, "error-rate", false},
+		{mm.MMSignalPropertyTypeEvdo, "sinr", true},
+		{mm.MMSignalPropertyTypeGsm, "error-rate", true},
+		{mm.MMSignalPropertyTypeUmts, "rscp", true},
+		{mm.MMSignalPropertyTypeUmts, "rsrq", false},
+		{mm.MMSignalPropertyTypeLte, "snr", true},
+		{mm.MMSignalPropertyTypeLte, "ecio", false},
+		{mm.MMSignalPropertyTypeNr5g, "rsrq", true},
+		{mm.MMSignalPropertyTypeNr5g, "rssi", false},
+		{mm.MMSignalPropertyType(42), "rssi", false},
+	}
+	for _, tt := range tests {
+		if got := (mm.SignalProperty{Type: tt.signalType}).IsApplicable(tt.name); got != tt.want {
+			t.Errorf("%s IsApplicable(%q) = %t, want %t", tt.signalType, tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package modemmanager
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/godbus/dbus/v5"
@@ -38,11 +39,19 @@ type ModemSimple interface {
 	//create a new packet data bearer using the given "apn", and connect that bearer.
 	Connect(properties SimpleProperties) (Bearer, error)
 
+	// Same as Connect, but the call is canceled when ctx is done
+	ConnectWithContext(ctx context.Context, properties SimpleProperties) (Bearer, error)
+
 	// Disconnect an active packet data connection. while if "/" (ie, no object given) this method will disconnect all active packet data bearers.
 	Disconnect(bearer Bearer) error
 
+	// Same as Disconnect, but the call is canceled when ctx is done
+	DisconnectWithContext(ctx context.Context, bearer Bearer) error
+
 	// Get the general modem status.
 	GetStatus() (SimpleStatus, error)
+	// Same as GetStatus, but the call is canceled when ctx is done
+	GetStatusWithContext(ctx context.Context) (SimpleStatus, error)
 }
 
 // SimpleProperties defines all available properties
@@ -121,11 +130,21 @@ func NewModemSimple(objectPath dbus.ObjectPath) (ModemSimple, error) {
 	return &ms, ms.init(ModemManagerInterface, objectPath)
 }
 
+// NewModemSimpleWithConn returns new ModemSimple Interface using the given dbus connection
+func NewModemSimpleWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (ModemSimple, error) {
+	var ms modemSimple
+	return &ms, ms.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type modemSimple struct {
 	dbusBase
 }
 
 func (ms modemSimple) Connect(properties SimpleProperties) (Bearer, error) {
+	return ms.ConnectWithContext(context.Background(), properties)
+}
+
+func (ms modemSimple) ConnectWithContext(ctx context.Context, properties SimpleProperties) (Bearer, error) {
 	v := reflect.ValueOf(properties)
 	st := reflect.TypeOf(properties)
 	type dynMap interface{}
@@ -141,22 +160,30 @@ func (ms modemSimple) Connect(properties SimpleProperties) (Bearer, error) {
 		myMap[tag] = value
 	}
 	var path dbus.ObjectPath
-	err := ms.callWithReturn(&path, ModemSimpleConnect, &myMap)
+	err := ms.callWithReturnContext(ctx, &path, ModemSimpleConnect, &myMap)
 	if err != nil {
 		return nil, err
 	}
-	return NewBearer(path)
+	return NewBearerWithConn(ms.conn, path)
 }
 
 func (ms modemSimple) Disconnect(bearer Bearer) error {
-	return ms.call(ModemSimpleDisconnect, bearer.GetObjectPath())
+	return ms.DisconnectWithContext(context.Background(), bearer)
+}
+
+func (ms modemSimple) DisconnectWithContext(ctx context.Context, bearer Bearer) error {
+	return ms.callContext(ctx, ModemSimpleDisconnect, bearer.GetObjectPath())
 }
 
 func (ms modemSimple) GetStatus() (status SimpleStatus, err error) {
+	return ms.GetStatusWithContext(context.Background())
+}
+
+func (ms modemSimple) GetStatusWithContext(ctx context.Context) (status SimpleStatus, err error) {
 	type dynMap interface{}
 	var myMap map[string]dynMap
 	myMap = make(map[string]dynMap)
-	err = ms.callWithReturn(&myMap, ModemSimpleGetStatus)
+	err = ms.callWithReturnContext(ctx, &myMap, ModemSimpleGetStatus)
 	if err != nil {
 		return status, err
 	}
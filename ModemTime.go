@@ -59,9 +59,14 @@ func NewModemTime(objectPath dbus.ObjectPath) (ModemTime, error) {
 	return &ti, ti.init(ModemManagerInterface, objectPath)
 }
 
+// NewModemTimeWithConn returns new ModemTime Interface using the given dbus connection
+func NewModemTimeWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (ModemTime, error) {
+	var ti modemTime
+	return &ti, ti.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type modemTime struct {
 	dbusBase
-	sigChan chan *dbus.Signal
 }
 
 // Represents the TimeZone of the Modem
@@ -131,14 +136,7 @@ func (ti modemTime) GetNetworkTimezone() (mTz ModemTimeZone, err error) {
 }
 
 func (ti modemTime) SubscribeNetworkTimeChanged() <-chan *dbus.Signal {
-	if ti.sigChan != nil {
-		return ti.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", ModemTimeSignalNetworkTimeChanged, fmt.Sprint(ti.GetObjectPath()))
-	ti.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	ti.sigChan = make(chan *dbus.Signal, 10)
-	ti.conn.Signal(ti.sigChan)
-	return ti.sigChan
+	return ti.subscribeSignal(ModemTimeInterface, ModemTimeSignalNetworkTimeChanged, ti.GetObjectPath())
 }
 
 func (ti modemTime) ParseNetworkTimeChanged(v *dbus.Signal) (networkTime time.Time, err error) {
@@ -156,8 +154,7 @@ func (ti modemTime) ParseNetworkTimeChanged(v *dbus.Signal) (networkTime time.Ti
 }
 
 func (ti modemTime) Unsubscribe() {
-	ti.conn.RemoveSignal(ti.sigChan)
-	ti.sigChan = nil
+	ti.unsubscribeSignals()
 }
 
 func (ti modemTime) MarshalJSON() ([]byte, error) {
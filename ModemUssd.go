@@ -71,6 +71,12 @@ func NewUssd(objectPath dbus.ObjectPath) (Ussd, error) {
 	return &mu, mu.init(ModemManagerInterface, objectPath)
 }
 
+// NewUssdWithConn returns new ModemUssd Interface using the given dbus connection
+func NewUssdWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (Ussd, error) {
+	var mu ussd
+	return &mu, mu.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type ussd struct {
 	dbusBase
 }
@@ -118,10 +118,16 @@ func NewModemVoice(objectPath dbus.ObjectPath, modem modem) (ModemVoice, error)
 	return &vo, vo.init(ModemManagerInterface, objectPath)
 }
 
+// NewModemVoiceWithConn returns new ModemVoice Interface using the given dbus connection
+func NewModemVoiceWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath, modem modem) (ModemVoice, error) {
+	var vo modemVoice
+	vo.modem = modem
+	return &vo, vo.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type modemVoice struct {
 	modem modem
 	dbusBase
-	sigChan chan *dbus.Signal
 }
 
 func (m modemVoice) GetObjectPath() dbus.ObjectPath {
@@ -136,7 +142,7 @@ func (m modemVoice) ListCalls() (calls []Call, err error) {
 	}
 
 	for idx := range callPaths {
-		singleCall, err := NewCall(callPaths[idx])
+		singleCall, err := NewCallWithConn(m.conn, callPaths[idx])
 		if err != nil {
 			return nil, err
 		}
@@ -162,7 +168,7 @@ func (m modemVoice) CreateCall(number string, optionalParameters ...Pair) (c Cal
 	if err != nil {
 		return nil, err
 	}
-	singleCall, err := NewCall(path)
+	singleCall, err := NewCallWithConn(m.conn, path)
 	if err != nil {
 		return nil, err
 	}
@@ -199,7 +205,7 @@ func (m modemVoice) GetCalls() (c []Call, err error) {
 		return nil, err
 	}
 	for idx := range callPaths {
-		singleCall, err := NewCall(callPaths[idx])
+		singleCall, err := NewCallWithConn(m.conn, callPaths[idx])
 		if err != nil {
 			return nil, err
 		}
@@ -213,24 +219,10 @@ func (m modemVoice) GetEmergencyOnly() (bool, error) {
 }
 
 func (m modemVoice) SubscribeCallAdded() <-chan *dbus.Signal {
-	if m.sigChan != nil {
-		return m.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", ModemVoiceSignalCallAdded, fmt.Sprint(m.modem.GetObjectPath()))
-	m.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	m.sigChan = make(chan *dbus.Signal, 10)
-	m.conn.Signal(m.sigChan)
-	return m.sigChan
+	return m.subscribeSignal(ModemVoiceInterface, ModemVoiceSignalCallAdded, m.modem.GetObjectPath())
 }
 func (m modemVoice) SubscribeCallDeleted() <-chan *dbus.Signal {
-	if m.sigChan != nil {
-		return m.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", ModemVoiceSignalCallDeleted, fmt.Sprint(m.modem.GetObjectPath()))
-	m.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	m.sigChan = make(chan *dbus.Signal, 10)
-	m.conn.Signal(m.sigChan)
-	return m.sigChan
+	return m.subscribeSignal(ModemVoiceInterface, ModemVoiceSignalCallDeleted, m.modem.GetObjectPath())
 }
 
 func (m modemVoice) ParseCallAdded(v *dbus.Signal) (call Call, err error) {
@@ -249,12 +241,11 @@ func (m modemVoice) ParseCallAdded(v *dbus.Signal) (call Call, err error) {
 		return
 	}
 
-	return NewCall(path)
+	return NewCallWithConn(m.conn, path)
 }
 
 func (m modemVoice) Unsubscribe() {
-	m.conn.RemoveSignal(m.sigChan)
-	m.sigChan = nil
+	m.unsubscribeSignals()
 }
 
 func (m modemVoice) MarshalJSON() ([]byte, error) {
@@ -0,0 +1,308 @@
+package modemmanager_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+func TestModemProperties(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{
+		Manufacturer:        "Quectel",
+		Model:               "EC25",
+		EquipmentIdentifier: "861234567890123",
+		SignalQuality:       42,
+	})
+	manufacturer, err := modem.GetManufacturer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, err := modem.GetModel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	imei, err := modem.GetEquipmentIdentifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manufacturer != "Quectel" || model != "EC25" || imei != "861234567890123" {
+		t.Errorf("got %s %s %s, want Quectel EC25 861234567890123", manufacturer, model, imei)
+	}
+	quality, recent, err := modem.GetSignalQuality()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quality != 42 || !recent {
+		t.Errorf("signal quality = %d %v, want 42 true", quality, recent)
+	}
+	technologies, err := modem.GetAccessTechnologies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(technologies) != 1 || technologies[0] != mm.MmModemAccessTechnologyLte {
+		t.Errorf("access technologies = %v, want [lte]", technologies)
+	}
+	sim, err := modem.GetSim()
+	if err != nil {
+		t.Fatal(err)
+	}
+	imsi, err := sim.GetImsi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imsi != "262010000000001" {
+		t.Errorf("imsi = %s, want 262010000000001", imsi)
+	}
+}
+
+func TestModemEnableDisable(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	state, err := modem.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != mm.MmModemStateDisabled {
+		t.Fatalf("state = %s, want disabled", state)
+	}
+	if err := modem.Enable(); err != nil {
+		t.Fatal(err)
+	}
+	state, err = modem.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != mm.MmModemStateRegistered {
+		t.Errorf("state after enable = %s, want registered", state)
+	}
+	modem3gpp, err := modem.Get3gpp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	operatorCode, err := modem3gpp.GetOperatorCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if operatorCode != "26201" {
+		t.Errorf("operator code = %s, want 26201", operatorCode)
+	}
+	if err := modem.Disable(); err != nil {
+		t.Fatal(err)
+	}
+	state, err = modem.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != mm.MmModemStateDisabled {
+		t.Errorf("state after disable = %s, want disabled", state)
+	}
+}
+
+func TestModemSimPin(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{Sim: mmtest.SimConfig{Locked: true, Pin: "4711"}})
+	lock, err := modem.GetUnlockRequired()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock != mm.MmModemLockSimPin {
+		t.Fatalf("unlock required = %s, want sim-pin", lock)
+	}
+	err = modem.Enable()
+	if !errors.Is(err, mm.MmCoreErrorWrongState) {
+		t.Errorf("enable of a locked modem returned %v, want MmCoreErrorWrongState", err)
+	}
+	sim, err := modem.GetSim()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sim.SendPin("0000")
+	if !errors.Is(err, mm.MmMobileEquipmentErrorIncorrectPassword) {
+		t.Errorf("wrong pin returned %v, want MmMobileEquipmentErrorIncorrectPassword", err)
+	}
+	if err := sim.SendPin("4711"); err != nil {
+		t.Fatal(err)
+	}
+	if err := modem.Enable(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestModemSimpleConnect(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	simple, err := modem.GetSimpleModem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bearer, err := simple.Connect(mm.SimpleProperties{Apn: "internet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := modem.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != mm.MmModemStateConnected {
+		t.Errorf("state = %s, want connected", state)
+	}
+	status, err := simple.GetStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != mm.MmModemStateConnected || status.M3GppOperatorCode != "26201" {
+		t.Errorf("status = %s, want connected to 26201", status)
+	}
+	if err := simple.Disconnect(bearer); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.Bearers()) != 1 || fake.Bearers()[0].IsConnected() {
+		t.Errorf("bearer still connected after disconnect")
+	}
+}
+
+func TestModemError(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	srv.SetError(fake.GetObjectPath(), mm.ModemEnable, mm.MmMobileEquipmentErrorSimNotInserted)
+	err := modem.Enable()
+	if !errors.Is(err, mm.MmMobileEquipmentErrorSimNotInserted) {
+		t.Fatalf("enable returned %v, want MmMobileEquipmentErrorSimNotInserted", err)
+	}
+	srv.SetError(fake.GetObjectPath(), mm.ModemEnable, nil)
+	if err := modem.Enable(); err != nil {
+		t.Fatal(err)
+	}
+	calls := srv.Calls()
+	var enables int
+	for _, call := range calls {
+		if call.Path == fake.GetObjectPath() && call.Method == mm.ModemEnable {
+			enables++
+		}
+	}
+	if enables != 2 {
+		t.Errorf("recorded %d enable calls, want 2", enables)
+	}
+}
+
+func TestModemGetCellInfo(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	fake.SetCellInfo([]mm.CellInfo{
+		&mm.CellInfoLte{Serving: true, OperatorId: "26201", Tac: "b3c4", Ci: "1a2d005", PhysicalCi: "f1", Earfcn: 6300,
+			Rsrp: -95.5, Rsrq: -10, TimingAdvance: 3, Bandwidth: 20000},
+		&mm.CellInfoGsm{OperatorId: "26201", Lac: "1a2b", Ci: "3c4d", Arfcn: 62},
+	})
+	cells, err := modem.GetCellInfoWithContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cells) != 2 {
+		t.Fatalf("got cells %v, want the serving and the neighboring cell", cells)
+	}
+	lte, ok := cells[0].(*mm.CellInfoLte)
+	if !ok || !lte.IsServing() || lte.Ci != "1a2d005" || lte.Earfcn != 6300 || lte.Rsrp != -95.5 || lte.Bandwidth != 20000 {
+		t.Errorf("got serving cell %+v", cells[0])
+	}
+	gsm, ok := cells[1].(*mm.CellInfoGsm)
+	if !ok || gsm.IsServing() || gsm.Lac != "1a2b" || gsm.Arfcn != 62 {
+		t.Errorf("got neighboring cell %+v", cells[1])
+	}
+
+	srv.SetError(fake.GetObjectPath(), mm.ModemGetCellInfo, mm.MmCoreErrorUnsupported)
+	if _, err := modem.GetCellInfo(); !errors.Is(err, mm.MmCoreErrorUnsupported) {
+		t.Errorf("got error %v, want MmCoreErrorUnsupported", err)
+	}
+}
+
+// waitForModem waits until ModemManager lists a modem other than the given one and returns it
+func waitForModem(t *testing.T, mmgr mm.ModemManager, old dbus.ObjectPath) mm.Modem {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		modems, err := mmgr.GetModems()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, modem := range modems {
+			if modem.GetObjectPath() != old {
+				return modem
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("modem not reprobed")
+	return nil
+}
+
+func TestModemSimSlots(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{SimSlots: []mmtest.SimConfig{
+		{SimIdentifier: "8949000000000000011"},
+		{Empty: true},
+		{SimIdentifier: "8949000000000000033", SimType: mm.MmSimTypeEsim},
+	}})
+	sims, err := modem.GetSimSlots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sims) != 3 || sims[1] != nil {
+		t.Fatalf("got sims %v, want 3 slots with the second one empty", sims)
+	}
+	for i, want := range []string{"8949000000000000011", "", "8949000000000000033"} {
+		if sims[i] == nil {
+			continue
+		}
+		if id, err := sims[i].GetSimIdentifier(); err != nil || id != want {
+			t.Errorf("slot %d: got sim %s, %v, want %s", i+1, id, err, want)
+		}
+		if active, err := sims[i].GetActive(); err != nil || active != (i == 0) {
+			t.Errorf("slot %d: got active %t, %v", i+1, active, err)
+		}
+	}
+	if slot, err := modem.GetPrimarySimSlot(); err != nil || slot != 1 {
+		t.Errorf("got primary slot %d, %v, want 1", slot, err)
+	}
+	if err := modem.SetPrimarySimSlot(4); !errors.Is(err, mm.MmCoreErrorInvalidArgs) {
+		t.Errorf("got error %v of a missing slot, want MmCoreErrorInvalidArgs", err)
+	}
+
+	// the modem is reprobed with the sim of the new primary slot
+	mmgr, err := mm.NewModemManagerWithConn(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := modem.SetPrimarySimSlot(3); err != nil {
+		t.Fatal(err)
+	}
+	reprobed := waitForModem(t, mmgr, fake.GetObjectPath())
+	if slot, err := reprobed.GetPrimarySimSlot(); err != nil || slot != 3 {
+		t.Errorf("got primary slot %d, %v, want 3", slot, err)
+	}
+	sim, err := reprobed.GetSim()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, err := sim.GetSimIdentifier(); err != nil || id != "8949000000000000033" {
+		t.Errorf("got sim %s, %v of the primary slot", id, err)
+	}
+	if simType, err := sim.GetSimType(); err != nil || simType != mm.MmSimTypeEsim {
+		t.Errorf("got sim type %s, %v, want esim", simType, err)
+	}
+	if sims, err := reprobed.GetSimSlots(); err != nil || len(sims) != 3 || sims[1] != nil {
+		t.Errorf("got sims %v, %v after switching, want the same slots", sims, err)
+	}
+}
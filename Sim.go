@@ -1,7 +1,9 @@
 package modemmanager
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/godbus/dbus/v5"
 )
@@ -16,12 +18,20 @@ const (
 	SimEnablePin   = SimInterface + ".EnablePin"
 	SimChangePin   = SimInterface + ".ChangePin"
 
+	SimSetPreferredNetworks = SimInterface + ".SetPreferredNetworks"
+
 	/* Property */
 	SimPropertySimIdentifier      = SimInterface + ".SimIdentifier"      // readable   s
 	SimPropertyImsi               = SimInterface + ".Imsi"               // readable   s
 	SimPropertyOperatorIdentifier = SimInterface + ".OperatorIdentifier" // readable   s
 	SimPropertyOperatorName       = SimInterface + ".OperatorName"       // readable   s
 	SimPropertyEmergencyNumbers   = SimInterface + ".EmergencyNumbers"   // readable   as
+	SimPropertyActive             = SimInterface + ".Active"             // readable   b
+	SimPropertySimType            = SimInterface + ".SimType"            // readable   u
+	SimPropertyEid                = SimInterface + ".Eid"                // readable   s
+	SimPropertyEsimStatus         = SimInterface + ".EsimStatus"         // readable   u
+	SimPropertyRemovability       = SimInterface + ".Removability"       // readable   u
+	SimPropertyPreferredNetworks  = SimInterface + ".PreferredNetworks"  // readable   a(su)
 
 )
 
@@ -35,19 +45,38 @@ type Sim interface {
 	// Send the PIN to unlock the SIM card.
 	SendPin(pin string) error
 
+	// Same as SendPin, but the call is canceled when ctx is done
+	SendPinWithContext(ctx context.Context, pin string) error
+
 	// Send the PUK and a new PIN to unlock the SIM card.
 	SendPuk(pin string, puk string) error
 
+	// Same as SendPuk, but the call is canceled when ctx is done
+	SendPukWithContext(ctx context.Context, pin string, puk string) error
+
 	// Enable or disable the PIN checking.
 	//		IN s pin: A string containing the PIN code.
 	//		IN b enabled: TRUE to enable PIN checking, FALSE otherwise.
 	EnablePin(pin string, enable bool) error
 
+	// Same as EnablePin, but the call is canceled when ctx is done
+	EnablePinWithContext(ctx context.Context, pin string, enable bool) error
+
 	// Change the PIN code.
 	// 		IN s old_pin: A string containing the current PIN code.
 	// I	N s new_pin: A string containing the new PIN code.
 	ChangePin(oldPin string, newPin string) error
 
+	// Same as ChangePin, but the call is canceled when ctx is done
+	ChangePinWithContext(ctx context.Context, oldPin string, newPin string) error
+
+	// Stores the provided preferred network list to the SIM card, replacing the previous list.
+	// 		IN a(su) preferred_networks: the preferred networks, see SimPreferredNetwork
+	SetPreferredNetworks(networks []SimPreferredNetwork) error
+
+	// Same as SetPreferredNetworks, but the call is canceled when ctx is done
+	SetPreferredNetworksWithContext(ctx context.Context, networks []SimPreferredNetwork) error
+
 	/* PROPERTIES */
 
 	// The ICCID of the SIM card.
@@ -67,6 +96,30 @@ type Sim interface {
 	// These numbers should be treated as numbers for emergency calls in addition to 112 and 911.
 	GetEmergencyNumbers() ([]string, error)
 
+	// Boolean indicating whether the SIM is currently active.
+	// On systems that support Multi SIM Single Standby, only one SIM may be active at any given time, which will be
+	// the one considered primary.
+	// On systems that support Multi SIM Multi Standby, more than one SIM may be active at any given time, but only
+	// one of them is considered primary.
+	GetActive() (bool, error)
+
+	// Indicates whether the current primary SIM is a ESIM or a physical SIM, given as MMSimType value.
+	GetSimType() (MMSimType, error)
+
+	// The EID of the SIM card, if any.
+	GetEid() (string, error)
+
+	// If current SIM is ESIM then this indicates whether there is a profile or not, given as MMSimEsimStatus value.
+	GetEsimStatus() (MMSimEsimStatus, error)
+
+	// Indicates whether the current SIM is a removable SIM or not, given as a MMSimRemovability value.
+	GetRemovability() (MMSimRemovability, error)
+
+	// List of preferred networks with access technologies configured in the SIM card.
+	// Each entry contains an operator id string ("MCCMNC") consisting of 5 or 6 digits, and the access technologies
+	// of the network.
+	GetPreferredNetworks() ([]SimPreferredNetwork, error)
+
 	MarshalJSON() ([]byte, error)
 
 	/* SIGNALS */
@@ -89,28 +142,85 @@ func NewSim(objectPath dbus.ObjectPath) (Sim, error) {
 	return &sm, sm.init(ModemManagerInterface, objectPath)
 }
 
+// NewSimWithConn returns new Sim Interface using the given dbus connection
+func NewSimWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (Sim, error) {
+	var sm sim
+	return &sm, sm.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type sim struct {
 	dbusBase
-	sigChan chan *dbus.Signal
+}
+
+// SimPreferredNetwork represents an entry of the preferred network list of the SIM card
+type SimPreferredNetwork struct {
+	OperatorCode       string                    `json:"operator-code"`       // The operator id ("MCCMNC")
+	AccessTechnologies []MMModemAccessTechnology `json:"access-technologies"` // The access technologies of the network
+}
+
+// MarshalJSON returns a byte array
+func (spn SimPreferredNetwork) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"OperatorCode":       spn.OperatorCode,
+		"AccessTechnologies": fmt.Sprint(spn.AccessTechnologies),
+	})
+}
+
+func (spn SimPreferredNetwork) String() string {
+	return "OperatorCode: " + spn.OperatorCode +
+		", AccessTechnologies: " + fmt.Sprint(spn.AccessTechnologies)
 }
 
 func (sm sim) GetObjectPath() dbus.ObjectPath {
 	return sm.obj.Path()
 }
 func (sm sim) SendPin(pin string) error {
-	return sm.call(SimSendPin, &pin)
+	return sm.SendPinWithContext(context.Background(), pin)
+}
+
+func (sm sim) SendPinWithContext(ctx context.Context, pin string) error {
+	return sm.callContext(ctx, SimSendPin, &pin)
 }
 
 func (sm sim) SendPuk(pin string, puk string) error {
-	return sm.call(SimSendSendPuk, &pin, &puk)
+	return sm.SendPukWithContext(context.Background(), pin, puk)
+}
+
+func (sm sim) SendPukWithContext(ctx context.Context, pin string, puk string) error {
+	return sm.callContext(ctx, SimSendSendPuk, &pin, &puk)
 }
 
 func (sm sim) EnablePin(pin string, enable bool) error {
-	return sm.call(SimEnablePin, &pin, &enable)
+	return sm.EnablePinWithContext(context.Background(), pin, enable)
+}
+
+func (sm sim) EnablePinWithContext(ctx context.Context, pin string, enable bool) error {
+	return sm.callContext(ctx, SimEnablePin, &pin, &enable)
 }
 
 func (sm sim) ChangePin(oldPin string, newPin string) error {
-	return sm.call(SimChangePin, &oldPin, &newPin)
+	return sm.ChangePinWithContext(context.Background(), oldPin, newPin)
+}
+
+func (sm sim) ChangePinWithContext(ctx context.Context, oldPin string, newPin string) error {
+	return sm.callContext(ctx, SimChangePin, &oldPin, &newPin)
+}
+
+func (sm sim) SetPreferredNetworks(networks []SimPreferredNetwork) error {
+	return sm.SetPreferredNetworksWithContext(context.Background(), networks)
+}
+
+func (sm sim) SetPreferredNetworksWithContext(ctx context.Context, networks []SimPreferredNetwork) error {
+	type preferredNetwork struct {
+		OperatorCode       string
+		AccessTechnologies uint32
+	}
+	var tmp MMModemAccessTechnology
+	list := make([]preferredNetwork, 0, len(networks))
+	for _, n := range networks {
+		list = append(list, preferredNetwork{n.OperatorCode, tmp.SliceToBitmask(n.AccessTechnologies)})
+	}
+	return sm.callContext(ctx, SimSetPreferredNetworks, list)
 }
 
 func (sm sim) GetSimIdentifier() (string, error) {
@@ -133,23 +243,70 @@ func (sm sim) GetEmergencyNumbers() ([]string, error) {
 	return sm.getSliceStringProperty(SimPropertyEmergencyNumbers)
 }
 
-func (sm sim) SubscribePropertiesChanged() <-chan *dbus.Signal {
-	if sm.sigChan != nil {
-		return sm.sigChan
+func (sm sim) GetActive() (bool, error) {
+	return sm.getBoolProperty(SimPropertyActive)
+}
+
+func (sm sim) GetSimType() (MMSimType, error) {
+	res, err := sm.getUint32Property(SimPropertySimType)
+	if err != nil {
+		return MmSimTypeUnknown, err
+	}
+	return MMSimType(res), nil
+}
+
+func (sm sim) GetEid() (string, error) {
+	return sm.getStringProperty(SimPropertyEid)
+}
+
+func (sm sim) GetEsimStatus() (MMSimEsimStatus, error) {
+	res, err := sm.getUint32Property(SimPropertyEsimStatus)
+	if err != nil {
+		return MmSimEsimStatusUnknown, err
+	}
+	return MMSimEsimStatus(res), nil
+}
+
+func (sm sim) GetRemovability() (MMSimRemovability, error) {
+	res, err := sm.getUint32Property(SimPropertyRemovability)
+	if err != nil {
+		return MmSimRemovabilityUnknown, err
 	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", dbusPropertiesChanged, fmt.Sprint(sm.GetObjectPath()))
-	sm.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	sm.sigChan = make(chan *dbus.Signal, 10)
-	sm.conn.Signal(sm.sigChan)
-	return sm.sigChan
+	return MMSimRemovability(res), nil
+}
+
+func (sm sim) GetPreferredNetworks() (networks []SimPreferredNetwork, err error) {
+	res, err := sm.getSliceSlicePairProperty(SimPropertyPreferredNetworks)
+	if err != nil {
+		return nil, err
+	}
+	var tmp MMModemAccessTechnology
+	for _, e := range res {
+		operatorCode, ok := e.GetLeft().(string)
+		if !ok {
+			return nil, errors.New("wrong type")
+		}
+		accessTechnologies, ok := e.GetRight().(uint32)
+		if !ok {
+			return nil, errors.New("wrong type")
+		}
+		networks = append(networks, SimPreferredNetwork{
+			OperatorCode:       operatorCode,
+			AccessTechnologies: tmp.BitmaskToSlice(accessTechnologies),
+		})
+	}
+	return
+}
+
+func (sm sim) SubscribePropertiesChanged() <-chan *dbus.Signal {
+	return sm.subscribeSignal(dbusPropertiesInterface, dbusPropertiesChanged, sm.GetObjectPath())
 }
 func (sm sim) ParsePropertiesChanged(v *dbus.Signal) (interfaceName string, changedProperties map[string]dbus.Variant, invalidatedProperties []string, err error) {
 	return sm.parsePropertiesChanged(v)
 }
 
 func (sm sim) Unsubscribe() {
-	sm.conn.RemoveSignal(sm.sigChan)
-	sm.sigChan = nil
+	sm.unsubscribeSignals()
 }
 
 func (sm sim) MarshalJSON() ([]byte, error) {
@@ -0,0 +1,36 @@
+package modemmanager_test
+
+import (
+	"reflect"
+	"testing"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+func TestSimPreferredNetworks(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	sim, err := modem.GetSim()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if networks, err := sim.GetPreferredNetworks(); err != nil || len(networks) != 0 {
+		t.Errorf("got preferred networks %v, %v, want none", networks, err)
+	}
+	networks := []mm.SimPreferredNetwork{
+		{OperatorCode: "26201", AccessTechnologies: []mm.MMModemAccessTechnology{mm.MmModemAccessTechnologyGsm, mm.MmModemAccessTechnologyLte}},
+		{OperatorCode: "310410", AccessTechnologies: []mm.MMModemAccessTechnology{mm.MmModemAccessTechnologyUmts}},
+	}
+	if err := sim.SetPreferredNetworks(networks); err != nil {
+		t.Fatal(err)
+	}
+	got, err := sim.GetPreferredNetworks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, networks) {
+		t.Errorf("got preferred networks %v, want %v", got, networks)
+	}
+}
@@ -1,9 +1,9 @@
 package modemmanager
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"github.com/godbus/dbus/v5"
 	"time"
 )
@@ -49,10 +49,16 @@ type Sms interface {
 	// If the message has not yet been sent, queue it for delivery.
 	Send() error
 
+	// Same as Send, but the call is canceled when ctx is done
+	SendWithContext(ctx context.Context) error
+
 	// This method requires a MMSmsStorage value, describing the storage where this message is to be kept; or
 	// MM_SMS_STORAGE_UNKNOWN if the default storage should be used.
 	Store(MMSmsStorage) error
 
+	// Same as Store, but the call is canceled when ctx is done
+	StoreWithContext(ctx context.Context, storage MMSmsStorage) error
+
 	/* PROPERTIES */
 
 	// A MMSmsState value, describing the state of the message.
@@ -142,9 +148,14 @@ func NewSms(objectPath dbus.ObjectPath) (Sms, error) {
 	return &ss, ss.init(ModemManagerInterface, objectPath)
 }
 
+// NewSmsWithConn returns new Sms Interface using the given dbus connection
+func NewSmsWithConn(conn *dbus.Conn, objectPath dbus.ObjectPath) (Sms, error) {
+	var ss sms
+	return &ss, ss.initWithConn(conn, ModemManagerInterface, objectPath)
+}
+
 type sms struct {
 	dbusBase
-	sigChan chan *dbus.Signal
 }
 
 func (ss sms) GetObjectPath() dbus.ObjectPath {
@@ -152,11 +163,19 @@ func (ss sms) GetObjectPath() dbus.ObjectPath {
 }
 
 func (ss sms) Send() error {
-	return ss.call(SmsSend)
+	return ss.SendWithContext(context.Background())
 }
 
-func (ss sms) Store(MMSmsStorage) error {
-	return ss.call(SmsStore)
+func (ss sms) SendWithContext(ctx context.Context) error {
+	return ss.callContext(ctx, SmsSend)
+}
+
+func (ss sms) Store(storage MMSmsStorage) error {
+	return ss.StoreWithContext(context.Background(), storage)
+}
+
+func (ss sms) StoreWithContext(ctx context.Context, storage MMSmsStorage) error {
+	return ss.callContext(ctx, SmsStore, storage)
 }
 
 func (ss sms) GetState() (MMSmsState, error) {
@@ -292,22 +311,14 @@ func (ss sms) GetStorage() (MMSmsStorage, error) {
 }
 
 func (ss sms) SubscribePropertiesChanged() <-chan *dbus.Signal {
-	if ss.sigChan != nil {
-		return ss.sigChan
-	}
-	rule := fmt.Sprintf("type='signal', member='%s',path_namespace='%s'", dbusPropertiesChanged, fmt.Sprint(ss.GetObjectPath()))
-	ss.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
-	ss.sigChan = make(chan *dbus.Signal, 10)
-	ss.conn.Signal(ss.sigChan)
-	return ss.sigChan
+	return ss.subscribeSignal(dbusPropertiesInterface, dbusPropertiesChanged, ss.GetObjectPath())
 }
 func (ss sms) ParsePropertiesChanged(v *dbus.Signal) (interfaceName string, changedProperties map[string]dbus.Variant, invalidatedProperties []string, err error) {
 	return ss.parsePropertiesChanged(v)
 }
 
 func (ss sms) Unsubscribe() {
-	ss.conn.RemoveSignal(ss.sigChan)
-	ss.sigChan = nil
+	ss.unsubscribeSignals()
 }
 
 func (ss sms) MarshalJSON() ([]byte, error) {
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/maltegrosse/go-modemmanager"
+)
+
+var ipTypes = map[string]modemmanager.MMBearerIpFamily{
+	"ipv4":   modemmanager.MmBearerIpFamilyIpv4,
+	"ipv6":   modemmanager.MmBearerIpFamilyIpv6,
+	"ipv4v6": modemmanager.MmBearerIpFamilyIpv4v6,
+}
+
+func runConnect(c *cli, args []string) error {
+	fs := newFlagSet("connect", "-apn APN [-user USER] [-password PASSWORD] [-ip-type ipv4|ipv6|ipv4v6] [-pin PIN] [-allow-roaming]")
+	apn := fs.String("apn", "", "the access point name")
+	user := fs.String("user", "", "the user name required by the network")
+	password := fs.String("password", "", "the password required by the network")
+	ipType := fs.String("ip-type", "", "the IP addressing type, one of ipv4, ipv6 or ipv4v6")
+	pin := fs.String("pin", "", "the SIM-PIN to unlock the sim")
+	allowRoaming := fs.Bool("allow-roaming", false, "allow the connection in roaming networks")
+	fs.Parse(args)
+	properties := modemmanager.SimpleProperties{
+		Apn:            *apn,
+		User:           *user,
+		Password:       *password,
+		Pin:            *pin,
+		AllowedRoaming: *allowRoaming,
+	}
+	if *ipType != "" {
+		ipFamily, ok := ipTypes[strings.ToLower(*ipType)]
+		if !ok {
+			return errors.New("unknown ip type " + *ipType)
+		}
+		properties.IpType = ipFamily
+	}
+	modem, err := c.modem()
+	if err != nil {
+		return err
+	}
+	modemSimple, err := modem.GetSimpleModem()
+	if err != nil {
+		return err
+	}
+	bearer, err := modemSimple.Connect(properties)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(bearer)
+	}
+	fmt.Fprintln(c.out, "successfully connected the modem, bearer "+string(bearer.GetObjectPath()))
+	return nil
+}
+
+func runDisconnect(c *cli, args []string) error {
+	fs := newFlagSet("disconnect", "[bearer]")
+	fs.Parse(args)
+	modem, err := c.modem()
+	if err != nil {
+		return err
+	}
+	modemSimple, err := modem.GetSimpleModem()
+	if err != nil {
+		return err
+	}
+	bearers, err := modem.GetBearers()
+	if err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		// the bearer is given as index or object path
+		name := fs.Arg(0)
+		var selected []modemmanager.Bearer
+		for _, bearer := range bearers {
+			objectPath := string(bearer.GetObjectPath())
+			if name == objectPath || name == path.Base(objectPath) {
+				selected = append(selected, bearer)
+			}
+		}
+		if len(selected) == 0 {
+			return errors.New("bearer " + name + " not found")
+		}
+		bearers = selected
+	}
+	for _, bearer := range bearers {
+		connected, err := bearer.GetConnected()
+		if err != nil || !connected {
+			continue
+		}
+		if err = modemSimple.Disconnect(bearer); err != nil {
+			return err
+		}
+		fmt.Fprintln(c.out, "successfully disconnected bearer "+string(bearer.GetObjectPath()))
+	}
+	return nil
+}
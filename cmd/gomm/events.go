@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/maltegrosse/go-modemmanager"
+)
+
+func runEvents(c *cli, args []string) error {
+	fs := newFlagSet("events", "[-interface INTERFACE]")
+	iface := fs.String("interface", "", "only stream the events of the given interface, e.g. "+modemmanager.ModemInterface)
+	fs.Parse(args)
+	filter := modemmanager.EventFilter{Interface: *iface}
+	// the events of a modem are sent by the modem and its bearers, messages and calls, so they can't be selected
+	// by the path of the filter
+	var objects *modemObjects
+	if c.modemName != "" {
+		modem, err := c.modem()
+		if err != nil {
+			return err
+		}
+		objects = &modemObjects{modem: modem}
+	}
+	dispatcher, err := modemmanager.NewEventDispatcherWithConn(c.conn)
+	if err != nil {
+		return err
+	}
+	defer dispatcher.Close()
+	subscription := dispatcher.Subscribe(filter, 64)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	for {
+		select {
+		case <-interrupt:
+			return nil
+		case e, ok := <-subscription.Events():
+			if !ok {
+				return nil
+			}
+			if objects != nil && !objects.contains(e.GetObjectPath()) {
+				continue
+			}
+			if err := c.printEvent(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// printEvent prints an event as line, with -json as one JSON object per line
+func (c *cli) printEvent(e modemmanager.Event) error {
+	now := time.Now()
+	if !c.json {
+		fmt.Fprintf(c.out, "%s %s\n", now.Format(time.RFC3339), e)
+		return nil
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"Time":       now,
+		"Type":       reflect.TypeOf(e).Name(),
+		"ObjectPath": e.GetObjectPath(),
+		"Interface":  e.GetInterface(),
+		"Event":      fmt.Sprint(e),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, string(data))
+	return nil
+}
+
+// modemObjects matches the paths of a modem and of its bearers, messages and calls
+type modemObjects struct {
+	modem modemmanager.Modem
+	paths map[dbus.ObjectPath]bool
+}
+
+// contains returns true for the modem path and the paths of its objects. The objects are listed again for an
+// unknown path, e.g. of a new bearer. The paths of removed objects are kept, so their last events still match.
+func (o *modemObjects) contains(path dbus.ObjectPath) bool {
+	if path == o.modem.GetObjectPath() || o.paths[path] {
+		return true
+	}
+	if o.paths == nil {
+		o.paths = make(map[dbus.ObjectPath]bool)
+	}
+	// errors are ignored, e.g. if the modem has no messaging or voice interface
+	if bearers, err := o.modem.GetBearers(); err == nil {
+		for _, bearer := range bearers {
+			o.paths[bearer.GetObjectPath()] = true
+		}
+	}
+	if messaging, err := o.modem.GetMessaging(); err == nil {
+		if messages, err := messaging.List(); err == nil {
+			for _, sms := range messages {
+				o.paths[sms.GetObjectPath()] = true
+			}
+		}
+	}
+	if voice, err := o.modem.GetVoice(); err == nil {
+		if calls, err := voice.ListCalls(); err == nil {
+			for _, call := range calls {
+				o.paths[call.GetObjectPath()] = true
+			}
+		}
+	}
+	return o.paths[path]
+}
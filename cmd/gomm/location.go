@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"strings"
+
+	"github.com/maltegrosse/go-modemmanager"
+)
+
+// parseLocationSources parses a comma separated list of location sources, e.g. "3gpp-lac-ci,gps-nmea" or
+// "3gppLacCi,GpsNmea"
+func parseLocationSources(value string) (sources []modemmanager.MMModemLocationSource, err error) {
+	if value == "" {
+		return nil, nil
+	}
+	var tmp modemmanager.MMModemLocationSource
+	for _, name := range strings.Split(value, ",") {
+		normalized := strings.ToLower(strings.Replace(strings.TrimSpace(name), "-", "", -1))
+		found := false
+		for _, source := range tmp.GetAllSources() {
+			if strings.ToLower(source.String()) == normalized {
+				sources = append(sources, source)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("unknown location source " + name)
+		}
+	}
+	return sources, nil
+}
+
+func runLocation(c *cli, args []string) error {
+	fs := newFlagSet("location", "[-enable source,...] [-disable source,...] [-signal]")
+	enable := fs.String("enable", "", "location sources to enable, e.g. 3gpp-lac-ci,gps-raw,gps-nmea")
+	disable := fs.String("disable", "", "location sources to disable")
+	signal := fs.Bool("signal", false, "signal location updates through the Location property")
+	fs.Parse(args)
+	enableSources, err := parseLocationSources(*enable)
+	if err != nil {
+		return err
+	}
+	disableSources, err := parseLocationSources(*disable)
+	if err != nil {
+		return err
+	}
+	signalSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "signal" {
+			signalSet = true
+		}
+	})
+
+	modem, err := c.modem()
+	if err != nil {
+		return err
+	}
+	location, err := modem.GetLocation()
+	if err != nil {
+		return err
+	}
+	if len(enableSources) > 0 || len(disableSources) > 0 || signalSet {
+		current, err := location.GetEnabledLocationSources()
+		if err != nil {
+			return err
+		}
+		signalsLocation, err := location.GetSignalsLocation()
+		if err != nil {
+			return err
+		}
+		if signalSet {
+			signalsLocation = *signal
+		}
+		var sources []modemmanager.MMModemLocationSource
+		for _, source := range append(current, enableSources...) {
+			if !containsSource(disableSources, source) && !containsSource(sources, source) {
+				sources = append(sources, source)
+			}
+		}
+		if err = location.Setup(sources, signalsLocation); err != nil {
+			return err
+		}
+	}
+	if c.json {
+		return c.printJSON(location)
+	}
+
+	capabilities, _ := location.GetCapabilities()
+	enabled, _ := location.GetEnabledLocationSources()
+	signalsLocation, _ := location.GetSignalsLocation()
+	c.printSection("Location",
+		field{"capabilities", joinValues(capabilities)},
+		field{"enabled", joinValues(enabled)},
+		field{"signals", signalsLocation},
+	)
+	current, err := location.GetCurrentLocation()
+	if err != nil {
+		return err
+	}
+	lacCi := current.ThreeGppLacCi
+	if lacCi.Mcc != "" {
+		c.printSection("3GPP",
+			field{"mobile country code", lacCi.Mcc},
+			field{"mobile network code", lacCi.Mnc},
+			field{"location area code", lacCi.Lac},
+			field{"tracking area code", lacCi.Tac},
+			field{"cell id", lacCi.Ci},
+		)
+	}
+	gpsRaw := current.GpsRaw
+	if gpsRaw.Latitude != 0 || gpsRaw.Longitude != 0 {
+		c.printSection("GPS",
+			field{"utc time", gpsRaw.UtcTime},
+			field{"latitude", gpsRaw.Latitude},
+			field{"longitude", gpsRaw.Longitude},
+			field{"altitude", gpsRaw.Altitude},
+		)
+	}
+	if len(current.GpsNmea.NmeaSentences) > 0 {
+		c.printSection("NMEA", field{"sentences", strings.Join(current.GpsNmea.NmeaSentences, "\n    ")})
+	}
+	cdmaBs := current.CdmaBs
+	if cdmaBs.Latitude != 0 || cdmaBs.Longitude != 0 {
+		c.printSection("CDMA BS",
+			field{"latitude", cdmaBs.Latitude},
+			field{"longitude", cdmaBs.Longitude},
+		)
+	}
+	return nil
+}
+
+func containsSource(sources []modemmanager.MMModemLocationSource, source modemmanager.MMModemLocationSource) bool {
+	for _, s := range sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+// Command gomm controls ModemManager from the command line, similar to mmcli, but on top of this library, so the
+// output matches what a Go integration sees.
+//
+// Usage:
+//
+//	gomm [-json] [-m modem] <command> [arguments]
+//
+// The commands are:
+//
+//	list                                  list the available modems
+//	status                                print the status of the modem
+//	enable, disable                       enable or disable the modem
+//	connect -apn APN [-user U] [-password P] [-ip-type ipv4|ipv6|ipv4v6]
+//	                                      connect a bearer with the simple interface
+//	disconnect [bearer]                   disconnect the given or all bearers
+//	sms-list                              list the messages of the modem
+//	sms-send -number NUMBER -text TEXT    create and send a message
+//	ussd [-cancel] [command]              initiate or respond to a USSD session
+//	location [-enable src,...] [-disable src,...] [-signal]
+//	                                      toggle location sources and print the location
+//	events [-interface INTERFACE]         stream the events of all modems, or with -m of the modem and its
+//	                                      bearers, messages and calls, until interrupted
+//
+// The modem is selected by -m, given as index (the last element of the object path), object path, equipment
+// identifier or device identifier. If no modem is given, the only available modem is used.
+// With -json the status is printed using the MarshalJSON methods of the library.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/maltegrosse/go-modemmanager"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(c *cli, args []string) error
+}
+
+var commands = []command{
+	{"list", "list the available modems", runList},
+	{"status", "print the status of the modem", runStatus},
+	{"enable", "enable the modem", runEnable},
+	{"disable", "disable the modem", runDisable},
+	{"connect", "connect a bearer with the simple interface", runConnect},
+	{"disconnect", "disconnect the given or all bearers", runDisconnect},
+	{"sms-list", "list the messages of the modem", runSmsList},
+	{"sms-send", "create and send a message", runSmsSend},
+	{"ussd", "initiate or respond to a USSD session", runUssd},
+	{"location", "toggle location sources and print the location", runLocation},
+	{"events", "stream the events of all modems or of the given modem until interrupted", runEvents},
+}
+
+// cli holds the global options shared by all commands
+type cli struct {
+	conn      *dbus.Conn
+	json      bool
+	modemName string
+	mmgr      modemmanager.ModemManager
+	out       io.Writer
+}
+
+func main() {
+	flag.Usage = usage
+	jsonOutput := flag.Bool("json", false, "print the output as JSON")
+	modemName := flag.String("m", "", "the modem, given as index, object path, equipment identifier or device identifier")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == flag.Arg(0) {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "gomm: unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	c := &cli{json: *jsonOutput, modemName: *modemName, out: os.Stdout}
+	err := c.connect()
+	if err == nil {
+		err = cmd.run(c, flag.Args()[1:])
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gomm: "+err.Error())
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gomm [-json] [-m modem] <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "options:")
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "run 'gomm <command> -h' for the arguments of a command")
+}
+
+// newFlagSet returns the flag set of a command, which exits on -h or invalid arguments
+func newFlagSet(name string, arguments string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gomm %s %s\n", name, arguments)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func (c *cli) connect() (err error) {
+	c.conn, err = dbus.SystemBus()
+	if err != nil {
+		return err
+	}
+	c.mmgr, err = modemmanager.NewModemManagerWithConn(c.conn)
+	return err
+}
+
+// modem returns the modem selected by -m, or the only available modem
+func (c *cli) modem() (modemmanager.Modem, error) {
+	modems, err := c.mmgr.GetModems()
+	if err != nil {
+		return nil, err
+	}
+	if len(modems) == 0 {
+		return nil, errors.New("no modems found")
+	}
+	if c.modemName == "" {
+		if len(modems) > 1 {
+			return nil, errors.New("more than one modem found, select one with -m")
+		}
+		return modems[0], nil
+	}
+	for _, modem := range modems {
+		objectPath := string(modem.GetObjectPath())
+		if c.modemName == objectPath || c.modemName == path.Base(objectPath) {
+			return modem, nil
+		}
+		if equipmentIdentifier, err := modem.GetEquipmentIdentifier(); err == nil && equipmentIdentifier == c.modemName {
+			return modem, nil
+		}
+		if deviceIdentifier, err := modem.GetDeviceIdentifier(); err == nil && deviceIdentifier == c.modemName {
+			return modem, nil
+		}
+	}
+	return nil, errors.New("modem " + c.modemName + " not found")
+}
+
+// printJSON prints v indented, using the MarshalJSON method of v if available
+func (c *cli) printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, string(data))
+	return nil
+}
+
+// field is a line of the text output
+type field struct {
+	name  string
+	value interface{}
+}
+
+// printSection prints the fields aligned below the title, similar to the sections of mmcli. Empty values are skipped.
+func (c *cli) printSection(title string, fields ...field) {
+	width := 0
+	for _, f := range fields {
+		if len(f.name) > width {
+			width = len(f.name)
+		}
+	}
+	fmt.Fprintln(c.out, title)
+	for _, f := range fields {
+		value := fmt.Sprint(f.value)
+		if value == "" || value == "[]" {
+			continue
+		}
+		fmt.Fprintf(c.out, "  %-*s : %s\n", width, f.name, value)
+	}
+}
+
+// joinValues formats a slice, e.g. of enum values, as comma separated list
+func joinValues(values interface{}) string {
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice {
+		return fmt.Sprint(values)
+	}
+	res := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		res = append(res, fmt.Sprint(v.Index(i).Interface()))
+	}
+	return strings.Join(res, ", ")
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+// output is a buffer which can be written by a running command while the test reads it
+type output struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.Write(p)
+}
+
+// String returns and resets the output written so far
+func (o *output) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	s := o.buf.String()
+	o.buf.Reset()
+	return s
+}
+
+// newCli starts a fake ModemManager and returns a cli connected to it
+func newCli(t *testing.T) (*mmtest.Server, *cli, *output, func()) {
+	t.Helper()
+	srv, conn, stop := mmtest.StartT(t)
+	mmgr, err := modemmanager.NewModemManagerWithConn(conn)
+	if err != nil {
+		stop()
+		t.Fatal(err)
+	}
+	out := &output{}
+	return srv, &cli{conn: conn, mmgr: mmgr, out: out}, out, stop
+}
+
+func addModem(t *testing.T, srv *mmtest.Server, cfg mmtest.ModemConfig) *mmtest.Modem {
+	t.Helper()
+	fake, err := srv.AddModem(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fake
+}
+
+// run runs the command and returns its output
+func run(t *testing.T, c *cli, out *output, name string, args ...string) string {
+	t.Helper()
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(c, args); err != nil {
+				t.Fatalf("%s %v: %v", name, args, err)
+			}
+			return out.String()
+		}
+	}
+	t.Fatalf("unknown command %s", name)
+	return ""
+}
+
+func assertContains(t *testing.T, got string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("output %q does not contain %q", got, w)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	srv, c, out, stop := newCli(t)
+	defer stop()
+	assertContains(t, run(t, c, out, "list"), "No modems were found")
+	fake := addModem(t, srv, mmtest.ModemConfig{Manufacturer: "Quectel", Model: "EC25", EquipmentIdentifier: "490154203237518"})
+	assertContains(t, run(t, c, out, "list"), string(fake.GetObjectPath())+" [Quectel] EC25\n")
+
+	c.json = true
+	var list []map[string]interface{}
+	if err := json.Unmarshal([]byte(run(t, c, out, "list")), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0]["EquipmentIdentifier"] != "490154203237518" || list[0]["State"] != "Disabled" {
+		t.Errorf("got list %v, want the disabled modem", list)
+	}
+}
+
+func TestSelectModem(t *testing.T) {
+	srv, c, _, stop := newCli(t)
+	defer stop()
+	first := addModem(t, srv, mmtest.ModemConfig{EquipmentIdentifier: "490154203237518", DeviceIdentifier: "a1b2c3"})
+	second := addModem(t, srv, mmtest.ModemConfig{})
+	if _, err := c.modem(); err == nil {
+		t.Error("modem selected without -m out of two modems")
+	}
+	names := []string{
+		string(first.GetObjectPath()),
+		path.Base(string(first.GetObjectPath())),
+		"490154203237518",
+		"a1b2c3",
+	}
+	for _, name := range names {
+		c.modemName = name
+		modem, err := c.modem()
+		if err != nil {
+			t.Errorf("-m %s: %v", name, err)
+		} else if modem.GetObjectPath() != first.GetObjectPath() {
+			t.Errorf("-m %s selected %s", name, modem.GetObjectPath())
+		}
+	}
+	c.modemName = "000000000000000"
+	if _, err := c.modem(); err == nil {
+		t.Error("unknown modem selected")
+	}
+	second.Remove()
+	c.modemName = ""
+	if modem, err := c.modem(); err != nil || modem.GetObjectPath() != first.GetObjectPath() {
+		t.Errorf("selected %v, %v, want the only modem", modem, err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	srv, c, out, stop := newCli(t)
+	defer stop()
+	addModem(t, srv, mmtest.ModemConfig{EquipmentIdentifier: "490154203237518", State: modemmanager.MmModemStateRegistered})
+	assertContains(t, run(t, c, out, "status"),
+		"Hardware\n",
+		"  equipment id      : 490154203237518\n",
+		"  state          : Registered\n",
+		"  operator id          : 26201\n",
+		"SIM\n",
+	)
+
+	c.json = true
+	var status map[string]interface{}
+	if err := json.Unmarshal([]byte(run(t, c, out, "status")), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status["EquipmentIdentifier"] != "490154203237518" {
+		t.Errorf("got status %v, want the status of the modem", status)
+	}
+}
+
+func TestConnect(t *testing.T) {
+	srv, c, out, stop := newCli(t)
+	defer stop()
+	fake := addModem(t, srv, mmtest.ModemConfig{})
+	assertContains(t, run(t, c, out, "enable"), "successfully enabled the modem")
+	assertContains(t, run(t, c, out, "connect", "-apn", "internet", "-ip-type", "ipv4v6"), "successfully connected the modem, bearer ")
+	bearers := fake.Bearers()
+	if len(bearers) != 1 || !bearers[0].IsConnected() {
+		t.Fatalf("got bearers %v, want one connected bearer", bearers)
+	}
+	assertContains(t, run(t, c, out, "status"), "  apn       : internet\n", "  connected : true\n")
+	assertContains(t, run(t, c, out, "disconnect"), "successfully disconnected bearer "+string(bearers[0].GetObjectPath()))
+	if bearers[0].IsConnected() {
+		t.Error("bearer connected after disconnect")
+	}
+	if err := runConnect(c, []string{"-apn", "internet", "-ip-type", "ipx"}); err == nil {
+		t.Error("connected with an unknown ip type")
+	}
+	if err := runDisconnect(c, []string{"99"}); err == nil {
+		t.Error("disconnected an unknown bearer")
+	}
+	assertContains(t, run(t, c, out, "disable"), "successfully disabled the modem")
+	if state := fake.State(); state != modemmanager.MmModemStateDisabled {
+		t.Errorf("state = %s, want disabled", state)
+	}
+}
+
+func TestSms(t *testing.T) {
+	srv, c, out, stop := newCli(t)
+	defer stop()
+	fake := addModem(t, srv, mmtest.ModemConfig{State: modemmanager.MmModemStateRegistered})
+	assertContains(t, run(t, c, out, "sms-list"), "No sms messages were found")
+	received, err := fake.ReceiveSms("+491234567890", "Hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, run(t, c, out, "sms-list"), string(received.GetObjectPath())+"\n", "  number    : +491234567890\n", "  text      : Hello\n")
+	assertContains(t, run(t, c, out, "sms-send", "-number", "+499876543210", "-text", "Hi"), "successfully sent sms ")
+	messages := fake.Messages()
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want the received and the sent one", len(messages))
+	}
+	if err := runSmsSend(c, []string{"-number", "+499876543210"}); err == nil {
+		t.Error("sms sent without text")
+	}
+}
+
+func TestUssd(t *testing.T) {
+	srv, c, out, stop := newCli(t)
+	defer stop()
+	fake := addModem(t, srv, mmtest.ModemConfig{State: modemmanager.MmModemStateRegistered})
+	fake.SetUssdReply("*100#", "Your balance is 10.00 EUR")
+	if got := run(t, c, out, "ussd", "*100#"); got != "Your balance is 10.00 EUR\n" {
+		t.Errorf("reply = %q, want the balance", got)
+	}
+	assertContains(t, run(t, c, out, "ussd", "-cancel"), "successfully cancelled the USSD session")
+	if err := runUssd(c, nil); err == nil {
+		t.Error("ussd without command succeeded")
+	}
+}
+
+func TestLocation(t *testing.T) {
+	srv, c, out, stop := newCli(t)
+	defer stop()
+	fake := addModem(t, srv, mmtest.ModemConfig{State: modemmanager.MmModemStateRegistered})
+	fake.SetLocation(map[modemmanager.MMModemLocationSource]interface{}{
+		modemmanager.MmModemLocationSource3gppLacCi: "262,01,84CD,2BAF,6FFE",
+	})
+	assertContains(t, run(t, c, out, "location", "-enable", "3gpp-lac-ci", "-signal"),
+		"  enabled      : 3gppLacCi\n",
+		"  signals      : true\n",
+		"  mobile country code : 262\n",
+		"  cell id             : 2BAF\n",
+	)
+	got := run(t, c, out, "location", "-disable", "3gpp-lac-ci", "-signal=false")
+	assertContains(t, got, "  signals      : false\n")
+	if strings.Contains(got, "3GPP") {
+		t.Errorf("output %q contains the location of the disabled source", got)
+	}
+}
+
+func TestParseLocationSources(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []modemmanager.MMModemLocationSource
+		err   bool
+	}{
+		{"", nil, false},
+		{"3gpp-lac-ci", []modemmanager.MMModemLocationSource{modemmanager.MmModemLocationSource3gppLacCi}, false},
+		{"GpsRaw, gps-nmea", []modemmanager.MMModemLocationSource{modemmanager.MmModemLocationSourceGpsRaw, modemmanager.MmModemLocationSourceGpsNmea}, false},
+		{"gps-raw,galileo", nil, true},
+	}
+	for _, test := range tests {
+		got, err := parseLocationSources(test.value)
+		if (err != nil) != test.err {
+			t.Errorf("parseLocationSources(%q) error = %v, want error %t", test.value, err, test.err)
+			continue
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("parseLocationSources(%q) = %v, want %v", test.value, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("parseLocationSources(%q) = %v, want %v", test.value, got, test.want)
+			}
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	srv, c, out, stop := newCli(t)
+	defer stop()
+	fake := addModem(t, srv, mmtest.ModemConfig{})
+	c.json = true
+	done := make(chan error, 1)
+	go func() {
+		done <- runEvents(c, []string{"-interface", modemmanager.ModemInterface})
+	}()
+	// the dispatcher subscribes asynchronously, so the state is changed until the event is printed
+	states := []modemmanager.MMModemState{modemmanager.MmModemStateEnabling, modemmanager.MmModemStateDisabled}
+	var got string
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 0; !strings.Contains(got, "ModemStateChangedEvent"); i++ {
+		if time.Now().After(deadline) {
+			t.Fatalf("no state change printed, got %q", got)
+		}
+		fake.SetState(states[i%len(states)], modemmanager.MmModemStateChangeReasonUserRequested)
+		time.Sleep(50 * time.Millisecond)
+		got += out.String()
+	}
+	var event map[string]interface{}
+	line := got[:strings.Index(got, "\n")]
+	if err := json.Unmarshal([]byte(line), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event["ObjectPath"] != string(fake.GetObjectPath()) || event["Interface"] != modemmanager.ModemInterface {
+		t.Errorf("got event %v, want the state change of the modem", event)
+	}
+
+	// the command ends when the connection is closed
+	c.conn.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("events not stopped after the connection was closed")
+	}
+}
+
+func TestEventsOfModem(t *testing.T) {
+	srv, c, out, stop := newCli(t)
+	defer stop()
+	first := addModem(t, srv, mmtest.ModemConfig{})
+	second := addModem(t, srv, mmtest.ModemConfig{})
+	c.modemName = string(first.GetObjectPath())
+	run(t, c, out, "enable")
+	run(t, c, out, "connect", "-apn", "internet")
+	bearer := first.Bearers()[0]
+	done := make(chan error, 1)
+	go func() {
+		done <- runEvents(c, nil)
+	}()
+	defer func() {
+		c.conn.Close()
+		<-done
+	}()
+	// the events of the bearer of the selected modem are printed, the events of another modem are not
+	var got string
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(got, string(bearer.GetObjectPath())) {
+		if time.Now().After(deadline) {
+			t.Fatalf("no bearer event printed, got %q", got)
+		}
+		second.SetState(modemmanager.MmModemStateEnabling, modemmanager.MmModemStateChangeReasonUserRequested)
+		bearer.AddTraffic(100, 10)
+		time.Sleep(50 * time.Millisecond)
+		got += out.String()
+	}
+	if strings.Contains(got, string(second.GetObjectPath())) {
+		t.Errorf("got events of another modem: %q", got)
+	}
+}
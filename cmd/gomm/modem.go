@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/maltegrosse/go-modemmanager"
+)
+
+func runList(c *cli, args []string) error {
+	newFlagSet("list", "").Parse(args)
+	modems, err := c.mmgr.GetModems()
+	if err != nil {
+		return err
+	}
+	if c.json {
+		list := make([]map[string]interface{}, 0, len(modems))
+		for _, modem := range modems {
+			manufacturer, _ := modem.GetManufacturer()
+			model, _ := modem.GetModel()
+			equipmentIdentifier, _ := modem.GetEquipmentIdentifier()
+			state, _ := modem.GetState()
+			list = append(list, map[string]interface{}{
+				"ObjectPath":          modem.GetObjectPath(),
+				"Manufacturer":        manufacturer,
+				"Model":               model,
+				"EquipmentIdentifier": equipmentIdentifier,
+				"State":               fmt.Sprint(state),
+			})
+		}
+		return c.printJSON(list)
+	}
+	if len(modems) == 0 {
+		fmt.Fprintln(c.out, "No modems were found")
+		return nil
+	}
+	for _, modem := range modems {
+		manufacturer, _ := modem.GetManufacturer()
+		model, _ := modem.GetModel()
+		fmt.Fprintf(c.out, "%s [%s] %s\n", modem.GetObjectPath(), manufacturer, model)
+	}
+	return nil
+}
+
+func runStatus(c *cli, args []string) error {
+	newFlagSet("status", "").Parse(args)
+	modem, err := c.modem()
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(modem)
+	}
+
+	manufacturer, _ := modem.GetManufacturer()
+	model, _ := modem.GetModel()
+	revision, _ := modem.GetRevision()
+	hardwareRevision, _ := modem.GetHardwareRevision()
+	equipmentIdentifier, _ := modem.GetEquipmentIdentifier()
+	deviceIdentifier, _ := modem.GetDeviceIdentifier()
+	c.printSection("General",
+		field{"path", modem.GetObjectPath()},
+		field{"index", path.Base(string(modem.GetObjectPath()))},
+		field{"device id", deviceIdentifier},
+	)
+	c.printSection("Hardware",
+		field{"manufacturer", manufacturer},
+		field{"model", model},
+		field{"firmware revision", revision},
+		field{"h/w revision", hardwareRevision},
+		field{"equipment id", equipmentIdentifier},
+	)
+
+	device, _ := modem.GetDevice()
+	drivers, _ := modem.GetDrivers()
+	plugin, _ := modem.GetPlugin()
+	primaryPort, _ := modem.GetPrimaryPort()
+	c.printSection("System",
+		field{"device", device},
+		field{"drivers", joinValues(drivers)},
+		field{"plugin", plugin},
+		field{"primary port", primaryPort},
+	)
+
+	state, _ := modem.GetState()
+	failedReason, _ := modem.GetStateFailedReason()
+	powerState, _ := modem.GetPowerState()
+	accessTechnologies, _ := modem.GetAccessTechnologies()
+	percent, recent, _ := modem.GetSignalQuality()
+	ownNumbers, _ := modem.GetOwnNumbers()
+	statusFields := []field{
+		{"state", state},
+		{"power state", powerState},
+		{"access tech", joinValues(accessTechnologies)},
+		{"signal quality", fmt.Sprintf("%d%% (recent: %t)", percent, recent)},
+		{"own numbers", joinValues(ownNumbers)},
+	}
+	if state == modemmanager.MmModemStateFailed {
+		statusFields = append(statusFields, field{"failed reason", failedReason})
+	}
+	c.printSection("Status", statusFields...)
+
+	if modem3gpp, err := modem.Get3gpp(); err == nil {
+		imei, _ := modem3gpp.GetImei()
+		operatorCode, _ := modem3gpp.GetOperatorCode()
+		operatorName, _ := modem3gpp.GetOperatorName()
+		registrationState, _ := modem3gpp.GetRegistrationState()
+		packetServiceState, _ := modem3gpp.GetPacketServiceState()
+		c.printSection("3GPP",
+			field{"imei", imei},
+			field{"operator id", operatorCode},
+			field{"operator name", operatorName},
+			field{"registration", registrationState},
+			field{"packet service state", packetServiceState},
+		)
+	}
+
+	if sim, err := modem.GetSim(); err == nil && sim.GetObjectPath() != "/" {
+		simIdentifier, _ := sim.GetSimIdentifier()
+		imsi, _ := sim.GetImsi()
+		operatorName, _ := sim.GetOperatorName()
+		c.printSection("SIM",
+			field{"path", sim.GetObjectPath()},
+			field{"iccid", simIdentifier},
+			field{"imsi", imsi},
+			field{"operator name", operatorName},
+		)
+	}
+
+	bearers, err := modem.GetBearers()
+	if err != nil {
+		return err
+	}
+	for _, bearer := range bearers {
+		connected, _ := bearer.GetConnected()
+		bearerInterface, _ := bearer.GetInterface()
+		var apn string
+		if properties, err := bearer.GetProperties(); err == nil {
+			apn = properties.APN
+		}
+		c.printSection("Bearer",
+			field{"path", bearer.GetObjectPath()},
+			field{"apn", apn},
+			field{"connected", connected},
+			field{"interface", bearerInterface},
+		)
+	}
+	return nil
+}
+
+func runEnable(c *cli, args []string) error {
+	newFlagSet("enable", "").Parse(args)
+	modem, err := c.modem()
+	if err != nil {
+		return err
+	}
+	if err = modem.Enable(); err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, "successfully enabled the modem")
+	return nil
+}
+
+func runDisable(c *cli, args []string) error {
+	newFlagSet("disable", "").Parse(args)
+	modem, err := c.modem()
+	if err != nil {
+		return err
+	}
+	if err = modem.Disable(); err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, "successfully disabled the modem")
+	return nil
+}
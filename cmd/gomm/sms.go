@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/maltegrosse/go-modemmanager"
+)
+
+func runSmsList(c *cli, args []string) error {
+	newFlagSet("sms-list", "").Parse(args)
+	modem, err := c.modem()
+	if err != nil {
+		return err
+	}
+	messaging, err := modem.GetMessaging()
+	if err != nil {
+		return err
+	}
+	messages, err := messaging.List()
+	if err != nil {
+		return err
+	}
+	if c.json {
+		if messages == nil {
+			messages = []modemmanager.Sms{}
+		}
+		return c.printJSON(messages)
+	}
+	if len(messages) == 0 {
+		fmt.Fprintln(c.out, "No sms messages were found")
+		return nil
+	}
+	for _, sms := range messages {
+		number, _ := sms.GetNumber()
+		state, _ := sms.GetState()
+		text, _ := sms.GetText()
+		fields := []field{
+			{"number", number},
+			{"state", state},
+			{"text", text},
+		}
+		if timestamp, err := sms.GetTimestamp(); err == nil && !timestamp.IsZero() {
+			fields = append(fields, field{"timestamp", timestamp})
+		}
+		c.printSection(string(sms.GetObjectPath()), fields...)
+	}
+	return nil
+}
+
+func runSmsSend(c *cli, args []string) error {
+	fs := newFlagSet("sms-send", "-number NUMBER -text TEXT")
+	number := fs.String("number", "", "the phone number of the recipient")
+	text := fs.String("text", "", "the text of the message")
+	fs.Parse(args)
+	if *number == "" || *text == "" {
+		return errors.New("sms-send requires -number and -text")
+	}
+	modem, err := c.modem()
+	if err != nil {
+		return err
+	}
+	messaging, err := modem.GetMessaging()
+	if err != nil {
+		return err
+	}
+	sms, err := messaging.CreateSms(*number, *text)
+	if err != nil {
+		return err
+	}
+	if err = sms.Send(); err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(sms)
+	}
+	fmt.Fprintln(c.out, "successfully sent sms "+string(sms.GetObjectPath()))
+	return nil
+}
+
+func runUssd(c *cli, args []string) error {
+	fs := newFlagSet("ussd", "[-cancel] [command]")
+	cancel := fs.Bool("cancel", false, "cancel the ongoing USSD session")
+	fs.Parse(args)
+	modem, err := c.modem()
+	if err != nil {
+		return err
+	}
+	modem3gpp, err := modem.Get3gpp()
+	if err != nil {
+		return err
+	}
+	ussd, err := modem3gpp.GetUssd()
+	if err != nil {
+		return err
+	}
+	if *cancel {
+		if err = ussd.Cancel(); err != nil {
+			return err
+		}
+		fmt.Fprintln(c.out, "successfully cancelled the USSD session")
+		return nil
+	}
+	if fs.NArg() != 1 {
+		return errors.New("ussd requires a command, e.g. *100#")
+	}
+	state, err := ussd.GetState()
+	if err != nil {
+		return err
+	}
+	// a command given while the network waits for a response continues the session
+	var reply string
+	if state == modemmanager.MmModem3gppUssdSessionStateUserResponse {
+		reply, err = ussd.Respond(fs.Arg(0))
+	} else {
+		reply, err = ussd.Initiate(fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	if c.json {
+		state, _ := ussd.GetState()
+		return c.printJSON(map[string]interface{}{
+			"Reply": reply,
+			"State": fmt.Sprint(state),
+		})
+	}
+	fmt.Fprintln(c.out, reply)
+	return nil
+}
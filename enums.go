@@ -154,6 +154,9 @@ const (
 	MmModemAccessTechnologyEvdoa      MMModemAccessTechnology = 1 << 12    // CDMA2000 EVDO revision A.
 	MmModemAccessTechnologyEvdob      MMModemAccessTechnology = 1 << 13    // CDMA2000 EVDO revision B.
 	MmModemAccessTechnologyLte        MMModemAccessTechnology = 1 << 14    // LTE (ETSI 27.007: "E-UTRAN")
+	MmModemAccessTechnology5gnr       MMModemAccessTechnology = 1 << 15    // 5GNR (ETSI 27.007: "NG-RAN").
+	MmModemAccessTechnologyLteCatM    MMModemAccessTechnology = 1 << 16    // Cat-M (ETSI 23.401: LTE Category M1/M2).
+	MmModemAccessTechnologyLteNbIot   MMModemAccessTechnology = 1 << 17    // NB IoT (ETSI 23.401: LTE Category NB1/NB2).
 	MmModemAccessTechnologyAny        MMModemAccessTechnology = 0xFFFFFFFF // Mask specifying all access technologies.
 )
 
@@ -162,6 +165,7 @@ func (t MMModemAccessTechnology) GetAllTechnologies() []MMModemAccessTechnology
 	var technologies = []MMModemAccessTechnology{MmModemAccessTechnologyPots, MmModemAccessTechnologyGsm, MmModemAccessTechnologyGsmCompact,
 		MmModemAccessTechnologyGprs, MmModemAccessTechnologyEdge, MmModemAccessTechnologyUmts, MmModemAccessTechnologyHsdpa, MmModemAccessTechnologyHsupa, MmModemAccessTechnologyHspa,
 		MmModemAccessTechnologyHspaPlus, MmModemAccessTechnology1xrtt, MmModemAccessTechnologyEvdo0, MmModemAccessTechnologyEvdoa, MmModemAccessTechnologyEvdob, MmModemAccessTechnologyLte,
+		MmModemAccessTechnology5gnr, MmModemAccessTechnologyLteCatM, MmModemAccessTechnologyLteNbIot,
 	}
 	return technologies
 }
@@ -392,6 +396,36 @@ const (
 
 )
 
+// MMSimType Type of SIM card.
+type MMSimType uint32
+
+//go:generate stringer -type=MMSimType -trimprefix=MmSimType
+const (
+	MmSimTypeUnknown  MMSimType = 0 // SIM type is not known.
+	MmSimTypePhysical MMSimType = 1 // SIM is a physical SIM.
+	MmSimTypeEsim     MMSimType = 2 // SIM is an eSIM.
+)
+
+// MMSimEsimStatus Status of the profiles for ESIM.
+type MMSimEsimStatus uint32
+
+//go:generate stringer -type=MMSimEsimStatus -trimprefix=MmSimEsimStatus
+const (
+	MmSimEsimStatusUnknown      MMSimEsimStatus = 0 // ESIM status unknown.
+	MmSimEsimStatusNoProfiles   MMSimEsimStatus = 1 // ESIM without profiles.
+	MmSimEsimStatusWithProfiles MMSimEsimStatus = 2 // ESIM with profiles.
+)
+
+// MMSimRemovability Whether the SIM is removable or not.
+type MMSimRemovability uint32
+
+//go:generate stringer -type=MMSimRemovability -trimprefix=MmSimRemovability
+const (
+	MmSimRemovabilityUnknown      MMSimRemovability = 0 // SIM removability not known.
+	MmSimRemovabilityRemovable    MMSimRemovability = 1 // SIM is a removable SIM.
+	MmSimRemovabilityNotRemovable MMSimRemovability = 2 // SIM is not a removable SIM.
+)
+
 // MMSmsPduType Type of PDUs used in the SMS.
 type MMSmsPduType uint32
 
@@ -738,6 +772,65 @@ const (
 	MmBearerTypeDedicated     MMBearerType = 3 // Secondary context (2G/3G) or dedicated bearer (4G), defined by the user of the API. These bearers use the same IP address  used by a primary context or default bearer and provide a dedicated flow for  specific traffic with different QoS settings.
 )
 
+// MMBearerAccessTypePreference Access type preference of a 5G profile.
+type MMBearerAccessTypePreference uint32
+
+//go:generate stringer -type=MMBearerAccessTypePreference -trimprefix=MmBearerAccessTypePreference
+const (
+	MmBearerAccessTypePreferenceNone          MMBearerAccessTypePreference = 0 // No access type preference, or unknown.
+	MmBearerAccessTypePreference3gppOnly      MMBearerAccessTypePreference = 1 // 3GPP access only.
+	MmBearerAccessTypePreference3gppPreferred MMBearerAccessTypePreference = 2 // All access types allowed, 3GPP preferred.
+	MmBearerAccessTypePreferenceNon3gppOnly   MMBearerAccessTypePreference = 3 // Non-3GPP access only.
+)
+
+// MMBearerApnType Purposes of an APN used in a connection profile, given as a bitmask.
+type MMBearerApnType uint32
+
+//go:generate stringer -type=MMBearerApnType -trimprefix=MmBearerApnType
+const (
+	MmBearerApnTypeNone       MMBearerApnType = 0       // Unknown or not set.
+	MmBearerApnTypeInitial    MMBearerApnType = 1 << 0  // APN used for the initial attach procedure.
+	MmBearerApnTypeDefault    MMBearerApnType = 1 << 1  // Default connection APN providing access to the Internet.
+	MmBearerApnTypeIms        MMBearerApnType = 1 << 2  // APN providing access to IMS services.
+	MmBearerApnTypeMms        MMBearerApnType = 1 << 3  // APN providing access to MMS services.
+	MmBearerApnTypeManagement MMBearerApnType = 1 << 4  // APN providing access to over-the-air device management procedures.
+	MmBearerApnTypeVoice      MMBearerApnType = 1 << 5  // APN providing access to voice-over-IP services.
+	MmBearerApnTypeEmergency  MMBearerApnType = 1 << 6  // APN providing access to emergency services.
+	MmBearerApnTypePrivate    MMBearerApnType = 1 << 7  // APN providing access to private networks.
+	MmBearerApnTypePurchase   MMBearerApnType = 1 << 8  // APN providing access to over-the-air activation sites.
+	MmBearerApnTypeVideoShare MMBearerApnType = 1 << 9  // APN providing access to video sharing service.
+	MmBearerApnTypeLocal      MMBearerApnType = 1 << 10 // APN providing access to a local connection with the device.
+	MmBearerApnTypeApp        MMBearerApnType = 1 << 11 // APN providing access to certain applications allowed by mobile operators.
+	MmBearerApnTypeXcap       MMBearerApnType = 1 << 12 // APN providing access to XCAP provisioning on IMS services.
+	MmBearerApnTypeTether     MMBearerApnType = 1 << 13 // APN providing access to mobile hotspot tethering.
+)
+
+// GetAllApnTypes returns all apn types
+func (i MMBearerApnType) GetAllApnTypes() []MMBearerApnType {
+	return []MMBearerApnType{MmBearerApnTypeInitial, MmBearerApnTypeDefault, MmBearerApnTypeIms, MmBearerApnTypeMms,
+		MmBearerApnTypeManagement, MmBearerApnTypeVoice, MmBearerApnTypeEmergency, MmBearerApnTypePrivate,
+		MmBearerApnTypePurchase, MmBearerApnTypeVideoShare, MmBearerApnTypeLocal, MmBearerApnTypeApp,
+		MmBearerApnTypeXcap, MmBearerApnTypeTether}
+}
+
+// BitmaskToSlice bitmask to slice
+func (i MMBearerApnType) BitmaskToSlice(bitmask uint32) (apnTypes []MMBearerApnType) {
+	for _, x := range i.GetAllApnTypes() {
+		if bitmask&uint32(x) > 0 {
+			apnTypes = append(apnTypes, x)
+		}
+	}
+	return apnTypes
+}
+
+// SliceToBitmask slice to bitmask
+func (i MMBearerApnType) SliceToBitmask(apnTypes []MMBearerApnType) (bitmask uint32) {
+	for _, x := range apnTypes {
+		bitmask = bitmask | uint32(x)
+	}
+	return bitmask
+}
+
 // MMBearerIpMethod Type of IP method configuration to be used in a given Bearer.
 type MMBearerIpMethod uint32
 
@@ -945,6 +1038,65 @@ const (
 
 )
 
+// MMModem3gppPacketServiceState The packet domain service state.
+type MMModem3gppPacketServiceState uint32
+
+//go:generate stringer -type=MMModem3gppPacketServiceState -trimprefix=MmModem3gppPacketServiceState
+const (
+	MmModem3gppPacketServiceStateUnknown  MMModem3gppPacketServiceState = 0 // Unknown.
+	MmModem3gppPacketServiceStateDetached MMModem3gppPacketServiceState = 1 // Detached.
+	MmModem3gppPacketServiceStateAttached MMModem3gppPacketServiceState = 2 // Attached.
+)
+
+// MMNetworkError Network errors reported on registration rejection, as defined in 3GPP TS 24.008 annex G.
+type MMNetworkError uint32
+
+//go:generate stringer -type=MMNetworkError -trimprefix=MmNetworkError
+const (
+	MmNetworkErrorNone                                MMNetworkError = 0   // No error.
+	MmNetworkErrorImsiUnknownInHlr                    MMNetworkError = 2   // IMSI unknown in HLR.
+	MmNetworkErrorIllegalMs                           MMNetworkError = 3   // Illegal MS.
+	MmNetworkErrorImsiUnknownInVlr                    MMNetworkError = 4   // IMSI unknown in VLR.
+	MmNetworkErrorImeiNotAccepted                     MMNetworkError = 5   // IMEI not accepted.
+	MmNetworkErrorIllegalMe                           MMNetworkError = 6   // Illegal ME.
+	MmNetworkErrorGprsNotAllowed                      MMNetworkError = 7   // GPRS services not allowed.
+	MmNetworkErrorGprsAndNonGprsNotAllowed            MMNetworkError = 8   // GPRS and non-GPRS services not allowed.
+	MmNetworkErrorMsIdentityNotDerivedByNetwork       MMNetworkError = 9   // MS identity cannot be derived by the network.
+	MmNetworkErrorImplicitlyDetached                  MMNetworkError = 10  // Implicitly detached.
+	MmNetworkErrorPlmnNotAllowed                      MMNetworkError = 11  // PLMN not allowed.
+	MmNetworkErrorLocationAreaNotAllowed              MMNetworkError = 12  // Location area not allowed.
+	MmNetworkErrorRoamingNotAllowedInLocationArea     MMNetworkError = 13  // Roaming not allowed in this location area.
+	MmNetworkErrorGprsNotAllowedInPlmn                MMNetworkError = 14  // GPRS services not allowed in this PLMN.
+	MmNetworkErrorNoCellsInLocationArea               MMNetworkError = 15  // No suitable cells in location area.
+	MmNetworkErrorMscTemporarilyNotReachable          MMNetworkError = 16  // MSC temporarily not reachable.
+	MmNetworkErrorNetworkFailure                      MMNetworkError = 17  // Network failure.
+	MmNetworkErrorCsDomainNotAvailable                MMNetworkError = 18  // CS domain not available.
+	MmNetworkErrorEsmFailure                          MMNetworkError = 19  // ESM failure.
+	MmNetworkErrorMacFailure                          MMNetworkError = 20  // MAC failure.
+	MmNetworkErrorSynchFailure                        MMNetworkError = 21  // Synch failure.
+	MmNetworkErrorCongestion                          MMNetworkError = 22  // Congestion.
+	MmNetworkErrorGsmAuthenticationUnacceptable       MMNetworkError = 23  // GSM authentication unacceptable.
+	MmNetworkErrorNotAuthorizedForCsg                 MMNetworkError = 25  // Not authorized for this CSG.
+	MmNetworkErrorInsufficientResources               MMNetworkError = 26  // Insufficient resources.
+	MmNetworkErrorMissingOrUnknownApn                 MMNetworkError = 27  // Missing or unknown access point name.
+	MmNetworkErrorUnknownPdpAddressOrType             MMNetworkError = 28  // Unknown PDP address or PDP type.
+	MmNetworkErrorUserAuthenticationFailed            MMNetworkError = 29  // User authentication failed.
+	MmNetworkErrorActivationRejectedByGgsnOrGw        MMNetworkError = 30  // Activation rejected by GGSN, Serving GW or PDN GW.
+	MmNetworkErrorActivationRejectedUnspecified       MMNetworkError = 31  // Activation rejected, unspecified.
+	MmNetworkErrorServiceOptionNotSupported           MMNetworkError = 32  // Service option not supported.
+	MmNetworkErrorRequestedServiceOptionNotSubscribed MMNetworkError = 33  // Requested service option not subscribed.
+	MmNetworkErrorServiceOptionOutOfOrder             MMNetworkError = 34  // Service option temporarily out of order.
+	MmNetworkErrorNoPdpContextActivated               MMNetworkError = 40  // No PDP context activated.
+	MmNetworkErrorSemanticallyIncorrectMessage        MMNetworkError = 95  // Semantically incorrect message.
+	MmNetworkErrorInvalidMandatoryInformation         MMNetworkError = 96  // Invalid mandatory information.
+	MmNetworkErrorMessageTypeNonExistent              MMNetworkError = 97  // Message type non-existent or not implemented.
+	MmNetworkErrorMessageTypeNotCompatible            MMNetworkError = 98  // Message type not compatible with the protocol state.
+	MmNetworkErrorInformationElementNonExistent       MMNetworkError = 99  // Information element non-existent or not implemented.
+	MmNetworkErrorConditionalIeError                  MMNetworkError = 100 // Conditional IE error.
+	MmNetworkErrorMessageNotCompatible                MMNetworkError = 101 // Message not compatible with the protocol state.
+	MmNetworkErrorProtocolErrorUnspecified            MMNetworkError = 111 // Protocol error, unspecified.
+)
+
 // MMModem3gppUssdSessionState State of a USSD session.
 type MMModem3gppUssdSessionState uint32
 
@@ -970,6 +1122,30 @@ const (
 
 )
 
+// MMModem3gppMicoMode Mobile Initiated Connection Only (MICO) mode, as per 3GPP TS 24.501.
+type MMModem3gppMicoMode uint32
+
+//go:generate stringer -type=MMModem3gppMicoMode -trimprefix=MmModem3gppMicoMode
+const (
+	MmModem3gppMicoModeUnknown     MMModem3gppMicoMode = 0 // Unknown or not specified.
+	MmModem3gppMicoModeUnsupported MMModem3gppMicoMode = 1 // Unsupported.
+	MmModem3gppMicoModeDisabled    MMModem3gppMicoMode = 2 // Disabled.
+	MmModem3gppMicoModeEnabled     MMModem3gppMicoMode = 3 // Enabled.
+)
+
+// MMModem3gppDrxCycle DRX cycle, as per 3GPP TS 24.008.
+type MMModem3gppDrxCycle uint32
+
+//go:generate stringer -type=MMModem3gppDrxCycle -trimprefix=MmModem3gppDrxCycle
+const (
+	MmModem3gppDrxCycleUnknown     MMModem3gppDrxCycle = 0 // Unknown or not specified.
+	MmModem3gppDrxCycleUnsupported MMModem3gppDrxCycle = 1 // Unsupported.
+	MmModem3gppDrxCycle32          MMModem3gppDrxCycle = 2 // DRX cycle T=32.
+	MmModem3gppDrxCycle64          MMModem3gppDrxCycle = 3 // DRX cycle T=64.
+	MmModem3gppDrxCycle128         MMModem3gppDrxCycle = 4 // DRX cycle T=128.
+	MmModem3gppDrxCycle256         MMModem3gppDrxCycle = 5 // DRX cycle T=256.
+)
+
 // MMFirmwareImageType Type of firmware image.
 type MMFirmwareImageType uint32
 
@@ -1390,7 +1566,35 @@ const (
 	MMSignalPropertyTypeGsm  MMSignalPropertyType = 2 // Signal Type Gsm.
 	MMSignalPropertyTypeUmts MMSignalPropertyType = 3 // Signal Type Umts.
 	MMSignalPropertyTypeLte  MMSignalPropertyType = 4 // Signal Type Lte.
+	MMSignalPropertyTypeNr5g MMSignalPropertyType = 5 // Signal Type 5G NR.
+
+)
+
+// MMCellType Type of cell information reported.
+type MMCellType uint32
 
+//go:generate stringer -type=MMCellType -trimprefix=MmCellType
+const (
+	MmCellTypeUnknown MMCellType = 0 // Unknown.
+	MmCellTypeCdma    MMCellType = 1 // CDMA.
+	MmCellTypeGsm     MMCellType = 2 // GSM.
+	MmCellTypeUmts    MMCellType = 3 // UMTS.
+	MmCellTypeTdscdma MMCellType = 4 // TD-SCDMA.
+	MmCellTypeLte     MMCellType = 5 // LTE.
+	MmCellType5gnr    MMCellType = 6 // 5GNR.
+)
+
+// MMServingCellType Indicates the type of the serving cell in a carrier aggregation or dual connectivity setup.
+type MMServingCellType uint32
+
+//go:generate stringer -type=MMServingCellType -trimprefix=MmServingCellType
+const (
+	MmServingCellTypeUnknown MMServingCellType = 0          // Unknown.
+	MmServingCellTypePcell   MMServingCellType = 1          // Primary cell.
+	MmServingCellTypeScell   MMServingCellType = 2          // Secondary cell.
+	MmServingCellTypePscell  MMServingCellType = 3          // Primary cell of the secondary cell group (dual connectivity).
+	MmServingCellTypeSscell  MMServingCellType = 4          // Secondary cell of the secondary cell group (dual connectivity).
+	MmServingCellTypeInvalid MMServingCellType = 0xFFFFFFFF // Invalid.
 )
 
 // ref https://gitlab.freedesktop.org/mobile-broadband/ModemManager/-/blob/master/include/ModemManager-enums.h
@@ -0,0 +1,122 @@
+package modemmanager
+
+import (
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Error domains of ModemManager
+const (
+	ModemManagerErrorInterface = ModemManagerInterface + ".Error"
+
+	ModemManagerErrorCore            = ModemManagerErrorInterface + ".Core"
+	ModemManagerErrorMobileEquipment = ModemManagerErrorInterface + ".MobileEquipment"
+	ModemManagerErrorConnection      = ModemManagerErrorInterface + ".Connection"
+	ModemManagerErrorSerial          = ModemManagerErrorInterface + ".Serial"
+	ModemManagerErrorMessage         = ModemManagerErrorInterface + ".Message"
+	ModemManagerErrorCdmaActivation  = ModemManagerErrorInterface + ".CdmaActivation"
+)
+
+// ModemManagerError represents an error returned by ModemManager, e.g. org.freedesktop.ModemManager1.Error.MobileEquipment.SimPin.
+// It wraps the typed error (MMCoreError, MMMobileEquipmentError, MMConnectionError, MMSerialError, MMMessageError or
+// MMCdmaActivationError), so it can be checked by errors.Is(err, MmMobileEquipmentErrorSimPin) or errors.As.
+type ModemManagerError struct {
+	Name    string // The dbus error name
+	Message string // The error message sent by ModemManager
+	Err     error  // The typed error, nil if the error name is unknown
+}
+
+// Error returns the error message
+func (e *ModemManagerError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return e.Name
+}
+
+// Unwrap returns the typed error
+func (e *ModemManagerError) Unwrap() error {
+	return e.Err
+}
+
+func (e MMCoreError) Error() string {
+	return "core error: " + strings.TrimPrefix(e.String(), "MmCoreError")
+}
+
+func (e MMMobileEquipmentError) Error() string {
+	return "mobile equipment error: " + strings.TrimPrefix(e.String(), "MmMobileEquipmentError")
+}
+
+func (e MMConnectionError) Error() string {
+	return "connection error: " + strings.TrimPrefix(e.String(), "MmConnectionError")
+}
+
+func (e MMSerialError) Error() string {
+	return "serial error: " + strings.TrimPrefix(e.String(), "MmSerialError")
+}
+
+func (e MMMessageError) Error() string {
+	return "message error: " + strings.TrimPrefix(e.String(), "MmMessageError")
+}
+
+func (e MMCdmaActivationError) Error() string {
+	return "cdma activation error: " + e.String()
+}
+
+// errorNames maps the dbus error names to the typed errors
+var errorNames = makeErrorNames()
+
+func makeErrorNames() map[string]error {
+	names := make(map[string]error)
+	// the error enums have no gaps above these values
+	for i := MMCoreError(0); i <= MmCoreErrorExists; i++ {
+		addErrorName(names, ModemManagerErrorCore, "MmCoreError", i.String(), i)
+	}
+	for i := MMMobileEquipmentError(0); i <= MmMobileEquipmentErrorGprsRequestRejectedBcmViolation; i++ {
+		addErrorName(names, ModemManagerErrorMobileEquipment, "MmMobileEquipmentError", i.String(), i)
+	}
+	for i := MMConnectionError(0); i <= MmConnectionErrorNoAnswer; i++ {
+		addErrorName(names, ModemManagerErrorConnection, "MmConnectionError", i.String(), i)
+	}
+	for i := MMSerialError(0); i <= MmSerialErrorFrameNotFound; i++ {
+		addErrorName(names, ModemManagerErrorSerial, "MmSerialError", i.String(), i)
+	}
+	for i := MMMessageError(0); i <= MmMessageErrorUnknown; i++ {
+		addErrorName(names, ModemManagerErrorMessage, "MmMessageError", i.String(), i)
+	}
+	for i := MMCdmaActivationError(0); i <= MmCdmaActivationErrorStartFailed; i++ {
+		names[ModemManagerErrorCdmaActivation+"."+i.String()] = i
+	}
+	return names
+}
+
+func addErrorName(names map[string]error, domain string, prefix string, name string, err error) {
+	// values without a constant are printed as e.g. MMCoreError(42)
+	if !strings.HasPrefix(name, prefix) {
+		return
+	}
+	names[domain+"."+strings.TrimPrefix(name, prefix)] = err
+}
+
+// parseError translates ModemManager dbus errors into a ModemManagerError, other errors are returned unchanged
+func parseError(err error) error {
+	var name string
+	var body []interface{}
+	switch e := err.(type) {
+	case dbus.Error:
+		name, body = e.Name, e.Body
+	case *dbus.Error:
+		name, body = e.Name, e.Body
+	default:
+		return err
+	}
+	if !strings.HasPrefix(name, ModemManagerErrorInterface+".") {
+		return err
+	}
+	mmErr := &ModemManagerError{Name: name, Err: errorNames[name]}
+	if len(body) > 0 {
+		mmErr.Message, _ = body[0].(string)
+	}
+	return mmErr
+}
@@ -0,0 +1,82 @@
+package modemmanager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name string
+		want error
+	}{
+		{ModemManagerErrorCore + ".WrongState", MmCoreErrorWrongState},
+		{ModemManagerErrorCore + ".Unsupported", MmCoreErrorUnsupported},
+		{ModemManagerErrorMobileEquipment + ".SimPin", MmMobileEquipmentErrorSimPin},
+		{ModemManagerErrorMobileEquipment + ".SimPuk", MmMobileEquipmentErrorSimPuk},
+		{ModemManagerErrorMobileEquipment + ".IncorrectPassword", MmMobileEquipmentErrorIncorrectPassword},
+		{ModemManagerErrorMobileEquipment + ".GprsServiceOptionNotSubscribed", MmMobileEquipmentErrorGprsServiceOptionNotSubscribed},
+		{ModemManagerErrorConnection + ".NoCarrier", MmConnectionErrorNoCarrier},
+		{ModemManagerErrorSerial + ".OpenFailed", MmSerialErrorOpenFailed},
+		{ModemManagerErrorMessage + ".NotSupported", MmMessageErrorNotSupported},
+		{ModemManagerErrorCdmaActivation + ".Roaming", MmCdmaActivationErrorRoaming},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseError(dbus.Error{Name: tt.name, Body: []interface{}{"message of ModemManager"}})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+			var mmErr *ModemManagerError
+			if !errors.As(err, &mmErr) {
+				t.Fatalf("errors.As(%v, *ModemManagerError) = false", err)
+			}
+			if mmErr.Name != tt.name || mmErr.Message != "message of ModemManager" {
+				t.Errorf("got name %q and message %q", mmErr.Name, mmErr.Message)
+			}
+			if err.Error() != "message of ModemManager" {
+				t.Errorf("Error() = %q, want the message of ModemManager", err.Error())
+			}
+		})
+	}
+}
+
+func TestParseErrorAs(t *testing.T) {
+	err := parseError(&dbus.Error{Name: ModemManagerErrorMobileEquipment + ".SimNotInserted"})
+	var meErr MMMobileEquipmentError
+	if !errors.As(err, &meErr) || meErr != MmMobileEquipmentErrorSimNotInserted {
+		t.Errorf("errors.As(%v, MMMobileEquipmentError) = %v, want MmMobileEquipmentErrorSimNotInserted", err, meErr)
+	}
+	var coreErr MMCoreError
+	if errors.As(err, &coreErr) {
+		t.Errorf("errors.As(%v, MMCoreError) = true", err)
+	}
+	if err.Error() != ModemManagerErrorMobileEquipment+".SimNotInserted" {
+		t.Errorf("Error() = %q, want the error name without a message", err.Error())
+	}
+}
+
+func TestParseErrorUnknown(t *testing.T) {
+	unknown := parseError(dbus.Error{Name: ModemManagerErrorCore + ".SomethingNew"})
+	var mmErr *ModemManagerError
+	if !errors.As(unknown, &mmErr) || mmErr.Err != nil {
+		t.Errorf("unknown ModemManager error %v not kept as ModemManagerError without typed error", unknown)
+	}
+	if errors.Is(unknown, MmCoreErrorFailed) {
+		t.Errorf("unknown ModemManager error %v matches MmCoreErrorFailed", unknown)
+	}
+	var dbusErr dbus.Error
+	other := parseError(dbus.Error{Name: "org.freedesktop.DBus.Error.ServiceUnknown"})
+	if !errors.As(other, &dbusErr) || dbusErr.Name != "org.freedesktop.DBus.Error.ServiceUnknown" {
+		t.Errorf("other dbus error changed to %#v", other)
+	}
+	plain := errors.New("plain")
+	if err := parseError(plain); err != plain {
+		t.Errorf("plain error changed to %v", err)
+	}
+	if err := parseError(nil); err != nil {
+		t.Errorf("parseError(nil) = %v", err)
+	}
+}
@@ -0,0 +1,694 @@
+package modemmanager
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	dbusMethodRemoveMatch       = "org.freedesktop.DBus.RemoveMatch"
+	dbusMethodGetNameOwner      = "org.freedesktop.DBus.GetNameOwner"
+	dbusPropertiesInterface     = "org.freedesktop.DBus.Properties"
+	dbusPropertiesChangedSignal = dbusPropertiesInterface + "." + dbusPropertiesChanged
+	dbusObjectManagerInterface  = "org.freedesktop.DBus.ObjectManager"
+	dbusInterfacesAdded         = "InterfacesAdded"
+	dbusInterfacesRemoved       = "InterfacesRemoved"
+)
+
+// Event is emitted by the EventDispatcher for each signal sent by ModemManager
+type Event interface {
+	// GetObjectPath returns the path of the object which sent the signal
+	GetObjectPath() dbus.ObjectPath
+	// GetInterface returns the interface of the signal, for PropertiesChanged the interface of the changed properties
+	GetInterface() string
+}
+
+// ModemStateChangedEvent is emitted by the StateChanged signal of a modem
+type ModemStateChangedEvent struct {
+	Path     dbus.ObjectPath          // The modem path
+	OldState MMModemState             // The previous state
+	NewState MMModemState             // The new state
+	Reason   MMModemStateChangeReason // The reason of the state change
+}
+
+// GetObjectPath returns the modem path
+func (e ModemStateChangedEvent) GetObjectPath() dbus.ObjectPath { return e.Path }
+
+// GetInterface returns ModemInterface
+func (e ModemStateChangedEvent) GetInterface() string { return ModemInterface }
+
+func (e ModemStateChangedEvent) String() string {
+	return fmt.Sprintf("%s: modem state changed from %s to %s (%s)", e.Path, e.OldState, e.NewState, e.Reason)
+}
+
+// BearerConnectedEvent is emitted if the Connected property of a bearer changes
+type BearerConnectedEvent struct {
+	Path      dbus.ObjectPath // The bearer path
+	Connected bool            // The new value of the Connected property
+}
+
+// GetObjectPath returns the bearer path
+func (e BearerConnectedEvent) GetObjectPath() dbus.ObjectPath { return e.Path }
+
+// GetInterface returns BearerInterface
+func (e BearerConnectedEvent) GetInterface() string { return BearerInterface }
+
+func (e BearerConnectedEvent) String() string {
+	return fmt.Sprintf("%s: bearer connected: %t", e.Path, e.Connected)
+}
+
+// SmsAddedEvent is emitted by the Added signal of the messaging interface of a modem
+type SmsAddedEvent struct {
+	Path     dbus.ObjectPath // The modem path
+	Sms      Sms             // The added message
+	Received bool            // True if the message was received from the network, false if it was created by the user
+}
+
+// GetObjectPath returns the modem path
+func (e SmsAddedEvent) GetObjectPath() dbus.ObjectPath { return e.Path }
+
+// GetInterface returns ModemMessagingInterface
+func (e SmsAddedEvent) GetInterface() string { return ModemMessagingInterface }
+
+func (e SmsAddedEvent) String() string {
+	return fmt.Sprintf("%s: sms added: %s (received: %t)", e.Path, e.Sms.GetObjectPath(), e.Received)
+}
+
+// CallStateChangedEvent is emitted by the StateChanged signal of a call
+type CallStateChangedEvent struct {
+	Path     dbus.ObjectPath   // The call path
+	OldState MMCallState       // The previous state
+	NewState MMCallState       // The new state
+	Reason   MMCallStateReason // The reason of the state change
+}
+
+// GetObjectPath returns the call path
+func (e CallStateChangedEvent) GetObjectPath() dbus.ObjectPath { return e.Path }
+
+// GetInterface returns CallInterface
+func (e CallStateChangedEvent) GetInterface() string { return CallInterface }
+
+func (e CallStateChangedEvent) String() string {
+	return fmt.Sprintf("%s: call state changed from %s to %s (%s)", e.Path, e.OldState, e.NewState, e.Reason)
+}
+
+// ModemAddedEvent is emitted if ModemManager exports a new modem, e.g. after plugging in or resetting a modem
+type ModemAddedEvent struct {
+	Path                dbus.ObjectPath // The modem path
+	Modem               Modem           // The added modem
+	DeviceIdentifier    string          // The DeviceIdentifier property of the modem
+	EquipmentIdentifier string          // The EquipmentIdentifier property of the modem
+	PreviousPath        dbus.ObjectPath // The path of a removed modem with the same identifier, only set by a ModemWatcher
+}
+
+// GetObjectPath returns the modem path
+func (e ModemAddedEvent) GetObjectPath() dbus.ObjectPath { return e.Path }
+
+// GetInterface returns ModemInterface
+func (e ModemAddedEvent) GetInterface() string { return ModemInterface }
+
+func (e ModemAddedEvent) String() string {
+	if e.PreviousPath != "" {
+		return fmt.Sprintf("%s: modem added (%s, previously %s)", e.Path, e.EquipmentIdentifier, e.PreviousPath)
+	}
+	return fmt.Sprintf("%s: modem added (%s)", e.Path, e.EquipmentIdentifier)
+}
+
+// ModemRemovedEvent is emitted if ModemManager removes a modem, e.g. after unplugging or resetting a modem
+type ModemRemovedEvent struct {
+	Path                dbus.ObjectPath // The modem path, which is not valid anymore
+	DeviceIdentifier    string          // The DeviceIdentifier property of the modem, only set by a ModemWatcher
+	EquipmentIdentifier string          // The EquipmentIdentifier property of the modem, only set by a ModemWatcher
+}
+
+// GetObjectPath returns the modem path
+func (e ModemRemovedEvent) GetObjectPath() dbus.ObjectPath { return e.Path }
+
+// GetInterface returns ModemInterface
+func (e ModemRemovedEvent) GetInterface() string { return ModemInterface }
+
+func (e ModemRemovedEvent) String() string {
+	return fmt.Sprintf("%s: modem removed (%s)", e.Path, e.EquipmentIdentifier)
+}
+
+// PropertyChangedEvent is emitted for each property of a PropertiesChanged signal
+type PropertyChangedEvent struct {
+	Path        dbus.ObjectPath // The object path
+	Interface   string          // The interface of the property, e.g. ModemInterface
+	Name        string          // The property name without interface, e.g. State
+	Value       interface{}     // The decoded value, e.g. MMModemState for the State property of a modem. Nil if invalidated
+	Variant     dbus.Variant    // The value as sent by ModemManager
+	Invalidated bool            // True if the property changed, but the new value was not sent
+}
+
+// GetObjectPath returns the object path
+func (e PropertyChangedEvent) GetObjectPath() dbus.ObjectPath { return e.Path }
+
+// GetInterface returns the interface of the property
+func (e PropertyChangedEvent) GetInterface() string { return e.Interface }
+
+func (e PropertyChangedEvent) String() string {
+	if e.Invalidated {
+		return fmt.Sprintf("%s: %s.%s invalidated", e.Path, e.Interface, e.Name)
+	}
+	return fmt.Sprintf("%s: %s.%s changed to %v", e.Path, e.Interface, e.Name, e.Value)
+}
+
+// SignalEvent is emitted for all signals without a typed event, e.g. DtmfReceived of a call
+type SignalEvent struct {
+	Path      dbus.ObjectPath // The object path
+	Interface string          // The interface of the signal
+	Member    string          // The signal name without interface, e.g. DtmfReceived
+	Body      []interface{}   // The signal values
+}
+
+// GetObjectPath returns the object path
+func (e SignalEvent) GetObjectPath() dbus.ObjectPath { return e.Path }
+
+// GetInterface returns the interface of the signal
+func (e SignalEvent) GetInterface() string { return e.Interface }
+
+func (e SignalEvent) String() string {
+	return fmt.Sprintf("%s: %s.%s %v", e.Path, e.Interface, e.Member, e.Body)
+}
+
+// EventFilter selects the events of a subscription, empty fields match all events
+type EventFilter struct {
+	Path      dbus.ObjectPath // The object path, e.g. of a modem or a bearer
+	Interface string          // The interface, e.g. ModemInterface, for PropertiesChanged the interface of the changed properties
+}
+
+func (f EventFilter) match(e Event) bool {
+	if f.Path != "" && f.Path != e.GetObjectPath() {
+		return false
+	}
+	if f.Interface != "" && f.Interface != e.GetInterface() {
+		return false
+	}
+	return true
+}
+
+// EventSubscription receives the events matching its filter until Unsubscribe is called
+type EventSubscription struct {
+	dispatcher *EventDispatcher
+	filter     EventFilter
+	events     chan Event
+	done       chan struct{}
+	doneOnce   sync.Once
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// Events returns the channel of the events, which is closed after Unsubscribe
+func (s *EventSubscription) Events() <-chan Event {
+	return s.events
+}
+
+// Unsubscribe stops the subscription and closes the event channel. It is safe to call it multiple times.
+func (s *EventSubscription) Unsubscribe() {
+	s.dispatcher.mu.Lock()
+	delete(s.dispatcher.subscriptions, s)
+	s.dispatcher.mu.Unlock()
+	s.close()
+}
+
+func (s *EventSubscription) close() {
+	// unblock a pending send before waiting for the lock
+	s.doneOnce.Do(func() { close(s.done) })
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.events)
+}
+
+func (s *EventSubscription) send(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.events <- e:
+	case <-s.done:
+	}
+}
+
+// EventDispatcher receives all signals of ModemManager on one connection and sends them as typed events
+// (ModemAddedEvent, ModemRemovedEvent, ModemStateChangedEvent, BearerConnectedEvent, SmsAddedEvent,
+// CallStateChangedEvent, PropertyChangedEvent or SignalEvent) to the subscriptions with a matching filter.
+// Subscribers must receive their events continuously, as a blocked subscription delays all other subscriptions.
+type EventDispatcher struct {
+	conn    *dbus.Conn
+	rule    string
+	signals chan *dbus.Signal
+	done    chan struct{}
+
+	mu            sync.Mutex
+	subscriptions map[*EventSubscription]struct{}
+	closed        bool
+}
+
+// NewEventDispatcher returns a new EventDispatcher using the system bus
+func NewEventDispatcher() (*EventDispatcher, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return nil, err
+	}
+	return NewEventDispatcherWithConn(conn)
+}
+
+// NewEventDispatcherWithConn returns a new EventDispatcher using the given dbus connection
+func NewEventDispatcherWithConn(conn *dbus.Conn) (*EventDispatcher, error) {
+	if conn == nil {
+		return nil, errors.New("no dbus connection given")
+	}
+	ed := &EventDispatcher{
+		conn:          conn,
+		rule:          fmt.Sprintf("type='signal',sender='%s',path_namespace='%s'", ModemManagerInterface, ModemManagerObjectPath),
+		signals:       make(chan *dbus.Signal, 64),
+		done:          make(chan struct{}),
+		subscriptions: make(map[*EventSubscription]struct{}),
+	}
+	err := conn.BusObject().Call(dbusMethodAddMatch, 0, ed.rule).Err
+	if err != nil {
+		return nil, err
+	}
+	conn.Signal(ed.signals)
+	go ed.run()
+	return ed, nil
+}
+
+// Subscribe returns a new subscription for all events matching the filter. The size of the channel buffer
+// is given by buffer.
+func (ed *EventDispatcher) Subscribe(filter EventFilter, buffer int) *EventSubscription {
+	s := &EventSubscription{
+		dispatcher: ed,
+		filter:     filter,
+		events:     make(chan Event, buffer),
+		done:       make(chan struct{}),
+	}
+	ed.mu.Lock()
+	defer ed.mu.Unlock()
+	if ed.closed {
+		s.close()
+		return s
+	}
+	ed.subscriptions[s] = struct{}{}
+	return s
+}
+
+// Close stops the dispatcher and all subscriptions, the connection is not closed
+func (ed *EventDispatcher) Close() error {
+	ed.mu.Lock()
+	if ed.closed {
+		ed.mu.Unlock()
+		return nil
+	}
+	ed.closed = true
+	subscriptions := ed.subscriptions
+	ed.subscriptions = make(map[*EventSubscription]struct{})
+	ed.mu.Unlock()
+
+	close(ed.done)
+	ed.conn.RemoveSignal(ed.signals)
+	for s := range subscriptions {
+		s.close()
+	}
+	return ed.conn.BusObject().Call(dbusMethodRemoveMatch, 0, ed.rule).Err
+}
+
+func (ed *EventDispatcher) run() {
+	for {
+		select {
+		case <-ed.done:
+			return
+		case sig, ok := <-ed.signals:
+			if !ok {
+				// the connection was closed
+				_ = ed.Close()
+				return
+			}
+			for _, e := range ed.parseSignal(sig) {
+				ed.dispatch(e)
+			}
+		}
+	}
+}
+
+func (ed *EventDispatcher) dispatch(e Event) {
+	ed.mu.Lock()
+	var matches []*EventSubscription
+	for s := range ed.subscriptions {
+		if s.filter.match(e) {
+			matches = append(matches, s)
+		}
+	}
+	ed.mu.Unlock()
+	for _, s := range matches {
+		s.send(e)
+	}
+}
+
+func (ed *EventDispatcher) parseSignal(sig *dbus.Signal) []Event {
+	if !strings.HasPrefix(string(sig.Path), ModemManagerObjectPath) {
+		return nil
+	}
+	idx := strings.LastIndex(sig.Name, ".")
+	if idx < 0 {
+		return nil
+	}
+	iface, member := sig.Name[:idx], sig.Name[idx+1:]
+	raw := []Event{SignalEvent{Path: sig.Path, Interface: iface, Member: member, Body: sig.Body}}
+
+	switch {
+	case sig.Name == dbusPropertiesChangedSignal:
+		var base dbusBase
+		propIface, changed, invalidated, err := base.parsePropertiesChanged(sig)
+		if err != nil {
+			return raw
+		}
+		return parsePropertiesChangedEvents(sig.Path, propIface, changed, invalidated)
+
+	case iface == dbusObjectManagerInterface && member == dbusInterfacesAdded:
+		if len(sig.Body) != 2 {
+			return raw
+		}
+		path, ok1 := sig.Body[0].(dbus.ObjectPath)
+		interfaces, ok2 := sig.Body[1].(map[string]map[string]dbus.Variant)
+		if !ok1 || !ok2 {
+			return raw
+		}
+		if e, ok := parseModemAdded(ed.conn, path, interfaces); ok {
+			return []Event{e}
+		}
+		return raw
+
+	case iface == dbusObjectManagerInterface && member == dbusInterfacesRemoved:
+		if len(sig.Body) != 2 {
+			return raw
+		}
+		path, ok1 := sig.Body[0].(dbus.ObjectPath)
+		interfaces, ok2 := sig.Body[1].([]string)
+		if !ok1 || !ok2 {
+			return raw
+		}
+		for _, i := range interfaces {
+			if i == ModemInterface {
+				return []Event{ModemRemovedEvent{Path: path}}
+			}
+		}
+		return raw
+
+	case iface == ModemInterface && member == ModemSignalStateChanged:
+		oldState, newState, reason, err := modem{}.ParseStateChanged(sig)
+		if err != nil {
+			return raw
+		}
+		return []Event{ModemStateChangedEvent{Path: sig.Path, OldState: oldState, NewState: newState, Reason: reason}}
+
+	case iface == CallInterface && member == CallSignalStateChanged:
+		oldState, newState, reason, err := call{}.ParseStateChanged(sig)
+		if err != nil {
+			return raw
+		}
+		return []Event{CallStateChangedEvent{Path: sig.Path, OldState: oldState, NewState: newState, Reason: reason}}
+
+	case iface == ModemMessagingInterface && member == ModemMessagingSignalAdded:
+		me := modemMessaging{dbusBase: dbusBase{conn: ed.conn}}
+		sms, received, err := me.ParseAdded(sig)
+		if err != nil {
+			return raw
+		}
+		return []Event{SmsAddedEvent{Path: sig.Path, Sms: sms, Received: received}}
+	}
+	return raw
+}
+
+// parseModemAdded returns a ModemAddedEvent if the interfaces of the object contain the modem interface
+func parseModemAdded(conn *dbus.Conn, path dbus.ObjectPath, interfaces map[string]map[string]dbus.Variant) (e ModemAddedEvent, ok bool) {
+	properties, ok := interfaces[ModemInterface]
+	if !ok {
+		return
+	}
+	modem, err := NewModemWithConn(conn, path)
+	if err != nil {
+		return e, false
+	}
+	e = ModemAddedEvent{Path: path, Modem: modem}
+	e.DeviceIdentifier, _ = properties["DeviceIdentifier"].Value().(string)
+	e.EquipmentIdentifier, _ = properties["EquipmentIdentifier"].Value().(string)
+	return e, true
+}
+
+func parsePropertiesChangedEvents(path dbus.ObjectPath, iface string, changed map[string]dbus.Variant, invalidated []string) (events []Event) {
+	names := make([]string, 0, len(changed))
+	for name := range changed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		variant := changed[name]
+		if iface == BearerInterface && name == "Connected" {
+			if connected, ok := variant.Value().(bool); ok {
+				events = append(events, BearerConnectedEvent{Path: path, Connected: connected})
+			}
+		}
+		events = append(events, PropertyChangedEvent{
+			Path:      path,
+			Interface: iface,
+			Name:      name,
+			Value:     decodeProperty(iface+"."+name, variant.Value()),
+			Variant:   variant,
+		})
+	}
+	for _, name := range invalidated {
+		events = append(events, PropertyChangedEvent{Path: path, Interface: iface, Name: name, Invalidated: true})
+	}
+	return
+}
+
+// propertyDecoders converts the raw values of properties into the types returned by the getters
+var propertyDecoders = map[string]func(v interface{}) interface{}{
+	ModemPropertyState: decodeInt32(func(v int32) interface{} { return MMModemState(v) }),
+	ModemPropertyStateFailedReason: decodeUint32(func(v uint32) interface{} {
+		return MMModemStateFailedReason(v)
+	}),
+	ModemPropertyUnlockRequired: decodeUint32(func(v uint32) interface{} { return MMModemLock(v) }),
+	ModemPropertyPowerState:     decodeUint32(func(v uint32) interface{} { return MMModemPowerState(v) }),
+	ModemPropertyAccessTechnologies: decodeUint32(func(v uint32) interface{} {
+		var tmp MMModemAccessTechnology
+		return tmp.BitmaskToSlice(v)
+	}),
+	ModemPropertySignalQuality: decodePair(func(p Pair) interface{} { return p }),
+	ModemPropertyCurrentModes: decodePair(func(p Pair) interface{} {
+		allowed, ok1 := p.a.(uint32)
+		preferred, ok2 := p.b.(uint32)
+		if !ok1 || !ok2 {
+			return p
+		}
+		var tmp MMModemMode
+		return Mode{AllowedModes: tmp.BitmaskToSlice(allowed), PreferredMode: MMModemMode(preferred)}
+	}),
+	Modem3gppPropertyRegistrationState: decodeUint32(func(v uint32) interface{} {
+		return MMModem3gppRegistrationState(v)
+	}),
+	Modem3gppUssdPropertyState: decodeUint32(func(v uint32) interface{} { return MMModem3gppUssdSessionState(v) }),
+	ModemLocationPropertyEnabled: decodeUint32(func(v uint32) interface{} {
+		var tmp MMModemLocationSource
+		return tmp.BitmaskToSlice(v)
+	}),
+	SmsPropertyState:         decodeUint32(func(v uint32) interface{} { return MMSmsState(v) }),
+	SmsPropertyDeliveryState: decodeUint32(func(v uint32) interface{} { return MMSmsDeliveryState(v) }),
+	CallPropertyState:        decodeInt32(func(v int32) interface{} { return MMCallState(v) }),
+	CallPropertyStateReason:  decodeInt32(func(v int32) interface{} { return MMCallStateReason(v) }),
+	CallPropertyDirection:    decodeInt32(func(v int32) interface{} { return MMCallDirection(v) }),
+}
+
+func decodeProperty(property string, value interface{}) interface{} {
+	decoder, ok := propertyDecoders[property]
+	if !ok {
+		return value
+	}
+	return decoder(value)
+}
+
+func decodeInt32(f func(v int32) interface{}) func(v interface{}) interface{} {
+	return func(v interface{}) interface{} {
+		if i, ok := v.(int32); ok {
+			return f(i)
+		}
+		return v
+	}
+}
+
+func decodeUint32(f func(v uint32) interface{}) func(v interface{}) interface{} {
+	return func(v interface{}) interface{} {
+		if u, ok := v.(uint32); ok {
+			return f(u)
+		}
+		return v
+	}
+}
+
+func decodePair(f func(p Pair) interface{}) func(v interface{}) interface{} {
+	return func(v interface{}) interface{} {
+		values, ok := v.([]interface{})
+		if !ok || len(values) != 2 {
+			return v
+		}
+		return f(Pair{a: values[0], b: values[1]})
+	}
+}
+
+// signalSubscription holds the signal channels of an object. It is shared by all copies of the object, so
+// Unsubscribe works on every copy.
+type signalSubscription struct {
+	mu        sync.Mutex
+	rules     []string
+	forwarder *signalForwarder
+}
+
+// signalForwarder receives the signals of the connection and forwards them to the receivers with a matching
+// subscription, until the object unsubscribes
+type signalForwarder struct {
+	conn *dbus.Conn
+	in   chan *dbus.Signal
+	done chan struct{}
+
+	mu        sync.Mutex
+	receivers []signalReceiver
+	owner     string // the unique bus name of ModemManager
+}
+
+// signalReceiver is the channel returned by a single subscription
+type signalReceiver struct {
+	match signalMatch
+	out   chan *dbus.Signal
+}
+
+type signalMatch struct {
+	name      string // interface and member of the signal
+	path      dbus.ObjectPath
+	namespace bool // true if the signals of all objects below path match
+}
+
+func (m signalMatch) match(sig *dbus.Signal) bool {
+	if sig.Name != m.name {
+		return false
+	}
+	return sig.Path == m.path || m.namespace && strings.HasPrefix(string(sig.Path), string(m.path)+"/")
+}
+
+// subscribeSignal adds a match rule for the signal of ModemManager sent by the object at path and returns a new
+// channel, which only receives this signal
+func (d *dbusBase) subscribeSignal(iface string, member string, path dbus.ObjectPath) <-chan *dbus.Signal {
+	rule := fmt.Sprintf("type='signal',sender='%s',interface='%s',member='%s',path='%s'", ModemManagerInterface, iface, member, path)
+	return d.addSignalReceiver(rule, signalMatch{name: iface + "." + member, path: path})
+}
+
+// subscribeSignalNamespace is the same as subscribeSignal for the signals of all objects within the path namespace
+func (d *dbusBase) subscribeSignalNamespace(iface string, member string, namespace dbus.ObjectPath) <-chan *dbus.Signal {
+	rule := fmt.Sprintf("type='signal',sender='%s',interface='%s',member='%s',path_namespace='%s'", ModemManagerInterface, iface, member, namespace)
+	return d.addSignalReceiver(rule, signalMatch{name: iface + "." + member, path: namespace, namespace: true})
+}
+
+func (d *dbusBase) addSignalReceiver(rule string, match signalMatch) <-chan *dbus.Signal {
+	s := d.sig
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !d.Contains(s.rules, rule) {
+		d.conn.BusObject().Call(dbusMethodAddMatch, 0, rule)
+		s.rules = append(s.rules, rule)
+	}
+	if s.forwarder == nil {
+		s.forwarder = &signalForwarder{
+			conn: d.conn,
+			in:   make(chan *dbus.Signal, 10),
+			done: make(chan struct{}),
+		}
+		d.conn.Signal(s.forwarder.in)
+		go s.forwarder.run()
+	}
+	out := make(chan *dbus.Signal, 10)
+	s.forwarder.mu.Lock()
+	s.forwarder.receivers = append(s.forwarder.receivers, signalReceiver{match: match, out: out})
+	s.forwarder.mu.Unlock()
+	return out
+}
+
+// unsubscribeSignals removes the match rules and closes the signal channels of the object
+func (d *dbusBase) unsubscribeSignals() {
+	s := d.sig
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.forwarder == nil {
+		return
+	}
+	d.conn.RemoveSignal(s.forwarder.in)
+	close(s.forwarder.done)
+	for _, rule := range s.rules {
+		d.conn.BusObject().Call(dbusMethodRemoveMatch, 0, rule)
+	}
+	s.rules, s.forwarder = nil, nil
+}
+
+func (f *signalForwarder) run() {
+	defer func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for _, r := range f.receivers {
+			close(r.out)
+		}
+	}()
+	for {
+		select {
+		case <-f.done:
+			return
+		case sig, ok := <-f.in:
+			if !ok {
+				return
+			}
+			var matches []chan *dbus.Signal
+			f.mu.Lock()
+			for _, r := range f.receivers {
+				if r.match.match(sig) {
+					matches = append(matches, r.out)
+				}
+			}
+			f.mu.Unlock()
+			if len(matches) == 0 || !f.fromModemManager(sig.Sender) {
+				continue
+			}
+			for _, out := range matches {
+				select {
+				case out <- sig:
+				case <-f.done:
+					return
+				}
+			}
+		}
+	}
+}
+
+// fromModemManager returns true if the sender is ModemManager. Signals carry the unique name of the sender, which
+// is looked up again if it does not match, e.g. after a restart of ModemManager.
+func (f *signalForwarder) fromModemManager(sender string) bool {
+	if sender == ModemManagerInterface || sender != "" && sender == f.owner {
+		return true
+	}
+	var owner string
+	if err := f.conn.BusObject().Call(dbusMethodGetNameOwner, 0, ModemManagerInterface).Store(&owner); err != nil {
+		return false
+	}
+	f.owner = owner
+	return sender == owner
+}
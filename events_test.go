@@ -0,0 +1,276 @@
+package modemmanager_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+// nextEvent returns the next event matching f, it fails the test after a timeout or if the channel is closed
+func nextEvent(t *testing.T, events <-chan mm.Event, f func(e mm.Event) bool) mm.Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatal("event channel closed")
+			}
+			if f(e) {
+				return e
+			}
+		case <-timeout:
+			t.Fatal("timeout waiting for event")
+		}
+	}
+}
+
+// nextSignal returns the next signal, it fails the test after a timeout or if the channel is closed
+func nextSignal(t *testing.T, signals <-chan *dbus.Signal) *dbus.Signal {
+	t.Helper()
+	select {
+	case sig, ok := <-signals:
+		if !ok {
+			t.Fatal("signal channel closed")
+		}
+		return sig
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for signal")
+	}
+	return nil
+}
+
+func newDispatcher(t *testing.T, conn *dbus.Conn) *mm.EventDispatcher {
+	t.Helper()
+	ed, err := mm.NewEventDispatcherWithConn(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ed
+}
+
+func TestEventDispatcherModem(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	ed := newDispatcher(t, conn)
+	defer ed.Close()
+	sub := ed.Subscribe(mm.EventFilter{Interface: mm.ModemInterface}, 32)
+
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{EquipmentIdentifier: "861234567890123"})
+	e := nextEvent(t, sub.Events(), func(e mm.Event) bool {
+		_, ok := e.(mm.ModemAddedEvent)
+		return ok
+	}).(mm.ModemAddedEvent)
+	if e.Path != fake.GetObjectPath() || e.EquipmentIdentifier != "861234567890123" || e.Modem == nil {
+		t.Errorf("got %s, want modem added at %s", e, fake.GetObjectPath())
+	}
+
+	if err := modem.Enable(); err != nil {
+		t.Fatal(err)
+	}
+	state := nextEvent(t, sub.Events(), func(e mm.Event) bool {
+		_, ok := e.(mm.ModemStateChangedEvent)
+		return ok
+	}).(mm.ModemStateChangedEvent)
+	if state.OldState != mm.MmModemStateDisabled || state.NewState != mm.MmModemStateEnabling ||
+		state.Reason != mm.MmModemStateChangeReasonUserRequested {
+		t.Errorf("got %s, want change from disabled to enabling by user request", state)
+	}
+	property := nextEvent(t, sub.Events(), func(e mm.Event) bool {
+		p, ok := e.(mm.PropertyChangedEvent)
+		return ok && p.Name == "State" && p.Value == mm.MmModemStateRegistered
+	}).(mm.PropertyChangedEvent)
+	if property.Path != fake.GetObjectPath() || property.Interface != mm.ModemInterface {
+		t.Errorf("got %s, want state property of %s", property, fake.GetObjectPath())
+	}
+
+	fake.Remove()
+	removed := nextEvent(t, sub.Events(), func(e mm.Event) bool {
+		_, ok := e.(mm.ModemRemovedEvent)
+		return ok
+	})
+	if removed.GetObjectPath() != fake.GetObjectPath() {
+		t.Errorf("got %s, want modem removed at %s", removed, fake.GetObjectPath())
+	}
+}
+
+func TestEventDispatcherFilter(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	first, _ := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	second, _ := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	ed := newDispatcher(t, conn)
+	defer ed.Close()
+	sub := ed.Subscribe(mm.EventFilter{Path: first.GetObjectPath(), Interface: mm.ModemInterface}, 32)
+	all := ed.Subscribe(mm.EventFilter{}, 32)
+
+	second.SetSignalQuality(10)
+	first.SetSignalQuality(20)
+	e := nextEvent(t, sub.Events(), func(e mm.Event) bool {
+		if e.GetObjectPath() != first.GetObjectPath() || e.GetInterface() != mm.ModemInterface {
+			t.Errorf("event %s does not match the filter", e)
+		}
+		p, ok := e.(mm.PropertyChangedEvent)
+		return ok && p.Name == "SignalQuality"
+	}).(mm.PropertyChangedEvent)
+	if quality, ok := e.Value.(mm.Pair); !ok || quality.GetLeft() != uint32(20) {
+		t.Errorf("signal quality = %v, want 20", e.Value)
+	}
+	nextEvent(t, all.Events(), func(e mm.Event) bool {
+		p, ok := e.(mm.PropertyChangedEvent)
+		return ok && p.Path == second.GetObjectPath() && p.Name == "SignalQuality"
+	})
+}
+
+func TestEventDispatcherBearerAndSms(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	ed := newDispatcher(t, conn)
+	defer ed.Close()
+	sub := ed.Subscribe(mm.EventFilter{}, 64)
+
+	simple, err := modem.GetSimpleModem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bearer, err := simple.Connect(mm.SimpleProperties{Apn: "internet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	connected := nextEvent(t, sub.Events(), func(e mm.Event) bool {
+		_, ok := e.(mm.BearerConnectedEvent)
+		return ok
+	}).(mm.BearerConnectedEvent)
+	if connected.Path != bearer.GetObjectPath() || !connected.Connected {
+		t.Errorf("got %s, want %s connected", connected, bearer.GetObjectPath())
+	}
+
+	received, err := fake.ReceiveSms("+491701234567", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	added := nextEvent(t, sub.Events(), func(e mm.Event) bool {
+		_, ok := e.(mm.SmsAddedEvent)
+		return ok
+	}).(mm.SmsAddedEvent)
+	if added.Path != fake.GetObjectPath() || !added.Received || added.Sms.GetObjectPath() != received.GetObjectPath() {
+		t.Errorf("got %s, want %s received", added, received.GetObjectPath())
+	}
+}
+
+func TestEventDispatcherClose(t *testing.T) {
+	_, conn, stop := mmtest.StartT(t)
+	defer stop()
+	ed := newDispatcher(t, conn)
+	sub := ed.Subscribe(mm.EventFilter{}, 1)
+	unsubscribed := ed.Subscribe(mm.EventFilter{}, 1)
+	unsubscribed.Unsubscribe()
+	if _, ok := <-unsubscribed.Events(); ok {
+		t.Error("event channel open after Unsubscribe")
+	}
+	if err := ed.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-sub.Events(); ok {
+		t.Error("event channel open after Close")
+	}
+	if _, ok := <-ed.Subscribe(mm.EventFilter{}, 1).Events(); ok {
+		t.Error("event channel of a closed dispatcher open")
+	}
+}
+
+func TestSubscribeSeparateChannels(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	stateChanged := modem.SubscribeStateChanged()
+	propertiesChanged := modem.SubscribePropertiesChanged()
+	defer modem.Unsubscribe()
+	if err := modem.Enable(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		sig := nextSignal(t, stateChanged)
+		if sig.Name != mm.ModemInterface+"."+mm.ModemSignalStateChanged {
+			t.Fatalf("state channel received %s", sig.Name)
+		}
+		if _, _, _, err := modem.ParseStateChanged(sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		sig := nextSignal(t, propertiesChanged)
+		if sig.Name != "org.freedesktop.DBus.Properties.PropertiesChanged" {
+			t.Fatalf("properties channel received %s", sig.Name)
+		}
+	}
+	modem.Unsubscribe()
+	for range stateChanged {
+	}
+	for range propertiesChanged {
+	}
+}
+
+func TestSubscribeDtmfReceived(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, _ := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	fakeCall, err := fake.IncomingCall("+491701234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+	call, err := mm.NewCallWithConn(conn, fakeCall.GetObjectPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dtmf := call.SubscribeDtmfReceived()
+	stateChanged := call.SubscribeStateChanged()
+	defer call.Unsubscribe()
+	fakeCall.ReceiveDtmf("5")
+	fakeCall.SetState(mm.MmCallStateActive, mm.MmCallStateReasonAccepted)
+	tone, err := call.ParseDtmfReceived(nextSignal(t, dtmf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tone != "5" {
+		t.Errorf("dtmf = %q, want 5", tone)
+	}
+	_, newState, _, err := call.ParseStateChanged(nextSignal(t, stateChanged))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newState != mm.MmCallStateActive {
+		t.Errorf("state = %s, want active", newState)
+	}
+}
+
+func TestSubscribeSignalSender(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	stateChanged := modem.SubscribeStateChanged()
+	defer modem.Unsubscribe()
+	other, err := srv.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	// another client on the bus must not be able to fake signals of ModemManager
+	err = other.Emit(fake.GetObjectPath(), mm.ModemInterface+"."+mm.ModemSignalStateChanged,
+		int32(mm.MmModemStateDisabled), int32(mm.MmModemStateFailed), uint32(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.SetState(mm.MmModemStateEnabled, mm.MmModemStateChangeReasonUnknown)
+	_, newState, _, err := modem.ParseStateChanged(nextSignal(t, stateChanged))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newState != mm.MmModemStateEnabled {
+		t.Errorf("state = %s, want the state enabled sent by ModemManager", newState)
+	}
+}
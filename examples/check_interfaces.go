@@ -231,5 +231,18 @@ func main() {
 			fmt.Println("Oma available")
 		}
 
+		modemSar, err := modem.GetSar()
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		fmt.Println("ModemSar for: ", modemSar.GetObjectPath())
+		tmpByteSlice, err = modemSar.MarshalJSON()
+		if err != nil {
+			fmt.Println(err)
+			fmt.Println("Sar not available")
+		} else {
+			fmt.Println("Sar available")
+		}
+
 	}
 }
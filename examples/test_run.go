@@ -388,16 +388,16 @@ func main() {
 				}
 				fmt.Println("------- ")
 				fmt.Println("Scanned Networks: ", networks)
-				modem3gpp.RequestScan() //async, takes ~1min
+				scanRequest := modem3gpp.RequestScan() //async, takes ~1min
 				fmt.Println("-----")
 				networkRes, err := modem3gpp.GetScanResults()
 				if err != nil {
 					log.Fatal(err.Error())
 				}
 				fmt.Println(networkRes)
-				time.Sleep(1*time.Minute)
-				fmt.Println("----- sleep 1 min ------")
-				networkRes2, err := modem3gpp.GetScanResults()
+				<-scanRequest.Done()
+				fmt.Println("----- scan done ------")
+				networkRes2, err := scanRequest.Result()
 				if err != nil {
 					log.Fatal(err.Error())
 				}
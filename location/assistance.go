@@ -0,0 +1,349 @@
+package location
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// ErrXtraNotSupported is returned if the modem does not support XTRA assistance data
+var ErrXtraNotSupported = errors.New("xtra assistance data not supported by the modem")
+
+// HTTPClient downloads the assistance data, it is implemented by *http.Client
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Injection describes assistance data injected into the GNSS module
+type Injection struct {
+	Time     time.Time // The time of the injection
+	Server   string    // The URL the data was downloaded from, empty if taken from the cache
+	Size     int       // The size of the data in bytes
+	Modified time.Time // The time the data was published, by the Last-Modified header or else the download time
+}
+
+// AssistanceUpdater downloads XTRA assistance data from the servers advertised by the modem, verifies its size and
+// age, caches it on disk and injects it into the GNSS module, which speeds up the first GPS fix.
+type AssistanceUpdater struct {
+	modem mm.Modem
+
+	// Client downloads the data, defaults to a http.Client with a timeout of 1 minute
+	Client HTTPClient
+	// Servers are the URLs to download from in the given order, defaults to the AssistanceDataServers of the modem
+	Servers []string
+	// CacheDir is the directory of the cached data, nothing is cached if empty
+	CacheDir string
+	// MaxAge is the age after which cached data is downloaded again, defaults to 24h
+	MaxAge time.Duration
+	// Validity is the maximum age of data to be injected, by its Last-Modified header, defaults to 7 days
+	Validity time.Duration
+	// MinSize is the minimum size of valid data in bytes, defaults to 1 KiB
+	MinSize int
+	// MaxSize is the maximum size of valid data in bytes, defaults to 4 MiB
+	MaxSize int
+	// Interval is the interval of updates after Start, defaults to 12h
+	Interval time.Duration
+
+	mu          sync.Mutex
+	onInject    []func(Injection)
+	onError     []func(error)
+	cancel      context.CancelFunc
+	stopped     chan struct{}
+	updateMutex sync.Mutex
+}
+
+// NewAssistanceUpdater returns a new AssistanceUpdater of the given modem
+func NewAssistanceUpdater(modem mm.Modem) (*AssistanceUpdater, error) {
+	if modem == nil {
+		return nil, errors.New("no modem given")
+	}
+	return &AssistanceUpdater{
+		modem:    modem,
+		Client:   &http.Client{Timeout: time.Minute},
+		MaxAge:   24 * time.Hour,
+		Validity: 7 * 24 * time.Hour,
+		MinSize:  1 << 10,
+		MaxSize:  4 << 20,
+		Interval: 12 * time.Hour,
+	}, nil
+}
+
+// OnInject adds a callback, which is called after data is injected. Callbacks are called from the goroutine calling
+// Update and should not block.
+func (u *AssistanceUpdater) OnInject(f func(injection Injection)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.onInject = append(u.onInject, f)
+}
+
+// OnError adds a callback, which is called for failed downloads, even if cached data is injected instead, and for
+// errors of the updater goroutine
+func (u *AssistanceUpdater) OnError(f func(err error)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.onError = append(u.onError, f)
+}
+
+// Supported returns true if the modem supports XTRA assistance data
+func (u *AssistanceUpdater) Supported() (bool, error) {
+	location, err := u.modem.GetLocation()
+	if err != nil {
+		return false, err
+	}
+	types, err := location.GetSupportedAssistanceData()
+	if err != nil {
+		return false, err
+	}
+	for _, t := range types {
+		if t == mm.MmModemLocationAssistanceDataTypeXtra {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Update injects the cached data if it is younger than MaxAge, otherwise it downloads new data from the first server
+// which returns valid data. If all downloads fail, cached data within Validity is injected.
+func (u *AssistanceUpdater) Update(ctx context.Context) error {
+	u.updateMutex.Lock()
+	defer u.updateMutex.Unlock()
+	supported, err := u.Supported()
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return ErrXtraNotSupported
+	}
+	location, err := u.modem.GetLocation()
+	if err != nil {
+		return err
+	}
+	data, modified, cacheErr := u.readCache()
+	server := ""
+	if cacheErr != nil || time.Since(modified) > u.MaxAge {
+		downloaded, downloadedModified, downloadedServer, err := u.download(ctx, location)
+		switch {
+		case err == nil:
+			data, modified, server = downloaded, downloadedModified, downloadedServer
+			u.handleError(u.writeCache(data, modified))
+		case cacheErr != nil || time.Since(modified) > u.Validity:
+			return err
+		default:
+			u.handleError(err)
+		}
+	}
+	if err := location.InjectAssistanceData(data); err != nil {
+		return err
+	}
+	injection := Injection{Time: time.Now(), Server: server, Size: len(data), Modified: modified}
+	u.mu.Lock()
+	onInject := u.onInject
+	u.mu.Unlock()
+	for _, f := range onInject {
+		f(injection)
+	}
+	return nil
+}
+
+// download returns the data of the first server with valid data
+func (u *AssistanceUpdater) download(ctx context.Context, location mm.ModemLocation) (data []byte, modified time.Time, server string, err error) {
+	servers := u.Servers
+	if len(servers) == 0 {
+		servers, err = location.GetAssistanceDataServers()
+		if err != nil {
+			return
+		}
+	}
+	if len(servers) == 0 {
+		err = errors.New("no assistance data server given")
+		return
+	}
+	for _, server = range servers {
+		data, modified, err = u.downloadFrom(ctx, server)
+		if err == nil {
+			return
+		}
+	}
+	return nil, time.Time{}, "", err
+}
+
+func (u *AssistanceUpdater) downloadFrom(ctx context.Context, server string) ([]byte, time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server, nil)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, time.Time{}, fmt.Errorf("download of %s failed: %s", server, resp.Status)
+	}
+	if resp.ContentLength > int64(u.MaxSize) {
+		return nil, time.Time{}, fmt.Errorf("assistance data of %s too large: %d bytes", server, resp.ContentLength)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, int64(u.MaxSize)+1))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	modified := time.Now()
+	if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+		if t, err := http.ParseTime(lastModified); err == nil {
+			modified = t
+		}
+	}
+	if err := u.verify(data, modified); err != nil {
+		return nil, time.Time{}, fmt.Errorf("assistance data of %s: %v", server, err)
+	}
+	return data, modified, nil
+}
+
+// verify checks the size and the age of the data
+func (u *AssistanceUpdater) verify(data []byte, modified time.Time) error {
+	if len(data) < u.MinSize {
+		return fmt.Errorf("too small: %d bytes", len(data))
+	}
+	if len(data) > u.MaxSize {
+		return fmt.Errorf("too large: more than %d bytes", u.MaxSize)
+	}
+	if age := time.Since(modified); age > u.Validity {
+		return fmt.Errorf("outdated: published %s ago", age.Round(time.Second))
+	}
+	return nil
+}
+
+// cacheFile returns the path of the cached data
+func (u *AssistanceUpdater) cacheFile() string {
+	return filepath.Join(u.CacheDir, "xtra.bin")
+}
+
+// readCache returns the cached data and its publishing time, which is kept as modification time of the file
+func (u *AssistanceUpdater) readCache() ([]byte, time.Time, error) {
+	if u.CacheDir == "" {
+		return nil, time.Time{}, errors.New("no cache directory given")
+	}
+	info, err := os.Stat(u.cacheFile())
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := ioutil.ReadFile(u.cacheFile())
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if err := u.verify(data, info.ModTime()); err != nil {
+		return nil, time.Time{}, errors.New("cached assistance data " + err.Error())
+	}
+	return data, info.ModTime(), nil
+}
+
+// writeCache writes the data to a temporary file and renames it, so a crash never leaves partial data behind
+func (u *AssistanceUpdater) writeCache(data []byte, modified time.Time) error {
+	if u.CacheDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(u.CacheDir, 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(u.CacheDir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chtimes(tmp.Name(), modified, modified)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), u.cacheFile())
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// Start checks the XTRA support of the modem and updates the assistance data now and every Interval in a new
+// goroutine
+func (u *AssistanceUpdater) Start() error {
+	supported, err := u.Supported()
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return ErrXtraNotSupported
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.cancel != nil {
+		return errors.New("assistance updater already started")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	u.cancel = cancel
+	u.stopped = make(chan struct{})
+	go u.run(ctx, u.stopped)
+	return nil
+}
+
+// Stop cancels a running download and waits for the updater goroutine
+func (u *AssistanceUpdater) Stop() {
+	u.mu.Lock()
+	cancel, stopped := u.cancel, u.stopped
+	u.cancel, u.stopped = nil, nil
+	u.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-stopped
+}
+
+func (u *AssistanceUpdater) run(ctx context.Context, stopped chan struct{}) {
+	defer close(stopped)
+	interval := u.Interval
+	if interval <= 0 {
+		interval = 12 * time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := u.Update(ctx); err != nil && ctx.Err() == nil {
+			u.handleError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *AssistanceUpdater) handleError(err error) {
+	if err == nil {
+		return
+	}
+	u.mu.Lock()
+	onError := u.onError
+	u.mu.Unlock()
+	for _, f := range onError {
+		f(err)
+	}
+}
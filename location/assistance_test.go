@@ -0,0 +1,275 @@
+package location
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+// xtraServer serves assistance data and counts the requests
+type xtraServer struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	status       int
+	data         []byte
+	lastModified time.Time
+	chunked      bool
+	requests     int
+}
+
+func newXtraServer(data []byte, lastModified time.Time) *xtraServer {
+	s := &xtraServer{status: http.StatusOK, data: data, lastModified: lastModified}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests++
+		if s.status != http.StatusOK {
+			w.WriteHeader(s.status)
+			return
+		}
+		if !s.lastModified.IsZero() {
+			w.Header().Set("Last-Modified", s.lastModified.UTC().Format(http.TimeFormat))
+		}
+		if !s.chunked {
+			w.Header().Set("Content-Length", strconv.Itoa(len(s.data)))
+		}
+		w.Write(s.data)
+	}))
+	return s
+}
+
+func (s *xtraServer) set(status int, data []byte, lastModified time.Time, chunked bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status, s.data, s.lastModified, s.chunked = status, data, lastModified, chunked
+}
+
+func (s *xtraServer) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "location")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// newUpdater returns an updater with a cache in dir of a modem supporting XTRA, which advertises the given servers
+func newUpdater(t *testing.T, modem mm.Modem, fake *mmtest.Modem, dir string, servers ...string) *AssistanceUpdater {
+	t.Helper()
+	fake.SetProperty(mm.ModemLocationInterface, "SupportedAssistanceData", uint32(mm.MmModemLocationAssistanceDataTypeXtra))
+	fake.SetProperty(mm.ModemLocationInterface, "AssistanceDataServers", servers)
+	u, err := NewAssistanceUpdater(modem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.CacheDir = dir
+	return u
+}
+
+// injected returns the data of the InjectAssistanceData calls
+func injected(srv *mmtest.Server) [][]byte {
+	var data [][]byte
+	for _, call := range srv.Calls() {
+		if call.Method == mm.ModemLocationInjectAssistanceData {
+			data = append(data, call.Args[0].([]byte))
+		}
+	}
+	return data
+}
+
+func writeCache(t *testing.T, u *AssistanceUpdater, data []byte, modified time.Time) {
+	t.Helper()
+	if err := u.writeCache(data, modified); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// recorder keeps the injections and errors reported by an updater
+type recorder struct {
+	injections []Injection
+	errs       []error
+}
+
+func record(u *AssistanceUpdater) *recorder {
+	r := &recorder{}
+	u.OnInject(func(injection Injection) {
+		r.injections = append(r.injections, injection)
+	})
+	u.OnError(func(err error) {
+		r.errs = append(r.errs, err)
+	})
+	return r
+}
+
+// inject updates and returns the injection and the errors reported by the update
+func (r *recorder) inject(t *testing.T, u *AssistanceUpdater) (Injection, []error) {
+	t.Helper()
+	injections, errs := len(r.injections), len(r.errs)
+	if err := u.Update(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.injections) != injections+1 {
+		t.Fatalf("got injections %v, want one more", r.injections)
+	}
+	return r.injections[injections], r.errs[errs:]
+}
+
+func TestAssistanceUpdaterDownload(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	data := bytes.Repeat([]byte{0xa5}, 2048)
+	published := time.Now().Add(-time.Hour).Truncate(time.Second)
+	xtra := newXtraServer(data, published)
+	defer xtra.Close()
+	u := newUpdater(t, modem, fake, dir, xtra.URL+"/xtra3grc.bin")
+	r := record(u)
+
+	injection, errs := r.inject(t, u)
+	if len(errs) > 0 {
+		t.Errorf("got errors %v", errs)
+	}
+	if injection.Server != xtra.URL+"/xtra3grc.bin" || injection.Size != len(data) || !injection.Modified.Equal(published) {
+		t.Errorf("got injection %+v, want the downloaded data", injection)
+	}
+	if got := injected(srv); len(got) != 1 || !bytes.Equal(got[0], data) {
+		t.Fatalf("injected %d times, want the downloaded data", len(got))
+	}
+	info, err := os.Stat(filepath.Join(dir, "xtra.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(published) {
+		t.Errorf("modification time of the cache = %s, want the publishing time %s", info.ModTime(), published)
+	}
+
+	// the cache within MaxAge is injected without download
+	injection, _ = r.inject(t, u)
+	if xtra.Requests() != 1 {
+		t.Errorf("got %d requests, want the cached data injected", xtra.Requests())
+	}
+	if injection.Server != "" || !injection.Modified.Equal(published) {
+		t.Errorf("got injection %+v, want the cached data", injection)
+	}
+	if got := injected(srv); len(got) != 2 || !bytes.Equal(got[1], data) {
+		t.Errorf("injected %d times, want the cached data", len(got))
+	}
+}
+
+func TestAssistanceUpdaterCacheFallback(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	xtra := newXtraServer(nil, time.Time{})
+	defer xtra.Close()
+	xtra.set(http.StatusServiceUnavailable, nil, time.Time{}, false)
+	u := newUpdater(t, modem, fake, dir, xtra.URL)
+	r := record(u)
+	cached := bytes.Repeat([]byte{0x5a}, 4096)
+
+	// the cache older than MaxAge is injected if the download fails
+	writeCache(t, u, cached, time.Now().Add(-48*time.Hour))
+	injection, errs := r.inject(t, u)
+	if xtra.Requests() != 1 || len(errs) != 1 {
+		t.Errorf("got %d requests and errors %v, want the failed download reported", xtra.Requests(), errs)
+	}
+	if injection.Server != "" || injection.Size != len(cached) {
+		t.Errorf("got injection %+v, want the cached data", injection)
+	}
+	if got := injected(srv); len(got) != 1 || !bytes.Equal(got[0], cached) {
+		t.Errorf("injected %d times, want the cached data", len(got))
+	}
+
+	// the cache older than Validity is not injected
+	writeCache(t, u, cached, time.Now().Add(-8*24*time.Hour))
+	if err := u.Update(context.Background()); err == nil {
+		t.Error("outdated cache injected")
+	}
+	if got := injected(srv); len(got) != 1 {
+		t.Errorf("injected %d times, want no injection of the outdated cache", len(got))
+	}
+}
+
+func TestAssistanceUpdaterVerify(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	xtra := newXtraServer(nil, time.Time{})
+	defer xtra.Close()
+	u := newUpdater(t, modem, fake, dir, xtra.URL)
+	u.MaxSize = 8192
+
+	tests := []struct {
+		name         string
+		data         []byte
+		lastModified time.Time
+		chunked      bool
+		err          string
+	}{
+		{"too small", make([]byte, 100), time.Time{}, false, "too small: 100 bytes"},
+		{"too large", make([]byte, 8193), time.Time{}, false, "too large: 8193 bytes"},
+		{"too large without content length", make([]byte, 10000), time.Time{}, true, "too large: more than 8192 bytes"},
+		{"outdated", make([]byte, 2048), time.Now().Add(-8 * 24 * time.Hour), false, "outdated"},
+	}
+	for _, test := range tests {
+		xtra.set(http.StatusOK, test.data, test.lastModified, test.chunked)
+		if err := u.Update(context.Background()); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.err)
+		}
+	}
+	if got := injected(srv); len(got) != 0 {
+		t.Errorf("injected %d times, want no injection of invalid data", len(got))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "xtra.bin")); !os.IsNotExist(err) {
+		t.Errorf("invalid data cached: %v", err)
+	}
+
+	// invalid data of a server falls back to the next server
+	valid := newXtraServer(make([]byte, 2048), time.Time{})
+	defer valid.Close()
+	u.Servers = []string{xtra.URL, valid.URL}
+	if injection, _ := record(u).inject(t, u); injection.Server != valid.URL {
+		t.Errorf("got injection %+v, want the data of the second server", injection)
+	}
+}
+
+func TestAssistanceUpdaterNotSupported(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	u, err := NewAssistanceUpdater(modem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Update(context.Background()); err != ErrXtraNotSupported {
+		t.Errorf("got error %v, want ErrXtraNotSupported", err)
+	}
+	if err := u.Start(); err != ErrXtraNotSupported {
+		t.Errorf("got error %v, want ErrXtraNotSupported", err)
+	}
+}
@@ -0,0 +1,307 @@
+package location
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// ErrCellNotFound is returned by a CellResolver for unknown cells
+var ErrCellNotFound = errors.New("cell not found")
+
+// CellPosition is the approximate position of a cell
+type CellPosition struct {
+	Latitude  float64 // Latitude in decimal degrees
+	Longitude float64 // Longitude in decimal degrees
+	Range     float64 // Approximate radius of the cell coverage in meters, 0 if unknown
+}
+
+// CellResolver turns a serving cell into an approximate position, e.g. by an offline CellDatabase or an online
+// geolocation service
+type CellResolver interface {
+	// Resolve returns the position of the cell, or ErrCellNotFound if the cell is unknown
+	Resolve(cell mm.ThreeGppLacCiLocation) (CellPosition, error)
+}
+
+// AddCellPosition sets the position of a fix without position to the position of its serving cell, with the
+// source MmModemLocationSource3gppLacCi and the range of the cell as accuracy
+func (f *Fix) AddCellPosition(resolver CellResolver) error {
+	if f.HasPosition || !f.HasCell() {
+		return nil
+	}
+	position, err := resolver.Resolve(f.Cell)
+	if err != nil {
+		return err
+	}
+	f.Source = mm.MmModemLocationSource3gppLacCi
+	f.HasPosition = true
+	f.Latitude, f.Longitude, f.Accuracy = position.Latitude, position.Longitude, position.Range
+	return nil
+}
+
+// cellKey identifies a cell, area is the LAC or TAC and cell the CI of the cell. The mnc keeps its digits, as the
+// 2 digit network code 10 and the 3 digit network code 010 are different networks.
+type cellKey struct {
+	mcc  uint16
+	mnc  string
+	area uint32
+	cell uint64
+}
+
+// cellEntry is the position of a cell in a compact form, as a database may hold millions of cells
+type cellEntry struct {
+	latitude  float32
+	longitude float32
+	rng       uint32
+}
+
+// CellDatabase is an in-memory index of cell positions, e.g. loaded from an OpenCelliD export
+type CellDatabase struct {
+	mu      sync.RWMutex
+	cells   map[cellKey]cellEntry
+	skipped int
+}
+
+// NewCellDatabase returns a new, empty CellDatabase
+func NewCellDatabase() *CellDatabase {
+	return &CellDatabase{cells: make(map[cellKey]cellEntry)}
+}
+
+// LoadCellDatabase reads an OpenCelliD-style CSV file, optionally gzip compressed, into a new CellDatabase. The
+// columns are taken from the header line if present (mcc, net or mnc, area or lac, cell, lon, lat and range),
+// otherwise the OpenCelliD column order radio,mcc,net,area,cell,unit,lon,lat,range,... is assumed. If mccs are
+// given, only the cells of these country codes are loaded to save memory. Malformed rows are skipped, see Skipped.
+func LoadCellDatabase(r io.Reader, mccs ...int) (*CellDatabase, error) {
+	db := NewCellDatabase()
+	return db, db.Load(r, mccs...)
+}
+
+// LoadCellDatabaseFile reads an OpenCelliD-style CSV file into a new CellDatabase, see LoadCellDatabase
+func LoadCellDatabaseFile(path string, mccs ...int) (*CellDatabase, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadCellDatabase(file, mccs...)
+}
+
+// Load adds the cells of an OpenCelliD-style CSV file to the database, see LoadCellDatabase. Cells are resolved
+// after the file is loaded. Malformed rows are skipped and counted, an error is only returned if the file can't be
+// read.
+func (db *CellDatabase) Load(r io.Reader, mccs ...int) error {
+	buffered := bufio.NewReader(r)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = buffered
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	columns := map[string]int{"mcc": 1, "mnc": 2, "area": 3, "cell": 4, "lon": 6, "lat": 7, "range": 8}
+	countries := make(map[uint16]bool)
+	for _, mcc := range mccs {
+		countries[uint16(mcc)] = true
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			db.skipped++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if line == 1 && isCellHeader(record) {
+			columns = cellColumns(record)
+			continue
+		}
+		key, entry, err := parseCell(record, columns)
+		if err != nil {
+			db.skipped++
+			continue
+		}
+		if len(countries) > 0 && !countries[key.mcc] {
+			continue
+		}
+		db.cells[key] = entry
+	}
+	return nil
+}
+
+// isCellHeader returns true if the record is a header line with a mcc column
+func isCellHeader(record []string) bool {
+	for _, field := range record {
+		if strings.EqualFold(strings.TrimSpace(field), "mcc") {
+			return true
+		}
+	}
+	return false
+}
+
+// cellColumns returns the indexes of the columns of a header line
+func cellColumns(header []string) map[string]int {
+	aliases := map[string]string{"net": "mnc", "lac": "area", "tac": "area", "ci": "cell", "cid": "cell",
+		"longitude": "lon", "latitude": "lat"}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	return columns
+}
+
+func parseCell(record []string, columns map[string]int) (cellKey, cellEntry, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	mcc, err := strconv.ParseUint(field("mcc"), 10, 16)
+	if err != nil {
+		return cellKey{}, cellEntry{}, err
+	}
+	mnc := field("mnc")
+	if !validMnc(mnc) {
+		return cellKey{}, cellEntry{}, errors.New("invalid mnc " + mnc)
+	}
+	area, err := strconv.ParseUint(field("area"), 10, 32)
+	if err != nil {
+		return cellKey{}, cellEntry{}, err
+	}
+	cell, err := strconv.ParseUint(field("cell"), 10, 64)
+	if err != nil {
+		return cellKey{}, cellEntry{}, err
+	}
+	latitude, err := strconv.ParseFloat(field("lat"), 32)
+	if err != nil {
+		return cellKey{}, cellEntry{}, err
+	}
+	longitude, err := strconv.ParseFloat(field("lon"), 32)
+	if err != nil {
+		return cellKey{}, cellEntry{}, err
+	}
+	var rng uint64
+	if value := field("range"); value != "" {
+		if rng, err = strconv.ParseUint(value, 10, 32); err != nil {
+			return cellKey{}, cellEntry{}, err
+		}
+	}
+	key := cellKey{mcc: uint16(mcc), mnc: mnc, area: uint32(area), cell: cell}
+	return key, cellEntry{latitude: float32(latitude), longitude: float32(longitude), rng: uint32(rng)}, nil
+}
+
+// validMnc returns true if the network code has 1 to 3 decimal digits, exports with numeric network codes drop the
+// leading zeros
+func validMnc(mnc string) bool {
+	if len(mnc) == 0 || len(mnc) > 3 {
+		return false
+	}
+	for _, c := range mnc {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Add adds the position of a cell, given by its decimal country code, network code with its leading zeros, e.g.
+// "01", LAC or TAC and cell id
+func (db *CellDatabase) Add(mcc int, mnc string, area uint32, cell uint64, position CellPosition) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.cells[cellKey{mcc: uint16(mcc), mnc: mnc, area: area, cell: cell}] = cellEntry{
+		latitude:  float32(position.Latitude),
+		longitude: float32(position.Longitude),
+		rng:       uint32(position.Range),
+	}
+}
+
+// Len returns the number of cells in the database
+func (db *CellDatabase) Len() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return len(db.cells)
+}
+
+// Skipped returns the number of malformed rows skipped by Load
+func (db *CellDatabase) Skipped() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.skipped
+}
+
+// Resolve returns the position of the cell. LAC, TAC and CI are given in hex by ModemManager, the cell is looked
+// up by its LAC and by its TAC, as LTE cells are listed with the TAC as area. A cell which is not listed with the
+// network code of ModemManager is looked up by the network code without leading zeros, as in numeric exports like
+// the one of OpenCelliD.
+func (db *CellDatabase) Resolve(cell mm.ThreeGppLacCiLocation) (CellPosition, error) {
+	mcc, err := strconv.ParseUint(cell.Mcc, 10, 16)
+	if err != nil {
+		return CellPosition{}, errors.New("invalid mcc " + cell.Mcc)
+	}
+	if !validMnc(cell.Mnc) {
+		return CellPosition{}, errors.New("invalid mnc " + cell.Mnc)
+	}
+	mncs := []string{cell.Mnc}
+	if numeric := strings.TrimLeft(cell.Mnc, "0"); numeric != cell.Mnc {
+		if numeric == "" {
+			numeric = "0"
+		}
+		mncs = append(mncs, numeric)
+	}
+	ci, err := strconv.ParseUint(cell.Ci, 16, 64)
+	if err != nil {
+		return CellPosition{}, errors.New("invalid ci " + cell.Ci)
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	for _, mnc := range mncs {
+		for _, value := range []string{cell.Lac, cell.Tac} {
+			area, err := strconv.ParseUint(value, 16, 32)
+			if err != nil {
+				continue
+			}
+			if entry, ok := db.cells[cellKey{mcc: uint16(mcc), mnc: mnc, area: uint32(area), cell: ci}]; ok {
+				return CellPosition{
+					Latitude:  roundCoordinate(entry.latitude),
+					Longitude: roundCoordinate(entry.longitude),
+					Range:     float64(entry.rng),
+				}, nil
+			}
+		}
+	}
+	return CellPosition{}, ErrCellNotFound
+}
+
+// roundCoordinate rounds a stored coordinate to 6 decimals, the precision of float32 at most
+func roundCoordinate(f float32) float64 {
+	return math.Round(float64(f)*1e6) / 1e6
+}
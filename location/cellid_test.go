@@ -0,0 +1,179 @@
+package location
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// openCelliD is an excerpt in the format of the OpenCelliD exports, with the LTE cell listed by its TAC
+const openCelliD = `radio,mcc,net,area,cell,unit,lon,lat,range,samples,changeable,created,updated,averageSignal
+GSM,262,1,33835,10611,0,13.388889,52.516944,1203,12,1,1459692104,1603372410,0
+LTE,262,2,28670,26383873,0,13.404954,52.520008,1000,88,1,1459807253,1614077112,0
+UMTS,232,10,1030,1234567,0,16.372,48.208,500,3,1,1459807253,1614077112,0
+`
+
+func near(got float64, want float64) bool {
+	return math.Abs(got-want) < 1e-5
+}
+
+func resolve(t *testing.T, db *CellDatabase, cell mm.ThreeGppLacCiLocation) CellPosition {
+	t.Helper()
+	position, err := db.Resolve(cell)
+	if err != nil {
+		t.Fatalf("Resolve(%+v): %v", cell, err)
+	}
+	return position
+}
+
+func TestCellDatabaseOpenCelliD(t *testing.T) {
+	db, err := LoadCellDatabase(strings.NewReader(openCelliD))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 3 || db.Skipped() != 0 {
+		t.Fatalf("got %d cells and %d skipped rows, want 3 cells", db.Len(), db.Skipped())
+	}
+	// LAC 33835 and CI 10611 in hex
+	position := resolve(t, db, mm.ThreeGppLacCiLocation{Mcc: "262", Mnc: "01", Lac: "842B", Ci: "2973"})
+	// the positions are stored as float32
+	if !near(position.Latitude, 52.516944) || !near(position.Longitude, 13.388889) || position.Range != 1203 {
+		t.Errorf("got position %+v of the GSM cell", position)
+	}
+	// the LAC of a LTE cell is not set, the cell is looked up by its TAC 28670
+	position = resolve(t, db, mm.ThreeGppLacCiLocation{Mcc: "262", Mnc: "02", Lac: "FFFE", Ci: "1929601", Tac: "6FFE"})
+	if !near(position.Latitude, 52.520008) || !near(position.Longitude, 13.404954) {
+		t.Errorf("got position %+v of the LTE cell", position)
+	}
+	for _, cell := range []mm.ThreeGppLacCiLocation{
+		{Mcc: "262", Mnc: "01", Lac: "842C", Ci: "2973"},
+		{Mcc: "262", Mnc: "03", Lac: "842B", Ci: "2973"},
+		{Mcc: "262", Mnc: "02", Lac: "6FFE", Ci: "1929602"},
+	} {
+		if _, err := db.Resolve(cell); err != ErrCellNotFound {
+			t.Errorf("Resolve(%+v) returned %v, want ErrCellNotFound", cell, err)
+		}
+	}
+	if _, err := db.Resolve(mm.ThreeGppLacCiLocation{Mcc: "262", Mnc: "x1", Lac: "842B", Ci: "2973"}); err == nil || err == ErrCellNotFound {
+		t.Errorf("got error %v of an invalid mnc", err)
+	}
+}
+
+func TestCellDatabaseHeaderAliases(t *testing.T) {
+	csv := "Latitude; Longitude; MCC; MNC; TAC; CID\n" +
+		"52.5,13.4,262,01,28670,26383873\n"
+	db, err := LoadCellDatabase(strings.NewReader(strings.Replace(csv, ";", ",", -1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	position := resolve(t, db, mm.ThreeGppLacCiLocation{Mcc: "262", Mnc: "01", Ci: "1929601", Tac: "6FFE"})
+	if !near(position.Latitude, 52.5) || !near(position.Longitude, 13.4) || position.Range != 0 {
+		t.Errorf("got position %+v, want the columns of the header", position)
+	}
+
+	// without header, the column order of OpenCelliD is assumed
+	lines := strings.SplitN(openCelliD, "\n", 2)
+	db, err = LoadCellDatabase(strings.NewReader(lines[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 3 {
+		t.Errorf("got %d cells without header, want 3", db.Len())
+	}
+}
+
+func TestCellDatabaseMalformedRows(t *testing.T) {
+	csv := "mcc,mnc,lac,cell,lat,lon\n" +
+		"262,01,1,1,52.5,13.4\n" +
+		"26x,01,1,2,52.5,13.4\n" +
+		"262,01,1,3,52.5\n" +
+		"262,,1,4,52.5,13.4\n" +
+		"262,01,1,5,5\"2.5,13.4\n" +
+		"262,01,1,6,52.6,13.5\n"
+	db, err := LoadCellDatabase(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 2 || db.Skipped() != 4 {
+		t.Errorf("got %d cells and %d skipped rows, want 2 cells and 4 skipped rows", db.Len(), db.Skipped())
+	}
+	position := resolve(t, db, mm.ThreeGppLacCiLocation{Mcc: "262", Mnc: "01", Lac: "1", Ci: "6"})
+	if !near(position.Latitude, 52.6) {
+		t.Errorf("got position %+v of the row after the malformed rows", position)
+	}
+}
+
+func TestCellDatabaseMnc(t *testing.T) {
+	db := NewCellDatabase()
+	db.Add(310, "10", 1, 1, CellPosition{Latitude: 1})
+	db.Add(310, "010", 1, 1, CellPosition{Latitude: 2})
+	db.Add(310, "2", 1, 1, CellPosition{Latitude: 3})
+	if db.Len() != 3 {
+		t.Fatalf("got %d cells, want the network codes 10 and 010 kept apart", db.Len())
+	}
+	tests := []struct {
+		mnc      string
+		latitude float64
+	}{
+		{"10", 1},
+		{"010", 2},
+		// the network code of numeric exports lacks the leading zeros
+		{"02", 3},
+		{"002", 3},
+	}
+	for _, test := range tests {
+		position := resolve(t, db, mm.ThreeGppLacCiLocation{Mcc: "310", Mnc: test.mnc, Lac: "1", Ci: "1"})
+		if position.Latitude != test.latitude {
+			t.Errorf("mnc %s resolved to %+v, want latitude %v", test.mnc, position, test.latitude)
+		}
+	}
+}
+
+func TestCellDatabaseGzipCountries(t *testing.T) {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write([]byte(openCelliD)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	db, err := LoadCellDatabase(&b, 232)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 1 {
+		t.Fatalf("got %d cells, want the cell of mcc 232", db.Len())
+	}
+	resolve(t, db, mm.ThreeGppLacCiLocation{Mcc: "232", Mnc: "10", Lac: "406", Ci: "12D687"})
+}
+
+// wrappingResolver returns ErrCellNotFound wrapped, like a resolver of an online service
+type wrappingResolver struct{}
+
+func (wrappingResolver) Resolve(cell mm.ThreeGppLacCiLocation) (CellPosition, error) {
+	return CellPosition{}, fmt.Errorf("cell %s: %w", cell.Ci, ErrCellNotFound)
+}
+
+func TestTrackerUnknownCell(t *testing.T) {
+	tracker := &Tracker{CellResolver: wrappingResolver{}}
+	var errs []error
+	tracker.OnError(func(err error) {
+		errs = append(errs, err)
+	})
+	fixes := tracker.Fixes()
+	received := time.Now()
+	tracker.handle(NewFix(mm.CurrentLocation{ThreeGppLacCi: mm.ThreeGppLacCiLocation{Mcc: "262", Mnc: "01", Lac: "1", Ci: "1"}}, received), received)
+	if len(errs) > 0 {
+		t.Errorf("got errors %v of an unknown cell", errs)
+	}
+	if f := <-fixes; f.HasPosition || !f.HasCell() {
+		t.Errorf("got fix %+v, want the fix of the unknown cell without position", f)
+	}
+}
@@ -0,0 +1,141 @@
+package location
+
+import (
+	"math"
+	"time"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/nmea"
+)
+
+// earthRadius is the mean earth radius in meters
+const earthRadius = 6371008.8
+
+// userEquivalentRangeError is the typical range error of a GNSS receiver in meters, multiplied by the HDOP for the
+// estimated accuracy of a fix
+const userEquivalentRangeError = 5.0
+
+// Fix is a timestamped location of a modem, merged from all enabled location sources
+type Fix struct {
+	Time           time.Time                `json:"time"`            // Time of the fix, the GPS time if known, otherwise the time it was received
+	Source         mm.MMModemLocationSource `json:"source"`          // Source of the position, MmModemLocationSourceNone if only the serving cell is known
+	HasPosition    bool                     `json:"has_position"`    // True if Latitude and Longitude are set
+	Latitude       float64                  `json:"latitude"`        // Latitude in decimal degrees
+	Longitude      float64                  `json:"longitude"`       // Longitude in decimal degrees
+	HasAltitude    bool                     `json:"has_altitude"`    // True if the source reported the altitude
+	Altitude       float64                  `json:"altitude"`        // Altitude above mean sea level in meters, 0 if unknown
+	Accuracy       float64                  `json:"accuracy"`        // Estimated horizontal accuracy in meters, 0 if unknown
+	HasSpeed       bool                     `json:"has_speed"`       // True if SpeedKmh and Course are set
+	SpeedKmh       float64                  `json:"speed_kmh"`       // Speed over ground in km/h
+	Course         float64                  `json:"course"`          // Course over ground in degrees true north
+	Quality        nmea.FixQuality          `json:"quality"`         // The GPS fix quality of NMEA fixes
+	Type           nmea.FixType             `json:"type"`            // The GPS fix type of NMEA fixes
+	SatellitesUsed int                      `json:"satellites_used"` // Number of satellites used for NMEA fixes
+	HDOP           float64                  `json:"hdop"`            // Horizontal dilution of precision of NMEA fixes
+	PDOP           float64                  `json:"pdop"`            // Position dilution of precision of NMEA fixes
+	Satellites     []nmea.Satellite         `json:"satellites"`      // The satellites in view of NMEA fixes
+	Cell           mm.ThreeGppLacCiLocation `json:"cell"`            // The serving cell, empty if the 3GPP source is not enabled
+	SignalQuality  uint32                   `json:"signal_quality"`  // Signal quality in percent, 0 if not added by AddSignal
+	Signal         []mm.SignalProperty      `json:"signal"`          // Extended signal information of the access technologies, see AddSignal
+}
+
+// HasCell returns true if the serving cell is known
+func (f Fix) HasCell() bool {
+	return f.Cell.Ci != ""
+}
+
+// AddSignal adds the signal quality and the extended signal information of the modem to the fix, e.g. for coverage
+// mapping. The extended signal information requires ModemSignal.Setup with a rate greater than 0.
+func (f *Fix) AddSignal(modem mm.Modem) error {
+	quality, _, err := modem.GetSignalQuality()
+	if err != nil {
+		return err
+	}
+	signal, err := modem.GetSignal()
+	if err != nil {
+		return err
+	}
+	properties, err := signal.GetCurrentSignals()
+	if err != nil {
+		return err
+	}
+	f.SignalQuality, f.Signal = quality, properties
+	return nil
+}
+
+// NewFix merges a location of the modem, received at the given time, into a Fix. The position is taken from the
+// NMEA sentences if they contain a valid fix, otherwise from the raw GPS location or the CDMA base station.
+func NewFix(location mm.CurrentLocation, received time.Time) Fix {
+	f := Fix{Time: received.UTC(), Cell: location.ThreeGppLacCi}
+	if gps, _ := nmea.FromLocation(location.GpsNmea); gps.Valid && gps.HasPosition {
+		f.Source = mm.MmModemLocationSourceGpsNmea
+		f.HasPosition = true
+		f.Latitude, f.Longitude, f.Altitude, f.HasAltitude = gps.Latitude, gps.Longitude, gps.Altitude, gps.HasAltitude
+		f.HasSpeed, f.SpeedKmh, f.Course = gps.HasSpeed, gps.SpeedKmh, gps.Course
+		f.Quality, f.Type, f.SatellitesUsed = gps.Quality, gps.Type, gps.SatellitesUsed
+		f.HDOP, f.PDOP = gps.HDOP, gps.PDOP
+		f.Satellites = gps.Satellites
+		f.Accuracy = gps.HDOP * userEquivalentRangeError
+		if !gps.Time.IsZero() {
+			f.Time = gpsTime(gps.Time, f.Time)
+		}
+		return f
+	}
+	if raw := location.GpsRaw; raw.Latitude != 0 || raw.Longitude != 0 {
+		f.Source = mm.MmModemLocationSourceGpsRaw
+		f.HasPosition = true
+		f.Latitude, f.Longitude, f.Altitude = raw.Latitude, raw.Longitude, raw.Altitude
+		// the altitude of the raw location is optional and left 0 if not reported
+		f.HasAltitude = raw.Altitude != 0
+		if !raw.UtcTime.IsZero() {
+			// the raw location only carries the time of day
+			f.Time = timeOfDay(raw.UtcTime, f.Time)
+		}
+		return f
+	}
+	if cdma := location.CdmaBs; cdma.Latitude != 0 || cdma.Longitude != 0 {
+		f.Source = mm.MmModemLocationSourceCdmaBs
+		f.HasPosition = true
+		f.Latitude, f.Longitude = cdma.Latitude, cdma.Longitude
+	}
+	return f
+}
+
+// gpsTime returns the time of a NMEA fix, sentences without date get the date of the received time
+func gpsTime(t time.Time, received time.Time) time.Time {
+	if t.Year() > 0 {
+		return t
+	}
+	return timeOfDay(t, received)
+}
+
+// timeOfDay returns the time of day of t on the day of received, which is moved by a day if the time of day
+// is more than 12 hours off, e.g. for a fix shortly before midnight received after midnight
+func timeOfDay(t time.Time, received time.Time) time.Time {
+	year, month, day := received.Date()
+	result := time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	switch diff := result.Sub(received); {
+	case diff > 12*time.Hour:
+		result = result.AddDate(0, 0, -1)
+	case diff < -12*time.Hour:
+		result = result.AddDate(0, 0, 1)
+	}
+	return result
+}
+
+// Distance returns the great circle distance between two positions in meters
+func Distance(latitude1, longitude1, latitude2, longitude2 float64) float64 {
+	phi1, phi2 := latitude1*math.Pi/180, latitude2*math.Pi/180
+	dPhi := phi2 - phi1
+	dLambda := (longitude2 - longitude1) * math.Pi / 180
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// DistanceTo returns the distance to another fix in meters, 0 if one of them has no position
+func (f Fix) DistanceTo(other Fix) float64 {
+	if !f.HasPosition || !other.HasPosition {
+		return 0
+	}
+	return Distance(f.Latitude, f.Longitude, other.Latitude, other.Longitude)
+}
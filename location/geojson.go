@@ -0,0 +1,103 @@
+package location
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// geoJSONPosition returns the position of the fix as longitude, latitude and altitude if known
+func geoJSONPosition(f Fix) []float64 {
+	if f.HasAltitude {
+		return []float64{f.Longitude, f.Latitude, f.Altitude}
+	}
+	return []float64{f.Longitude, f.Latitude}
+}
+
+// WriteGeoJSON writes the track as GeoJSON FeatureCollection: a LineString feature of the whole track with the
+// times of its coordinates, followed by a Point feature for each fix with time, source, speed, course, accuracy,
+// serving cell and signal quality as properties, e.g. for coverage maps.
+func (tr Track) WriteGeoJSON(w io.Writer) error {
+	fixes := tr.positions()
+	collection := geoJSONFeatureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	if len(fixes) > 1 {
+		coordinates := make([][]float64, 0, len(fixes))
+		times := make([]string, 0, len(fixes))
+		for _, f := range fixes {
+			coordinates = append(coordinates, geoJSONPosition(f))
+			times = append(times, f.Time.UTC().Format(time.RFC3339Nano))
+		}
+		properties := map[string]interface{}{"coordTimes": times}
+		if tr.Name != "" {
+			properties["name"] = tr.Name
+		}
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "LineString", Coordinates: coordinates},
+			Properties: properties,
+		})
+	}
+	for _, f := range fixes {
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "Point", Coordinates: geoJSONPosition(f)},
+			Properties: fixProperties(f),
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(collection)
+}
+
+// fixProperties returns the properties of a fix which are set
+func fixProperties(f Fix) map[string]interface{} {
+	properties := map[string]interface{}{
+		"time":   f.Time.UTC().Format(time.RFC3339Nano),
+		"source": f.Source.String(),
+	}
+	if f.Accuracy > 0 {
+		properties["accuracy"] = f.Accuracy
+	}
+	if f.HasSpeed {
+		properties["speed_kmh"] = f.SpeedKmh
+		properties["course"] = f.Course
+	}
+	if f.SatellitesUsed > 0 {
+		properties["satellites_used"] = f.SatellitesUsed
+	}
+	if f.HDOP > 0 {
+		properties["hdop"] = f.HDOP
+	}
+	if f.HasCell() {
+		properties["mcc"] = f.Cell.Mcc
+		properties["mnc"] = f.Cell.Mnc
+		properties["lac"] = f.Cell.Lac
+		properties["ci"] = f.Cell.Ci
+		properties["tac"] = f.Cell.Tac
+	}
+	if f.SignalQuality > 0 {
+		properties["signal_quality"] = f.SignalQuality
+	}
+	for _, sp := range f.Signal {
+		for _, v := range signalValues(sp) {
+			properties[signalType(sp)+"_"+v.name] = v.value
+		}
+	}
+	return properties
+}
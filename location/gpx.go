@@ -0,0 +1,140 @@
+package location
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+
+	"github.com/maltegrosse/go-modemmanager/nmea"
+)
+
+// GpxExtensionNamespace is the namespace of the GPX extensions with speed, course, source, serving cell and signal
+// quality of a track point
+const GpxExtensionNamespace = "https://github.com/maltegrosse/go-modemmanager/location/gpx/1"
+
+type gpxFile struct {
+	XMLName        xml.Name `xml:"gpx"`
+	Version        string   `xml:"version,attr"`
+	Creator        string   `xml:"creator,attr"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	XmlnsExtension string   `xml:"xmlns:mm,attr"`
+	Track          gpxTrack `xml:"trk"`
+}
+
+type gpxTrack struct {
+	Name    string     `xml:"name,omitempty"`
+	Segment []gpxPoint `xml:"trkseg>trkpt"`
+}
+
+// gpxPoint is a track point, the elements are in the order of the GPX 1.1 schema
+type gpxPoint struct {
+	Latitude   string         `xml:"lat,attr"`
+	Longitude  string         `xml:"lon,attr"`
+	Elevation  string         `xml:"ele,omitempty"`
+	Time       string         `xml:"time,omitempty"`
+	Fix        string         `xml:"fix,omitempty"`
+	Satellites int            `xml:"sat,omitempty"`
+	HDOP       string         `xml:"hdop,omitempty"`
+	PDOP       string         `xml:"pdop,omitempty"`
+	Extensions *gpxExtensions `xml:"extensions,omitempty"`
+}
+
+type gpxExtensions struct {
+	Speed         string      `xml:"mm:speed,omitempty"`
+	Course        string      `xml:"mm:course,omitempty"`
+	Source        string      `xml:"mm:source,omitempty"`
+	Cell          *gpxCell    `xml:"mm:cell,omitempty"`
+	SignalQuality uint32      `xml:"mm:signalquality,omitempty"`
+	Signal        []gpxSignal `xml:"mm:signal,omitempty"`
+}
+
+type gpxCell struct {
+	Mcc string `xml:"mcc,attr"`
+	Mnc string `xml:"mnc,attr"`
+	Lac string `xml:"lac,attr,omitempty"`
+	Ci  string `xml:"ci,attr"`
+	Tac string `xml:"tac,attr,omitempty"`
+}
+
+type gpxSignal struct {
+	Type       string     `xml:"type,attr"`
+	Attributes []xml.Attr `xml:",any,attr"`
+}
+
+// gpxFixType returns the GPX fix type of a NMEA fix
+func gpxFixType(f Fix) string {
+	switch f.Quality {
+	case nmea.FixQualityDgps:
+		return "dgps"
+	case nmea.FixQualityPps:
+		return "pps"
+	}
+	switch f.Type {
+	case nmea.FixTypeNone:
+		return "none"
+	case nmea.FixType2D:
+		return "2d"
+	case nmea.FixType3D:
+		return "3d"
+	}
+	return ""
+}
+
+// WriteGPX writes the track as GPX 1.1 file. Speed in meters per second, course, source, serving cell and signal
+// quality of each point are written as extensions in the GpxExtensionNamespace.
+func (tr Track) WriteGPX(w io.Writer) error {
+	file := gpxFile{
+		Version:        "1.1",
+		Creator:        "go-modemmanager",
+		Xmlns:          "http://www.topografix.com/GPX/1/1",
+		XmlnsExtension: GpxExtensionNamespace,
+		Track:          gpxTrack{Name: tr.Name},
+	}
+	for _, f := range tr.positions() {
+		point := gpxPoint{
+			Latitude:   formatFloat(f.Latitude),
+			Longitude:  formatFloat(f.Longitude),
+			Fix:        gpxFixType(f),
+			Satellites: f.SatellitesUsed,
+		}
+		if f.HasAltitude {
+			point.Elevation = formatFloat(f.Altitude)
+		}
+		if !f.Time.IsZero() {
+			point.Time = f.Time.UTC().Format(time.RFC3339Nano)
+		}
+		if f.HDOP > 0 {
+			point.HDOP = formatFloat(f.HDOP)
+		}
+		if f.PDOP > 0 {
+			point.PDOP = formatFloat(f.PDOP)
+		}
+		extensions := gpxExtensions{Source: f.Source.String(), SignalQuality: f.SignalQuality}
+		if f.HasSpeed {
+			extensions.Speed = formatFloat(f.SpeedKmh / 3.6)
+			extensions.Course = formatFloat(f.Course)
+		}
+		if f.HasCell() {
+			extensions.Cell = &gpxCell{Mcc: f.Cell.Mcc, Mnc: f.Cell.Mnc, Lac: f.Cell.Lac, Ci: f.Cell.Ci, Tac: f.Cell.Tac}
+		}
+		for _, sp := range f.Signal {
+			signal := gpxSignal{Type: signalType(sp)}
+			for _, v := range signalValues(sp) {
+				signal.Attributes = append(signal.Attributes, xml.Attr{Name: xml.Name{Local: v.name}, Value: formatFloat(v.value)})
+			}
+			extensions.Signal = append(extensions.Signal, signal)
+		}
+		point.Extensions = &extensions
+		file.Track.Segment = append(file.Track.Segment, point)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(file); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,122 @@
+package location
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+type kmlFile struct {
+	XMLName  xml.Name    `xml:"kml"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name   string      `xml:"name,omitempty"`
+	Folder []kmlFolder `xml:"Folder"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name         string           `xml:"name,omitempty"`
+	TimeStamp    *kmlTimeStamp    `xml:"TimeStamp,omitempty"`
+	ExtendedData *kmlExtendedData `xml:"ExtendedData,omitempty"`
+	LineString   *kmlGeometry     `xml:"LineString,omitempty"`
+	Point        *kmlGeometry     `xml:"Point,omitempty"`
+}
+
+type kmlTimeStamp struct {
+	When string `xml:"when"`
+}
+
+type kmlGeometry struct {
+	AltitudeMode string `xml:"altitudeMode,omitempty"`
+	Coordinates  string `xml:"coordinates"`
+}
+
+type kmlExtendedData struct {
+	Data []kmlData `xml:"Data"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+// kmlCoordinate returns the position of the fix as longitude,latitude[,altitude]
+func kmlCoordinate(f Fix) string {
+	if f.HasAltitude {
+		return formatFloat(f.Longitude) + "," + formatFloat(f.Latitude) + "," + formatFloat(f.Altitude)
+	}
+	return formatFloat(f.Longitude) + "," + formatFloat(f.Latitude)
+}
+
+// kmlAltitudeMode returns absolute if all fixes have an altitude, so the altitude is not ignored
+func kmlAltitudeMode(fixes ...Fix) string {
+	for _, f := range fixes {
+		if !f.HasAltitude {
+			return ""
+		}
+	}
+	return "absolute"
+}
+
+// WriteKML writes the track as KML 2.2 document with a LineString placemark of the whole track and a Point placemark
+// for each fix with time stamp and the properties of the GeoJSON points as extended data.
+func (tr Track) WriteKML(w io.Writer) error {
+	fixes := tr.positions()
+	file := kmlFile{Xmlns: "http://www.opengis.net/kml/2.2", Document: kmlDocument{Name: tr.Name}}
+	if len(fixes) > 1 {
+		coordinates := make([]string, 0, len(fixes))
+		for _, f := range fixes {
+			coordinates = append(coordinates, kmlCoordinate(f))
+		}
+		file.Document.Folder = append(file.Document.Folder, kmlFolder{
+			Name: "Track",
+			Placemarks: []kmlPlacemark{{
+				Name:       tr.Name,
+				LineString: &kmlGeometry{AltitudeMode: kmlAltitudeMode(fixes...), Coordinates: strings.Join(coordinates, " ")},
+			}},
+		})
+	}
+	points := kmlFolder{Name: "Fixes"}
+	for _, f := range fixes {
+		properties := fixProperties(f)
+		delete(properties, "time")
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		data := &kmlExtendedData{}
+		for _, name := range names {
+			data.Data = append(data.Data, kmlData{Name: name, Value: fmt.Sprint(properties[name])})
+		}
+		points.Placemarks = append(points.Placemarks, kmlPlacemark{
+			TimeStamp:    &kmlTimeStamp{When: f.Time.UTC().Format(time.RFC3339Nano)},
+			ExtendedData: data,
+			Point:        &kmlGeometry{AltitudeMode: kmlAltitudeMode(f), Coordinates: kmlCoordinate(f)},
+		})
+	}
+	if len(points.Placemarks) > 0 {
+		file.Document.Folder = append(file.Document.Folder, points)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(file); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,61 @@
+package location
+
+import (
+	"strconv"
+	"strings"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// Track is a recorded sequence of fixes, which can be written as GPX, GeoJSON or KML. Fixes without position, e.g.
+// with only the serving cell, are skipped by the writers.
+type Track struct {
+	Name  string // The name of the track, e.g. the equipment identifier of the modem
+	Fixes []Fix  // The fixes in chronological order
+}
+
+// Add appends a fix to the track
+func (tr *Track) Add(f Fix) {
+	tr.Fixes = append(tr.Fixes, f)
+}
+
+// positions returns the fixes with position
+func (tr Track) positions() []Fix {
+	fixes := make([]Fix, 0, len(tr.Fixes))
+	for _, f := range tr.Fixes {
+		if f.HasPosition {
+			fixes = append(fixes, f)
+		}
+	}
+	return fixes
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// signalValue is a named value of the extended signal information
+type signalValue struct {
+	name  string
+	value float64
+}
+
+// signalValues returns the values of the signal property which are applicable to its type, named like the json
+// fields
+func signalValues(sp mm.SignalProperty) []signalValue {
+	var values []signalValue
+	for _, v := range []signalValue{
+		{"rssi", sp.Rssi}, {"rsrq", sp.Rsrq}, {"rsrp", sp.Rsrp}, {"snr", sp.Snr}, {"sinr", sp.Sinr},
+		{"ecio", sp.Ecio}, {"io", sp.Io}, {"rscp", sp.Rscp}, {"error-rate", sp.ErrorRate},
+	} {
+		if sp.IsApplicable(v.name) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// signalType returns the lower case name of the access technology of the signal property, e.g. lte
+func signalType(sp mm.SignalProperty) string {
+	return strings.ToLower(sp.Type.String())
+}
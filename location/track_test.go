@@ -0,0 +1,85 @@
+package location
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// testTrack returns a track of a NMEA fix with altitude and signal, a RMC fix without altitude, a fix with only the
+// serving cell and a raw GPS fix
+func testTrack() Track {
+	received := time.Date(2021, time.March, 15, 9, 45, 13, 0, time.UTC)
+	cell := mm.ThreeGppLacCiLocation{Mcc: "262", Mnc: "01", Lac: "1A2B", Ci: "A1B2C3", Tac: "6FFE"}
+	track := Track{Name: "356938035643809"}
+
+	f := NewFix(mm.CurrentLocation{ThreeGppLacCi: cell, GpsNmea: mm.GpsNmeaLocation{NmeaSentences: []string{
+		"$GNRMC,094512.00,A,5230.81248,N,01323.59812,E,1.204,87.31,150321,,,A,V*38",
+		"$GNGGA,094512.00,5230.81248,N,01323.59812,E,1,08,0.92,38.4,M,39.6,M,,*7E",
+		"$GNGSA,A,3,05,13,15,18,,,,,,,,,1.62,0.92,1.33,1*04",
+	}}}, received)
+	f.SignalQuality = 75
+	// a SNR of 0 dB is a valid reading and written like the other values of the signal type
+	f.Signal = []mm.SignalProperty{{Type: mm.MMSignalPropertyTypeLte, Rssi: -67, Rsrq: -11, Rsrp: -95, Snr: 0, ErrorRate: 1.5}}
+	track.Add(f)
+	track.Add(NewFix(mm.CurrentLocation{ThreeGppLacCi: cell, GpsNmea: mm.GpsNmeaLocation{NmeaSentences: []string{
+		"$GPRMC,094513.00,A,5230.81250,N,01323.59815,E,0.500,90.00,150321,,,A*55",
+	}}}, received))
+	track.Add(NewFix(mm.CurrentLocation{ThreeGppLacCi: cell}, received.Add(time.Second)))
+	track.Add(NewFix(mm.CurrentLocation{GpsRaw: mm.GpsRawLocation{
+		UtcTime:   time.Date(0, time.January, 1, 9, 45, 15, 0, time.UTC),
+		Latitude:  52.513542,
+		Longitude: 13.396636,
+		Altitude:  41.2,
+	}}, received.Add(2*time.Second)))
+	return track
+}
+
+// assertGolden compares the output of write with the golden file in testdata, which is written by go test -update
+func assertGolden(t *testing.T, name string, write func(w io.Writer) error) {
+	t.Helper()
+	var b bytes.Buffer
+	if err := write(&b); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(path, b.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), want) {
+		t.Errorf("%s differs from the golden file:\n%s", name, b.String())
+	}
+}
+
+func TestWriteGPX(t *testing.T) {
+	assertGolden(t, "track.gpx", testTrack().WriteGPX)
+}
+
+func TestWriteGeoJSON(t *testing.T) {
+	assertGolden(t, "track.geojson", testTrack().WriteGeoJSON)
+}
+
+func TestWriteKML(t *testing.T) {
+	assertGolden(t, "track.kml", testTrack().WriteKML)
+}
+
+func TestWriteEmptyTrack(t *testing.T) {
+	track := Track{Fixes: []Fix{NewFix(mm.CurrentLocation{}, time.Now())}}
+	assertGolden(t, "empty.gpx", track.WriteGPX)
+	assertGolden(t, "empty.geojson", track.WriteGeoJSON)
+	assertGolden(t, "empty.kml", track.WriteKML)
+}
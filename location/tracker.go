@@ -0,0 +1,396 @@
+// Package location tracks the position of a modem. A Tracker sets up the location sources, follows the changes of
+// the Location property and merges the 3GPP serving cell, raw GPS, NMEA and CDMA base station data into a stream of
+// timestamped fixes, filtered by a minimum interval and distance.
+package location
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// Tracker streams the location fixes of a modem
+type Tracker struct {
+	modem mm.Modem
+
+	// Sources are the location sources enabled by Start, defaults to the 3GPP, raw GPS, NMEA and CDMA base
+	// station sources supported by the modem. Sources which are already enabled stay enabled.
+	Sources []mm.MMModemLocationSource
+	// GpsRefreshRate sets the refresh rate of the GPS sources in seconds if greater than 0
+	GpsRefreshRate uint32
+	// MinInterval is the minimum time between two fixes
+	MinInterval time.Duration
+	// MinDistance is the minimum distance in meters between two fixes with position
+	MinDistance float64
+	// SignalRate sets up the extended signal information with the given refresh rate in seconds if greater than 0,
+	// and adds the signal quality to each fix, see Fix.AddSignal
+	SignalRate uint32
+	// CellResolver sets the position of fixes without GPS position to the position of the serving cell if given,
+	// see Fix.AddCellPosition
+	CellResolver CellResolver
+	// DisableOnStop disables the sources enabled by Start when the tracker is stopped, e.g. to save power
+	DisableOnStop bool
+	// BufferSize is the size of the buffer of the fix channel, defaults to 16. If the buffer is full, the oldest
+	// fix is dropped.
+	BufferSize int
+
+	mu           sync.Mutex
+	fixes        chan Fix
+	last         *Fix
+	lastReceived time.Time // GPS time can't be compared with the time of other fixes, MinInterval uses the received time
+	enabled      []mm.MMModemLocationSource
+	restore      func() error // restores the GPS refresh rate and the signal rate changed by Start
+	onError      []func(error)
+	cancel       context.CancelFunc
+	stopped      chan struct{}
+	closed       bool
+}
+
+// NewTracker returns a new Tracker of the given modem
+func NewTracker(modem mm.Modem) (*Tracker, error) {
+	if modem == nil {
+		return nil, errors.New("no modem given")
+	}
+	return &Tracker{modem: modem, BufferSize: 16}, nil
+}
+
+// OnError adds a callback, which is called for errors of the tracker goroutine, e.g. if the location can't be read.
+// Callbacks are called from the tracker goroutine and should not block.
+func (t *Tracker) OnError(f func(err error)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onError = append(t.onError, f)
+}
+
+// Fixes returns the channel of fixes, which is closed by Stop
+func (t *Tracker) Fixes() <-chan Fix {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.fixes == nil {
+		t.fixes = make(chan Fix, t.bufferSize())
+	}
+	return t.fixes
+}
+
+// Last returns the last fix sent to the channel
+func (t *Tracker) Last() (Fix, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.last == nil {
+		return Fix{}, false
+	}
+	return *t.last, true
+}
+
+func (t *Tracker) bufferSize() int {
+	if t.BufferSize <= 0 {
+		return 16
+	}
+	return t.BufferSize
+}
+
+// defaultSources are the sources which provide location data
+var defaultSources = []mm.MMModemLocationSource{
+	mm.MmModemLocationSource3gppLacCi,
+	mm.MmModemLocationSourceGpsRaw,
+	mm.MmModemLocationSourceGpsNmea,
+	mm.MmModemLocationSourceCdmaBs,
+}
+
+// Start enables the location sources with location signals, sends the current location and follows its changes
+// in a new goroutine. If the sources can't be set up, the GPS refresh rate and the signal rate are restored. A
+// stopped tracker can't be started again.
+func (t *Tracker) Start() error {
+	t.mu.Lock()
+	started, closed := t.cancel != nil, t.closed
+	t.mu.Unlock()
+	if closed {
+		return errors.New("tracker already stopped")
+	}
+	if started {
+		return errors.New("tracker already started")
+	}
+	location, err := t.modem.GetLocation()
+	if err != nil {
+		return err
+	}
+	sources, enabled, err := t.setupSources(location)
+	if err != nil {
+		return err
+	}
+	restore, err := t.setupRates(location)
+	if err != nil {
+		return err
+	}
+	changed := location.SubscribePropertiesChanged()
+	if err := location.Setup(sources, true); err != nil {
+		location.Unsubscribe()
+		_ = restore()
+		return err
+	}
+	t.Fixes()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel != nil {
+		location.Unsubscribe()
+		_ = restore()
+		return errors.New("tracker already started")
+	}
+	t.enabled = enabled
+	t.restore = restore
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.stopped = make(chan struct{})
+	go t.run(ctx, location, changed, t.stopped)
+	return nil
+}
+
+// setupRates sets the GPS refresh rate and the signal rate if configured, and returns a function restoring the
+// previous rates and the first error. If one of the rates can't be set, the rates set before are restored.
+func (t *Tracker) setupRates(location mm.ModemLocation) (restore func() error, err error) {
+	var previous []func() error
+	restore = func() (err error) {
+		for i := len(previous) - 1; i >= 0; i-- {
+			if e := previous[i](); e != nil && err == nil {
+				err = e
+			}
+		}
+		return err
+	}
+	if t.GpsRefreshRate > 0 {
+		rate, err := location.GetGpsRefreshRate()
+		if err != nil {
+			return nil, err
+		}
+		if err := location.SetGpsRefreshRate(t.GpsRefreshRate); err != nil {
+			return nil, err
+		}
+		previous = append(previous, func() error { return location.SetGpsRefreshRate(rate) })
+	}
+	if t.SignalRate > 0 {
+		signal, err := t.modem.GetSignal()
+		if err != nil {
+			_ = restore()
+			return nil, err
+		}
+		rate, err := signal.GetRate()
+		if err != nil {
+			_ = restore()
+			return nil, err
+		}
+		if err := signal.Setup(t.SignalRate); err != nil {
+			_ = restore()
+			return nil, err
+		}
+		previous = append(previous, func() error { return signal.Setup(rate) })
+	}
+	return restore, nil
+}
+
+// setupSources returns the sources to set up, which are the already enabled sources and the requested sources,
+// and the sources newly enabled by the tracker
+func (t *Tracker) setupSources(location mm.ModemLocation) (sources []mm.MMModemLocationSource, enabled []mm.MMModemLocationSource, err error) {
+	capabilities, err := location.GetCapabilities()
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := location.GetEnabledLocationSources()
+	if err != nil {
+		return nil, nil, err
+	}
+	requested := t.Sources
+	if len(requested) == 0 {
+		for _, source := range defaultSources {
+			if containsSource(capabilities, source) {
+				requested = append(requested, source)
+			}
+		}
+	}
+	for _, source := range requested {
+		if !containsSource(capabilities, source) {
+			return nil, nil, errors.New("location source " + source.String() + " not supported by the modem")
+		}
+		if !containsSource(current, source) {
+			enabled = append(enabled, source)
+		}
+	}
+	sources = append(sources, current...)
+	sources = append(sources, enabled...)
+	if len(sources) == 0 {
+		return nil, nil, errors.New("no location source supported by the modem")
+	}
+	return sources, enabled, nil
+}
+
+func containsSource(sources []mm.MMModemLocationSource, source mm.MMModemLocationSource) bool {
+	for _, s := range sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// Stop unsubscribes, waits for the tracker goroutine, restores the GPS refresh rate and the signal rate changed by
+// Start and closes the fix channel. The sources enabled by Start are disabled before if DisableOnStop is set.
+func (t *Tracker) Stop() (err error) {
+	t.mu.Lock()
+	cancel, stopped, enabled, restore := t.cancel, t.stopped, t.enabled, t.restore
+	t.cancel, t.stopped, t.enabled, t.restore = nil, nil, nil, nil
+	alreadyClosed := t.closed
+	t.closed = true
+	t.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-stopped
+		if t.DisableOnStop && len(enabled) > 0 {
+			err = t.disable(enabled)
+		}
+		if e := restore(); e != nil && err == nil {
+			err = e
+		}
+	}
+	if !alreadyClosed {
+		t.Fixes()
+		close(t.fixes)
+	}
+	return err
+}
+
+// disable disables the given sources and keeps the other enabled sources
+func (t *Tracker) disable(sources []mm.MMModemLocationSource) error {
+	location, err := t.modem.GetLocation()
+	if err != nil {
+		return err
+	}
+	current, err := location.GetEnabledLocationSources()
+	if err != nil {
+		return err
+	}
+	var remaining []mm.MMModemLocationSource
+	for _, source := range current {
+		if !containsSource(sources, source) {
+			remaining = append(remaining, source)
+		}
+	}
+	signals, err := location.GetSignalsLocation()
+	if err != nil {
+		return err
+	}
+	return location.Setup(remaining, signals)
+}
+
+func (t *Tracker) run(ctx context.Context, location mm.ModemLocation, changed <-chan *dbus.Signal, stopped chan struct{}) {
+	defer close(stopped)
+	defer location.Unsubscribe()
+	current, err := location.GetLocation()
+	if err != nil {
+		t.handleError(err)
+	} else {
+		received := time.Now()
+		t.handle(NewFix(current, received), received)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case signal, ok := <-changed:
+			if !ok {
+				return
+			}
+			interfaceName, changedProperties, invalidatedProperties, err := location.ParsePropertiesChanged(signal)
+			if err != nil || interfaceName != mm.ModemLocationInterface {
+				continue
+			}
+			if v, ok := changedProperties["Location"]; ok {
+				current, err = location.ParseLocation(v)
+			} else if containsString(invalidatedProperties, "Location") {
+				current, err = location.GetLocation()
+			} else {
+				continue
+			}
+			if err != nil {
+				t.handleError(err)
+				continue
+			}
+			received := time.Now()
+			t.handle(NewFix(current, received), received)
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// handle sends the fix if it passes the filter, dropping the oldest fix if the channel is full
+func (t *Tracker) handle(f Fix, received time.Time) {
+	if t.CellResolver != nil {
+		if err := f.AddCellPosition(t.CellResolver); !errors.Is(err, ErrCellNotFound) {
+			t.handleError(err)
+		}
+	}
+	t.mu.Lock()
+	accepted := (f.HasPosition || f.HasCell()) && (t.last == nil || t.accept(*t.last, f, received))
+	t.mu.Unlock()
+	if !accepted {
+		return
+	}
+	if t.SignalRate > 0 {
+		t.handleError(f.AddSignal(t.modem))
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.last, t.lastReceived = &f, received
+	for {
+		select {
+		case t.fixes <- f:
+			return
+		default:
+		}
+		select {
+		case <-t.fixes:
+		default:
+		}
+	}
+}
+
+// accept returns true if the fix is received at least MinInterval after the last fix, and at least MinDistance away
+// from it. Fixes without position are accepted if the serving cell changed, repeated GPS fixes with the same time
+// and repeated positions of the same serving cell are dropped.
+func (t *Tracker) accept(last Fix, f Fix, received time.Time) bool {
+	if received.Sub(t.lastReceived) < t.MinInterval {
+		return false
+	}
+	switch {
+	case f.HasPosition && last.HasPosition:
+		if f.Source == last.Source && (f.Time.Equal(last.Time) || f.Source == mm.MmModemLocationSource3gppLacCi && f.Cell == last.Cell) {
+			return false
+		}
+		return f.DistanceTo(last) >= t.MinDistance
+	case f.HasPosition:
+		return true
+	}
+	return f.Cell != last.Cell
+}
+
+func (t *Tracker) handleError(err error) {
+	if err == nil {
+		return
+	}
+	t.mu.Lock()
+	onError := t.onError
+	t.mu.Unlock()
+	for _, f := range onError {
+		f(err)
+	}
+}
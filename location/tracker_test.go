@@ -0,0 +1,250 @@
+package location
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+	"github.com/maltegrosse/go-modemmanager/nmea"
+)
+
+// gga returns a GGA sentence of a GPS fix at the given time of day and position in the northern and eastern
+// hemisphere
+func gga(timeOfDay string, latitude float64, longitude float64) string {
+	coordinate := func(degrees float64, width int) string {
+		d := int(degrees)
+		return fmt.Sprintf("%0*d%08.5f", width, d, (degrees-float64(d))*60)
+	}
+	body := fmt.Sprintf("GPGGA,%s,%s,N,%s,E,1,08,0.9,38.4,M,39.6,M,,", timeOfDay, coordinate(latitude, 2), coordinate(longitude, 3))
+	return fmt.Sprintf("$%s*%02X", body, nmea.Checksum(body))
+}
+
+func setNmea(fake *mmtest.Modem, sentences string) {
+	fake.SetLocation(map[mm.MMModemLocationSource]interface{}{mm.MmModemLocationSourceGpsNmea: sentences})
+}
+
+func setCell(fake *mmtest.Modem, ci string) {
+	fake.SetLocation(map[mm.MMModemLocationSource]interface{}{mm.MmModemLocationSource3gppLacCi: "262,01,1A2B," + ci + ",0"})
+}
+
+func startTracker(t *testing.T, tracker *Tracker) {
+	t.Helper()
+	if err := tracker.Start(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func nextFix(t *testing.T, fixes <-chan Fix) Fix {
+	t.Helper()
+	select {
+	case f, ok := <-fixes:
+		if !ok {
+			t.Fatal("fix channel closed")
+		}
+		return f
+	case <-time.After(5 * time.Second):
+		t.Fatal("no fix received")
+	}
+	return Fix{}
+}
+
+func assertNoFix(t *testing.T, fixes <-chan Fix, wait time.Duration) {
+	t.Helper()
+	select {
+	case f := <-fixes:
+		t.Errorf("got fix %+v, want none", f)
+	case <-time.After(wait):
+	}
+}
+
+func TestTrackerMinDistance(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	tracker, err := NewTracker(modem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.Sources = []mm.MMModemLocationSource{mm.MmModemLocationSourceGpsNmea}
+	tracker.MinDistance = 100
+	startTracker(t, tracker)
+	defer tracker.Stop()
+	fixes := tracker.Fixes()
+
+	setNmea(fake, gga("120000.00", 52.5, 13.4))
+	first := nextFix(t, fixes)
+	if first.Source != mm.MmModemLocationSourceGpsNmea || first.Time.Hour() != 12 || first.Altitude != 38.4 {
+		t.Errorf("got fix %+v, want the fix of 12:00", first)
+	}
+	// about 11 meters away
+	setNmea(fake, gga("120001.00", 52.5001, 13.4))
+	// a repeated fix with the time of the last fix
+	setNmea(fake, gga("120000.00", 52.51, 13.4))
+	setNmea(fake, gga("120002.00", 52.51, 13.4))
+	f := nextFix(t, fixes)
+	if f.Time.Second() != 2 || f.DistanceTo(first) < 1000 {
+		t.Errorf("got fix %+v, want the fix of 12:00:02 about 1.1 km away", f)
+	}
+	assertNoFix(t, fixes, 50*time.Millisecond)
+	if last, ok := tracker.Last(); !ok || !last.Time.Equal(f.Time) {
+		t.Errorf("last fix = %+v, want the fix of 12:00:02", last)
+	}
+}
+
+func TestTrackerMinInterval(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	tracker, err := NewTracker(modem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.Sources = []mm.MMModemLocationSource{mm.MmModemLocationSourceGpsNmea}
+	tracker.MinInterval = 300 * time.Millisecond
+	startTracker(t, tracker)
+	defer tracker.Stop()
+	fixes := tracker.Fixes()
+
+	setNmea(fake, gga("120000.00", 52.5, 13.4))
+	nextFix(t, fixes)
+	setNmea(fake, gga("120001.00", 52.6, 13.4))
+	assertNoFix(t, fixes, 100*time.Millisecond)
+	time.Sleep(300 * time.Millisecond)
+	setNmea(fake, gga("120002.00", 52.7, 13.4))
+	if f := nextFix(t, fixes); f.Time.Second() != 2 {
+		t.Errorf("got fix %+v, want the fix after the interval", f)
+	}
+}
+
+func TestTrackerBuffer(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	tracker, err := NewTracker(modem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.Sources = []mm.MMModemLocationSource{mm.MmModemLocationSource3gppLacCi}
+	tracker.BufferSize = 2
+	startTracker(t, tracker)
+	fixes := tracker.Fixes()
+
+	// the repeated cell is dropped, the full buffer drops the oldest fixes
+	for _, ci := range []string{"A1", "A2", "A2", "A3", "A4"} {
+		setCell(fake, ci)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if last, ok := tracker.Last(); ok && last.Cell.Ci == "A4" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("last cell not received")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := tracker.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	var cells []string
+	for f := range fixes {
+		if f.HasPosition || f.Cell.Mcc != "262" || f.Cell.Lac != "1A2B" {
+			t.Errorf("got fix %+v, want the serving cell without position", f)
+		}
+		cells = append(cells, f.Cell.Ci)
+	}
+	if len(cells) != 2 || cells[0] != "A3" || cells[1] != "A4" {
+		t.Errorf("got cells %v, want the last 2 cells", cells)
+	}
+}
+
+func assertRates(t *testing.T, modem mm.Modem, gpsRefreshRate uint32, signalRate uint32) {
+	t.Helper()
+	location, err := modem.GetLocation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signal, err := modem.GetSignal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gps, err := location.GetGpsRefreshRate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rate, err := signal.GetRate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gps != gpsRefreshRate || rate != signalRate {
+		t.Errorf("rates = %d/%d, want %d/%d", gps, rate, gpsRefreshRate, signalRate)
+	}
+}
+
+func TestTrackerStartRollback(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	tracker, err := NewTracker(modem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.GpsRefreshRate = 5
+	tracker.SignalRate = 10
+
+	srv.SetError(fake.GetObjectPath(), mm.ModemLocationSetup, errors.New("location setup failed"))
+	if err := tracker.Start(); err == nil {
+		t.Fatal("tracker started without location setup")
+	}
+	assertRates(t, modem, 30, 0)
+	srv.SetError(fake.GetObjectPath(), mm.ModemLocationSetup, nil)
+
+	srv.SetError(fake.GetObjectPath(), mm.ModemSignalSetup, errors.New("signal setup failed"))
+	if err := tracker.Start(); err == nil {
+		t.Fatal("tracker started without signal setup")
+	}
+	assertRates(t, modem, 30, 0)
+	srv.SetError(fake.GetObjectPath(), mm.ModemSignalSetup, nil)
+
+	// a failed start can be retried
+	if err := tracker.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer tracker.Stop()
+	assertRates(t, modem, 5, 10)
+}
+
+func TestTrackerStopRestoresRates(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	tracker, err := NewTracker(modem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.GpsRefreshRate = 5
+	tracker.SignalRate = 10
+	startTracker(t, tracker)
+	assertRates(t, modem, 5, 10)
+	if err := tracker.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	assertRates(t, modem, 30, 0)
+
+	// the other rates are restored if one can't be restored, and the error is returned
+	tracker, err = NewTracker(modem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.GpsRefreshRate = 5
+	tracker.SignalRate = 10
+	startTracker(t, tracker)
+	srv.SetError(fake.GetObjectPath(), mm.ModemSignalSetup, errors.New("signal setup failed"))
+	if err := tracker.Stop(); err == nil {
+		t.Error("stopped without restoring the signal rate")
+	}
+	assertRates(t, modem, 30, 10)
+}
@@ -0,0 +1,600 @@
+// Package messaging keeps the received SMS of a modem in a persistent inbox. Segments of concatenated messages,
+// which some modems or plugins expose as separate Sms objects, are reassembled by their reference number (see
+// PduPartFunc for the detection of segments), messages seen twice are stored once, and the messages can be
+// deleted from the modem once persisted, so the SIM storage does not fill up.
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/pdu"
+)
+
+// Message is a complete received message
+type Message struct {
+	ID         string    `json:"id"`         // Unique id derived from number, timestamp and content, used to deduplicate
+	Number     string    `json:"number"`     // The number of the sender
+	Text       string    `json:"text"`       // The text of the message
+	Data       []byte    `json:"data"`       // The data of the message, if not a text message
+	SMSC       string    `json:"smsc"`       // The service centre
+	Timestamp  time.Time `json:"timestamp"`  // The time the service centre received the (first segment of the) message
+	Received   time.Time `json:"received"`   // The time the message was added to the inbox
+	Parts      int       `json:"parts"`      // The number of segments, 1 if not concatenated
+	Incomplete bool      `json:"incomplete"` // True if segments were missing after PartTimeout
+}
+
+// Part is a segment of a concatenated message
+type Part struct {
+	Reference uint16 // The concatenation reference number
+	Total     int    // The total number of segments
+	Sequence  int    // The 1-based number of the segment
+	Text      string // The text of the segment
+	Data      []byte // The data of the segment without the user data header
+}
+
+// PartFunc returns the segment information of a received sms, ok is false if the sms is a complete message
+type PartFunc func(sms mm.Sms) (part Part, ok bool, err error)
+
+// DefaultPartFunc reports every sms as complete message. ModemManager reassembles the segments of concatenated
+// messages itself and exposes the text and data of the message without user data header, so the segments of a
+// message only show up as separate Sms objects on modems or plugins which fail to match them, e.g. if the segments
+// are stored in different storages. Use PduPartFunc for those modems.
+func DefaultPartFunc(sms mm.Sms) (part Part, ok bool, err error) {
+	return part, false, nil
+}
+
+// DataHeaderPartFunc detects segments by the concatenation element of a user data header at the start of the Data
+// property, e.g. of messages injected without ModemManager. Messages without Data, or whose Data does not start with
+// a valid header, are complete messages. Data messages whose payload starts like a header are misread as segments,
+// so only use it if the Data is known to keep the header.
+func DataHeaderPartFunc(sms mm.Sms) (part Part, ok bool, err error) {
+	data, err := sms.GetData()
+	if err != nil || len(data) == 0 {
+		return part, false, err
+	}
+	header, length, err := pdu.ParseHeader(data)
+	if err != nil {
+		// the data does not start with a header
+		return part, false, nil
+	}
+	reference, total, sequence, ok := header.Concatenation()
+	if !ok || total < 2 || sequence < 1 || sequence > total {
+		return part, false, nil
+	}
+	text, err := sms.GetText()
+	if err != nil {
+		return part, false, err
+	}
+	return Part{
+		Reference: reference,
+		Total:     int(total),
+		Sequence:  int(sequence),
+		Text:      text,
+		Data:      append([]byte(nil), data[length:]...),
+	}, true, nil
+}
+
+// listPdus lists all messages of the current storage in PDU mode
+const listPdus = "AT+CMGL=4"
+
+// PduPartFunc returns a PartFunc for modems which expose the segments of concatenated messages as separate Sms
+// objects. It lists the PDUs of the modem storage by the +CMGL AT command, which requires ModemManager running in
+// debug mode, and reads the concatenation element of the PDU with the number, timestamp, text and data of the sms.
+// An sms without matching PDU is a complete message. The PDUs are listed once per sms.
+func PduPartFunc(modem mm.Modem) PartFunc {
+	return func(sms mm.Sms) (part Part, ok bool, err error) {
+		number, err := sms.GetNumber()
+		if err != nil {
+			return part, false, err
+		}
+		text, err := sms.GetText()
+		if err != nil {
+			return part, false, err
+		}
+		data, err := sms.GetData()
+		if err != nil {
+			return part, false, err
+		}
+		timestamp, _ := sms.GetTimestamp()
+		response, err := modem.Command(listPdus, 10)
+		if err != nil {
+			return part, false, err
+		}
+		for _, d := range parsePduList(response) {
+			if d.Originator.Number != number || (!timestamp.IsZero() && !d.Timestamp.Equal(timestamp)) {
+				continue
+			}
+			if d.UserData.Text != text || !bytes.Equal(d.UserData.Data, data) {
+				continue
+			}
+			reference, total, sequence, ok := d.UserData.Header.Concatenation()
+			if !ok || total < 2 || sequence < 1 || sequence > total {
+				return part, false, nil
+			}
+			return Part{
+				Reference: reference,
+				Total:     int(total),
+				Sequence:  int(sequence),
+				Text:      text,
+				Data:      append([]byte(nil), data...),
+			}, true, nil
+		}
+		return part, false, nil
+	}
+}
+
+// parsePduList returns the SMS-DELIVER PDUs of a +CMGL response, each given in the line following its +CMGL line.
+// Other messages and lines which fail to decode are skipped.
+func parsePduList(response string) []*pdu.Deliver {
+	var res []*pdu.Deliver
+	lines := strings.Split(strings.Replace(response, "\r", "", -1), "\n")
+	for i := 0; i+1 < len(lines); i++ {
+		if !strings.HasPrefix(strings.TrimSpace(lines[i]), "+CMGL:") {
+			continue
+		}
+		i++
+		message, err := pdu.DecodeHex(lines[i])
+		if err != nil {
+			continue
+		}
+		if d, ok := message.(*pdu.Deliver); ok {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// partKey identifies the segments of a concatenated message
+type partKey struct {
+	number    string
+	reference uint16
+	total     int
+}
+
+// pendingMessage collects the segments of a concatenated message
+type pendingMessage struct {
+	parts     map[int]Part
+	smsc      string
+	timestamp time.Time
+	firstSeen time.Time
+	sms       []mm.Sms
+}
+
+// Inbox receives the messages of a modem and persists them to a Store
+type Inbox struct {
+	modem mm.Modem
+	store Store
+
+	// DeleteFromModem deletes the messages from the modem once they are persisted
+	DeleteFromModem bool
+	// PartFunc detects the segments of concatenated messages, defaults to DefaultPartFunc
+	PartFunc PartFunc
+	// PartTimeout is the time to wait for missing segments, before the available segments are stored as incomplete
+	// message, defaults to 24h
+	PartTimeout time.Duration
+	// SyncInterval is the interval of listing the messages of the modem, which picks up messages missed while
+	// not subscribed or still being received, defaults to 1m
+	SyncInterval time.Duration
+
+	mu        sync.Mutex
+	pending   map[partKey]*pendingMessage
+	seen      map[dbus.ObjectPath]bool
+	undeleted map[dbus.ObjectPath]mm.Sms // persisted sms which failed to delete, retried by Sync
+	onMessage []func(Message)
+	onError   []func(error)
+	cancel    context.CancelFunc
+	stopped   chan struct{}
+	syncMutex sync.Mutex
+}
+
+// NewInbox returns a new Inbox of the modem using the given store
+func NewInbox(modem mm.Modem, store Store) (*Inbox, error) {
+	if modem == nil {
+		return nil, errors.New("no modem given")
+	}
+	if store == nil {
+		return nil, errors.New("no store given")
+	}
+	return &Inbox{
+		modem:        modem,
+		store:        store,
+		PartFunc:     DefaultPartFunc,
+		PartTimeout:  24 * time.Hour,
+		SyncInterval: time.Minute,
+		pending:      make(map[partKey]*pendingMessage),
+		seen:         make(map[dbus.ObjectPath]bool),
+		undeleted:    make(map[dbus.ObjectPath]mm.Sms),
+	}, nil
+}
+
+// OnMessage adds a callback, which is called for every new message after it is persisted. Callbacks are called from
+// the inbox goroutine and should not block.
+func (in *Inbox) OnMessage(f func(message Message)) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.onMessage = append(in.onMessage, f)
+}
+
+// OnError adds a callback, which is called for errors of the inbox goroutine, e.g. if the store fails
+func (in *Inbox) OnError(f func(err error)) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.onError = append(in.onError, f)
+}
+
+// Messages returns all stored messages, ordered by timestamp
+func (in *Inbox) Messages() ([]Message, error) {
+	return in.store.List()
+}
+
+// Conversation returns the stored messages of the given number, ordered by timestamp
+func (in *Inbox) Conversation(number string) ([]Message, error) {
+	messages, err := in.store.List()
+	if err != nil {
+		return nil, err
+	}
+	var res []Message
+	for _, message := range messages {
+		if message.Number == number {
+			res = append(res, message)
+		}
+	}
+	return res, nil
+}
+
+// Conversations returns the stored messages grouped by number, each ordered by timestamp
+func (in *Inbox) Conversations() (map[string][]Message, error) {
+	messages, err := in.store.List()
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string][]Message)
+	for _, message := range messages {
+		res[message.Number] = append(res[message.Number], message)
+	}
+	return res, nil
+}
+
+// Sync processes all received messages of the modem and stores the segments which did not complete within
+// PartTimeout as incomplete messages. A message which fails, e.g. because the store fails, is reported to the
+// OnError callbacks and retried by the next Sync, the other messages are processed anyway. This includes the
+// deletion of persisted messages if DeleteFromModem is set. The returned error is only set if the messages can't be
+// listed.
+func (in *Inbox) Sync() error {
+	in.syncMutex.Lock()
+	defer in.syncMutex.Unlock()
+	messaging, err := in.modem.GetMessaging()
+	if err != nil {
+		return err
+	}
+	messages, err := messaging.List()
+	if err != nil {
+		return err
+	}
+	// complete messages are only pending if they failed to save before
+	in.savePending(messaging, func(key partKey, p *pendingMessage) bool {
+		return len(p.parts) == key.total
+	})
+	present := make(map[dbus.ObjectPath]bool, len(messages))
+	for _, sms := range messages {
+		present[sms.GetObjectPath()] = true
+	}
+	in.mu.Lock()
+	// forget deleted messages, so the sets do not grow
+	for path := range in.seen {
+		if !present[path] {
+			delete(in.seen, path)
+		}
+	}
+	var undeleted []mm.Sms
+	for path, sms := range in.undeleted {
+		if present[path] {
+			undeleted = append(undeleted, sms)
+		} else {
+			delete(in.undeleted, path)
+		}
+	}
+	in.mu.Unlock()
+	if err := in.delete(messaging, undeleted); err != nil {
+		in.handleError(fmt.Errorf("delete persisted sms: %w", err))
+	}
+	for _, sms := range messages {
+		if err := in.process(messaging, sms); err != nil {
+			in.handleError(fmt.Errorf("sms %s: %w", sms.GetObjectPath(), err))
+		}
+	}
+	timeout := in.PartTimeout
+	if timeout <= 0 {
+		timeout = 24 * time.Hour
+	}
+	in.savePending(messaging, func(key partKey, p *pendingMessage) bool {
+		return time.Since(p.firstSeen) >= timeout
+	})
+	return nil
+}
+
+// process adds a received sms to the inbox, segments are kept until the message is complete
+func (in *Inbox) process(messaging mm.ModemMessaging, sms mm.Sms) error {
+	in.mu.Lock()
+	seen := in.seen[sms.GetObjectPath()]
+	in.mu.Unlock()
+	if seen {
+		return nil
+	}
+	state, err := sms.GetState()
+	if err != nil {
+		// the sms was deleted in between
+		return nil
+	}
+	if state != mm.MmSmsStateReceived {
+		// messages being received are picked up by the next sync
+		return nil
+	}
+	number, err := sms.GetNumber()
+	if err != nil {
+		return err
+	}
+	smsc, _ := sms.GetSMSC()
+	timestamp, _ := sms.GetTimestamp()
+	partFunc := in.PartFunc
+	if partFunc == nil {
+		partFunc = DefaultPartFunc
+	}
+	part, isPart, err := partFunc(sms)
+	if err != nil {
+		return err
+	}
+	if !isPart {
+		text, err := sms.GetText()
+		if err != nil {
+			return err
+		}
+		data, err := sms.GetData()
+		if err != nil {
+			return err
+		}
+		message := Message{Number: number, Text: text, Data: data, SMSC: smsc, Timestamp: timestamp, Parts: 1}
+		return in.save(messaging, message, []mm.Sms{sms})
+	}
+
+	key := partKey{number: number, reference: part.Reference, total: part.Total}
+	in.mu.Lock()
+	in.seen[sms.GetObjectPath()] = true
+	p, ok := in.pending[key]
+	if !ok {
+		p = &pendingMessage{parts: make(map[int]Part), firstSeen: time.Now()}
+		in.pending[key] = p
+	}
+	// duplicated segments are ignored, but deleted with the message
+	p.sms = append(p.sms, sms)
+	if _, duplicate := p.parts[part.Sequence]; !duplicate {
+		p.parts[part.Sequence] = part
+		if part.Sequence == 1 || p.smsc == "" {
+			p.smsc = smsc
+		}
+		if p.timestamp.IsZero() || (!timestamp.IsZero() && timestamp.Before(p.timestamp)) {
+			p.timestamp = timestamp
+		}
+	}
+	complete := len(p.parts) == part.Total
+	if complete {
+		delete(in.pending, key)
+	}
+	in.mu.Unlock()
+	if !complete {
+		return nil
+	}
+	if err := in.save(messaging, assemble(key, p, false), p.sms); err != nil {
+		// the segments are kept to retry saving the message by the next Sync
+		in.mu.Lock()
+		in.pending[key] = p
+		in.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// savePending stores the pending messages selected by due, with the available segments. Messages which fail to save
+// are reported and kept for the next Sync.
+func (in *Inbox) savePending(messaging mm.ModemMessaging, due func(key partKey, p *pendingMessage) bool) {
+	var selected []*pendingMessage
+	var keys []partKey
+	in.mu.Lock()
+	for key, p := range in.pending {
+		if due(key, p) {
+			selected = append(selected, p)
+			keys = append(keys, key)
+			delete(in.pending, key)
+		}
+	}
+	in.mu.Unlock()
+	for i, p := range selected {
+		key := keys[i]
+		if err := in.save(messaging, assemble(key, p, len(p.parts) < key.total), p.sms); err != nil {
+			in.mu.Lock()
+			in.pending[key] = p
+			in.mu.Unlock()
+			in.handleError(fmt.Errorf("message of %s with reference %d: %w", key.number, key.reference, err))
+		}
+	}
+}
+
+// assemble joins the segments in their order
+func assemble(key partKey, p *pendingMessage, incomplete bool) Message {
+	sequences := make([]int, 0, len(p.parts))
+	for sequence := range p.parts {
+		sequences = append(sequences, sequence)
+	}
+	sort.Ints(sequences)
+	var text strings.Builder
+	var data []byte
+	for _, sequence := range sequences {
+		text.WriteString(p.parts[sequence].Text)
+		data = append(data, p.parts[sequence].Data...)
+	}
+	return Message{
+		Number:     key.number,
+		Text:       text.String(),
+		Data:       data,
+		SMSC:       p.smsc,
+		Timestamp:  p.timestamp,
+		Parts:      key.total,
+		Incomplete: incomplete,
+	}
+}
+
+// messageID returns the id of a message, which is the same for a message seen twice
+func messageID(message Message) string {
+	h := sha256.New()
+	h.Write([]byte(message.Number + "\x00" + message.Timestamp.UTC().Format(time.RFC3339Nano) + "\x00" + message.Text + "\x00"))
+	h.Write(message.Data)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// save persists a new message and deletes its sms from the modem if DeleteFromModem is set
+func (in *Inbox) save(messaging mm.ModemMessaging, message Message, sms []mm.Sms) error {
+	message.ID = messageID(message)
+	exists, err := in.store.Has(message.ID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		message.Received = time.Now()
+		if err := in.store.Save(message); err != nil {
+			return err
+		}
+	}
+	in.mu.Lock()
+	for _, s := range sms {
+		in.seen[s.GetObjectPath()] = true
+	}
+	onMessage := in.onMessage
+	in.mu.Unlock()
+	if !exists {
+		for _, f := range onMessage {
+			f(message)
+		}
+	}
+	if in.DeleteFromModem {
+		return in.delete(messaging, sms)
+	}
+	return nil
+}
+
+// delete deletes persisted sms from the modem, sms which fail to delete are kept to retry them by the next Sync
+func (in *Inbox) delete(messaging mm.ModemMessaging, sms []mm.Sms) error {
+	var res error
+	for _, s := range sms {
+		err := messaging.Delete(s)
+		in.mu.Lock()
+		if err != nil {
+			in.undeleted[s.GetObjectPath()] = s
+		} else {
+			delete(in.undeleted, s.GetObjectPath())
+		}
+		in.mu.Unlock()
+		if err != nil && res == nil {
+			res = err
+		}
+	}
+	return res
+}
+
+// Start processes the messages stored in the modem and subscribes to added messages in a new goroutine
+func (in *Inbox) Start() error {
+	in.mu.Lock()
+	started := in.cancel != nil
+	in.mu.Unlock()
+	if started {
+		return errors.New("inbox already started")
+	}
+	messaging, err := in.modem.GetMessaging()
+	if err != nil {
+		return err
+	}
+	added := messaging.SubscribeAdded()
+	if err := in.Sync(); err != nil {
+		messaging.Unsubscribe()
+		return err
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.cancel != nil {
+		messaging.Unsubscribe()
+		return errors.New("inbox already started")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	in.cancel = cancel
+	in.stopped = make(chan struct{})
+	go in.run(ctx, messaging, added, in.stopped)
+	return nil
+}
+
+// Stop unsubscribes and waits for the inbox goroutine, segments of incomplete messages are kept until the next Sync
+func (in *Inbox) Stop() {
+	in.mu.Lock()
+	cancel, stopped := in.cancel, in.stopped
+	in.cancel, in.stopped = nil, nil
+	in.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-stopped
+}
+
+func (in *Inbox) run(ctx context.Context, messaging mm.ModemMessaging, added <-chan *dbus.Signal, stopped chan struct{}) {
+	defer close(stopped)
+	defer messaging.Unsubscribe()
+	interval := in.SyncInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			in.handleError(in.Sync())
+		case signal, ok := <-added:
+			if !ok {
+				return
+			}
+			sms, received, err := messaging.ParseAdded(signal)
+			if err != nil || !received {
+				continue
+			}
+			in.syncMutex.Lock()
+			err = in.process(messaging, sms)
+			in.syncMutex.Unlock()
+			if err != nil {
+				in.handleError(fmt.Errorf("sms %s: %w", sms.GetObjectPath(), err))
+			}
+		}
+	}
+}
+
+func (in *Inbox) handleError(err error) {
+	if err == nil {
+		return
+	}
+	in.mu.Lock()
+	onError := in.onError
+	in.mu.Unlock()
+	for _, f := range onError {
+		f(err)
+	}
+}
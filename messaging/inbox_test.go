@@ -0,0 +1,528 @@
+package messaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+	"github.com/maltegrosse/go-modemmanager/pdu"
+)
+
+// segment returns the data of a segment of a concatenated message with a header of 8 bit reference number
+func segment(reference byte, total byte, sequence byte, payload string) []byte {
+	return append([]byte{0x05, 0x00, 0x03, reference, total, sequence}, payload...)
+}
+
+func receive(t *testing.T, fake *mmtest.Modem, number string, data ...[]byte) {
+	t.Helper()
+	for _, d := range data {
+		if _, err := fake.ReceiveSmsData(number, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// collect returns a function returning the messages and errors passed to the callbacks so far
+func collect(in *Inbox) func() ([]Message, []error) {
+	var mu sync.Mutex
+	var messages []Message
+	var errs []error
+	in.OnMessage(func(message Message) {
+		mu.Lock()
+		defer mu.Unlock()
+		messages = append(messages, message)
+	})
+	in.OnError(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	})
+	return func() ([]Message, []error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]Message(nil), messages...), append([]error(nil), errs...)
+	}
+}
+
+func listMessages(t *testing.T, in *Inbox) []Message {
+	t.Helper()
+	messages, err := in.Messages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return messages
+}
+
+func TestInboxReassembly(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	in, err := NewInbox(modem, NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the segments keep their user data header in the data
+	in.PartFunc = DataHeaderPartFunc
+	in.DeleteFromModem = true
+	received := collect(in)
+
+	// the segments arrive out of order, mixed with the segments of another message of the same sender
+	receive(t, fake, "+491701234567", segment(7, 3, 2, "-two-"), segment(8, 2, 1, "other"), segment(7, 3, 3, "three"))
+	if err := in.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if messages := listMessages(t, in); len(messages) != 0 {
+		t.Fatalf("got messages %v before all segments were received", messages)
+	}
+	if n := len(fake.Messages()); n != 3 {
+		t.Errorf("got %d sms on the modem, want the 3 segments kept until the message is complete", n)
+	}
+	receive(t, fake, "+491701234567", segment(7, 3, 1, "one"))
+	if _, err := fake.ReceiveSms("+491709876543", "Hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	messages, errs := received()
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("got messages %v, want the reassembled and the plain message", messages)
+	}
+	var concatenated, plain Message
+	for _, message := range messages {
+		if message.Parts > 1 {
+			concatenated = message
+		} else {
+			plain = message
+		}
+	}
+	if concatenated.Number != "+491701234567" || concatenated.Parts != 3 || string(concatenated.Data) != "one-two-three" ||
+		concatenated.Incomplete {
+		t.Errorf("got concatenated message %+v, want the data of the 3 segments in order", concatenated)
+	}
+	if plain.Number != "+491709876543" || plain.Text != "Hello" || plain.Parts != 1 {
+		t.Errorf("got plain message %+v", plain)
+	}
+	if stored := listMessages(t, in); len(stored) != 2 {
+		t.Errorf("got %d stored messages, want 2", len(stored))
+	}
+	// only the segment of the other message is left on the modem
+	if left := fake.Messages(); len(left) != 1 {
+		t.Errorf("got %d sms on the modem, want 1", len(left))
+	}
+}
+
+func TestInboxIncomplete(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	in, err := NewInbox(modem, NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.PartFunc = DataHeaderPartFunc
+	in.PartTimeout = time.Nanosecond
+	receive(t, fake, "+491701234567", segment(1, 3, 1, "one"), segment(1, 3, 3, "three"))
+	if err := in.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	messages := listMessages(t, in)
+	if len(messages) != 1 || !messages[0].Incomplete || messages[0].Parts != 3 || string(messages[0].Data) != "onethree" {
+		t.Errorf("got messages %v, want the incomplete message of the available segments", messages)
+	}
+}
+
+func TestInboxDuplicates(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	store := NewMemoryStore()
+	in, err := NewInbox(modem, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.PartFunc = DataHeaderPartFunc
+	received := collect(in)
+	// a segment received twice is ignored, but deleted with the message
+	receive(t, fake, "+491701234567", segment(3, 2, 1, "one"), segment(3, 2, 1, "one"), segment(3, 2, 2, "two"))
+	if _, err := fake.ReceiveSms("+491709876543", "Hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	messages, _ := received()
+	if len(messages) != 2 {
+		t.Fatalf("got messages %v, want 2", messages)
+	}
+	for _, message := range messages {
+		if message.Parts == 2 && string(message.Data) != "onetwo" {
+			t.Errorf("got data %q, want onetwo", message.Data)
+		}
+	}
+
+	// a new inbox, e.g. after a restart, finds the messages still on the modem in the store
+	again, err := NewInbox(modem, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again.PartFunc = DataHeaderPartFunc
+	again.DeleteFromModem = true
+	receivedAgain := collect(again)
+	if err := again.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if messages, errs := receivedAgain(); len(messages) != 0 || len(errs) != 0 {
+		t.Errorf("got messages %v and errors %v of stored messages", messages, errs)
+	}
+	if stored := listMessages(t, again); len(stored) != 2 {
+		t.Errorf("got %d stored messages, want 2", len(stored))
+	}
+	if left := fake.Messages(); len(left) != 0 {
+		t.Errorf("got %d sms on the modem, want all deleted", len(left))
+	}
+}
+
+// failingStore fails to save the messages of the given number until it is cleared
+type failingStore struct {
+	*MemoryStore
+	mu     sync.Mutex
+	number string
+}
+
+func (s *failingStore) setNumber(number string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.number = number
+}
+
+func (s *failingStore) Save(message Message) error {
+	s.mu.Lock()
+	number := s.number
+	s.mu.Unlock()
+	if message.Number == number {
+		return errors.New("disk full")
+	}
+	return s.MemoryStore.Save(message)
+}
+
+func TestInboxSyncErrors(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	store := &failingStore{MemoryStore: NewMemoryStore(), number: "+491701234567"}
+	in, err := NewInbox(modem, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.PartFunc = DataHeaderPartFunc
+	received := collect(in)
+	failing, err := fake.ReceiveSms("+491701234567", "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	receive(t, fake, "+491701234567", segment(5, 2, 1, "one"), segment(5, 2, 2, "two"))
+	if _, err := fake.ReceiveSms("+491709876543", "second"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the messages of the failing number are reported, the other message is stored anyway
+	if err := in.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	messages, errs := received()
+	if len(messages) != 1 || messages[0].Text != "second" {
+		t.Errorf("got messages %v, want the message of the other number", messages)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("got errors %v, want the errors of both messages", errs)
+	}
+	for _, err := range errs {
+		if !strings.Contains(err.Error(), "disk full") {
+			t.Errorf("got error %v, want the error of the store", err)
+		}
+	}
+	if !strings.Contains(errs[0].Error(), string(failing.GetObjectPath())) {
+		t.Errorf("error %v does not name the sms", errs[0])
+	}
+
+	// both messages are saved by the next sync
+	store.setNumber("")
+	if err := in.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	messages, errs = received()
+	if len(errs) != 2 || len(messages) != 3 {
+		t.Fatalf("got messages %v and errors %v, want all messages", messages, errs)
+	}
+	for _, message := range messages[1:] {
+		if message.Text != "first" && !bytes.Equal(message.Data, []byte("onetwo")) {
+			t.Errorf("got message %+v, want the messages of the failing number", message)
+		}
+	}
+}
+
+func TestInboxStart(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	if _, err := fake.ReceiveSms("+491701234567", "before"); err != nil {
+		t.Fatal(err)
+	}
+	in, err := NewInbox(modem, NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	texts := make(chan string, 2)
+	in.OnMessage(func(message Message) {
+		texts <- message.Text
+	})
+	if err := in.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer in.Stop()
+	if err := in.Start(); err == nil {
+		t.Error("inbox started twice")
+	}
+	if _, err := fake.ReceiveSms("+491701234567", "after"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"before", "after"} {
+		select {
+		case text := <-texts:
+			if text != want {
+				t.Errorf("got message %q, want %q", text, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("message %q not received", want)
+		}
+	}
+	conversation, err := in.Conversation("+491701234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conversation) != 2 {
+		t.Errorf("got conversation %v, want 2 messages", conversation)
+	}
+}
+
+func findSms(t *testing.T, modem mm.Modem, path dbus.ObjectPath) mm.Sms {
+	t.Helper()
+	messaging, err := modem.GetMessaging()
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages, err := messaging.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sms := range messages {
+		if sms.GetObjectPath() == path {
+			return sms
+		}
+	}
+	t.Fatalf("sms %s not found", path)
+	return nil
+}
+
+func TestDefaultPartFunc(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	// ModemManager exposes the data without header, which may start like one
+	data := []byte{0x05, 0x00, 0x03, 0x2a, 0x02, 0x01, 0xff}
+	received, err := fake.ReceiveSmsData("+491701234567", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sms := findSms(t, modem, received.GetObjectPath())
+	if part, ok, err := DefaultPartFunc(sms); ok || err != nil {
+		t.Errorf("got part %+v, %v of a complete message", part, err)
+	}
+	in, err := NewInbox(modem, NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := in.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	messages := listMessages(t, in)
+	if len(messages) != 1 || messages[0].Parts != 1 || !bytes.Equal(messages[0].Data, data) {
+		t.Errorf("got messages %v, want the complete data", messages)
+	}
+}
+
+func TestDataHeaderPartFunc(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	tests := []struct {
+		name string
+		data []byte
+		ok   bool
+	}{
+		{"concatenated 8 bit reference", segment(9, 2, 1, "one"), true},
+		{"concatenated 16 bit reference", append([]byte{0x06, 0x08, 0x04, 0x01, 0x02, 0x02, 0x02}, "two"...), true},
+		{"ports only", []byte{0x06, 0x05, 0x04, 0x0b, 0x84, 0x23, 0xf0}, false},
+		{"single segment", segment(9, 1, 1, "one"), false},
+		{"sequence out of range", segment(9, 2, 3, "one"), false},
+		{"no header", []byte("plain data"), false},
+	}
+	for _, test := range tests {
+		received, err := fake.ReceiveSmsData("+491701234567", test.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sms := findSms(t, modem, received.GetObjectPath())
+		part, ok, err := DataHeaderPartFunc(sms)
+		if err != nil || ok != test.ok {
+			t.Errorf("%s: got part %v, %v, want %t", test.name, ok, err, test.ok)
+			continue
+		}
+		if ok && (part.Total != 2 || len(part.Data) != 3) {
+			t.Errorf("%s: got part %+v", test.name, part)
+		}
+	}
+}
+
+// deliverPdu returns the +CMGL entry of a received sms with the given user data header
+func deliverPdu(t *testing.T, sms mm.Sms, index int, header pdu.Header) string {
+	t.Helper()
+	number, err := sms.GetNumber()
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := sms.GetText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	timestamp, err := sms.GetTimestamp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &pdu.Deliver{
+		Originator: pdu.NewAddress(number),
+		Timestamp:  timestamp,
+		UserData:   pdu.UserData{Header: header, Encoding: pdu.EncodingGsm7, Text: text},
+	}
+	encoded, err := pdu.EncodeHex(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf("+CMGL: %d,1,,%d\r\n%s\r\n", index, len(encoded)/2-1, encoded)
+}
+
+func TestPduPartFunc(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	// the modem exposes the text of each segment without header as separate sms
+	var reply strings.Builder
+	for i, text := range []string{"two", "one"} {
+		received, err := fake.ReceiveSms("+491701234567", text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		concatenation := pdu.InformationElement{ID: pdu.IeiConcatenated8, Data: []byte{0x2a, 2, byte(2 - i)}}
+		reply.WriteString(deliverPdu(t, findSms(t, modem, received.GetObjectPath()), i, pdu.Header{concatenation}))
+	}
+	plain, err := fake.ReceiveSms("+491701234567", "plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply.WriteString(deliverPdu(t, findSms(t, modem, plain.GetObjectPath()), 2, nil))
+	reply.WriteString("\r\nOK")
+	fake.SetCommandReply("AT+CMGL=4", reply.String())
+
+	in, err := NewInbox(modem, NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.PartFunc = PduPartFunc(modem)
+	received := collect(in)
+	if err := in.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	messages, errs := received()
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	texts := make(map[string]int)
+	for _, message := range messages {
+		texts[message.Text] = message.Parts
+	}
+	if len(messages) != 2 || texts["onetwo"] != 2 || texts["plain"] != 1 {
+		t.Errorf("got messages %v, want the reassembled and the plain message", messages)
+	}
+
+	// the sms are retried if the pdus can't be listed, e.g. without debug mode of ModemManager
+	srv.SetError(fake.GetObjectPath(), mm.ModemCommand, mm.MmCoreErrorUnauthorized)
+	if _, err := fake.ReceiveSms("+491701234567", "later"); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if _, errs := received(); len(errs) != 1 {
+		t.Errorf("got errors %v, want the error of the command", errs)
+	}
+}
+
+func TestInboxDeleteRetry(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	var failed int32
+	srv.OnCall(fake.GetObjectPath(), mm.ModemMessagingDelete, func(args ...interface{}) error {
+		if atomic.AddInt32(&failed, 1) == 1 {
+			return errors.New("storage busy")
+		}
+		return nil
+	})
+	in, err := NewInbox(modem, NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.DeleteFromModem = true
+	received := collect(in)
+	if _, err := fake.ReceiveSms("+491701234567", "Hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	messages, errs := received()
+	if len(messages) != 1 || len(errs) != 1 {
+		t.Fatalf("got messages %v and errors %v, want the message and the error of the deletion", messages, errs)
+	}
+	if left := fake.Messages(); len(left) != 1 {
+		t.Fatalf("got %d sms on the modem, want the sms which failed to delete", len(left))
+	}
+
+	// the next sync deletes the persisted sms without storing it again
+	if err := in.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	messages, errs = received()
+	if len(messages) != 1 || len(errs) != 1 {
+		t.Errorf("got messages %v and errors %v after the retry", messages, errs)
+	}
+	if left := fake.Messages(); len(left) != 0 {
+		t.Errorf("got %d sms on the modem, want all deleted", len(left))
+	}
+}
@@ -0,0 +1,192 @@
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Store persists the received messages
+type Store interface {
+	// Has returns true if a message with the given id is stored
+	Has(id string) (bool, error)
+	// Save stores the message, replacing a message with the same id
+	Save(message Message) error
+	// List returns all stored messages, ordered by timestamp
+	List() ([]Message, error)
+	// Delete deletes the message with the given id
+	Delete(id string) error
+}
+
+// sortMessages orders the messages by timestamp, and by id for the same timestamp
+func sortMessages(messages []Message) {
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].Timestamp.Equal(messages[j].Timestamp) {
+			return messages[i].ID < messages[j].ID
+		}
+		return messages[i].Timestamp.Before(messages[j].Timestamp)
+	})
+}
+
+// MemoryStore keeps the messages in memory, e.g. for tests or if the messages are forwarded by OnMessage
+type MemoryStore struct {
+	mu       sync.Mutex
+	messages map[string]Message
+}
+
+// NewMemoryStore returns a new, empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{messages: make(map[string]Message)}
+}
+
+// Has returns true if a message with the given id is stored
+func (s *MemoryStore) Has(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.messages[id]
+	return ok, nil
+}
+
+// Save stores the message
+func (s *MemoryStore) Save(message Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[message.ID] = message
+	return nil
+}
+
+// List returns all stored messages, ordered by timestamp
+func (s *MemoryStore) List() ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := make([]Message, 0, len(s.messages))
+	for _, message := range s.messages {
+		messages = append(messages, message)
+	}
+	sortMessages(messages)
+	return messages, nil
+}
+
+// Delete deletes the message with the given id
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.messages, id)
+	return nil
+}
+
+// FileStore stores each message as JSON file named by its id in a directory
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore returns a new FileStore using the given directory, which is created if missing
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, errors.New("no directory given")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id[0] == '.' {
+		return "", errors.New("invalid message id " + id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+// Has returns true if a message with the given id is stored
+func (s *FileStore) Has(id string) (bool, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Save writes the message to a temporary file and renames it, so a crash never leaves a partial message behind
+func (s *FileStore) Save(message Message) error {
+	path, err := s.path(message.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(message, "", "  ")
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tmp, err := ioutil.TempFile(s.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// List reads all stored messages, ordered by timestamp
+func (s *FileStore) List() ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var messages []Message
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(s.dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var message Message
+		if err = json.Unmarshal(data, &message); err != nil {
+			return nil, errors.New("invalid message file " + file.Name() + ": " + err.Error())
+		}
+		messages = append(messages, message)
+	}
+	sortMessages(messages)
+	return messages, nil
+}
+
+// Delete removes the file of the message with the given id
+func (s *FileStore) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
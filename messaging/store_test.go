@@ -0,0 +1,154 @@
+package messaging
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+func tempDir(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "messaging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestFileStore(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	store, err := NewFileStore(filepath.Join(dir, "inbox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2020, time.May, 4, 12, 0, 0, 0, time.UTC)
+	messages := []Message{
+		{ID: "b", Number: "+491701234567", Text: "second", Timestamp: now.Add(time.Minute), Parts: 1},
+		{ID: "a", Number: "+491701234567", Data: []byte{0x00, 0xff}, Timestamp: now, Parts: 2, Incomplete: true},
+		{ID: "c", Number: "+491709876543", Text: "same time", Timestamp: now, Parts: 1},
+	}
+	for _, message := range messages {
+		if err := store.Save(message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// temporary files of an interrupted save are ignored
+	if err := ioutil.WriteFile(filepath.Join(dir, "inbox", ".tmp-1"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// a new store of the same directory, e.g. after a restart, reads the messages
+	store, err = NewFileStore(filepath.Join(dir, "inbox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := store.Has("a"); err != nil || !ok {
+		t.Errorf("Has(a) = %t, %v, want true", ok, err)
+	}
+	if ok, err := store.Has("d"); err != nil || ok {
+		t.Errorf("Has(d) = %t, %v, want false", ok, err)
+	}
+	list, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || list[0].ID != "a" || list[1].ID != "c" || list[2].ID != "b" {
+		t.Fatalf("got messages %v, want the messages ordered by timestamp and id", list)
+	}
+	if !list[0].Timestamp.Equal(now) || string(list[0].Data) != "\x00\xff" || !list[0].Incomplete || list[0].Parts != 2 {
+		t.Errorf("got message %+v, want the saved message", list[0])
+	}
+
+	if err := store.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("a"); err != nil {
+		t.Errorf("delete of a deleted message returned %v", err)
+	}
+	if list, err := store.List(); err != nil || len(list) != 2 {
+		t.Errorf("got messages %v, %v after delete, want 2", list, err)
+	}
+	for _, id := range []string{"", "../a", `a\b`, ".hidden"} {
+		if err := store.Save(Message{ID: id}); err == nil {
+			t.Errorf("saved message with invalid id %q", id)
+		}
+	}
+}
+
+func TestFileStoreInvalidFile(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.List(); err == nil {
+		t.Error("listed an invalid message file")
+	}
+	if _, err := NewFileStore(""); err == nil {
+		t.Error("got a store without directory")
+	}
+}
+
+func TestInboxFileStore(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in, err := NewInbox(modem, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.PartFunc = DataHeaderPartFunc
+	in.DeleteFromModem = true
+	receive(t, fake, "+491701234567", segment(2, 2, 2, "two"), segment(2, 2, 1, "one"))
+	if _, err := fake.ReceiveSms("+491701234567", "Hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("got files %v, want one file per message", files)
+	}
+
+	reopened, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := NewInbox(modem, reopened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conversations, err := again.Conversations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conversation := conversations["+491701234567"]
+	if len(conversations) != 1 || len(conversation) != 2 {
+		t.Fatalf("got conversations %v, want both messages of the sender", conversations)
+	}
+	for _, message := range conversation {
+		if message.ID == "" || message.Received.IsZero() || (message.Text != "Hello" && string(message.Data) != "onetwo") {
+			t.Errorf("got message %+v", message)
+		}
+	}
+}
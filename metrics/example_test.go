@@ -0,0 +1,20 @@
+package metrics_test
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/maltegrosse/go-modemmanager/metrics"
+)
+
+// serves the metrics of all modems at http://localhost:9539/metrics
+func ExampleExporter() {
+	exporter, err := metrics.NewExporter()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	// enable the extended signal information with a rate of 10 seconds on modems which have it disabled
+	exporter.SignalRate = 10
+	http.Handle("/metrics", exporter)
+	log.Fatal(http.ListenAndServe(":9539", nil))
+}
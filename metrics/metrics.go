@@ -0,0 +1,284 @@
+// Package metrics exports the state of all modems as gauges in the Prometheus text exposition format, e.g. to be
+// scraped from a /metrics endpoint. The gauges are derived from the modem state, the signal quality, the extended
+// signal information, the 3gpp registration state and the stats of the bearers. All gauges of a modem are labelled
+// by its equipment identifier, operator code and access technology.
+// The format is written without dependencies, so the package works with any http server.
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// ContentType is the content type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Exporter collects the metrics of all modems on every scrape
+type Exporter struct {
+	conn *dbus.Conn
+
+	// Namespace is the prefix of all metric names, defaults to modemmanager
+	Namespace string
+	// SignalRate enables the extended signal information with the given refresh rate in seconds on modems which
+	// have it disabled. If 0, the extended signal information is only exported if enabled by someone else.
+	SignalRate uint32
+}
+
+// NewExporter returns a new Exporter using the system bus
+func NewExporter() (*Exporter, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return nil, err
+	}
+	return NewExporterWithConn(conn)
+}
+
+// NewExporterWithConn returns a new Exporter using the given dbus connection
+func NewExporterWithConn(conn *dbus.Conn) (*Exporter, error) {
+	if conn == nil {
+		return nil, errors.New("no dbus connection given")
+	}
+	return &Exporter{conn: conn, Namespace: "modemmanager"}, nil
+}
+
+// ServeHTTP writes the metrics of all modems, so the Exporter can be used as handler of the /metrics endpoint
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := e.Write(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", ContentType)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// Write collects the metrics of all modems and writes them in the text exposition format. Metrics of a modem which
+// can't be read, e.g. because the modem has no 3gpp interface or is removed meanwhile, are skipped.
+func (e *Exporter) Write(w io.Writer) error {
+	mmgr, err := mm.NewModemManagerWithConn(e.conn)
+	if err != nil {
+		return err
+	}
+	modems, err := mmgr.GetModems()
+	if err != nil {
+		return err
+	}
+	c := newCollector(e.Namespace)
+	c.add("modems", "Number of modems.", nil, float64(len(modems)))
+	for _, modem := range modems {
+		e.collectModem(c, modem)
+	}
+	return c.write(w)
+}
+
+func (e *Exporter) collectModem(c *collector, modem mm.Modem) {
+	equipmentIdentifier, err := modem.GetEquipmentIdentifier()
+	if err != nil {
+		return
+	}
+	var operatorCode string
+	modem3gpp, err := modem.Get3gpp()
+	has3gpp := err == nil
+	if has3gpp {
+		operatorCode, _ = modem3gpp.GetOperatorCode()
+	}
+	var technologies []string
+	if accessTechnologies, err := modem.GetAccessTechnologies(); err == nil {
+		for _, technology := range accessTechnologies {
+			technologies = append(technologies, technology.String())
+		}
+	}
+	labels := []label{
+		{"equipment_identifier", equipmentIdentifier},
+		{"operator_code", operatorCode},
+		{"access_technology", strings.Join(technologies, ",")},
+	}
+
+	manufacturer, _ := modem.GetManufacturer()
+	model, _ := modem.GetModel()
+	revision, _ := modem.GetRevision()
+	c.add("modem_info", "Information about the modem, the value is always 1.",
+		withLabels(labels, label{"manufacturer", manufacturer}, label{"model", model}, label{"revision", revision}), 1)
+	if state, err := modem.GetState(); err == nil {
+		c.add("modem_state", "State of the modem, as MMModemState value.", labels, float64(state))
+	}
+	if percent, recent, err := modem.GetSignalQuality(); err == nil {
+		c.add("signal_quality_percent", "Signal quality in percent.", labels, float64(percent))
+		c.add("signal_quality_recent", "Whether the signal quality was recently taken, 1 if recent.", labels, boolValue(recent))
+	}
+	if has3gpp {
+		if state, err := modem3gpp.GetRegistrationState(); err == nil {
+			c.add("registration_state", "3gpp registration state, as MMModem3gppRegistrationState value.", labels, float64(state))
+		}
+	}
+	e.collectSignal(c, modem, labels)
+	e.collectBearers(c, modem, labels)
+}
+
+func (e *Exporter) collectSignal(c *collector, modem mm.Modem, labels []label) {
+	modemSignal, err := modem.GetSignal()
+	if err != nil {
+		return
+	}
+	if e.SignalRate > 0 {
+		if rate, err := modemSignal.GetRate(); err == nil && rate == 0 {
+			_ = modemSignal.Setup(e.SignalRate)
+		}
+	}
+	signals, err := modemSignal.GetCurrentSignals()
+	if err != nil {
+		return
+	}
+	for _, signal := range signals {
+		signalLabels := withLabels(labels, label{"signal_type", signal.Type.String()})
+		values := []struct {
+			key   string
+			name  string
+			help  string
+			value float64
+		}{
+			{"rssi", "signal_rssi_dbm", "Received signal strength indication in dBm.", signal.Rssi},
+			{"ecio", "signal_ecio_db", "Ec/Io level in dB.", signal.Ecio},
+			{"sinr", "signal_sinr_db", "Signal to interference plus noise ratio in dB.", signal.Sinr},
+			{"io", "signal_io_dbm", "Received Io in dBm.", signal.Io},
+			{"rscp", "signal_rscp_dbm", "Received signal code power in dBm.", signal.Rscp},
+			{"rsrq", "signal_rsrq_db", "Reference signal received quality in dB.", signal.Rsrq},
+			{"rsrp", "signal_rsrp_dbm", "Reference signal received power in dBm.", signal.Rsrp},
+			{"snr", "signal_snr_db", "Signal to noise ratio in dB.", signal.Snr},
+			{"error-rate", "signal_error_rate_percent", "Error rate in percent.", signal.ErrorRate},
+		}
+		for _, v := range values {
+			// values not applicable to the signal type are not reported, applicable values are even if 0 dB
+			if signal.IsApplicable(v.key) {
+				c.add(v.name, v.help, signalLabels, v.value)
+			}
+		}
+	}
+}
+
+func (e *Exporter) collectBearers(c *collector, modem mm.Modem, labels []label) {
+	bearers, err := modem.GetBearers()
+	if err != nil {
+		return
+	}
+	for _, bearer := range bearers {
+		var apn string
+		if properties, err := bearer.GetProperties(); err == nil {
+			apn = properties.APN
+		}
+		bearerLabels := withLabels(labels, label{"bearer", string(bearer.GetObjectPath())}, label{"apn", apn})
+		if connected, err := bearer.GetConnected(); err == nil {
+			c.add("bearer_connected", "Whether the bearer is connected, 1 if connected.", bearerLabels, boolValue(connected))
+		}
+		stats, err := bearer.GetStats()
+		if err != nil {
+			continue
+		}
+		c.add("bearer_rx_bytes", "Received bytes of the ongoing or last connection of the bearer.", bearerLabels, float64(stats.RxBytes))
+		c.add("bearer_tx_bytes", "Transmitted bytes of the ongoing or last connection of the bearer.", bearerLabels, float64(stats.TxBytes))
+		c.add("bearer_duration_seconds", "Duration of the ongoing or last connection of the bearer.", bearerLabels, float64(stats.Duration))
+		if stats.Attempts > 0 {
+			c.add("bearer_attempts", "Connection attempts of the bearer.", bearerLabels, float64(stats.Attempts))
+			c.add("bearer_failed_attempts", "Failed connection attempts of the bearer.", bearerLabels, float64(stats.FailedAttempts))
+		}
+	}
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+// withLabels returns a copy of labels with the given additional labels
+func withLabels(labels []label, additional ...label) []label {
+	return append(append([]label(nil), labels...), additional...)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+type sample struct {
+	labels []label
+	value  float64
+}
+
+type family struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+// collector groups the samples by metric, in the order of the first sample of each metric
+type collector struct {
+	namespace string
+	families  []*family
+	index     map[string]*family
+}
+
+func newCollector(namespace string) *collector {
+	return &collector{namespace: namespace, index: make(map[string]*family)}
+}
+
+func (c *collector) add(name string, help string, labels []label, value float64) {
+	if c.namespace != "" {
+		name = c.namespace + "_" + name
+	}
+	f, ok := c.index[name]
+	if !ok {
+		f = &family{name: name, help: help}
+		c.index[name] = f
+		c.families = append(c.families, f)
+	}
+	f.samples = append(f.samples, sample{labels: labels, value: value})
+}
+
+func (c *collector) write(w io.Writer) error {
+	var b strings.Builder
+	for _, f := range c.families {
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", f.name)
+		for _, s := range f.samples {
+			b.WriteString(f.name)
+			if len(s.labels) > 0 {
+				b.WriteByte('{')
+				for i, l := range s.labels {
+					if i > 0 {
+						b.WriteByte(',')
+					}
+					b.WriteString(l.name + "=\"" + escapeLabelValue(l.value) + "\"")
+				}
+				b.WriteByte('}')
+			}
+			b.WriteString(" " + formatValue(s.value) + "\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
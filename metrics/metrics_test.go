@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+// scrape gets the metrics endpoint and returns the value of each series, keyed by the name and labels of the series
+func scrape(t *testing.T, url string) map[string]float64 {
+	t.Helper()
+	resp, err := http.Get(url + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != ContentType {
+		t.Fatalf("got status %d with content type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	series := make(map[string]float64)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndex(line, " ")
+		value, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("invalid sample %q: %v", line, err)
+		}
+		series[line[:i]] = value
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return series
+}
+
+func assertSeries(t *testing.T, series map[string]float64, name string, want float64) {
+	t.Helper()
+	got, ok := series[name]
+	if !ok {
+		t.Errorf("series %s missing", name)
+	} else if got != want {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestExporter(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	registered, err := srv.AddModem(mmtest.ModemConfig{
+		EquipmentIdentifier: "490154203237518",
+		State:               mm.MmModemStateRegistered,
+		AccessTechnologies:  []mm.MMModemAccessTechnology{mm.MmModemAccessTechnologyLte, mm.MmModemAccessTechnologyUmts},
+		SignalQuality:       62,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	registered.SetExtendedSignal(mm.SignalProperty{Type: mm.MMSignalPropertyTypeLte, Rssi: -65, Rsrp: -95.5, Rsrq: -10, Snr: 12})
+	registered.SetExtendedSignalValues(mm.MMSignalPropertyTypeUmts, map[string]float64{"rssi": -70, "ecio": 0, "rscp": -85})
+	if _, err := srv.AddModem(mmtest.ModemConfig{EquipmentIdentifier: "358240051111110", OperatorCode: "26202"}); err != nil {
+		t.Fatal(err)
+	}
+	modem, err := mm.NewModemWithConn(conn, registered.GetObjectPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	simple, err := modem.GetSimpleModem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bearer, err := simple.Connect(mm.SimpleProperties{Apn: "internet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	registered.Bearers()[0].AddTraffic(4096, 1024)
+
+	exporter, err := NewExporterWithConn(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exporter.SignalRate = 10
+	ts := httptest.NewServer(exporter)
+	defer ts.Close()
+	series := scrape(t, ts.URL)
+
+	labels := `equipment_identifier="490154203237518",operator_code="26201",access_technology="Umts,Lte"`
+	assertSeries(t, series, "modemmanager_modems", 2)
+	assertSeries(t, series, "modemmanager_modem_info{"+labels+`,manufacturer="Fake",model="FakeModem 1000",revision="1.0.0"}`, 1)
+	assertSeries(t, series, "modemmanager_modem_state{"+labels+"}", float64(mm.MmModemStateConnected))
+	assertSeries(t, series, "modemmanager_signal_quality_percent{"+labels+"}", 62)
+	assertSeries(t, series, "modemmanager_registration_state{"+labels+"}", float64(mm.MmModem3gppRegistrationStateHome))
+	signalLabels := labels + `,signal_type="Lte"`
+	assertSeries(t, series, "modemmanager_signal_rssi_dbm{"+signalLabels+"}", -65)
+	assertSeries(t, series, "modemmanager_signal_rsrp_dbm{"+signalLabels+"}", -95.5)
+	assertSeries(t, series, "modemmanager_signal_snr_db{"+signalLabels+"}", 12)
+	if _, ok := series["modemmanager_signal_ecio_db{"+signalLabels+"}"]; ok {
+		t.Error("value not applicable to lte exported")
+	}
+	// an Ec/Io of 0 dB is a valid reading
+	umtsLabels := labels + `,signal_type="Umts"`
+	assertSeries(t, series, "modemmanager_signal_ecio_db{"+umtsLabels+"}", 0)
+	assertSeries(t, series, "modemmanager_signal_rscp_dbm{"+umtsLabels+"}", -85)
+	if _, ok := series["modemmanager_signal_snr_db{"+umtsLabels+"}"]; ok {
+		t.Error("value not applicable to umts exported")
+	}
+	bearerLabels := labels + `,bearer="` + string(bearer.GetObjectPath()) + `",apn="internet"`
+	assertSeries(t, series, "modemmanager_bearer_connected{"+bearerLabels+"}", 1)
+	assertSeries(t, series, "modemmanager_bearer_rx_bytes{"+bearerLabels+"}", 4096)
+	assertSeries(t, series, "modemmanager_bearer_tx_bytes{"+bearerLabels+"}", 1024)
+
+	// the disabled modem is not registered to its operator yet
+	disabledLabels := `equipment_identifier="358240051111110",operator_code="",access_technology="Lte"`
+	assertSeries(t, series, "modemmanager_modem_state{"+disabledLabels+"}", float64(mm.MmModemStateDisabled))
+
+	// the extended signal information is only enabled on modems which have it disabled
+	modemSignal, err := modem.GetSignal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate, err := modemSignal.GetRate(); err != nil || rate != 10 {
+		t.Errorf("signal rate = %d, %v, want 10", rate, err)
+	}
+}
+
+func TestExporterNamespace(t *testing.T) {
+	_, conn, stop := mmtest.StartT(t)
+	defer stop()
+	exporter, err := NewExporterWithConn(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exporter.Namespace = "wwan"
+	ts := httptest.NewServer(exporter)
+	defer ts.Close()
+	series := scrape(t, ts.URL)
+	if len(series) != 1 {
+		t.Errorf("got series %v, want only the number of modems", series)
+	}
+	assertSeries(t, series, "wwan_modems", 0)
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	if got, want := escapeLabelValue("a\"b\\c\nd"), `a\"b\\c\nd`; got != want {
+		t.Errorf("escapeLabelValue = %s, want %s", got, want)
+	}
+}
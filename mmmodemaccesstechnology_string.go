@@ -24,10 +24,13 @@ func _() {
 	_ = x[MmModemAccessTechnologyEvdoa-4096]
 	_ = x[MmModemAccessTechnologyEvdob-8192]
 	_ = x[MmModemAccessTechnologyLte-16384]
+	_ = x[MmModemAccessTechnology5gnr-32768]
+	_ = x[MmModemAccessTechnologyLteCatM-65536]
+	_ = x[MmModemAccessTechnologyLteNbIot-131072]
 	_ = x[MmModemAccessTechnologyAny-4294967295]
 }
 
-const _MMModemAccessTechnology_name = "UnknownPotsGsmGsmCompactGprsEdgeUmtsHsdpaHsupaHspaHspaPlus1xrttEvdo0EvdoaEvdobLteAny"
+const _MMModemAccessTechnology_name = "UnknownPotsGsmGsmCompactGprsEdgeUmtsHsdpaHsupaHspaHspaPlus1xrttEvdo0EvdoaEvdobLte5gnrLteCatMLteNbIotAny"
 
 var _MMModemAccessTechnology_map = map[MMModemAccessTechnology]string{
 	0:          _MMModemAccessTechnology_name[0:7],
@@ -46,7 +49,10 @@ var _MMModemAccessTechnology_map = map[MMModemAccessTechnology]string{
 	4096:       _MMModemAccessTechnology_name[68:73],
 	8192:       _MMModemAccessTechnology_name[73:78],
 	16384:      _MMModemAccessTechnology_name[78:81],
-	4294967295: _MMModemAccessTechnology_name[81:84],
+	32768:      _MMModemAccessTechnology_name[81:85],
+	65536:      _MMModemAccessTechnology_name[85:92],
+	131072:     _MMModemAccessTechnology_name[92:100],
+	4294967295: _MMModemAccessTechnology_name[100:103],
 }
 
 func (i MMModemAccessTechnology) String() string {
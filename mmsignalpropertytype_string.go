@@ -13,11 +13,12 @@ func _() {
 	_ = x[MMSignalPropertyTypeGsm-2]
 	_ = x[MMSignalPropertyTypeUmts-3]
 	_ = x[MMSignalPropertyTypeLte-4]
+	_ = x[MMSignalPropertyTypeNr5g-5]
 }
 
-const _MMSignalPropertyType_name = "CdmaEvdoGsmUmtsLte"
+const _MMSignalPropertyType_name = "CdmaEvdoGsmUmtsLteNr5g"
 
-var _MMSignalPropertyType_index = [...]uint8{0, 4, 8, 11, 15, 18}
+var _MMSignalPropertyType_index = [...]uint8{0, 4, 8, 11, 15, 18, 22}
 
 func (i MMSignalPropertyType) String() string {
 	if i >= MMSignalPropertyType(len(_MMSignalPropertyType_index)-1) {
@@ -0,0 +1,215 @@
+package mmtest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// DefaultIp4Config returns the Ip4Config which is set on a bearer connect, if not changed by SetIp4Config
+func DefaultIp4Config() map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"method":  dbus.MakeVariant(uint32(mm.MmBearerIpMethodStatic)),
+		"address": dbus.MakeVariant("10.64.64.64"),
+		"prefix":  dbus.MakeVariant(uint32(30)),
+		"dns1":    dbus.MakeVariant("10.11.12.13"),
+		"dns2":    dbus.MakeVariant("10.11.12.14"),
+		"gateway": dbus.MakeVariant("10.64.64.65"),
+		"mtu":     dbus.MakeVariant(uint32(1500)),
+	}
+}
+
+// Bearer represents a fake bearer, exported at /org/freedesktop/ModemManager1/Bearer/N
+type Bearer struct {
+	object
+	modem *Modem
+
+	mu             sync.Mutex
+	ip4Config      map[string]dbus.Variant
+	ip6Config      map[string]dbus.Variant
+	connectedAt    time.Time
+	rxBytes        uint64
+	txBytes        uint64
+	attempts       uint32
+	failedAttempts uint32
+	totalDuration  uint32 // duration of the previous connections
+	totalRxBytes   uint64 // received bytes of the previous connections
+	totalTxBytes   uint64 // transmitted bytes of the previous connections
+}
+
+func newBearer(srv *Server, modem *Modem, properties map[string]dbus.Variant) (*Bearer, error) {
+	b := &Bearer{
+		object:    object{srv: srv, path: srv.nextPath("Bearer")},
+		modem:     modem,
+		ip4Config: DefaultIp4Config(),
+		ip6Config: map[string]dbus.Variant{},
+	}
+	err := b.export(map[string]map[string]*prop.Prop{
+		mm.BearerInterface: {
+			"Interface":  {Value: "", Emit: prop.EmitTrue},
+			"Connected":  {Value: false, Emit: prop.EmitTrue},
+			"Suspended":  {Value: false, Emit: prop.EmitTrue},
+			"Ip4Config":  {Value: map[string]dbus.Variant{}, Emit: prop.EmitTrue},
+			"Ip6Config":  {Value: map[string]dbus.Variant{}, Emit: prop.EmitTrue},
+			"Stats":      {Value: map[string]dbus.Variant{}, Emit: prop.EmitTrue},
+			"IpTimeout":  {Value: uint32(20), Emit: prop.EmitTrue},
+			"BearerType": {Value: uint32(mm.MmBearerTypeDefault), Emit: prop.EmitTrue},
+			"Properties": {Value: properties, Emit: prop.EmitTrue},
+		},
+	}, map[string]interface{}{
+		mm.BearerInterface: &bearerIface{b},
+	})
+	if err != nil {
+		return nil, err
+	}
+	srv.mu.Lock()
+	srv.bearers[b.path] = b
+	srv.mu.Unlock()
+	return b, nil
+}
+
+// SetIp4Config sets the ip configuration which is applied on the next connect
+func (b *Bearer) SetIp4Config(config map[string]dbus.Variant) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ip4Config = config
+}
+
+// SetIp6Config sets the ip configuration which is applied on the next connect
+func (b *Bearer) SetIp6Config(config map[string]dbus.Variant) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ip6Config = config
+}
+
+// IsConnected returns true if the bearer is connected
+func (b *Bearer) IsConnected() bool {
+	return b.GetProperty(mm.BearerInterface, "Connected").(bool)
+}
+
+// AddTraffic adds the given amount of bytes to the stats of a connected bearer
+func (b *Bearer) AddTraffic(rx uint64, tx uint64) {
+	if !b.IsConnected() {
+		return
+	}
+	b.mu.Lock()
+	b.rxBytes += rx
+	b.txBytes += tx
+	b.mu.Unlock()
+	b.updateStats()
+}
+
+// Drop simulates a connection loss initiated by the network
+func (b *Bearer) Drop() {
+	b.disconnect()
+	b.modem.bearerDisconnected()
+}
+
+func (b *Bearer) apn() string {
+	properties, _ := b.GetProperty(mm.BearerInterface, "Properties").(map[string]dbus.Variant)
+	apn, _ := properties["apn"].Value().(string)
+	return apn
+}
+
+func (b *Bearer) connect() {
+	b.mu.Lock()
+	b.connectedAt = time.Now()
+	b.attempts++
+	b.rxBytes = 0
+	b.txBytes = 0
+	ip4, ip6 := b.ip4Config, b.ip6Config
+	b.mu.Unlock()
+	b.SetProperty(mm.BearerInterface, "Interface", "wwan0")
+	b.SetProperty(mm.BearerInterface, "Ip4Config", ip4)
+	b.SetProperty(mm.BearerInterface, "Ip6Config", ip6)
+	b.SetProperty(mm.BearerInterface, "Connected", true)
+	b.updateStats()
+}
+
+func (b *Bearer) disconnect() {
+	if !b.IsConnected() {
+		return
+	}
+	b.updateStats()
+	b.mu.Lock()
+	b.totalDuration += uint32(time.Since(b.connectedAt).Seconds())
+	b.totalRxBytes += b.rxBytes
+	b.totalTxBytes += b.txBytes
+	b.mu.Unlock()
+	b.SetProperty(mm.BearerInterface, "Connected", false)
+	b.SetProperty(mm.BearerInterface, "Interface", "")
+	b.SetProperty(mm.BearerInterface, "Ip4Config", map[string]dbus.Variant{})
+	b.SetProperty(mm.BearerInterface, "Ip6Config", map[string]dbus.Variant{})
+}
+
+func (b *Bearer) updateStats() {
+	connected := b.IsConnected()
+	b.mu.Lock()
+	var duration uint32
+	if connected {
+		duration = uint32(time.Since(b.connectedAt).Seconds())
+	}
+	var startDate uint64
+	if !b.connectedAt.IsZero() {
+		startDate = uint64(b.connectedAt.Unix())
+	}
+	stats := map[string]dbus.Variant{
+		"duration":        dbus.MakeVariant(duration),
+		"rx-bytes":        dbus.MakeVariant(b.rxBytes),
+		"tx-bytes":        dbus.MakeVariant(b.txBytes),
+		"start-date":      dbus.MakeVariant(startDate),
+		"attempts":        dbus.MakeVariant(b.attempts),
+		"failed-attempts": dbus.MakeVariant(b.failedAttempts),
+		"total-duration":  dbus.MakeVariant(b.totalDuration + duration),
+		"total-rx-bytes":  dbus.MakeVariant(b.totalRxBytes + b.rxBytes),
+		"total-tx-bytes":  dbus.MakeVariant(b.totalTxBytes + b.txBytes),
+	}
+	b.mu.Unlock()
+	b.SetProperty(mm.BearerInterface, "Stats", stats)
+}
+
+func (b *Bearer) remove() {
+	b.unexport(mm.BearerInterface)
+	b.srv.mu.Lock()
+	delete(b.srv.bearers, b.path)
+	b.srv.mu.Unlock()
+}
+
+// bearerIface implements org.freedesktop.ModemManager1.Bearer
+type bearerIface struct {
+	b *Bearer
+}
+
+func (bi *bearerIface) Connect() *dbus.Error {
+	b := bi.b
+	if err := b.invoke(mm.BearerConnect); err != nil {
+		return err
+	}
+	if b.IsConnected() {
+		return nil
+	}
+	if err := b.modem.checkState(mm.MmModemStateRegistered); err != nil {
+		b.mu.Lock()
+		b.attempts++
+		b.failedAttempts++
+		b.mu.Unlock()
+		b.updateStats()
+		return err
+	}
+	b.connect()
+	b.modem.bearerConnected()
+	return nil
+}
+
+func (bi *bearerIface) Disconnect() *dbus.Error {
+	b := bi.b
+	if err := b.invoke(mm.BearerDisconnect); err != nil {
+		return err
+	}
+	b.disconnect()
+	b.modem.bearerDisconnected()
+	return nil
+}
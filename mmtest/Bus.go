@@ -0,0 +1,106 @@
+package mmtest
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// busConfig is a minimal dbus-daemon configuration allowing everything on a private bus
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:dir=%DIR%</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// Bus represents a private dbus-daemon instance, e.g. used to run a fake ModemManager in tests.
+type Bus struct {
+	cmd     *exec.Cmd
+	dir     string
+	address string
+}
+
+// StartBus launches a private dbus-daemon. The dbus-daemon binary needs to be available in PATH.
+func StartBus() (*Bus, error) {
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		return nil, err
+	}
+	dir, err := ioutil.TempDir("", "mmtest")
+	if err != nil {
+		return nil, err
+	}
+	configFile := filepath.Join(dir, "bus.conf")
+	config := strings.Replace(busConfig, "%DIR%", dir, 1)
+	err = ioutil.WriteFile(configFile, []byte(config), 0600)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	cmd := exec.Command(daemon, "--config-file="+configFile, "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	address = strings.TrimSpace(address)
+	if err != nil || len(address) < 1 {
+		cmd.Process.Kill()
+		cmd.Wait()
+		os.RemoveAll(dir)
+		return nil, errors.New("could not read address of dbus-daemon")
+	}
+	return &Bus{cmd: cmd, dir: dir, address: address}, nil
+}
+
+// Address returns the address of the bus
+func (b *Bus) Address() string {
+	return b.address
+}
+
+// Connect opens a new private connection to the bus
+func (b *Bus) Connect() (*dbus.Conn, error) {
+	conn, err := dbus.Dial(b.address)
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Auth(nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	err = conn.Hello()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Close stops the dbus-daemon
+func (b *Bus) Close() error {
+	err := b.cmd.Process.Kill()
+	b.cmd.Wait()
+	os.RemoveAll(b.dir)
+	return err
+}
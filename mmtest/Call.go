@@ -0,0 +1,149 @@
+package mmtest
+
+import (
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// Call represents a fake voice call, exported at /org/freedesktop/ModemManager1/Call/N
+type Call struct {
+	object
+	modem *Modem
+}
+
+func newCall(srv *Server, modem *Modem, number string, direction mm.MMCallDirection, state mm.MMCallState, reason mm.MMCallStateReason) (*Call, error) {
+	c := &Call{
+		object: object{srv: srv, path: srv.nextPath("Call")},
+		modem:  modem,
+	}
+	err := c.export(map[string]map[string]*prop.Prop{
+		mm.CallInterface: {
+			"State":       {Value: int32(state), Emit: prop.EmitTrue},
+			"StateReason": {Value: int32(reason), Emit: prop.EmitTrue},
+			"Direction":   {Value: int32(direction), Emit: prop.EmitTrue},
+			"Number":      {Value: number, Emit: prop.EmitTrue},
+			"Multiparty":  {Value: false, Emit: prop.EmitTrue},
+			"AudioPort":   {Value: "", Emit: prop.EmitTrue},
+			"AudioFormat": {Value: map[string]dbus.Variant{}, Emit: prop.EmitTrue},
+		},
+	}, map[string]interface{}{
+		mm.CallInterface: &callIface{c},
+	})
+	if err != nil {
+		return nil, err
+	}
+	srv.mu.Lock()
+	srv.voice[c.path] = c
+	srv.mu.Unlock()
+	return c, nil
+}
+
+// State returns the current call state
+func (c *Call) State() mm.MMCallState {
+	return mm.MMCallState(c.GetProperty(mm.CallInterface, "State").(int32))
+}
+
+// SetState updates the call state and emits the StateChanged signal, e.g. Active to simulate an answer of the remote
+// peer or Terminated for a remote hangup
+func (c *Call) SetState(state mm.MMCallState, reason mm.MMCallStateReason) {
+	old := c.State()
+	c.SetProperty(mm.CallInterface, "StateReason", int32(reason))
+	c.SetProperty(mm.CallInterface, "State", int32(state))
+	c.srv.emit(c.path, mm.CallInterface+"."+mm.CallSignalStateChanged, int32(old), int32(state), uint32(reason))
+}
+
+// ReceiveDtmf emits the DtmfReceived signal
+func (c *Call) ReceiveDtmf(dtmf string) {
+	c.srv.emit(c.path, mm.CallInterface+"."+mm.CallSignalDtmfReceived, dtmf)
+}
+
+func (c *Call) remove() {
+	c.unexport(mm.CallInterface)
+	c.srv.mu.Lock()
+	delete(c.srv.voice, c.path)
+	c.srv.mu.Unlock()
+}
+
+// callIface implements org.freedesktop.ModemManager1.Call
+type callIface struct {
+	c *Call
+}
+
+func (ci *callIface) Start() *dbus.Error {
+	c := ci.c
+	if err := c.invoke(mm.CallStart); err != nil {
+		return err
+	}
+	if c.State() != mm.MmCallStateUnknown {
+		return newError(ErrorWrongState, "Call already started")
+	}
+	c.SetState(mm.MmCallStateDialing, mm.MmCallStateReasonOutgoingStarted)
+	c.SetState(mm.MmCallStateRingingOut, mm.MmCallStateReasonOutgoingStarted)
+	return nil
+}
+
+func (ci *callIface) Accept() *dbus.Error {
+	c := ci.c
+	if err := c.invoke(mm.CallAccept); err != nil {
+		return err
+	}
+	if c.State() != mm.MmCallStateRingingIn && c.State() != mm.MmCallStateWaiting {
+		return newError(ErrorWrongState, "Call is not ringing")
+	}
+	c.SetState(mm.MmCallStateActive, mm.MmCallStateReasonAccepted)
+	return nil
+}
+
+func (ci *callIface) Deflect(number string) *dbus.Error {
+	c := ci.c
+	if err := c.invoke(mm.CallDeflect, number); err != nil {
+		return err
+	}
+	if c.State() != mm.MmCallStateRingingIn && c.State() != mm.MmCallStateWaiting {
+		return newError(ErrorWrongState, "Call is not ringing")
+	}
+	c.SetState(mm.MmCallStateTerminated, mm.MmCallStateReasonDeflected)
+	return nil
+}
+
+func (ci *callIface) JoinMultiparty() *dbus.Error {
+	c := ci.c
+	if err := c.invoke(mm.CallJoinMultiparty); err != nil {
+		return err
+	}
+	c.SetProperty(mm.CallInterface, "Multiparty", true)
+	return nil
+}
+
+func (ci *callIface) LeaveMultiparty() *dbus.Error {
+	c := ci.c
+	if err := c.invoke(mm.CallLeaveMultiparty); err != nil {
+		return err
+	}
+	c.SetProperty(mm.CallInterface, "Multiparty", false)
+	return nil
+}
+
+func (ci *callIface) Hangup() *dbus.Error {
+	c := ci.c
+	if err := c.invoke(mm.CallHangup); err != nil {
+		return err
+	}
+	if c.State() == mm.MmCallStateTerminated {
+		return newError(ErrorWrongState, "Call already terminated")
+	}
+	c.SetState(mm.MmCallStateTerminated, mm.MmCallStateReasonTerminated)
+	return nil
+}
+
+func (ci *callIface) SendDtmf(dtmf string) *dbus.Error {
+	c := ci.c
+	if err := c.invoke(mm.CallSendDtmf, dtmf); err != nil {
+		return err
+	}
+	if c.State() != mm.MmCallStateActive {
+		return newError(ErrorWrongState, "Call is not active")
+	}
+	return nil
+}
@@ -0,0 +1,1701 @@
+package mmtest
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// modemInterfaces lists all interfaces exported by a fake modem
+var modemInterfaces = []string{
+	mm.ModemInterface,
+	mm.ModemSimpleInterface,
+	mm.Modem3gppInterface,
+	mm.Modem3gppUssdInterface,
+	mm.Modem3gppProfileManagerInterface,
+	mm.ModemMessagingInterface,
+	mm.ModemVoiceInterface,
+	mm.ModemSignalInterface,
+	mm.ModemSarInterface,
+	mm.ModemLocationInterface,
+	mm.ModemTimeInterface,
+}
+
+// bearerKeys are the keys of a simple connect dictionary which are bearer properties
+var bearerKeys = []string{"apn", "ip-type", "allowed-auth", "user", "password", "allow-roaming", "rm-protocol", "number"}
+
+// ModemConfig defines the initial state of a fake modem. Empty values are replaced by defaults.
+type ModemConfig struct {
+	Manufacturer        string                            // defaults to Fake
+	Model               string                            // defaults to FakeModem 1000
+	Revision            string                            // defaults to 1.0.0
+	HardwareRevision    string                            // defaults to 1
+	DeviceIdentifier    string                            // defaults to an unique identifier per modem
+	Device              string                            // defaults to a sysfs like path
+	EquipmentIdentifier string                            // IMEI, defaults to an unique identifier per modem
+	Plugin              string                            // defaults to Generic
+	PrimaryPort         string                            // defaults to cdc-wdm0
+	Drivers             []string                          // defaults to qmi_wwan
+	State               mm.MMModemState                   // initial state, defaults to disabled or locked if the sim is locked
+	AccessTechnologies  []mm.MMModemAccessTechnology      // defaults to lte
+	SignalQuality       uint32                            // defaults to 75
+	OwnNumbers          []string                          // defaults to none
+	RegistrationState   mm.MMModem3gppRegistrationState   // registration state reached after enabling, defaults to home
+	OperatorCode        string                            // defaults to 26201
+	OperatorName        string                            // defaults to Fake Operator
+	Networks            []mm.Network3Gpp                  // results of a network scan
+	MaxBearers          uint32                            // defaults to 4
+	NoSim               bool                              // if true, no sim is inserted
+	Sim                 SimConfig                         // the inserted sim
+	SimSlots            []SimConfig                       // sims of a multi-SIM modem, the sim of the primary slot replaces Sim
+	PrimarySimSlot      uint32                            // 1-based index of the primary slot in SimSlots, defaults to 1
+	FacilityLocks       map[mm.MMModem3gppFacility]string // enabled facility locks with the control key to disable them
+}
+
+// signalQuality represents the (ub) signal quality
+type signalQuality struct {
+	Quality uint32
+	Recent  bool
+}
+
+// port represents a (su) port
+type port struct {
+	Name string
+	Type uint32
+}
+
+// modes represents a (uu) allowed and preferred mode combination
+type modes struct {
+	Allowed   uint32
+	Preferred uint32
+}
+
+// preferredNetwork represents a (su) preferred network of a sim
+type preferredNetwork struct {
+	OperatorCode       string
+	AccessTechnologies uint32
+}
+
+// facilityLock represents a (us) facility with its control key
+type facilityLock struct {
+	Facility   uint32
+	ControlKey string
+}
+
+// pco represents a (ubay) pco
+type pco struct {
+	SessionId uint32
+	Complete  bool
+	Data      []byte
+}
+
+// Modem represents a fake modem, exported at /org/freedesktop/ModemManager1/Modem/N
+type Modem struct {
+	object
+	sim      *Sim
+	slotSims []*Sim      // all sims of a multi-SIM modem, including sim
+	cfg      ModemConfig // the configuration with defaults, used to reprobe the modem
+
+	mu                sync.Mutex
+	bearers           []*Bearer
+	messages          []*Sms
+	calls             []*Call
+	maxBearers        uint32
+	registrationState mm.MMModem3gppRegistrationState
+	operatorCode      string
+	operatorName      string
+	networks          []mm.Network3Gpp
+	ussdReplies       map[string]string
+	commandReplies    map[string]string
+	networkTime       time.Time
+	callWaiting       bool
+	profiles          []map[string]dbus.Variant
+	facilityLocks     map[mm.MMModem3gppFacility]string
+	carrierLock       []byte
+	cellInfo          []map[string]dbus.Variant
+}
+
+// AddModem exports a new fake modem and emits InterfacesAdded
+func (s *Server) AddModem(cfg ModemConfig) (*Modem, error) {
+	m := &Modem{
+		object:         object{srv: s, path: s.nextPath("Modem")},
+		ussdReplies:    make(map[string]string),
+		commandReplies: make(map[string]string),
+	}
+	var idx int
+	fmt.Sscanf(string(m.path), mm.ModemManagerObjectPath+"/Modem/%d", &idx)
+	cfg.setDefaults(idx)
+	m.cfg = cfg
+	if len(cfg.SimSlots) > 0 {
+		cfg.Sim = cfg.SimSlots[cfg.PrimarySimSlot-1]
+		cfg.NoSim = cfg.Sim.Empty
+	}
+	m.maxBearers = cfg.MaxBearers
+	m.registrationState = cfg.RegistrationState
+	m.operatorCode = cfg.OperatorCode
+	m.operatorName = cfg.OperatorName
+	m.networks = cfg.Networks
+	m.facilityLocks = make(map[mm.MMModem3gppFacility]string)
+	var facilities []mm.MMModem3gppFacility
+	for facility, controlKey := range cfg.FacilityLocks {
+		m.facilityLocks[facility] = controlKey
+		facilities = append(facilities, facility)
+	}
+
+	simPath := dbus.ObjectPath("/")
+	unlockRequired := mm.MmModemLockNone
+	if !cfg.NoSim {
+		var err error
+		m.sim, err = newSim(s, m, cfg.Sim, true)
+		if err != nil {
+			return nil, err
+		}
+		simPath = m.sim.path
+		if cfg.Sim.Locked {
+			unlockRequired = mm.MmModemLockSimPin
+		}
+	}
+	simSlots := []dbus.ObjectPath{}
+	var primarySimSlot uint32
+	for i, slotCfg := range cfg.SimSlots {
+		primarySimSlot = cfg.PrimarySimSlot
+		if slotCfg.Empty {
+			m.slotSims = append(m.slotSims, nil)
+			simSlots = append(simSlots, "/")
+			continue
+		}
+		slotSim := m.sim
+		if uint32(i+1) != cfg.PrimarySimSlot {
+			var err error
+			slotSim, err = newSim(s, m, slotCfg, false)
+			if err != nil {
+				return nil, err
+			}
+		}
+		m.slotSims = append(m.slotSims, slotSim)
+		simSlots = append(simSlots, slotSim.path)
+	}
+	if cfg.State == mm.MmModemStateUnknown {
+		cfg.State = mm.MmModemStateDisabled
+		if unlockRequired != mm.MmModemLockNone {
+			cfg.State = mm.MmModemStateLocked
+		}
+	}
+	stateFailedReason := mm.MmModemStateFailedReasonNone
+	if cfg.NoSim {
+		cfg.State = mm.MmModemStateFailed
+		stateFailedReason = mm.MmModemStateFailedReasonSimMissing
+	}
+	registrationState := mm.MmModem3gppRegistrationStateIdle
+	packetServiceState := mm.MmModem3gppPacketServiceStateDetached
+	operatorCode, operatorName := "", ""
+	powerState := mm.MmModemPowerStateLow
+	if cfg.State >= mm.MmModemStateEnabled {
+		powerState = mm.MmModemPowerStateOn
+	}
+	if cfg.State >= mm.MmModemStateRegistered {
+		registrationState = cfg.RegistrationState
+		packetServiceState = mm.MmModem3gppPacketServiceStateAttached
+		operatorCode, operatorName = cfg.OperatorCode, cfg.OperatorName
+	}
+	var facility mm.MMModem3gppFacility
+	var accessTechnology mm.MMModemAccessTechnology
+	var mode mm.MMModemMode
+	var capability mm.MMModemCapability
+	var location mm.MMModemLocationSource
+	caps := capability.SliceToBitmask([]mm.MMModemCapability{mm.MmModemCapabilityGsmUmts, mm.MmModemCapabilityLte})
+	allModes := mode.SliceToBitmask([]mm.MMModemMode{mm.MmModemMode2g, mm.MmModemMode3g, mm.MmModemMode4g})
+	bands := []uint32{uint32(mm.MmModemBandEutran1), uint32(mm.MmModemBandEutran3), uint32(mm.MmModemBandEutran20)}
+	emptyMap := map[string]dbus.Variant{}
+
+	props := map[string]map[string]*prop.Prop{
+		mm.ModemInterface: {
+			"Sim":                          {Value: simPath, Emit: prop.EmitTrue},
+			"Bearers":                      {Value: []dbus.ObjectPath{}, Emit: prop.EmitTrue},
+			"SupportedCapabilities":        {Value: []uint32{caps}, Emit: prop.EmitTrue},
+			"CurrentCapabilities":          {Value: caps, Emit: prop.EmitTrue},
+			"MaxBearers":                   {Value: cfg.MaxBearers, Emit: prop.EmitTrue},
+			"MaxActiveBearers":             {Value: cfg.MaxBearers, Emit: prop.EmitTrue},
+			"Manufacturer":                 {Value: cfg.Manufacturer, Emit: prop.EmitTrue},
+			"Model":                        {Value: cfg.Model, Emit: prop.EmitTrue},
+			"Revision":                     {Value: cfg.Revision, Emit: prop.EmitTrue},
+			"CarrierConfiguration":         {Value: "default", Emit: prop.EmitTrue},
+			"CarrierConfigurationRevision": {Value: "", Emit: prop.EmitTrue},
+			"HardwareRevision":             {Value: cfg.HardwareRevision, Emit: prop.EmitTrue},
+			"DeviceIdentifier":             {Value: cfg.DeviceIdentifier, Emit: prop.EmitTrue},
+			"Device":                       {Value: cfg.Device, Emit: prop.EmitTrue},
+			"Drivers":                      {Value: cfg.Drivers, Emit: prop.EmitTrue},
+			"Plugin":                       {Value: cfg.Plugin, Emit: prop.EmitTrue},
+			"PrimaryPort":                  {Value: cfg.PrimaryPort, Emit: prop.EmitTrue},
+			"Ports":                        {Value: []port{{cfg.PrimaryPort, uint32(mm.MmModemPortTypeQmi)}, {"wwan0", uint32(mm.MmModemPortTypeNet)}}, Emit: prop.EmitTrue},
+			"EquipmentIdentifier":          {Value: cfg.EquipmentIdentifier, Emit: prop.EmitTrue},
+			"UnlockRequired":               {Value: uint32(unlockRequired), Emit: prop.EmitTrue},
+			"UnlockRetries":                {Value: map[uint32]uint32{}, Emit: prop.EmitTrue},
+			"State":                        {Value: int32(cfg.State), Emit: prop.EmitTrue},
+			"StateFailedReason":            {Value: uint32(stateFailedReason), Emit: prop.EmitTrue},
+			"AccessTechnologies":           {Value: accessTechnology.SliceToBitmask(cfg.AccessTechnologies), Emit: prop.EmitTrue},
+			"SignalQuality":                {Value: signalQuality{cfg.SignalQuality, true}, Emit: prop.EmitTrue},
+			"OwnNumbers":                   {Value: cfg.OwnNumbers, Emit: prop.EmitTrue},
+			"PowerState":                   {Value: uint32(powerState), Emit: prop.EmitTrue},
+			"SupportedModes":               {Value: []modes{{allModes, uint32(mm.MmModemMode4g)}}, Emit: prop.EmitTrue},
+			"CurrentModes":                 {Value: modes{allModes, uint32(mm.MmModemMode4g)}, Emit: prop.EmitTrue},
+			"SupportedBands":               {Value: bands, Emit: prop.EmitTrue},
+			"CurrentBands":                 {Value: bands, Emit: prop.EmitTrue},
+			"SupportedIpFamilies":          {Value: uint32(mm.MmBearerIpFamilyIpv4v6), Emit: prop.EmitTrue},
+			"SimSlots":                     {Value: simSlots, Emit: prop.EmitTrue},
+			"PrimarySimSlot":               {Value: primarySimSlot, Emit: prop.EmitTrue},
+		},
+		mm.Modem3gppInterface: {
+			"Imei":                     {Value: cfg.EquipmentIdentifier, Emit: prop.EmitTrue},
+			"RegistrationState":        {Value: uint32(registrationState), Emit: prop.EmitTrue},
+			"OperatorCode":             {Value: operatorCode, Emit: prop.EmitTrue},
+			"OperatorName":             {Value: operatorName, Emit: prop.EmitTrue},
+			"EnabledFacilityLocks":     {Value: facility.SliceToBitmask(facilities), Emit: prop.EmitTrue},
+			"SubscriptionState":        {Value: uint32(0), Emit: prop.EmitTrue},
+			"EpsUeModeOperation":       {Value: uint32(mm.MmModem3gppEpsUeModeOperationCsps2), Emit: prop.EmitTrue},
+			"Pco":                      {Value: []pco{}, Emit: prop.EmitTrue},
+			"InitialEpsBearer":         {Value: dbus.ObjectPath("/"), Emit: prop.EmitTrue},
+			"InitialEpsBearerSettings": {Value: emptyMap, Emit: prop.EmitTrue},
+			"Nr5gRegistrationSettings": {Value: emptyMap, Emit: prop.EmitTrue},
+			"PacketServiceState":       {Value: uint32(packetServiceState), Emit: prop.EmitTrue},
+			"NetworkRejection":         {Value: emptyMap, Emit: prop.EmitTrue},
+		},
+		mm.Modem3gppUssdInterface: {
+			"State":               {Value: uint32(mm.MmModem3gppUssdSessionStateIdle), Emit: prop.EmitTrue},
+			"NetworkNotification": {Value: "", Emit: prop.EmitTrue},
+			"NetworkRequest":      {Value: "", Emit: prop.EmitTrue},
+		},
+		mm.Modem3gppProfileManagerInterface: {
+			"IndexField": {Value: "profile-id", Emit: prop.EmitTrue},
+		},
+		mm.ModemMessagingInterface: {
+			"Messages":          {Value: []dbus.ObjectPath{}, Emit: prop.EmitTrue},
+			"SupportedStorages": {Value: []uint32{uint32(mm.MmSmsStorageSm), uint32(mm.MmSmsStorageMe)}, Emit: prop.EmitTrue},
+			"DefaultStorage":    {Value: uint32(mm.MmSmsStorageMe), Emit: prop.EmitTrue},
+		},
+		mm.ModemVoiceInterface: {
+			"Calls":         {Value: []dbus.ObjectPath{}, Emit: prop.EmitTrue},
+			"EmergencyOnly": {Value: false, Emit: prop.EmitTrue},
+		},
+		mm.ModemSignalInterface: {
+			"Rate":               {Value: uint32(0), Emit: prop.EmitTrue},
+			"RssiThreshold":      {Value: uint32(0), Emit: prop.EmitTrue},
+			"ErrorRateThreshold": {Value: false, Emit: prop.EmitTrue},
+			"Cdma":               {Value: emptyMap, Emit: prop.EmitTrue},
+			"Evdo":               {Value: emptyMap, Emit: prop.EmitTrue},
+			"Gsm":                {Value: emptyMap, Emit: prop.EmitTrue},
+			"Umts":               {Value: emptyMap, Emit: prop.EmitTrue},
+			"Lte":                {Value: emptyMap, Emit: prop.EmitTrue},
+			"Nr5g":               {Value: emptyMap, Emit: prop.EmitTrue},
+		},
+		mm.ModemSarInterface: {
+			"State":      {Value: false, Emit: prop.EmitTrue},
+			"PowerLevel": {Value: uint32(0), Emit: prop.EmitTrue},
+		},
+		mm.ModemLocationInterface: {
+			"Capabilities":            {Value: location.SliceToBitmask([]mm.MMModemLocationSource{mm.MmModemLocationSource3gppLacCi, mm.MmModemLocationSourceGpsRaw, mm.MmModemLocationSourceGpsNmea}), Emit: prop.EmitTrue},
+			"SupportedAssistanceData": {Value: uint32(mm.MmModemLocationAssistanceDataTypeNone), Emit: prop.EmitTrue},
+			"Enabled":                 {Value: uint32(mm.MmModemLocationSourceNone), Emit: prop.EmitTrue},
+			"SignalsLocation":         {Value: false, Emit: prop.EmitTrue},
+			"Location":                {Value: map[uint32]dbus.Variant{}, Emit: prop.EmitTrue},
+			"SuplServer":              {Value: "", Emit: prop.EmitTrue},
+			"AssistanceDataServers":   {Value: []string{}, Emit: prop.EmitTrue},
+			"GpsRefreshRate":          {Value: uint32(30), Emit: prop.EmitTrue},
+		},
+		mm.ModemTimeInterface: {
+			"NetworkTimezone": {Value: map[string]dbus.Variant{"offset": dbus.MakeVariant(int32(0))}, Emit: prop.EmitTrue},
+		},
+	}
+	err := m.export(props, map[string]interface{}{
+		mm.ModemInterface:                   &modemIface{m},
+		mm.ModemSimpleInterface:             &simpleIface{m},
+		mm.Modem3gppInterface:               &modem3gppIface{m},
+		mm.Modem3gppUssdInterface:           &ussdIface{m},
+		mm.Modem3gppProfileManagerInterface: &profileManagerIface{m},
+		mm.ModemMessagingInterface:          &messagingIface{m},
+		mm.ModemVoiceInterface:              &voiceIface{m},
+		mm.ModemSignalInterface:             &signalIface{m},
+		mm.ModemSarInterface:                &sarIface{m},
+		mm.ModemLocationInterface:           &locationIface{m},
+		mm.ModemTimeInterface:               &timeIface{m},
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.updateUnlockRetries()
+	s.mu.Lock()
+	s.modems[m.path] = m
+	s.mu.Unlock()
+	s.emit(mm.ModemManagerObjectPath, dbusObjectManagerInterface+".InterfacesAdded", m.path, m.interfaces())
+	return m, nil
+}
+
+// RemoveModem unexports the modem including its sim, bearers, sms and calls and emits InterfacesRemoved
+func (s *Server) RemoveModem(m *Modem) {
+	s.mu.Lock()
+	_, ok := s.modems[m.path]
+	delete(s.modems, m.path)
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	m.mu.Lock()
+	bearers, messages, calls := m.bearers, m.messages, m.calls
+	m.bearers, m.messages, m.calls = nil, nil, nil
+	m.mu.Unlock()
+	for _, b := range bearers {
+		b.remove()
+	}
+	for _, sms := range messages {
+		sms.remove()
+	}
+	for _, c := range calls {
+		c.remove()
+	}
+	for _, slotSim := range m.slotSims {
+		if slotSim != nil && slotSim != m.sim {
+			slotSim.remove()
+		}
+	}
+	if m.sim != nil {
+		m.sim.remove()
+	}
+	m.unexport(modemInterfaces...)
+	s.emit(mm.ModemManagerObjectPath, dbusObjectManagerInterface+".InterfacesRemoved", m.path, modemInterfaces)
+}
+
+func (c *ModemConfig) setDefaults(idx int) {
+	if c.Manufacturer == "" {
+		c.Manufacturer = "Fake"
+	}
+	if c.Model == "" {
+		c.Model = "FakeModem 1000"
+	}
+	if c.Revision == "" {
+		c.Revision = "1.0.0"
+	}
+	if c.HardwareRevision == "" {
+		c.HardwareRevision = "1"
+	}
+	if c.DeviceIdentifier == "" {
+		c.DeviceIdentifier = fmt.Sprintf("%040x", idx+1)
+	}
+	if c.Device == "" {
+		c.Device = fmt.Sprintf("/sys/devices/fake/usb1/1-%d", idx+1)
+	}
+	if c.EquipmentIdentifier == "" {
+		c.EquipmentIdentifier = fmt.Sprintf("35000000%07d", idx+1)
+	}
+	if c.Plugin == "" {
+		c.Plugin = "Generic"
+	}
+	if c.PrimaryPort == "" {
+		c.PrimaryPort = fmt.Sprintf("cdc-wdm%d", idx)
+	}
+	if c.Drivers == nil {
+		c.Drivers = []string{"qmi_wwan"}
+	}
+	if c.AccessTechnologies == nil {
+		c.AccessTechnologies = []mm.MMModemAccessTechnology{mm.MmModemAccessTechnologyLte}
+	}
+	if c.SignalQuality == 0 {
+		c.SignalQuality = 75
+	}
+	if c.OwnNumbers == nil {
+		c.OwnNumbers = []string{}
+	}
+	if c.RegistrationState == mm.MmModem3gppRegistrationStateIdle {
+		c.RegistrationState = mm.MmModem3gppRegistrationStateHome
+	}
+	if c.OperatorCode == "" {
+		c.OperatorCode = "26201"
+	}
+	if c.OperatorName == "" {
+		c.OperatorName = "Fake Operator"
+	}
+	if c.MaxBearers == 0 {
+		c.MaxBearers = 4
+	}
+	if c.PrimarySimSlot == 0 || int(c.PrimarySimSlot) > len(c.SimSlots) {
+		c.PrimarySimSlot = 1
+	}
+}
+
+// interfaces returns all properties of all interfaces, as used by the object manager
+func (m *Modem) interfaces() map[string]map[string]dbus.Variant {
+	res := make(map[string]map[string]dbus.Variant)
+	for _, iface := range modemInterfaces {
+		res[iface] = m.getAll(iface)
+	}
+	return res
+}
+
+// Sim returns the inserted sim, nil if none
+func (m *Modem) Sim() *Sim {
+	return m.sim
+}
+
+// SimSlots returns the sims of all slots of a multi-SIM modem with nil for empty slots, empty otherwise
+func (m *Modem) SimSlots() []*Sim {
+	return append([]*Sim(nil), m.slotSims...)
+}
+
+// Bearers returns all bearers of the modem
+func (m *Modem) Bearers() []*Bearer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Bearer(nil), m.bearers...)
+}
+
+// Messages returns all sms of the modem
+func (m *Modem) Messages() []*Sms {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Sms(nil), m.messages...)
+}
+
+// Calls returns all calls of the modem
+func (m *Modem) Calls() []*Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Call(nil), m.calls...)
+}
+
+// State returns the current modem state
+func (m *Modem) State() mm.MMModemState {
+	return mm.MMModemState(m.GetProperty(mm.ModemInterface, "State").(int32))
+}
+
+// SetState updates the modem state and emits the StateChanged signal
+func (m *Modem) SetState(state mm.MMModemState, reason mm.MMModemStateChangeReason) {
+	old := m.State()
+	if old == state {
+		return
+	}
+	m.SetProperty(mm.ModemInterface, "State", int32(state))
+	m.srv.emit(m.path, mm.ModemInterface+"."+mm.ModemSignalStateChanged, int32(old), int32(state), uint32(reason))
+}
+
+// SetRegistration updates the 3gpp registration. If the modem is enabled, the modem state follows the
+// registration, e.g. a loss of registration disconnects all bearers and sets the state to searching.
+func (m *Modem) SetRegistration(state mm.MMModem3gppRegistrationState, operatorCode string, operatorName string) {
+	m.mu.Lock()
+	m.registrationState = state
+	m.operatorCode = operatorCode
+	m.operatorName = operatorName
+	m.mu.Unlock()
+	if m.State() >= mm.MmModemStateEnabled {
+		m.register()
+	}
+}
+
+// SetNetworkRejection sets the details of the last registration rejection
+func (m *Modem) SetNetworkRejection(rejection mm.NetworkRejection) {
+	m.SetProperty(mm.Modem3gppInterface, "NetworkRejection", map[string]dbus.Variant{
+		"error":             dbus.MakeVariant(uint32(rejection.Error)),
+		"operator-id":       dbus.MakeVariant(rejection.OperatorId),
+		"operator-name":     dbus.MakeVariant(rejection.OperatorName),
+		"access-technology": dbus.MakeVariant(uint32(rejection.AccessTechnology)),
+	})
+}
+
+// CarrierLock returns the carrier lock data last sent with SetCarrierLock
+func (m *Modem) CarrierLock() []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]byte(nil), m.carrierLock...)
+}
+
+// SetSignalQuality sets the signal quality in percent
+func (m *Modem) SetSignalQuality(quality uint32) {
+	m.SetProperty(mm.ModemInterface, "SignalQuality", signalQuality{quality, true})
+}
+
+// SetExtendedSignal sets the extended signal information of the access technology given by the type of sp.
+// Zero values are not set, see SetExtendedSignalValues.
+func (m *Modem) SetExtendedSignal(sp mm.SignalProperty) {
+	values := make(map[string]float64)
+	v := reflect.ValueOf(sp)
+	for i := 0; i < v.NumField(); i++ {
+		value, ok := v.Field(i).Interface().(float64)
+		if ok && value != 0 {
+			values[v.Type().Field(i).Tag.Get("json")] = value
+		}
+	}
+	m.SetExtendedSignalValues(sp.Type, values)
+}
+
+// SetExtendedSignalValues sets the extended signal information of the given access technology to the values by
+// their names, e.g. rssi or snr, including values of 0
+func (m *Modem) SetExtendedSignalValues(signalType mm.MMSignalPropertyType, values map[string]float64) {
+	names := map[mm.MMSignalPropertyType]string{
+		mm.MMSignalPropertyTypeCdma: "Cdma",
+		mm.MMSignalPropertyTypeEvdo: "Evdo",
+		mm.MMSignalPropertyTypeGsm:  "Gsm",
+		mm.MMSignalPropertyTypeUmts: "Umts",
+		mm.MMSignalPropertyTypeLte:  "Lte",
+		mm.MMSignalPropertyTypeNr5g: "Nr5g",
+	}
+	variants := make(map[string]dbus.Variant, len(values))
+	for name, value := range values {
+		variants[name] = dbus.MakeVariant(value)
+	}
+	m.SetProperty(mm.ModemSignalInterface, names[signalType], variants)
+}
+
+// SetCellInfo sets the serving and neighboring cells returned by GetCellInfo. Zero values are not set.
+func (m *Modem) SetCellInfo(cells []mm.CellInfo) {
+	var res []map[string]dbus.Variant
+	for _, cell := range cells {
+		values := map[string]dbus.Variant{"cell-type": dbus.MakeVariant(uint32(cell.GetCellType()))}
+		v := reflect.Indirect(reflect.ValueOf(cell))
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if field.IsZero() {
+				continue
+			}
+			var value interface{}
+			switch field.Kind() {
+			case reflect.Uint32:
+				value = uint32(field.Uint())
+			default:
+				value = field.Interface()
+			}
+			values[v.Type().Field(i).Tag.Get("json")] = dbus.MakeVariant(value)
+		}
+		res = append(res, values)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cellInfo = res
+}
+
+// SetAccessTechnologies sets the current access technologies
+func (m *Modem) SetAccessTechnologies(technologies ...mm.MMModemAccessTechnology) {
+	var tmp mm.MMModemAccessTechnology
+	m.SetProperty(mm.ModemInterface, "AccessTechnologies", tmp.SliceToBitmask(technologies))
+}
+
+// SetNetworks sets the results of a network scan
+func (m *Modem) SetNetworks(networks []mm.Network3Gpp) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.networks = networks
+}
+
+// SetUssdReply sets the network reply for the given ussd command
+func (m *Modem) SetUssdReply(command string, reply string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ussdReplies[command] = reply
+}
+
+// SetCommandReply sets the reply for the given AT command, defaults to OK
+func (m *Modem) SetCommandReply(cmd string, reply string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commandReplies[cmd] = reply
+}
+
+// SetLocation sets the current location, keyed by MMModemLocationSource, e.g. a string for MmModemLocationSourceGpsNmea
+func (m *Modem) SetLocation(location map[mm.MMModemLocationSource]interface{}) {
+	res := make(map[uint32]dbus.Variant)
+	for source, value := range location {
+		res[uint32(source)] = dbus.MakeVariant(value)
+	}
+	m.SetProperty(mm.ModemLocationInterface, "Location", res)
+}
+
+// SetNetworkTime sets the network time and emits the NetworkTimeChanged signal
+func (m *Modem) SetNetworkTime(t time.Time) {
+	m.mu.Lock()
+	m.networkTime = t
+	m.mu.Unlock()
+	m.srv.emit(m.path, mm.ModemTimeInterface+"."+mm.ModemTimeSignalNetworkTimeChanged, t.Format(time.RFC3339))
+}
+
+// ReceiveSms simulates a received sms and emits the Added signal
+func (m *Modem) ReceiveSms(number string, text string) (*Sms, error) {
+	sms, err := newSms(m.srv, m, map[string]dbus.Variant{
+		"number":    dbus.MakeVariant(number),
+		"text":      dbus.MakeVariant(text),
+		"storage":   dbus.MakeVariant(uint32(mm.MmSmsStorageMe)),
+		"timestamp": dbus.MakeVariant(time.Now().Format(time.RFC3339)),
+	}, mm.MmSmsStateReceived, mm.MmSmsPduTypeDeliver)
+	if err != nil {
+		return nil, err
+	}
+	m.addSms(sms, true)
+	return sms, nil
+}
+
+// ReceiveSmsData simulates a received data message, e.g. a segment of a concatenated message with its user data
+// header, and emits the Added signal
+func (m *Modem) ReceiveSmsData(number string, data []byte) (*Sms, error) {
+	sms, err := newSms(m.srv, m, map[string]dbus.Variant{
+		"number":    dbus.MakeVariant(number),
+		"data":      dbus.MakeVariant(data),
+		"storage":   dbus.MakeVariant(uint32(mm.MmSmsStorageMe)),
+		"timestamp": dbus.MakeVariant(time.Now().Format(time.RFC3339)),
+	}, mm.MmSmsStateReceived, mm.MmSmsPduTypeDeliver)
+	if err != nil {
+		return nil, err
+	}
+	m.addSms(sms, true)
+	return sms, nil
+}
+
+// IncomingCall simulates an incoming call and emits the CallAdded signal
+func (m *Modem) IncomingCall(number string) (*Call, error) {
+	c, err := newCall(m.srv, m, number, mm.MmCallDirectionIncoming, mm.MmCallStateRingingIn, mm.MmCallStateReasonIncomingNew)
+	if err != nil {
+		return nil, err
+	}
+	m.addCall(c)
+	return c, nil
+}
+
+// Remove removes the modem from the bus, same as Server.RemoveModem
+func (m *Modem) Remove() {
+	m.srv.RemoveModem(m)
+}
+
+func (m *Modem) checkState(min mm.MMModemState) *dbus.Error {
+	state := m.State()
+	if state == mm.MmModemStateLocked {
+		return newError(ErrorWrongState, "modem is locked")
+	}
+	if state < min {
+		return newError(ErrorWrongState, fmt.Sprintf("modem not %s yet", min))
+	}
+	return nil
+}
+
+func (m *Modem) updateUnlockRetries() {
+	if m.sim == nil {
+		return
+	}
+	m.SetProperty(mm.ModemInterface, "UnlockRetries", m.sim.retries())
+}
+
+func (m *Modem) lock(lock mm.MMModemLock) {
+	m.disconnectAll()
+	m.SetProperty(mm.ModemInterface, "UnlockRequired", uint32(lock))
+	m.updateUnlockRetries()
+	m.SetState(mm.MmModemStateLocked, mm.MmModemStateChangeReasonUnknown)
+}
+
+func (m *Modem) unlock() {
+	m.SetProperty(mm.ModemInterface, "UnlockRequired", uint32(mm.MmModemLockNone))
+	m.updateUnlockRetries()
+	if m.State() == mm.MmModemStateLocked {
+		m.SetState(mm.MmModemStateDisabled, mm.MmModemStateChangeReasonUnknown)
+	}
+}
+
+// register applies the configured registration to an enabled modem
+func (m *Modem) register() {
+	m.mu.Lock()
+	regState, opCode, opName := m.registrationState, m.operatorCode, m.operatorName
+	m.mu.Unlock()
+	registered := regState == mm.MmModem3gppRegistrationStateHome || regState == mm.MmModem3gppRegistrationStateRoaming ||
+		regState == mm.MmModem3gppRegistrationStateHomeCsfbNotPreferred || regState == mm.MmModem3gppRegistrationStateRoamingCsfbNotPreferred
+	if !registered {
+		opCode, opName = "", ""
+	}
+	packetServiceState := mm.MmModem3gppPacketServiceStateDetached
+	if registered {
+		packetServiceState = mm.MmModem3gppPacketServiceStateAttached
+	}
+	m.SetProperty(mm.Modem3gppInterface, "RegistrationState", uint32(regState))
+	m.SetProperty(mm.Modem3gppInterface, "OperatorCode", opCode)
+	m.SetProperty(mm.Modem3gppInterface, "OperatorName", opName)
+	m.SetProperty(mm.Modem3gppInterface, "PacketServiceState", uint32(packetServiceState))
+	state := m.State()
+	switch {
+	case registered && state < mm.MmModemStateRegistered:
+		m.SetState(mm.MmModemStateSearching, mm.MmModemStateChangeReasonUnknown)
+		m.SetState(mm.MmModemStateRegistered, mm.MmModemStateChangeReasonUnknown)
+	case !registered && state >= mm.MmModemStateRegistered:
+		m.disconnectAll()
+		m.SetState(mm.MmModemStateSearching, mm.MmModemStateChangeReasonUnknown)
+	case !registered && state == mm.MmModemStateEnabled:
+		m.SetState(mm.MmModemStateSearching, mm.MmModemStateChangeReasonUnknown)
+	}
+}
+
+func (m *Modem) enable() *dbus.Error {
+	state := m.State()
+	if state == mm.MmModemStateLocked || state == mm.MmModemStateFailed {
+		return newError(ErrorWrongState, fmt.Sprintf("cannot enable modem: %s", state))
+	}
+	if state >= mm.MmModemStateEnabled {
+		return nil
+	}
+	m.SetProperty(mm.ModemInterface, "PowerState", uint32(mm.MmModemPowerStateOn))
+	m.SetState(mm.MmModemStateEnabling, mm.MmModemStateChangeReasonUserRequested)
+	m.SetState(mm.MmModemStateEnabled, mm.MmModemStateChangeReasonUserRequested)
+	m.register()
+	return nil
+}
+
+func (m *Modem) disable() *dbus.Error {
+	state := m.State()
+	if state == mm.MmModemStateLocked || state == mm.MmModemStateFailed {
+		return newError(ErrorWrongState, fmt.Sprintf("cannot disable modem: %s", state))
+	}
+	if state <= mm.MmModemStateDisabled {
+		return nil
+	}
+	m.disconnectAll()
+	m.SetState(mm.MmModemStateDisabling, mm.MmModemStateChangeReasonUserRequested)
+	m.SetProperty(mm.Modem3gppInterface, "RegistrationState", uint32(mm.MmModem3gppRegistrationStateIdle))
+	m.SetProperty(mm.Modem3gppInterface, "OperatorCode", "")
+	m.SetProperty(mm.Modem3gppInterface, "OperatorName", "")
+	m.SetProperty(mm.Modem3gppInterface, "PacketServiceState", uint32(mm.MmModem3gppPacketServiceStateDetached))
+	m.SetProperty(mm.ModemInterface, "PowerState", uint32(mm.MmModemPowerStateLow))
+	m.SetState(mm.MmModemStateDisabled, mm.MmModemStateChangeReasonUserRequested)
+	return nil
+}
+
+func (m *Modem) disconnectAll() {
+	for _, b := range m.Bearers() {
+		b.disconnect()
+	}
+	m.bearerDisconnected()
+}
+
+func (m *Modem) bearerConnected() {
+	if m.State() == mm.MmModemStateRegistered {
+		m.SetState(mm.MmModemStateConnecting, mm.MmModemStateChangeReasonUserRequested)
+		m.SetState(mm.MmModemStateConnected, mm.MmModemStateChangeReasonUserRequested)
+	}
+}
+
+func (m *Modem) bearerDisconnected() {
+	for _, b := range m.Bearers() {
+		if b.IsConnected() {
+			return
+		}
+	}
+	if m.State() == mm.MmModemStateConnected {
+		m.SetState(mm.MmModemStateDisconnecting, mm.MmModemStateChangeReasonUserRequested)
+		m.SetState(mm.MmModemStateRegistered, mm.MmModemStateChangeReasonUserRequested)
+	}
+}
+
+func (m *Modem) findBearer(path dbus.ObjectPath) *Bearer {
+	for _, b := range m.Bearers() {
+		if b.path == path {
+			return b
+		}
+	}
+	return nil
+}
+
+func (m *Modem) addBearer(properties map[string]dbus.Variant) (*Bearer, *dbus.Error) {
+	m.mu.Lock()
+	count := uint32(len(m.bearers))
+	m.mu.Unlock()
+	if count >= m.maxBearers {
+		return nil, newError(ErrorTooMany, "reached maximum number of bearers")
+	}
+	b, err := newBearer(m.srv, m, properties)
+	if err != nil {
+		return nil, dbus.MakeFailedError(err)
+	}
+	m.mu.Lock()
+	m.bearers = append(m.bearers, b)
+	m.mu.Unlock()
+	m.updateBearers()
+	return b, nil
+}
+
+func (m *Modem) deleteBearer(path dbus.ObjectPath) *dbus.Error {
+	b := m.findBearer(path)
+	if b == nil {
+		return newError(ErrorNotFound, "bearer not found")
+	}
+	b.disconnect()
+	m.bearerDisconnected()
+	m.mu.Lock()
+	for idx := range m.bearers {
+		if m.bearers[idx] == b {
+			m.bearers = append(m.bearers[:idx], m.bearers[idx+1:]...)
+			break
+		}
+	}
+	m.mu.Unlock()
+	b.remove()
+	m.updateBearers()
+	return nil
+}
+
+func (m *Modem) updateBearers() {
+	paths := []dbus.ObjectPath{}
+	for _, b := range m.Bearers() {
+		paths = append(paths, b.path)
+	}
+	m.SetProperty(mm.ModemInterface, "Bearers", paths)
+}
+
+func (m *Modem) addSms(sms *Sms, received bool) {
+	m.mu.Lock()
+	m.messages = append(m.messages, sms)
+	m.mu.Unlock()
+	m.updateMessages()
+	m.srv.emit(m.path, mm.ModemMessagingInterface+"."+mm.ModemMessagingSignalAdded, sms.path, received)
+}
+
+func (m *Modem) updateMessages() {
+	paths := []dbus.ObjectPath{}
+	for _, sms := range m.Messages() {
+		paths = append(paths, sms.path)
+	}
+	m.SetProperty(mm.ModemMessagingInterface, "Messages", paths)
+}
+
+func (m *Modem) addCall(c *Call) {
+	m.mu.Lock()
+	m.calls = append(m.calls, c)
+	m.mu.Unlock()
+	m.updateCalls()
+	m.srv.emit(m.path, mm.ModemVoiceInterface+"."+mm.ModemVoiceSignalCallAdded, c.path)
+}
+
+func (m *Modem) updateCalls() {
+	paths := []dbus.ObjectPath{}
+	for _, c := range m.Calls() {
+		paths = append(paths, c.path)
+	}
+	m.SetProperty(mm.ModemVoiceInterface, "Calls", paths)
+}
+
+// modemIface implements org.freedesktop.ModemManager1.Modem
+type modemIface struct {
+	m *Modem
+}
+
+func (mi *modemIface) Enable(enable bool) *dbus.Error {
+	if err := mi.m.invoke(mm.ModemEnable, enable); err != nil {
+		return err
+	}
+	if enable {
+		return mi.m.enable()
+	}
+	return mi.m.disable()
+}
+
+func (mi *modemIface) ListBearers() ([]dbus.ObjectPath, *dbus.Error) {
+	if err := mi.m.invoke(mm.ModemInterface + ".ListBearers"); err != nil {
+		return nil, err
+	}
+	return mi.m.GetProperty(mm.ModemInterface, "Bearers").([]dbus.ObjectPath), nil
+}
+
+func (mi *modemIface) CreateBearer(properties map[string]dbus.Variant) (dbus.ObjectPath, *dbus.Error) {
+	if err := mi.m.invoke(mm.ModemCreateBearer, properties); err != nil {
+		return "/", err
+	}
+	b, err := mi.m.addBearer(properties)
+	if err != nil {
+		return "/", err
+	}
+	return b.path, nil
+}
+
+func (mi *modemIface) DeleteBearer(bearer dbus.ObjectPath) *dbus.Error {
+	if err := mi.m.invoke(mm.ModemDeleteBearer, bearer); err != nil {
+		return err
+	}
+	return mi.m.deleteBearer(bearer)
+}
+
+func (mi *modemIface) Reset() *dbus.Error {
+	if err := mi.m.invoke(mm.ModemReset); err != nil {
+		return err
+	}
+	mi.m.disconnectAll()
+	return nil
+}
+
+func (mi *modemIface) FactoryReset(code string) *dbus.Error {
+	return mi.m.invoke(mm.ModemFactoryReset, code)
+}
+
+func (mi *modemIface) SetPowerState(state uint32) *dbus.Error {
+	if err := mi.m.invoke(mm.ModemSetPowerState, state); err != nil {
+		return err
+	}
+	if mi.m.State() > mm.MmModemStateDisabled && mm.MMModemPowerState(state) != mm.MmModemPowerStateOn {
+		return newError(ErrorWrongState, "modem must be disabled")
+	}
+	mi.m.SetProperty(mm.ModemInterface, "PowerState", state)
+	return nil
+}
+
+func (mi *modemIface) SetCurrentCapabilities(capabilities uint32) *dbus.Error {
+	if err := mi.m.invoke(mm.ModemSetCurrentCapabilities, capabilities); err != nil {
+		return err
+	}
+	mi.m.SetProperty(mm.ModemInterface, "CurrentCapabilities", capabilities)
+	return nil
+}
+
+func (mi *modemIface) SetCurrentModes(mode modes) *dbus.Error {
+	if err := mi.m.invoke(mm.ModemSetCurrentModes, mode); err != nil {
+		return err
+	}
+	mi.m.SetProperty(mm.ModemInterface, "CurrentModes", mode)
+	return nil
+}
+
+func (mi *modemIface) SetCurrentBands(bands []uint32) *dbus.Error {
+	if err := mi.m.invoke(mm.ModemSetCurrentBands, bands); err != nil {
+		return err
+	}
+	mi.m.SetProperty(mm.ModemInterface, "CurrentBands", bands)
+	return nil
+}
+
+func (mi *modemIface) Command(cmd string, timeout uint32) (string, *dbus.Error) {
+	if err := mi.m.invoke(mm.ModemCommand, cmd, timeout); err != nil {
+		return "", err
+	}
+	mi.m.mu.Lock()
+	defer mi.m.mu.Unlock()
+	if reply, ok := mi.m.commandReplies[cmd]; ok {
+		return reply, nil
+	}
+	return "OK", nil
+}
+
+func (mi *modemIface) GetCellInfo() ([]map[string]dbus.Variant, *dbus.Error) {
+	m := mi.m
+	if err := m.invoke(mm.ModemGetCellInfo); err != nil {
+		return nil, err
+	}
+	if err := m.checkState(mm.MmModemStateEnabled); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]map[string]dbus.Variant{}, m.cellInfo...), nil
+}
+
+func (mi *modemIface) SetPrimarySimSlot(slot uint32) *dbus.Error {
+	m := mi.m
+	if err := m.invoke(mm.ModemSetPrimarySimSlot, slot); err != nil {
+		return err
+	}
+	if len(m.cfg.SimSlots) == 0 {
+		return newError(ErrorUnsupported, "multiple sim slots not supported")
+	}
+	if slot == 0 || int(slot) > len(m.cfg.SimSlots) {
+		return newError(ErrorInvalidArgs, fmt.Sprintf("invalid sim slot: %d", slot))
+	}
+	if slot == m.cfg.PrimarySimSlot {
+		return nil
+	}
+	// like ModemManager, the modem is reprobed and exposed with a new object path after replying
+	cfg := m.cfg
+	cfg.PrimarySimSlot = slot
+	go func() {
+		m.srv.RemoveModem(m)
+		_, _ = m.srv.AddModem(cfg)
+	}()
+	return nil
+}
+
+// simpleIface implements org.freedesktop.ModemManager1.Modem.Simple
+type simpleIface struct {
+	m *Modem
+}
+
+func (si *simpleIface) Connect(properties map[string]dbus.Variant) (dbus.ObjectPath, *dbus.Error) {
+	m := si.m
+	if err := m.invoke(mm.ModemSimpleConnect, properties); err != nil {
+		return "/", err
+	}
+	if m.State() == mm.MmModemStateLocked {
+		pin, ok := properties["pin"].Value().(string)
+		if !ok || m.sim == nil {
+			return "/", newError(ErrorSimPin, "SIM PIN required")
+		}
+		if err := m.sim.sendPin(pin); err != nil {
+			return "/", err
+		}
+	}
+	if err := m.enable(); err != nil {
+		return "/", err
+	}
+	if m.State() < mm.MmModemStateRegistered {
+		return "/", newError(ErrorNoNetwork, "no network")
+	}
+	bearerProperties := make(map[string]dbus.Variant)
+	for _, key := range bearerKeys {
+		if value, ok := properties[key]; ok {
+			bearerProperties[key] = value
+		}
+	}
+	apn, _ := properties["apn"].Value().(string)
+	var bearer *Bearer
+	for _, b := range m.Bearers() {
+		if b.apn() == apn {
+			bearer = b
+			break
+		}
+	}
+	if bearer == nil {
+		var err *dbus.Error
+		bearer, err = m.addBearer(bearerProperties)
+		if err != nil {
+			return "/", err
+		}
+	}
+	if !bearer.IsConnected() {
+		if err := m.invoke(mm.BearerConnect); err != nil {
+			return "/", err
+		}
+		bearer.connect()
+		m.bearerConnected()
+	}
+	return bearer.path, nil
+}
+
+func (si *simpleIface) Disconnect(bearer dbus.ObjectPath) *dbus.Error {
+	m := si.m
+	if err := m.invoke(mm.ModemSimpleDisconnect, bearer); err != nil {
+		return err
+	}
+	if bearer == "/" {
+		m.disconnectAll()
+		return nil
+	}
+	b := m.findBearer(bearer)
+	if b == nil {
+		return newError(ErrorNotFound, "bearer not found")
+	}
+	b.disconnect()
+	m.bearerDisconnected()
+	return nil
+}
+
+func (si *simpleIface) GetStatus() (map[string]dbus.Variant, *dbus.Error) {
+	m := si.m
+	if err := m.invoke(mm.ModemSimpleGetStatus); err != nil {
+		return nil, err
+	}
+	state := m.State()
+	status := map[string]dbus.Variant{
+		"state": dbus.MakeVariant(uint32(state)),
+	}
+	if state >= mm.MmModemStateRegistered {
+		status["signal-quality"] = dbus.MakeVariant(m.GetProperty(mm.ModemInterface, "SignalQuality").(signalQuality).Quality)
+		status["current-bands"] = dbus.MakeVariant(m.GetProperty(mm.ModemInterface, "CurrentBands"))
+		status["access-technologies"] = dbus.MakeVariant(m.GetProperty(mm.ModemInterface, "AccessTechnologies"))
+		status["m3gpp-registration-state"] = dbus.MakeVariant(m.GetProperty(mm.Modem3gppInterface, "RegistrationState"))
+		status["m3gpp-operator-code"] = dbus.MakeVariant(m.GetProperty(mm.Modem3gppInterface, "OperatorCode"))
+		status["m3gpp-operator-name"] = dbus.MakeVariant(m.GetProperty(mm.Modem3gppInterface, "OperatorName"))
+	}
+	return status, nil
+}
+
+// modem3gppIface implements org.freedesktop.ModemManager1.Modem.Modem3gpp
+type modem3gppIface struct {
+	m *Modem
+}
+
+func (mi *modem3gppIface) Register(operatorId string) *dbus.Error {
+	m := mi.m
+	if err := m.invoke(mm.Modem3gppRegister, operatorId); err != nil {
+		return err
+	}
+	if err := m.checkState(mm.MmModemStateEnabled); err != nil {
+		return err
+	}
+	if operatorId != "" {
+		m.mu.Lock()
+		name := operatorId
+		for _, network := range m.networks {
+			if network.OperatorCode == operatorId {
+				name = network.OperatorLong
+			}
+		}
+		m.operatorCode = operatorId
+		m.operatorName = name
+		m.mu.Unlock()
+	}
+	m.register()
+	return nil
+}
+
+func (mi *modem3gppIface) Scan() ([]map[string]dbus.Variant, *dbus.Error) {
+	m := mi.m
+	if err := m.invoke(mm.Modem3gppScan); err != nil {
+		return nil, err
+	}
+	if err := m.checkState(mm.MmModemStateEnabled); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := []map[string]dbus.Variant{}
+	for _, network := range m.networks {
+		res = append(res, map[string]dbus.Variant{
+			"status":            dbus.MakeVariant(uint32(network.Status)),
+			"operator-long":     dbus.MakeVariant(network.OperatorLong),
+			"operator-short":    dbus.MakeVariant(network.OperatorShort),
+			"operator-code":     dbus.MakeVariant(network.OperatorCode),
+			"access-technology": dbus.MakeVariant(uint32(network.AccessTechnology)),
+		})
+	}
+	return res, nil
+}
+
+func (mi *modem3gppIface) SetEpsUeModeOperation(mode uint32) *dbus.Error {
+	if err := mi.m.invoke(mm.Modem3gppSetEpsUeModeOperation, mode); err != nil {
+		return err
+	}
+	mi.m.SetProperty(mm.Modem3gppInterface, "EpsUeModeOperation", mode)
+	return nil
+}
+
+func (mi *modem3gppIface) SetInitialEpsBearerSettings(settings map[string]dbus.Variant) *dbus.Error {
+	if err := mi.m.invoke(mm.Modem3gppSetInitialEpsBearerSettings, settings); err != nil {
+		return err
+	}
+	mi.m.SetProperty(mm.Modem3gppInterface, "InitialEpsBearerSettings", settings)
+	return nil
+}
+
+func (mi *modem3gppIface) SetNr5gRegistrationSettings(settings map[string]dbus.Variant) *dbus.Error {
+	if err := mi.m.invoke(mm.Modem3gppSetNr5gRegistrationSettings, settings); err != nil {
+		return err
+	}
+	current := make(map[string]dbus.Variant)
+	for key, value := range mi.m.GetProperty(mm.Modem3gppInterface, "Nr5gRegistrationSettings").(map[string]dbus.Variant) {
+		current[key] = value
+	}
+	for key, value := range settings {
+		current[key] = value
+	}
+	mi.m.SetProperty(mm.Modem3gppInterface, "Nr5gRegistrationSettings", current)
+	return nil
+}
+
+func (mi *modem3gppIface) SetPacketServiceState(state uint32) *dbus.Error {
+	m := mi.m
+	if err := m.invoke(mm.Modem3gppSetPacketServiceState, state); err != nil {
+		return err
+	}
+	if err := m.checkState(mm.MmModemStateRegistered); err != nil {
+		return err
+	}
+	switch mm.MMModem3gppPacketServiceState(state) {
+	case mm.MmModem3gppPacketServiceStateAttached:
+	case mm.MmModem3gppPacketServiceStateDetached:
+		m.disconnectAll()
+	default:
+		return newError(ErrorInvalidArgs, fmt.Sprintf("invalid packet service state: %d", state))
+	}
+	m.SetProperty(mm.Modem3gppInterface, "PacketServiceState", state)
+	return nil
+}
+
+func (mi *modem3gppIface) DisableFacilityLock(properties facilityLock) *dbus.Error {
+	m := mi.m
+	if err := m.invoke(mm.Modem3gppDisableFacilityLock, properties); err != nil {
+		return err
+	}
+	facility := mm.MMModem3gppFacility(properties.Facility)
+	m.mu.Lock()
+	controlKey, ok := m.facilityLocks[facility]
+	if !ok {
+		m.mu.Unlock()
+		return newError(ErrorWrongState, fmt.Sprintf("facility lock not enabled: %s", facility))
+	}
+	if controlKey != properties.ControlKey {
+		m.mu.Unlock()
+		return newError(ErrorInvalidArgs, "wrong control key")
+	}
+	delete(m.facilityLocks, facility)
+	var facilities []mm.MMModem3gppFacility
+	for enabled := range m.facilityLocks {
+		facilities = append(facilities, enabled)
+	}
+	m.mu.Unlock()
+	m.SetProperty(mm.Modem3gppInterface, "EnabledFacilityLocks", facility.SliceToBitmask(facilities))
+	return nil
+}
+
+func (mi *modem3gppIface) SetCarrierLock(data []byte) *dbus.Error {
+	m := mi.m
+	if err := m.invoke(mm.Modem3gppSetCarrierLock, data); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.carrierLock = append([]byte(nil), data...)
+	m.mu.Unlock()
+	return nil
+}
+
+// ussdIface implements org.freedesktop.ModemManager1.Modem.Modem3gpp.Ussd
+type ussdIface struct {
+	m *Modem
+}
+
+func (ui *ussdIface) reply(command string) (string, *dbus.Error) {
+	m := ui.m
+	if err := m.checkState(mm.MmModemStateRegistered); err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	reply := m.ussdReplies[command]
+	m.mu.Unlock()
+	m.SetProperty(mm.Modem3gppUssdInterface, "State", uint32(mm.MmModem3gppUssdSessionStateIdle))
+	return reply, nil
+}
+
+func (ui *ussdIface) Initiate(command string) (string, *dbus.Error) {
+	if err := ui.m.invoke(mm.Modem3gppUssdInitiate, command); err != nil {
+		return "", err
+	}
+	return ui.reply(command)
+}
+
+func (ui *ussdIface) Respond(response string) (string, *dbus.Error) {
+	if err := ui.m.invoke(mm.Modem3gppUssdRespond, response); err != nil {
+		return "", err
+	}
+	return ui.reply(response)
+}
+
+func (ui *ussdIface) Cancel() *dbus.Error {
+	if err := ui.m.invoke(mm.Modem3gppUssdCancel); err != nil {
+		return err
+	}
+	ui.m.SetProperty(mm.Modem3gppUssdInterface, "State", uint32(mm.MmModem3gppUssdSessionStateIdle))
+	return nil
+}
+
+// SetProfiles replaces the profiles stored in the modem and emits Updated
+func (m *Modem) SetProfiles(profiles []mm.Profile) {
+	m.mu.Lock()
+	m.profiles = nil
+	for _, p := range profiles {
+		m.profiles = append(m.profiles, profileToMap(p))
+	}
+	m.mu.Unlock()
+	m.srv.emit(m.path, mm.Modem3gppProfileManagerInterface+"."+mm.Modem3gppProfileManagerSignalUpdated)
+}
+
+func profileToMap(p mm.Profile) map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"profile-id":             dbus.MakeVariant(p.ProfileId),
+		"profile-name":           dbus.MakeVariant(p.ProfileName),
+		"apn":                    dbus.MakeVariant(p.APN),
+		"apn-type":               dbus.MakeVariant(uint32(p.ApnType)),
+		"ip-type":                dbus.MakeVariant(uint32(p.IPType)),
+		"allowed-auth":           dbus.MakeVariant(uint32(p.AllowedAuth)),
+		"user":                   dbus.MakeVariant(p.User),
+		"password":               dbus.MakeVariant(p.Password),
+		"access-type-preference": dbus.MakeVariant(uint32(p.AccessTypePreference)),
+		"enabled":                dbus.MakeVariant(p.Enabled),
+	}
+}
+
+// profileManagerIface implements org.freedesktop.ModemManager1.Modem.Modem3gpp.ProfileManager
+type profileManagerIface struct {
+	m *Modem
+}
+
+func (pi *profileManagerIface) List() ([]map[string]dbus.Variant, *dbus.Error) {
+	if err := pi.m.invoke(mm.Modem3gppProfileManagerList); err != nil {
+		return nil, err
+	}
+	pi.m.mu.Lock()
+	defer pi.m.mu.Unlock()
+	return append([]map[string]dbus.Variant{}, pi.m.profiles...), nil
+}
+
+func (pi *profileManagerIface) Set(requested map[string]dbus.Variant) (map[string]dbus.Variant, *dbus.Error) {
+	m := pi.m
+	if err := m.invoke(mm.Modem3gppProfileManagerSet, requested); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	id, ok := requested["profile-id"].Value().(int32)
+	var stored map[string]dbus.Variant
+	if ok {
+		for _, p := range m.profiles {
+			if p["profile-id"].Value().(int32) == id {
+				stored = p
+			}
+		}
+		if stored == nil {
+			m.mu.Unlock()
+			return nil, newError(ErrorNotFound, fmt.Sprintf("profile %d not found", id))
+		}
+	} else {
+		// new profiles get the lowest free id, starting at 1
+		for id = 1; ; id++ {
+			used := false
+			for _, p := range m.profiles {
+				used = used || p["profile-id"].Value().(int32) == id
+			}
+			if !used {
+				break
+			}
+		}
+		stored = profileToMap(mm.Profile{ProfileId: id, Enabled: true})
+		m.profiles = append(m.profiles, stored)
+	}
+	for key, value := range requested {
+		if _, known := stored[key]; known {
+			stored[key] = value
+		}
+	}
+	result := make(map[string]dbus.Variant, len(stored))
+	for key, value := range stored {
+		result[key] = value
+	}
+	m.mu.Unlock()
+	return result, nil
+}
+
+func (pi *profileManagerIface) Delete(properties map[string]dbus.Variant) *dbus.Error {
+	m := pi.m
+	if err := m.invoke(mm.Modem3gppProfileManagerDelete, properties); err != nil {
+		return err
+	}
+	id, ok := properties["profile-id"].Value().(int32)
+	if !ok {
+		return newError(ErrorInvalidArgs, "missing profile-id")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, p := range m.profiles {
+		if p["profile-id"].Value().(int32) == id {
+			m.profiles = append(m.profiles[:i], m.profiles[i+1:]...)
+			return nil
+		}
+	}
+	return newError(ErrorNotFound, fmt.Sprintf("profile %d not found", id))
+}
+
+// messagingIface implements org.freedesktop.ModemManager1.Modem.Messaging
+type messagingIface struct {
+	m *Modem
+}
+
+func (mi *messagingIface) List() ([]dbus.ObjectPath, *dbus.Error) {
+	if err := mi.m.invoke(mm.ModemMessagingList); err != nil {
+		return nil, err
+	}
+	return mi.m.GetProperty(mm.ModemMessagingInterface, "Messages").([]dbus.ObjectPath), nil
+}
+
+func (mi *messagingIface) Delete(path dbus.ObjectPath) *dbus.Error {
+	m := mi.m
+	if err := m.invoke(mm.ModemMessagingDelete, path); err != nil {
+		return err
+	}
+	var sms *Sms
+	m.mu.Lock()
+	for idx := range m.messages {
+		if m.messages[idx].path == path {
+			sms = m.messages[idx]
+			m.messages = append(m.messages[:idx], m.messages[idx+1:]...)
+			break
+		}
+	}
+	m.mu.Unlock()
+	if sms == nil {
+		return newError(ErrorNotFound, "sms not found")
+	}
+	sms.remove()
+	m.updateMessages()
+	m.srv.emit(m.path, mm.ModemMessagingInterface+"."+mm.ModemMessagingSignalDeleted, path)
+	return nil
+}
+
+func (mi *messagingIface) Create(properties map[string]dbus.Variant) (dbus.ObjectPath, *dbus.Error) {
+	m := mi.m
+	if err := m.invoke(mm.ModemMessagingCreate, properties); err != nil {
+		return "/", err
+	}
+	if _, ok := properties["number"]; !ok {
+		return "/", newError(ErrorInvalidArgs, "missing number")
+	}
+	_, hasText := properties["text"]
+	_, hasData := properties["data"]
+	if !hasText && !hasData {
+		return "/", newError(ErrorInvalidArgs, "missing text or data")
+	}
+	sms, err := newSms(m.srv, m, properties, mm.MmSmsStateUnknown, mm.MmSmsPduTypeSubmit)
+	if err != nil {
+		return "/", dbus.MakeFailedError(err)
+	}
+	m.addSms(sms, false)
+	return sms.path, nil
+}
+
+// voiceIface implements org.freedesktop.ModemManager1.Modem.Voice
+type voiceIface struct {
+	m *Modem
+}
+
+func (vi *voiceIface) ListCalls() ([]dbus.ObjectPath, *dbus.Error) {
+	if err := vi.m.invoke(mm.ModemVoiceListCalls); err != nil {
+		return nil, err
+	}
+	return vi.m.GetProperty(mm.ModemVoiceInterface, "Calls").([]dbus.ObjectPath), nil
+}
+
+func (vi *voiceIface) DeleteCall(path dbus.ObjectPath) *dbus.Error {
+	m := vi.m
+	if err := m.invoke(mm.ModemVoiceDeleteCall, path); err != nil {
+		return err
+	}
+	var c *Call
+	m.mu.Lock()
+	for idx := range m.calls {
+		if m.calls[idx].path == path {
+			c = m.calls[idx]
+			m.calls = append(m.calls[:idx], m.calls[idx+1:]...)
+			break
+		}
+	}
+	m.mu.Unlock()
+	if c == nil {
+		return newError(ErrorNotFound, "call not found")
+	}
+	c.remove()
+	m.updateCalls()
+	m.srv.emit(m.path, mm.ModemVoiceInterface+"."+mm.ModemVoiceSignalCallDeleted, path)
+	return nil
+}
+
+func (vi *voiceIface) CreateCall(properties map[string]dbus.Variant) (dbus.ObjectPath, *dbus.Error) {
+	m := vi.m
+	if err := m.invoke(mm.ModemVoiceCreateCall, properties); err != nil {
+		return "/", err
+	}
+	number, ok := properties["number"].Value().(string)
+	if !ok {
+		return "/", newError(ErrorInvalidArgs, "missing number")
+	}
+	c, err := newCall(m.srv, m, number, mm.MmCallDirectionOutgoing, mm.MmCallStateUnknown, mm.MmCallStateReasonUnknown)
+	if err != nil {
+		return "/", dbus.MakeFailedError(err)
+	}
+	m.addCall(c)
+	return c.path, nil
+}
+
+func (vi *voiceIface) HoldAndAccept() *dbus.Error {
+	if err := vi.m.invoke(mm.ModemVoiceHoldAndAccept); err != nil {
+		return err
+	}
+	for _, c := range vi.m.Calls() {
+		switch c.State() {
+		case mm.MmCallStateActive:
+			c.SetState(mm.MmCallStateHeld, mm.MmCallStateReasonUnknown)
+		case mm.MmCallStateWaiting, mm.MmCallStateRingingIn, mm.MmCallStateHeld:
+			c.SetState(mm.MmCallStateActive, mm.MmCallStateReasonAccepted)
+		}
+	}
+	return nil
+}
+
+func (vi *voiceIface) HangupAndAccept() *dbus.Error {
+	if err := vi.m.invoke(mm.ModemVoiceHangupAndAccept); err != nil {
+		return err
+	}
+	for _, c := range vi.m.Calls() {
+		switch c.State() {
+		case mm.MmCallStateActive:
+			c.SetState(mm.MmCallStateTerminated, mm.MmCallStateReasonTerminated)
+		case mm.MmCallStateWaiting, mm.MmCallStateRingingIn, mm.MmCallStateHeld:
+			c.SetState(mm.MmCallStateActive, mm.MmCallStateReasonAccepted)
+		}
+	}
+	return nil
+}
+
+func (vi *voiceIface) HangupAll() *dbus.Error {
+	if err := vi.m.invoke(mm.ModemVoiceHangupAll); err != nil {
+		return err
+	}
+	for _, c := range vi.m.Calls() {
+		if c.State() != mm.MmCallStateTerminated {
+			c.SetState(mm.MmCallStateTerminated, mm.MmCallStateReasonTerminated)
+		}
+	}
+	return nil
+}
+
+func (vi *voiceIface) Transfer() *dbus.Error {
+	if err := vi.m.invoke(mm.ModemVoiceTransfer); err != nil {
+		return err
+	}
+	for _, c := range vi.m.Calls() {
+		if c.State() == mm.MmCallStateActive || c.State() == mm.MmCallStateHeld {
+			c.SetState(mm.MmCallStateTerminated, mm.MmCallStateReasonTransferred)
+		}
+	}
+	return nil
+}
+
+func (vi *voiceIface) CallWaitingSetup(enable bool) *dbus.Error {
+	if err := vi.m.invoke(mm.ModemVoiceCallWaitingSetup, enable); err != nil {
+		return err
+	}
+	vi.m.mu.Lock()
+	defer vi.m.mu.Unlock()
+	vi.m.callWaiting = enable
+	return nil
+}
+
+func (vi *voiceIface) CallWaitingQuery() (bool, *dbus.Error) {
+	if err := vi.m.invoke(mm.ModemVoiceCallWaitingQuery); err != nil {
+		return false, err
+	}
+	vi.m.mu.Lock()
+	defer vi.m.mu.Unlock()
+	return vi.m.callWaiting, nil
+}
+
+// signalIface implements org.freedesktop.ModemManager1.Modem.Signal
+type signalIface struct {
+	m *Modem
+}
+
+func (si *signalIface) Setup(rate uint32) *dbus.Error {
+	if err := si.m.invoke(mm.ModemSignalSetup, rate); err != nil {
+		return err
+	}
+	si.m.SetProperty(mm.ModemSignalInterface, "Rate", rate)
+	return nil
+}
+
+func (si *signalIface) SetupThresholds(settings map[string]dbus.Variant) *dbus.Error {
+	if err := si.m.invoke(mm.ModemSignalSetupThresholds, settings); err != nil {
+		return err
+	}
+	if rssi, ok := settings["rssi-threshold"].Value().(uint32); ok {
+		si.m.SetProperty(mm.ModemSignalInterface, "RssiThreshold", rssi)
+	}
+	if errorRate, ok := settings["error-rate-threshold"].Value().(bool); ok {
+		si.m.SetProperty(mm.ModemSignalInterface, "ErrorRateThreshold", errorRate)
+	}
+	return nil
+}
+
+// sarIface implements org.freedesktop.ModemManager1.Modem.Sar
+type sarIface struct {
+	m *Modem
+}
+
+func (si *sarIface) Enable(enable bool) *dbus.Error {
+	if err := si.m.invoke(mm.ModemSarEnable, enable); err != nil {
+		return err
+	}
+	si.m.SetProperty(mm.ModemSarInterface, "State", enable)
+	return nil
+}
+
+func (si *sarIface) SetPowerLevel(level uint32) *dbus.Error {
+	if err := si.m.invoke(mm.ModemSarSetPowerLevel, level); err != nil {
+		return err
+	}
+	si.m.SetProperty(mm.ModemSarInterface, "PowerLevel", level)
+	return nil
+}
+
+// locationIface implements org.freedesktop.ModemManager1.Modem.Location
+type locationIface struct {
+	m *Modem
+}
+
+func (li *locationIface) Setup(sources uint32, signalLocation bool) *dbus.Error {
+	m := li.m
+	if err := m.invoke(mm.ModemLocationSetup, sources, signalLocation); err != nil {
+		return err
+	}
+	capabilities := m.GetProperty(mm.ModemLocationInterface, "Capabilities").(uint32)
+	if sources&^capabilities != 0 {
+		return newError(ErrorUnsupported, "location source not supported")
+	}
+	m.SetProperty(mm.ModemLocationInterface, "Enabled", sources)
+	m.SetProperty(mm.ModemLocationInterface, "SignalsLocation", signalLocation)
+	return nil
+}
+
+func (li *locationIface) GetLocation() (map[uint32]dbus.Variant, *dbus.Error) {
+	if err := li.m.invoke(mm.ModemLocationGetLocation); err != nil {
+		return nil, err
+	}
+	location := li.m.GetProperty(mm.ModemLocationInterface, "Location").(map[uint32]dbus.Variant)
+	enabled := li.m.GetProperty(mm.ModemLocationInterface, "Enabled").(uint32)
+	res := make(map[uint32]dbus.Variant)
+	for source, value := range location {
+		if source&enabled != 0 {
+			res[source] = value
+		}
+	}
+	return res, nil
+}
+
+func (li *locationIface) SetSuplServer(supl string) *dbus.Error {
+	if err := li.m.invoke(mm.ModemLocationSetSuplServer, supl); err != nil {
+		return err
+	}
+	li.m.SetProperty(mm.ModemLocationInterface, "SuplServer", supl)
+	return nil
+}
+
+func (li *locationIface) InjectAssistanceData(data []byte) *dbus.Error {
+	if err := li.m.invoke(mm.ModemLocationInjectAssistanceData, data); err != nil {
+		return err
+	}
+	if li.m.GetProperty(mm.ModemLocationInterface, "SupportedAssistanceData").(uint32) == 0 {
+		return newError(ErrorUnsupported, "assistance data not supported")
+	}
+	return nil
+}
+
+func (li *locationIface) SetGpsRefreshRate(rate uint32) *dbus.Error {
+	if err := li.m.invoke(mm.ModemLocationSetGpsRefreshRate, rate); err != nil {
+		return err
+	}
+	li.m.SetProperty(mm.ModemLocationInterface, "GpsRefreshRate", rate)
+	return nil
+}
+
+// timeIface implements org.freedesktop.ModemManager1.Modem.Time
+type timeIface struct {
+	m *Modem
+}
+
+func (ti *timeIface) GetNetworkTime() (string, *dbus.Error) {
+	if err := ti.m.invoke(mm.ModemTimeGetNetworkTime); err != nil {
+		return "", err
+	}
+	if err := ti.m.checkState(mm.MmModemStateRegistered); err != nil {
+		return "", err
+	}
+	ti.m.mu.Lock()
+	t := ti.m.networkTime
+	ti.m.mu.Unlock()
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.Format(time.RFC3339), nil
+}
@@ -0,0 +1,404 @@
+// Package mmtest provides an in-process fake ModemManager, exporting the org.freedesktop.ModemManager1 object
+// tree (manager, modems, SIMs, bearers, SMS and calls) on a private bus. It allows to test code built on top of
+// the modemmanager package without a real modem.
+//
+// Method results and state transitions can be scripted with OnCall/SetError and the setters of each object.
+package mmtest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+const (
+	dbusPropertiesInterface    = "org.freedesktop.DBus.Properties"
+	dbusObjectManagerInterface = "org.freedesktop.DBus.ObjectManager"
+
+	// DefaultVersion is the ModemManager version reported by the fake
+	DefaultVersion = "1.12.8"
+)
+
+// Error names as sent by ModemManager, which are used by the fake
+const (
+	ErrorFailed            = mm.ModemManagerErrorCore + ".Failed"
+	ErrorWrongState        = mm.ModemManagerErrorCore + ".WrongState"
+	ErrorNotFound          = mm.ModemManagerErrorCore + ".NotFound"
+	ErrorUnsupported       = mm.ModemManagerErrorCore + ".Unsupported"
+	ErrorInvalidArgs       = mm.ModemManagerErrorCore + ".InvalidArgs"
+	ErrorUnauthorized      = mm.ModemManagerErrorCore + ".Unauthorized"
+	ErrorTooMany           = mm.ModemManagerErrorCore + ".TooMany"
+	ErrorIncorrectPassword = mm.ModemManagerErrorMobileEquipment + ".IncorrectPassword"
+	ErrorSimPin            = mm.ModemManagerErrorMobileEquipment + ".SimPin"
+	ErrorSimPuk            = mm.ModemManagerErrorMobileEquipment + ".SimPuk"
+	ErrorNoNetwork         = mm.ModemManagerErrorMobileEquipment + ".NoNetwork"
+)
+
+// NewError returns a dbus error with the given name and message, e.g. to be returned by a Handler
+func NewError(name string, message string) error {
+	return newError(name, message)
+}
+
+func newError(name string, message string) *dbus.Error {
+	return dbus.NewError(name, []interface{}{message})
+}
+
+// Handler is called on every method call of the given object and method, before the default behaviour is executed.
+// Returning an error aborts the call and sends the error to the caller. Typed errors of the modemmanager package
+// (e.g. modemmanager.MmCoreErrorWrongState) are sent with their ModemManager error name, other errors which
+// are not of type dbus.Error are sent as org.freedesktop.DBus.Error.Failed.
+type Handler func(args ...interface{}) error
+
+// MethodCall represents a recorded method call
+type MethodCall struct {
+	Path   dbus.ObjectPath // object path of the called object
+	Method string          // full method name, e.g. modemmanager.ModemEnable
+	Args   []interface{}   // arguments as received
+}
+
+func (mc MethodCall) String() string {
+	return fmt.Sprint(mc.Path) + " " + mc.Method + " " + fmt.Sprint(mc.Args)
+}
+
+type hookKey struct {
+	path   dbus.ObjectPath
+	method string
+}
+
+// Server represents the fake ModemManager daemon
+type Server struct {
+	conn  *dbus.Conn
+	bus   *Bus
+	props *prop.Properties
+
+	mu       sync.Mutex
+	hooks    map[hookKey]Handler
+	calls    []MethodCall
+	modems   map[dbus.ObjectPath]*Modem
+	bearers  map[dbus.ObjectPath]*Bearer
+	sims     map[dbus.ObjectPath]*Sim
+	sms      map[dbus.ObjectPath]*Sms
+	voice    map[dbus.ObjectPath]*Call
+	counters map[string]int
+}
+
+// Start launches a private bus and exports a fake ModemManager on it. Closing the server also stops the bus.
+func Start() (*Server, error) {
+	bus, err := StartBus()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := bus.Connect()
+	if err != nil {
+		bus.Close()
+		return nil, err
+	}
+	srv, err := NewServer(conn)
+	if err != nil {
+		conn.Close()
+		bus.Close()
+		return nil, err
+	}
+	srv.bus = bus
+	return srv, nil
+}
+
+// NewServer exports a fake ModemManager on the given connection and acquires the org.freedesktop.ModemManager1 name.
+func NewServer(conn *dbus.Conn) (*Server, error) {
+	if conn == nil {
+		return nil, errors.New("no dbus connection given")
+	}
+	srv := &Server{
+		conn:     conn,
+		hooks:    make(map[hookKey]Handler),
+		modems:   make(map[dbus.ObjectPath]*Modem),
+		bearers:  make(map[dbus.ObjectPath]*Bearer),
+		sims:     make(map[dbus.ObjectPath]*Sim),
+		sms:      make(map[dbus.ObjectPath]*Sms),
+		voice:    make(map[dbus.ObjectPath]*Call),
+		counters: make(map[string]int),
+	}
+	var err error
+	srv.props, err = prop.Export(conn, mm.ModemManagerObjectPath, map[string]map[string]*prop.Prop{
+		mm.ModemManagerInterface: {
+			"Version": {Value: DefaultVersion, Emit: prop.EmitTrue},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Export(&manager{srv: srv}, mm.ModemManagerObjectPath, mm.ModemManagerInterface)
+	if err != nil {
+		return nil, err
+	}
+	err = conn.Export(&objectManager{srv: srv}, mm.ModemManagerObjectPath, dbusObjectManagerInterface)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.RequestName(mm.ModemManagerInterface, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return nil, err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return nil, errors.New("name " + mm.ModemManagerInterface + " already taken")
+	}
+	return srv, nil
+}
+
+// Conn returns the connection the fake is exported on
+func (s *Server) Conn() *dbus.Conn {
+	return s.conn
+}
+
+// Connect opens a new client connection to the private bus. Only available if the server was created by Start.
+func (s *Server) Connect() (*dbus.Conn, error) {
+	if s.bus == nil {
+		return nil, errors.New("server was not started with a private bus")
+	}
+	return s.bus.Connect()
+}
+
+// Close releases the name and closes the connection, and stops the private bus if started by Start.
+func (s *Server) Close() error {
+	s.conn.ReleaseName(mm.ModemManagerInterface)
+	err := s.conn.Close()
+	if s.bus != nil {
+		s.bus.Close()
+	}
+	return err
+}
+
+// SetVersion sets the reported ModemManager version
+func (s *Server) SetVersion(version string) {
+	s.props.SetMust(mm.ModemManagerInterface, "Version", version)
+}
+
+// OnCall registers a handler for the given object path and method (e.g. modemmanager.ModemEnable).
+// A nil handler removes a registered one.
+func (s *Server) OnCall(path dbus.ObjectPath, method string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if h == nil {
+		delete(s.hooks, hookKey{path, method})
+		return
+	}
+	s.hooks[hookKey{path, method}] = h
+}
+
+// SetError lets every call of the given method on the given object fail with err. A nil err removes it.
+func (s *Server) SetError(path dbus.ObjectPath, method string, err error) {
+	if err == nil {
+		s.OnCall(path, method, nil)
+		return
+	}
+	s.OnCall(path, method, func(args ...interface{}) error {
+		return err
+	})
+}
+
+// Calls returns all recorded method calls in order
+func (s *Server) Calls() []MethodCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]MethodCall(nil), s.calls...)
+}
+
+// ResetCalls clears the recorded method calls
+func (s *Server) ResetCalls() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+}
+
+// Modems returns all exported modems
+func (s *Server) Modems() []*Modem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var modems []*Modem
+	for _, m := range s.modems {
+		modems = append(modems, m)
+	}
+	return modems
+}
+
+// Modem returns the modem exported at the given path, if any
+func (s *Server) Modem(path dbus.ObjectPath) *Modem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.modems[path]
+}
+
+// Bearer returns the bearer exported at the given path, if any
+func (s *Server) Bearer(path dbus.ObjectPath) *Bearer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bearers[path]
+}
+
+// Sim returns the sim exported at the given path, if any
+func (s *Server) Sim(path dbus.ObjectPath) *Sim {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sims[path]
+}
+
+// Sms returns the sms exported at the given path, if any
+func (s *Server) Sms(path dbus.ObjectPath) *Sms {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sms[path]
+}
+
+// Call returns the call exported at the given path, if any
+func (s *Server) Call(path dbus.ObjectPath) *Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.voice[path]
+}
+
+// nextPath returns the next free object path of the given kind, e.g. Modem -> /org/freedesktop/ModemManager1/Modem/0
+func (s *Server) nextPath(kind string) dbus.ObjectPath {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := s.counters[kind]
+	s.counters[kind] = idx + 1
+	return dbus.ObjectPath(fmt.Sprintf("%s/%s/%d", mm.ModemManagerObjectPath, kind, idx))
+}
+
+// invoke records the call and runs a registered handler
+func (s *Server) invoke(path dbus.ObjectPath, method string, args ...interface{}) *dbus.Error {
+	s.mu.Lock()
+	s.calls = append(s.calls, MethodCall{Path: path, Method: method, Args: args})
+	h := s.hooks[hookKey{path, method}]
+	s.mu.Unlock()
+	if h == nil {
+		return nil
+	}
+	return toDbusError(h(args...))
+}
+
+func (s *Server) emit(path dbus.ObjectPath, name string, values ...interface{}) {
+	s.conn.Emit(path, name, values...)
+}
+
+// toDbusError converts err into a dbus error. The typed errors of the modemmanager package are sent with their
+// ModemManager error name, e.g. modemmanager.MmMobileEquipmentErrorSimPin as
+// org.freedesktop.ModemManager1.Error.MobileEquipment.SimPin.
+func toDbusError(err error) *dbus.Error {
+	if err == nil {
+		return nil
+	}
+	switch e := err.(type) {
+	case *dbus.Error:
+		return e
+	case dbus.Error:
+		return &e
+	case *mm.ModemManagerError:
+		return newError(e.Name, e.Message)
+	case mm.MMCoreError:
+		return newError(mm.ModemManagerErrorCore+"."+strings.TrimPrefix(e.String(), "MmCoreError"), e.Error())
+	case mm.MMMobileEquipmentError:
+		return newError(mm.ModemManagerErrorMobileEquipment+"."+strings.TrimPrefix(e.String(), "MmMobileEquipmentError"), e.Error())
+	case mm.MMConnectionError:
+		return newError(mm.ModemManagerErrorConnection+"."+strings.TrimPrefix(e.String(), "MmConnectionError"), e.Error())
+	case mm.MMSerialError:
+		return newError(mm.ModemManagerErrorSerial+"."+strings.TrimPrefix(e.String(), "MmSerialError"), e.Error())
+	case mm.MMMessageError:
+		return newError(mm.ModemManagerErrorMessage+"."+strings.TrimPrefix(e.String(), "MmMessageError"), e.Error())
+	case mm.MMCdmaActivationError:
+		return newError(mm.ModemManagerErrorCdmaActivation+"."+e.String(), e.Error())
+	}
+	return dbus.MakeFailedError(err)
+}
+
+// object is the base of all exported fake objects
+type object struct {
+	srv   *Server
+	path  dbus.ObjectPath
+	props *prop.Properties
+}
+
+func (o *object) export(props map[string]map[string]*prop.Prop, methods map[string]interface{}) (err error) {
+	o.props, err = prop.Export(o.srv.conn, o.path, props)
+	if err != nil {
+		return err
+	}
+	for iface, v := range methods {
+		err = o.srv.conn.Export(v, o.path, iface)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *object) unexport(ifaces ...string) {
+	o.srv.conn.Export(nil, o.path, dbusPropertiesInterface)
+	for _, iface := range ifaces {
+		o.srv.conn.Export(nil, o.path, iface)
+	}
+}
+
+func (o *object) invoke(method string, args ...interface{}) *dbus.Error {
+	return o.srv.invoke(o.path, method, args...)
+}
+
+// GetObjectPath returns the object path
+func (o *object) GetObjectPath() dbus.ObjectPath {
+	return o.path
+}
+
+// GetProperty returns the current value of the given property, e.g. (modemmanager.ModemInterface, "Model")
+func (o *object) GetProperty(iface string, name string) interface{} {
+	return o.props.GetMust(iface, name)
+}
+
+// SetProperty sets the given property and emits PropertiesChanged. The value must have the D-Bus type as
+// described in the ModemManager API, e.g. int32 for the modem state.
+func (o *object) SetProperty(iface string, name string, value interface{}) {
+	o.props.SetMust(iface, name, value)
+}
+
+func (o *object) getAll(iface string) map[string]dbus.Variant {
+	res, _ := o.props.GetAll(iface)
+	return res
+}
+
+// manager implements org.freedesktop.ModemManager1
+type manager struct {
+	srv *Server
+}
+
+func (m *manager) ScanDevices() *dbus.Error {
+	return m.srv.invoke(mm.ModemManagerObjectPath, mm.ModemManagerScanDevices)
+}
+
+func (m *manager) SetLogging(level string) *dbus.Error {
+	return m.srv.invoke(mm.ModemManagerObjectPath, mm.ModemManagerSetLogging, level)
+}
+
+func (m *manager) ReportKernelEvent(properties map[string]dbus.Variant) *dbus.Error {
+	return m.srv.invoke(mm.ModemManagerObjectPath, mm.ModemManagerReportKernelEvent, properties)
+}
+
+func (m *manager) InhibitDevice(uid string, inhibit bool) *dbus.Error {
+	return m.srv.invoke(mm.ModemManagerObjectPath, mm.ModemManagerInhibitDevice, uid, inhibit)
+}
+
+// objectManager implements org.freedesktop.DBus.ObjectManager
+type objectManager struct {
+	srv *Server
+}
+
+func (om *objectManager) GetManagedObjects() (map[dbus.ObjectPath]map[string]map[string]dbus.Variant, *dbus.Error) {
+	res := make(map[dbus.ObjectPath]map[string]map[string]dbus.Variant)
+	for _, m := range om.srv.Modems() {
+		res[m.path] = m.interfaces()
+	}
+	return res, nil
+}
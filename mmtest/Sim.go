@@ -0,0 +1,243 @@
+package mmtest
+
+import (
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// SimConfig defines the initial state of a fake sim. Empty values are replaced by defaults.
+type SimConfig struct {
+	SimIdentifier      string               // ICCID, defaults to 8949000000000000001
+	Imsi               string               // defaults to 262010000000001
+	OperatorIdentifier string               // defaults to 26201
+	OperatorName       string               // defaults to Fake Operator
+	EmergencyNumbers   []string             // defaults to 112 and 911
+	Pin                string               // the correct pin, defaults to 1234
+	Puk                string               // the correct puk, defaults to 12345678
+	Locked             bool                 // if true, the modem starts in state locked and requires the pin
+	PinRetries         uint32               // remaining pin attempts, defaults to 3
+	SimType            mm.MMSimType         // defaults to physical
+	Eid                string               // defaults to none
+	Removability       mm.MMSimRemovability // defaults to removable
+	Empty              bool                 // if true, the slot has no sim, only used in ModemConfig.SimSlots
+}
+
+func (c *SimConfig) setDefaults() {
+	if c.SimIdentifier == "" {
+		c.SimIdentifier = "8949000000000000001"
+	}
+	if c.Imsi == "" {
+		c.Imsi = "262010000000001"
+	}
+	if c.OperatorIdentifier == "" {
+		c.OperatorIdentifier = "26201"
+	}
+	if c.OperatorName == "" {
+		c.OperatorName = "Fake Operator"
+	}
+	if c.EmergencyNumbers == nil {
+		c.EmergencyNumbers = []string{"112", "911"}
+	}
+	if c.Pin == "" {
+		c.Pin = "1234"
+	}
+	if c.Puk == "" {
+		c.Puk = "12345678"
+	}
+	if c.PinRetries == 0 {
+		c.PinRetries = 3
+	}
+	if c.SimType == mm.MmSimTypeUnknown {
+		c.SimType = mm.MmSimTypePhysical
+	}
+	if c.Removability == mm.MmSimRemovabilityUnknown {
+		c.Removability = mm.MmSimRemovabilityRemovable
+	}
+}
+
+// Sim represents a fake sim card, exported at /org/freedesktop/ModemManager1/SIM/N
+type Sim struct {
+	object
+	modem *Modem
+
+	mu          sync.Mutex
+	pin         string
+	puk         string
+	pinEnabled  bool
+	pinRetries  uint32
+	pukRetries  uint32
+	maxRetries  uint32
+	lockedByPuk bool
+}
+
+func newSim(srv *Server, modem *Modem, cfg SimConfig, active bool) (*Sim, error) {
+	cfg.setDefaults()
+	s := &Sim{
+		object:     object{srv: srv, path: srv.nextPath("SIM")},
+		modem:      modem,
+		pin:        cfg.Pin,
+		puk:        cfg.Puk,
+		pinEnabled: cfg.Locked,
+		pinRetries: cfg.PinRetries,
+		pukRetries: 10,
+		maxRetries: cfg.PinRetries,
+	}
+	err := s.export(map[string]map[string]*prop.Prop{
+		mm.SimInterface: {
+			"SimIdentifier":      {Value: cfg.SimIdentifier, Emit: prop.EmitTrue},
+			"Imsi":               {Value: cfg.Imsi, Emit: prop.EmitTrue},
+			"OperatorIdentifier": {Value: cfg.OperatorIdentifier, Emit: prop.EmitTrue},
+			"OperatorName":       {Value: cfg.OperatorName, Emit: prop.EmitTrue},
+			"EmergencyNumbers":   {Value: cfg.EmergencyNumbers, Emit: prop.EmitTrue},
+			"Active":             {Value: active, Emit: prop.EmitTrue},
+			"SimType":            {Value: uint32(cfg.SimType), Emit: prop.EmitTrue},
+			"Eid":                {Value: cfg.Eid, Emit: prop.EmitTrue},
+			"EsimStatus":         {Value: uint32(mm.MmSimEsimStatusUnknown), Emit: prop.EmitTrue},
+			"Removability":       {Value: uint32(cfg.Removability), Emit: prop.EmitTrue},
+			"PreferredNetworks":  {Value: []preferredNetwork{}, Emit: prop.EmitTrue},
+		},
+	}, map[string]interface{}{
+		mm.SimInterface: &simIface{s},
+	})
+	if err != nil {
+		return nil, err
+	}
+	srv.mu.Lock()
+	srv.sims[s.path] = s
+	srv.mu.Unlock()
+	return s, nil
+}
+
+// SetPin changes the correct pin
+func (s *Sim) SetPin(pin string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pin = pin
+}
+
+// Lock locks the modem again, e.g. to simulate a sim change or a power cycle. Requires pin checking to be enabled.
+func (s *Sim) Lock() {
+	s.mu.Lock()
+	s.pinEnabled = true
+	lock := mm.MmModemLockSimPin
+	if s.lockedByPuk {
+		lock = mm.MmModemLockSimPuk
+	}
+	s.mu.Unlock()
+	s.modem.lock(lock)
+}
+
+func (s *Sim) retries() map[uint32]uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[uint32]uint32{
+		uint32(mm.MmModemLockSimPin): s.pinRetries,
+		uint32(mm.MmModemLockSimPuk): s.pukRetries,
+	}
+}
+
+func (s *Sim) remove() {
+	s.unexport(mm.SimInterface)
+	s.srv.mu.Lock()
+	delete(s.srv.sims, s.path)
+	s.srv.mu.Unlock()
+}
+
+// simIface implements org.freedesktop.ModemManager1.Sim
+type simIface struct {
+	s *Sim
+}
+
+func (si *simIface) SendPin(pin string) *dbus.Error {
+	s := si.s
+	if err := s.invoke(mm.SimSendPin, pin); err != nil {
+		return err
+	}
+	return s.sendPin(pin)
+}
+
+func (s *Sim) sendPin(pin string) *dbus.Error {
+	s.mu.Lock()
+	if s.lockedByPuk {
+		s.mu.Unlock()
+		return newError(ErrorSimPuk, "SIM PUK required")
+	}
+	if pin != s.pin {
+		s.pinRetries--
+		if s.pinRetries == 0 {
+			s.lockedByPuk = true
+		}
+		s.mu.Unlock()
+		if s.lockedByPuk {
+			s.modem.lock(mm.MmModemLockSimPuk)
+		} else {
+			s.modem.updateUnlockRetries()
+		}
+		return newError(ErrorIncorrectPassword, "Incorrect password")
+	}
+	s.pinRetries = s.maxRetries
+	s.mu.Unlock()
+	s.modem.unlock()
+	return nil
+}
+
+func (si *simIface) SendPuk(puk string, pin string) *dbus.Error {
+	s := si.s
+	if err := s.invoke(mm.SimSendSendPuk, puk, pin); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	if puk != s.puk {
+		if s.pukRetries > 0 {
+			s.pukRetries--
+		}
+		s.mu.Unlock()
+		s.modem.updateUnlockRetries()
+		return newError(ErrorIncorrectPassword, "Incorrect password")
+	}
+	s.pin = pin
+	s.pinRetries = s.maxRetries
+	s.lockedByPuk = false
+	s.mu.Unlock()
+	s.modem.unlock()
+	return nil
+}
+
+func (si *simIface) EnablePin(pin string, enabled bool) *dbus.Error {
+	s := si.s
+	if err := s.invoke(mm.SimEnablePin, pin, enabled); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pin != s.pin {
+		return newError(ErrorIncorrectPassword, "Incorrect password")
+	}
+	s.pinEnabled = enabled
+	return nil
+}
+
+func (si *simIface) ChangePin(oldPin string, newPin string) *dbus.Error {
+	s := si.s
+	if err := s.invoke(mm.SimChangePin, oldPin, newPin); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if oldPin != s.pin {
+		return newError(ErrorIncorrectPassword, "Incorrect password")
+	}
+	s.pin = newPin
+	return nil
+}
+
+func (si *simIface) SetPreferredNetworks(networks []preferredNetwork) *dbus.Error {
+	if err := si.s.invoke(mm.SimSetPreferredNetworks, networks); err != nil {
+		return err
+	}
+	si.s.SetProperty(mm.SimInterface, "PreferredNetworks", networks)
+	return nil
+}
@@ -0,0 +1,130 @@
+package mmtest
+
+import (
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/prop"
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// validity represents the (uv) sms validity
+type validity struct {
+	Type  uint32
+	Value dbus.Variant
+}
+
+// Sms represents a fake sms, exported at /org/freedesktop/ModemManager1/SMS/N
+type Sms struct {
+	object
+	modem *Modem
+}
+
+func newSms(srv *Server, modem *Modem, properties map[string]dbus.Variant, state mm.MMSmsState, pduType mm.MMSmsPduType) (*Sms, error) {
+	s := &Sms{
+		object: object{srv: srv, path: srv.nextPath("SMS")},
+		modem:  modem,
+	}
+	props := map[string]*prop.Prop{
+		"State":                 {Value: uint32(state), Emit: prop.EmitTrue},
+		"PduType":               {Value: uint32(pduType), Emit: prop.EmitTrue},
+		"Number":                {Value: "", Emit: prop.EmitTrue},
+		"Text":                  {Value: "", Emit: prop.EmitTrue},
+		"Data":                  {Value: []byte{}, Emit: prop.EmitTrue},
+		"SMSC":                  {Value: "", Emit: prop.EmitTrue},
+		"Validity":              {Value: validity{uint32(mm.MmSmsValidityTypeUnknown), dbus.MakeVariant(uint32(0))}, Emit: prop.EmitTrue},
+		"Class":                 {Value: int32(-1), Emit: prop.EmitTrue},
+		"TeleserviceId":         {Value: uint32(mm.MmSmsCdmaTeleserviceIdUnknown), Emit: prop.EmitTrue},
+		"ServiceCategory":       {Value: uint32(mm.MmSmsCdmaServiceCategoryUnknown), Emit: prop.EmitTrue},
+		"DeliveryReportRequest": {Value: false, Emit: prop.EmitTrue},
+		"MessageReference":      {Value: uint32(0), Emit: prop.EmitTrue},
+		"Timestamp":             {Value: "", Emit: prop.EmitTrue},
+		"DischargeTimestamp":    {Value: "", Emit: prop.EmitTrue},
+		"DeliveryState":         {Value: uint32(mm.MmSmsDeliveryStateUnknown), Emit: prop.EmitTrue},
+		"Storage":               {Value: uint32(mm.MmSmsStorageUnknown), Emit: prop.EmitTrue},
+	}
+	for key, value := range properties {
+		switch key {
+		case "number":
+			props["Number"].Value = value.Value()
+		case "text":
+			props["Text"].Value = value.Value()
+		case "data":
+			props["Data"].Value = value.Value()
+		case "smsc":
+			props["SMSC"].Value = value.Value()
+		case "class":
+			props["Class"].Value = value.Value()
+		case "delivery-report-request":
+			props["DeliveryReportRequest"].Value = value.Value()
+		case "storage":
+			props["Storage"].Value = value.Value()
+		case "timestamp":
+			props["Timestamp"].Value = value.Value()
+		case "validity":
+			if v, ok := value.Value().(uint32); ok {
+				props["Validity"].Value = validity{uint32(mm.MmSmsValidityTypeRelative), dbus.MakeVariant(v)}
+			}
+		}
+	}
+	err := s.export(map[string]map[string]*prop.Prop{
+		mm.SmsInterface: props,
+	}, map[string]interface{}{
+		mm.SmsInterface: &smsIface{s},
+	})
+	if err != nil {
+		return nil, err
+	}
+	srv.mu.Lock()
+	srv.sms[s.path] = s
+	srv.mu.Unlock()
+	return s, nil
+}
+
+// SetState sets the sms state
+func (s *Sms) SetState(state mm.MMSmsState) {
+	s.SetProperty(mm.SmsInterface, "State", uint32(state))
+}
+
+// SetDeliveryState sets the delivery state, e.g. after receiving a status report
+func (s *Sms) SetDeliveryState(state mm.MMSmsDeliveryState) {
+	s.SetProperty(mm.SmsInterface, "DeliveryState", uint32(state))
+}
+
+func (s *Sms) remove() {
+	s.unexport(mm.SmsInterface)
+	s.srv.mu.Lock()
+	delete(s.srv.sms, s.path)
+	s.srv.mu.Unlock()
+}
+
+// smsIface implements org.freedesktop.ModemManager1.Sms
+type smsIface struct {
+	s *Sms
+}
+
+func (si *smsIface) Send() *dbus.Error {
+	s := si.s
+	if err := s.invoke(mm.SmsSend); err != nil {
+		return err
+	}
+	if err := s.modem.checkState(mm.MmModemStateRegistered); err != nil {
+		return err
+	}
+	s.SetState(mm.MmSmsStateSending)
+	s.SetProperty(mm.SmsInterface, "Timestamp", time.Now().Format(time.RFC3339))
+	s.SetState(mm.MmSmsStateSent)
+	return nil
+}
+
+func (si *smsIface) Store(storage uint32) *dbus.Error {
+	s := si.s
+	if err := s.invoke(mm.SmsStore, storage); err != nil {
+		return err
+	}
+	if storage == uint32(mm.MmSmsStorageUnknown) {
+		storage = uint32(mm.MmSmsStorageMe)
+	}
+	s.SetProperty(mm.SmsInterface, "Storage", storage)
+	return nil
+}
@@ -0,0 +1,42 @@
+package mmtest
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// StartT starts a fake ModemManager on a private bus for the given test and returns it with a client connection.
+// The test is skipped if no dbus-daemon is available and fails on other errors. The returned function closes the
+// connection and the fake.
+func StartT(t testing.TB) (*Server, *dbus.Conn, func()) {
+	t.Helper()
+	srv, err := Start()
+	if err != nil {
+		t.Skip("fake ModemManager not available: ", err)
+	}
+	conn, err := srv.Connect()
+	if err != nil {
+		srv.Close()
+		t.Fatal(err)
+	}
+	return srv, conn, func() {
+		conn.Close()
+		srv.Close()
+	}
+}
+
+// AddModemT adds a fake modem and returns it with its client on the given connection. The test fails on errors.
+func (s *Server) AddModemT(t testing.TB, conn *dbus.Conn, cfg ModemConfig) (*Modem, mm.Modem) {
+	t.Helper()
+	fake, err := s.AddModem(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modem, err := mm.NewModemWithConn(conn, fake.GetObjectPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fake, modem
+}
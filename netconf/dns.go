@@ -0,0 +1,221 @@
+package netconf
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// DefaultResolvConfPath is the default path of the resolver configuration
+const DefaultResolvConfPath = "/etc/resolv.conf"
+
+// BackupSuffix is appended to the path of the written file for the backup of its previous content
+const BackupSuffix = ".go-modemmanager.bak"
+
+const generatedHeader = "# Generated by go-modemmanager"
+
+// ResolvConf writes the DNS servers to a resolv.conf file and restores the previous content on RevertDns.
+// The last interface configured wins, as the file is system-wide. If the file is a symlink, e.g. managed by
+// resolvconf, the target of the link is written and the link is kept. The previous content is saved next to the
+// written file with BackupSuffix until RevertDns, so a ResolvConf started after a crash still restores it.
+type ResolvConf struct {
+	Path string // The path of the file, defaults to DefaultResolvConfPath
+
+	mu      sync.Mutex
+	target  string // the written file, the path with resolved symlinks
+	backup  []byte
+	saved   bool
+	current string // the interface of the written configuration
+}
+
+func (r *ResolvConf) path() string {
+	if r.Path == "" {
+		return DefaultResolvConfPath
+	}
+	return r.Path
+}
+
+// SetDns writes the servers to the file, the previous content is kept for RevertDns
+func (r *ResolvConf) SetDns(ifname string, ifindex int, servers []net.IP) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.saved {
+		if err := r.save(); err != nil {
+			return err
+		}
+	}
+	var buf bytes.Buffer
+	buf.WriteString(generatedHeader + " for " + ifname + "\n")
+	for _, server := range servers {
+		buf.WriteString("nameserver " + server.String() + "\n")
+	}
+	if err := writeFile(r.target, buf.Bytes()); err != nil {
+		return err
+	}
+	r.current = ifname
+	return nil
+}
+
+// save resolves the path and keeps the previous content of the file in memory and in the backup file. An existing
+// backup file was left by a previous run, which did not revert its configuration, and is used instead.
+func (r *ResolvConf) save() error {
+	target, err := resolvePath(r.path())
+	if err != nil {
+		return err
+	}
+	backup, err := ioutil.ReadFile(target + BackupSuffix)
+	if os.IsNotExist(err) {
+		content, err := ioutil.ReadFile(target)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		// a generated file without backup was created by a previous run
+		if content != nil && !bytes.HasPrefix(content, []byte(generatedHeader)) {
+			if err := writeFile(target+BackupSuffix, content); err != nil {
+				return fmt.Errorf("write backup: %w", err)
+			}
+			backup = content
+		}
+	} else if err != nil {
+		return err
+	}
+	r.target = target
+	r.backup = backup
+	r.saved = true
+	return nil
+}
+
+// RevertDns restores the previous content of the file, if the file was last written for this interface
+func (r *ResolvConf) RevertDns(ifname string, ifindex int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.saved || r.current != ifname {
+		return nil
+	}
+	var err error
+	if r.backup == nil {
+		err = os.Remove(r.target)
+	} else {
+		err = writeFile(r.target, r.backup)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	r.saved = false
+	r.current = ""
+	if err := os.Remove(r.target + BackupSuffix); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// resolvePath returns the path with all symlinks resolved. A dangling symlink is refused, as replacing it by a
+// file would break the owner of the link.
+func resolvePath(path string) (string, error) {
+	target, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return target, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return "", fmt.Errorf("%s is a symlink to a missing file", path)
+	}
+	return path, nil
+}
+
+// writeFile replaces the file atomically, so readers never see a partial configuration. The path must not be a
+// symlink, as the link would be replaced. The mode of an existing file is kept.
+func writeFile(path string, content []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, content, mode); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+const (
+	resolvedInterface  = "org.freedesktop.resolve1"
+	resolvedObjectPath = "/org/freedesktop/resolve1"
+
+	resolvedSetLinkDNS          = resolvedInterface + ".Manager.SetLinkDNS"
+	resolvedSetLinkDomains      = resolvedInterface + ".Manager.SetLinkDomains"
+	resolvedSetLinkDefaultRoute = resolvedInterface + ".Manager.SetLinkDefaultRoute"
+	resolvedRevertLink          = resolvedInterface + ".Manager.RevertLink"
+)
+
+// Resolved sets the DNS servers of the interface by systemd-resolved
+type Resolved struct {
+	Conn *dbus.Conn // The system bus connection, defaults to dbus.SystemBus()
+}
+
+type resolvedAddress struct {
+	Family  int32
+	Address []byte
+}
+
+func (r *Resolved) object() (dbus.BusObject, error) {
+	conn := r.Conn
+	if conn == nil {
+		var err error
+		conn, err = dbus.SystemBus()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return conn.Object(resolvedInterface, resolvedObjectPath), nil
+}
+
+// SetDns sets the servers as link DNS and the link as default route for DNS queries
+func (r *Resolved) SetDns(ifname string, ifindex int, servers []net.IP) error {
+	obj, err := r.object()
+	if err != nil {
+		return err
+	}
+	var addresses []resolvedAddress
+	for _, server := range servers {
+		if ip4 := server.To4(); ip4 != nil {
+			addresses = append(addresses, resolvedAddress{Family: familyIp4, Address: ip4})
+		} else {
+			addresses = append(addresses, resolvedAddress{Family: familyIp6, Address: server.To16()})
+		}
+	}
+	if err := obj.Call(resolvedSetLinkDNS, 0, int32(ifindex), addresses).Err; err != nil {
+		return err
+	}
+	type domain struct {
+		Domain      string
+		RoutingOnly bool
+	}
+	// route all queries without a more specific link to this interface
+	if err := obj.Call(resolvedSetLinkDomains, 0, int32(ifindex), []domain{{Domain: ".", RoutingOnly: true}}).Err; err != nil {
+		return err
+	}
+	// not available before systemd 240, the routing domain above is sufficient then
+	_ = obj.Call(resolvedSetLinkDefaultRoute, 0, int32(ifindex), true).Err
+	return nil
+}
+
+// RevertDns removes all DNS settings of the interface
+func (r *Resolved) RevertDns(ifname string, ifindex int) error {
+	obj, err := r.object()
+	if err != nil {
+		return err
+	}
+	return obj.Call(resolvedRevertLink, 0, int32(ifindex)).Err
+}
@@ -0,0 +1,314 @@
+package netconf
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+const original = "nameserver 192.168.1.1\n"
+
+var servers = []net.IP{net.ParseIP("10.11.12.13"), net.ParseIP("2001:db8::1")}
+
+func tempDir(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "netconf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func assertNotExist(t *testing.T, path string) {
+	t.Helper()
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("%s exists, error %v", path, err)
+	}
+}
+
+func TestResolvConf(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "resolv.conf")
+	if err := ioutil.WriteFile(path, []byte(original), 0640); err != nil {
+		t.Fatal(err)
+	}
+	r := &ResolvConf{Path: path}
+	if err := r.SetDns("wwan0", 3, servers); err != nil {
+		t.Fatal(err)
+	}
+	want := "# Generated by go-modemmanager for wwan0\nnameserver 10.11.12.13\nnameserver 2001:db8::1\n"
+	if got := readFile(t, path); got != want {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+	if got := readFile(t, path+BackupSuffix); got != original {
+		t.Errorf("backup = %q, want %q", got, original)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("mode = %s, want the mode of the previous file", info.Mode())
+	}
+
+	// the file is only restored by the interface which wrote it last
+	if err := r.RevertDns("wwan1", 4); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != want {
+		t.Errorf("content after revert of another interface = %q, want %q", got, want)
+	}
+	if err := r.RevertDns("wwan0", 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != original {
+		t.Errorf("content after revert = %q, want %q", got, original)
+	}
+	assertNotExist(t, path+BackupSuffix)
+}
+
+func TestResolvConfMissingFile(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "resolv.conf")
+	r := &ResolvConf{Path: path}
+	if err := r.SetDns("wwan0", 3, servers); err != nil {
+		t.Fatal(err)
+	}
+	assertNotExist(t, path+BackupSuffix)
+	if err := r.RevertDns("wwan0", 3); err != nil {
+		t.Fatal(err)
+	}
+	assertNotExist(t, path)
+}
+
+func TestResolvConfSymlink(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	target := filepath.Join(dir, "run", "resolv.conf")
+	if err := os.Mkdir(filepath.Dir(target), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(target, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "resolv.conf")
+	if err := os.Symlink(target, path); err != nil {
+		t.Fatal(err)
+	}
+	r := &ResolvConf{Path: path}
+	if err := r.SetDns("wwan0", 3, servers); err != nil {
+		t.Fatal(err)
+	}
+	if link, err := os.Readlink(path); err != nil || link != target {
+		t.Fatalf("symlink replaced: %q, %v", link, err)
+	}
+	if got := readFile(t, target); !strings.HasPrefix(got, "# Generated by go-modemmanager") {
+		t.Errorf("target of the symlink not written: %q", got)
+	}
+	if got := readFile(t, target+BackupSuffix); got != original {
+		t.Errorf("backup next to the target = %q, want %q", got, original)
+	}
+	if err := r.RevertDns("wwan0", 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != original {
+		t.Errorf("content after revert = %q, want %q", got, original)
+	}
+}
+
+func TestResolvConfDanglingSymlink(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "resolv.conf")
+	target := filepath.Join(dir, "stub-resolv.conf")
+	if err := os.Symlink(target, path); err != nil {
+		t.Fatal(err)
+	}
+	r := &ResolvConf{Path: path}
+	if err := r.SetDns("wwan0", 3, servers); err == nil {
+		t.Fatal("dangling symlink replaced")
+	}
+	if link, err := os.Readlink(path); err != nil || link != target {
+		t.Errorf("symlink changed: %q, %v", link, err)
+	}
+	assertNotExist(t, target)
+}
+
+func TestResolvConfBackupAfterCrash(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "resolv.conf")
+	if err := ioutil.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&ResolvConf{Path: path}).SetDns("wwan0", 3, servers); err != nil {
+		t.Fatal(err)
+	}
+	// a new process restores the content saved by the crashed one
+	r := &ResolvConf{Path: path}
+	if err := r.SetDns("wwan0", 5, servers[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RevertDns("wwan0", 5); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != original {
+		t.Errorf("content after revert = %q, want %q", got, original)
+	}
+	assertNotExist(t, path+BackupSuffix)
+}
+
+func TestResolvConfGeneratedWithoutBackup(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "resolv.conf")
+	// the file was created by a crashed process, as no file existed before
+	if err := (&ResolvConf{Path: path}).SetDns("wwan0", 3, servers); err != nil {
+		t.Fatal(err)
+	}
+	r := &ResolvConf{Path: path}
+	if err := r.SetDns("wwan0", 5, servers); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RevertDns("wwan0", 5); err != nil {
+		t.Fatal(err)
+	}
+	assertNotExist(t, path)
+}
+
+type resolvedDomain struct {
+	Domain      string
+	RoutingOnly bool
+}
+
+// fakeResolved records the calls of the systemd-resolved manager
+type fakeResolved struct {
+	mu           sync.Mutex
+	dns          map[int32][]resolvedAddress
+	domains      map[int32][]resolvedDomain
+	defaultRoute map[int32]bool
+	reverted     []int32
+}
+
+func (r *fakeResolved) SetLinkDNS(ifindex int32, addresses []resolvedAddress) *dbus.Error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dns[ifindex] = addresses
+	return nil
+}
+
+func (r *fakeResolved) SetLinkDomains(ifindex int32, domains []resolvedDomain) *dbus.Error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.domains[ifindex] = domains
+	return nil
+}
+
+func (r *fakeResolved) SetLinkDefaultRoute(ifindex int32, enable bool) *dbus.Error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaultRoute[ifindex] = enable
+	return nil
+}
+
+func (r *fakeResolved) RevertLink(ifindex int32) *dbus.Error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.dns, ifindex)
+	delete(r.domains, ifindex)
+	delete(r.defaultRoute, ifindex)
+	r.reverted = append(r.reverted, ifindex)
+	return nil
+}
+
+// startResolved exports a fake systemd-resolved on a private bus and returns a connection to it
+func startResolved(t *testing.T) (*fakeResolved, *dbus.Conn, func()) {
+	t.Helper()
+	srv, conn, stop := mmtest.StartT(t)
+	resolvedConn, err := srv.Connect()
+	if err != nil {
+		stop()
+		t.Fatal(err)
+	}
+	cleanup := func() {
+		resolvedConn.Close()
+		stop()
+	}
+	r := &fakeResolved{dns: make(map[int32][]resolvedAddress), domains: make(map[int32][]resolvedDomain),
+		defaultRoute: make(map[int32]bool)}
+	if err := resolvedConn.Export(r, resolvedObjectPath, resolvedInterface+".Manager"); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	if reply, err := resolvedConn.RequestName(resolvedInterface, dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		cleanup()
+		t.Fatalf("request name: %v, %v", reply, err)
+	}
+	return r, conn, cleanup
+}
+
+func TestResolved(t *testing.T) {
+	fake, conn, stop := startResolved(t)
+	defer stop()
+	r := &Resolved{Conn: conn}
+	if err := r.SetDns("wwan0", 3, servers); err != nil {
+		t.Fatal(err)
+	}
+	fake.mu.Lock()
+	wantDns := []resolvedAddress{
+		{Family: familyIp4, Address: []byte{10, 11, 12, 13}},
+		{Family: familyIp6, Address: net.ParseIP("2001:db8::1")},
+	}
+	if !reflect.DeepEqual(fake.dns[3], wantDns) {
+		t.Errorf("link dns = %v, want %v", fake.dns[3], wantDns)
+	}
+	wantDomains := []resolvedDomain{{Domain: ".", RoutingOnly: true}}
+	if !reflect.DeepEqual(fake.domains[3], wantDomains) {
+		t.Errorf("link domains = %v, want %v", fake.domains[3], wantDomains)
+	}
+	if !fake.defaultRoute[3] {
+		t.Error("link not set as default route")
+	}
+	fake.mu.Unlock()
+
+	if err := r.RevertDns("wwan0", 3); err != nil {
+		t.Fatal(err)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if !reflect.DeepEqual(fake.reverted, []int32{3}) || len(fake.dns) != 0 || len(fake.domains) != 0 {
+		t.Errorf("reverted links %v, remaining dns %v, domains %v", fake.reverted, fake.dns, fake.domains)
+	}
+}
+
+func TestResolvedError(t *testing.T) {
+	_, conn, stop := mmtest.StartT(t)
+	defer stop()
+	// systemd-resolved is not running on the bus
+	r := &Resolved{Conn: conn}
+	if err := r.SetDns("wwan0", 3, servers); err == nil {
+		t.Error("dns set without systemd-resolved")
+	}
+	if err := r.RevertDns("wwan0", 3); err == nil {
+		t.Error("dns reverted without systemd-resolved")
+	}
+}
@@ -0,0 +1,410 @@
+// Package netconf applies the ip configuration of a connected bearer to its network interface: addresses, default
+// routes, MTU, link state and DNS servers. It supports the methods MmBearerIpMethodStatic and MmBearerIpMethodDhcp,
+// the latter by an optional DhcpClient for IPv4 and SLAAC of the kernel for IPv6.
+// The interface is configured by netlink, which requires Linux and the capability CAP_NET_ADMIN.
+package netconf
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// ErrNoDhcpClient is returned if a bearer requires DHCP for IPv4, but no DhcpClient is configured
+var ErrNoDhcpClient = errors.New("bearer requires dhcp, but no dhcp client given")
+
+// DnsConfigurator sets the DNS servers of an interface, e.g. ResolvConf or Resolved
+type DnsConfigurator interface {
+	SetDns(ifname string, ifindex int, servers []net.IP) error
+	RevertDns(ifname string, ifindex int) error
+}
+
+// DhcpClient runs DHCP on an interface, e.g. by starting udhcpc or dhclient
+type DhcpClient interface {
+	Start(ifname string) error
+	Stop(ifname string) error
+}
+
+// Config defines how the bearer configuration is applied
+type Config struct {
+	Dns         DnsConfigurator // Writes the DNS servers, DNS is not configured if nil
+	DhcpClient  DhcpClient      // Used for IPv4 bearers with MmBearerIpMethodDhcp without address
+	NoRoute     bool            // If true no default route is added
+	RouteMetric uint32          // The metric of the default routes
+}
+
+// Setup is the applied configuration of a bearer, which is removed by Teardown
+type Setup struct {
+	Interface string // The name of the network interface
+	Ip4       *mm.BearerIpConfig
+	Ip6       *mm.BearerIpConfig
+
+	cfg     Config
+	index   int
+	mtu     uint32 // the mtu before applying the configuration
+	up      bool   // the link state before applying the configuration
+	addrs   []*net.IPNet
+	routes  []route
+	dns     bool
+	dhcp    bool
+	removed bool
+}
+
+type route struct {
+	family  int
+	gateway net.IP
+	metric  uint32
+}
+
+// Apply reads the interface and the ip configurations of the connected bearer and applies them
+func Apply(bearer mm.Bearer, cfg Config) (*Setup, error) {
+	ifname, err := bearer.GetInterface()
+	if err != nil {
+		return nil, err
+	}
+	var ip4, ip6 *mm.BearerIpConfig
+	if c, err := bearer.GetIp4Config(); err == nil && c.Method != mm.MmBearerIpMethodUnknown {
+		ip4 = &c
+	}
+	if c, err := bearer.GetIp6Config(); err == nil && c.Method != mm.MmBearerIpMethodUnknown {
+		ip6 = &c
+	}
+	return ApplyConfig(ifname, ip4, ip6, cfg)
+}
+
+// ApplyConfig applies the given ip configurations to the interface, nil configurations are skipped.
+// On error, the parts already applied are removed again.
+func ApplyConfig(ifname string, ip4 *mm.BearerIpConfig, ip6 *mm.BearerIpConfig, cfg Config) (*Setup, error) {
+	if ifname == "" {
+		return nil, errors.New("no interface given")
+	}
+	iface, err := net.InterfaceByName(ifname)
+	if err != nil {
+		return nil, err
+	}
+	s := &Setup{Interface: ifname, Ip4: ip4, Ip6: ip6, cfg: cfg, index: iface.Index, mtu: uint32(iface.MTU),
+		up: iface.Flags&net.FlagUp != 0}
+	if err := s.apply(); err != nil {
+		_ = s.Teardown()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Setup) apply() error {
+	var mtu uint32
+	var servers []net.IP
+	for _, c := range []*mm.BearerIpConfig{s.Ip4, s.Ip6} {
+		if c == nil {
+			continue
+		}
+		switch c.Method {
+		case mm.MmBearerIpMethodStatic, mm.MmBearerIpMethodDhcp:
+		default:
+			return fmt.Errorf("unsupported ip method: %s", c.Method)
+		}
+		if c.Mtu > 0 && (mtu == 0 || c.Mtu < mtu) {
+			mtu = c.Mtu
+		}
+		for _, dns := range []string{c.Dns1, c.Dns2, c.Dns3} {
+			if ip := net.ParseIP(dns); ip != nil {
+				servers = append(servers, ip)
+			}
+		}
+	}
+	if err := setLink(s.index, true, mtu); err != nil {
+		return fmt.Errorf("set link up: %w", err)
+	}
+	for _, c := range []*mm.BearerIpConfig{s.Ip4, s.Ip6} {
+		if c == nil {
+			continue
+		}
+		if err := s.applyIpConfig(c); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Dns != nil && len(servers) > 0 {
+		if err := s.cfg.Dns.SetDns(s.Interface, s.index, servers); err != nil {
+			return fmt.Errorf("set dns: %w", err)
+		}
+		s.dns = true
+	}
+	return nil
+}
+
+func (s *Setup) applyIpConfig(c *mm.BearerIpConfig) error {
+	family := familyOf(c)
+	if c.Address == "" {
+		if c.Method == mm.MmBearerIpMethodStatic {
+			return errors.New("static ip config without address")
+		}
+		if family == familyIp6 {
+			// SLAAC is done by the kernel once the link is up
+			return nil
+		}
+		if s.cfg.DhcpClient == nil {
+			return ErrNoDhcpClient
+		}
+		if err := s.cfg.DhcpClient.Start(s.Interface); err != nil {
+			return fmt.Errorf("start dhcp: %w", err)
+		}
+		s.dhcp = true
+		return nil
+	}
+	ip := net.ParseIP(c.Address)
+	if ip == nil {
+		return fmt.Errorf("invalid address: %s", c.Address)
+	}
+	prefix := int(c.Prefix)
+	if prefix == 0 {
+		prefix = 8 * len(ipBytes(ip, family))
+	}
+	addr := &net.IPNet{IP: ip, Mask: net.CIDRMask(prefix, 8*len(ipBytes(ip, family)))}
+	if err := addAddress(s.index, family, addr); err != nil {
+		return fmt.Errorf("add address %s: %w", addr, err)
+	}
+	s.addrs = append(s.addrs, addr)
+	if s.cfg.NoRoute || (family == familyIp6 && c.Method == mm.MmBearerIpMethodDhcp) {
+		// the IPv6 default route is added by router advertisements
+		return nil
+	}
+	r := route{family: family, gateway: net.ParseIP(c.Gateway), metric: s.cfg.RouteMetric}
+	if err := addDefaultRoute(s.index, r); err != nil {
+		return fmt.Errorf("add default route: %w", err)
+	}
+	s.routes = append(s.routes, r)
+	return nil
+}
+
+// Teardown removes the applied routes, addresses and DNS servers, stops DHCP and restores the MTU and the previous
+// link state. It is safe to call it multiple times.
+func (s *Setup) Teardown() error {
+	if s.removed {
+		return nil
+	}
+	s.removed = true
+	var errs []error
+	if s.dns {
+		errs = append(errs, s.cfg.Dns.RevertDns(s.Interface, s.index))
+	}
+	if s.dhcp {
+		errs = append(errs, s.cfg.DhcpClient.Stop(s.Interface))
+	}
+	for _, r := range s.routes {
+		errs = append(errs, ignoreMissing(deleteDefaultRoute(s.index, r)))
+	}
+	for _, addr := range s.addrs {
+		family := familyIp4
+		if addr.IP.To4() == nil {
+			family = familyIp6
+		}
+		errs = append(errs, ignoreMissing(deleteAddress(s.index, family, addr)))
+	}
+	// the interface may already be gone, e.g. after a modem reset
+	if _, err := net.InterfaceByName(s.Interface); err == nil {
+		errs = append(errs, setLink(s.index, s.up, s.mtu))
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Manager applies the ip configuration of all bearers on a connection whenever they get connected, and tears it
+// down when they get disconnected or removed together with their modem
+type Manager struct {
+	conn        *dbus.Conn
+	cfg         Config
+	dispatcher  *mm.EventDispatcher
+	bearers     *mm.EventSubscription
+	modems      *mm.EventSubscription
+	done        chan struct{}
+	applyBearer func(bearer mm.Bearer, cfg Config) (*Setup, error)
+
+	mu     sync.Mutex
+	setups map[dbus.ObjectPath]*Setup
+	onErr  func(bearer dbus.ObjectPath, err error)
+}
+
+// NewManager starts a new Manager on the given connection. Errors of applying a configuration are passed to
+// onError, which may be nil.
+func NewManager(conn *dbus.Conn, cfg Config, onError func(bearer dbus.ObjectPath, err error)) (*Manager, error) {
+	dispatcher, err := mm.NewEventDispatcherWithConn(conn)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manager{
+		conn:        conn,
+		cfg:         cfg,
+		dispatcher:  dispatcher,
+		bearers:     dispatcher.Subscribe(mm.EventFilter{Interface: mm.BearerInterface}, 16),
+		modems:      dispatcher.Subscribe(mm.EventFilter{Interface: mm.ModemInterface}, 16),
+		done:        make(chan struct{}),
+		applyBearer: Apply,
+		setups:      make(map[dbus.ObjectPath]*Setup),
+		onErr:       onError,
+	}
+	go m.run()
+	return m, nil
+}
+
+// Apply applies the configuration of an already connected bearer, which is then managed like the others
+func (m *Manager) Apply(bearer mm.Bearer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.apply(bearer)
+}
+
+func (m *Manager) apply(bearer mm.Bearer) error {
+	if old, ok := m.setups[bearer.GetObjectPath()]; ok {
+		_ = old.Teardown()
+		delete(m.setups, bearer.GetObjectPath())
+	}
+	s, err := m.applyBearer(bearer, m.cfg)
+	if err != nil {
+		return err
+	}
+	m.setups[bearer.GetObjectPath()] = s
+	return nil
+}
+
+func (m *Manager) teardown(path dbus.ObjectPath) error {
+	s, ok := m.setups[path]
+	if !ok {
+		return nil
+	}
+	delete(m.setups, path)
+	return s.Teardown()
+}
+
+// Close stops the manager and tears down all configurations
+func (m *Manager) Close() error {
+	err := m.dispatcher.Close()
+	<-m.done
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for path := range m.setups {
+		if terr := m.teardown(path); err == nil {
+			err = terr
+		}
+	}
+	return err
+}
+
+func (m *Manager) run() {
+	defer close(m.done)
+	bearers, modems := m.bearers.Events(), m.modems.Events()
+	for bearers != nil || modems != nil {
+		select {
+		case e, ok := <-bearers:
+			if !ok {
+				bearers = nil
+				continue
+			}
+			if connected, ok := e.(mm.BearerConnectedEvent); ok {
+				m.bearerConnected(connected)
+			}
+		case e, ok := <-modems:
+			if !ok {
+				modems = nil
+				continue
+			}
+			if _, ok := e.(mm.ModemRemovedEvent); ok {
+				m.modemRemoved()
+			}
+		}
+	}
+}
+
+func (m *Manager) bearerConnected(e mm.BearerConnectedEvent) {
+	m.mu.Lock()
+	var err error
+	if e.Connected {
+		var bearer mm.Bearer
+		bearer, err = mm.NewBearerWithConn(m.conn, e.Path)
+		if err == nil {
+			err = m.apply(bearer)
+		}
+	} else {
+		err = m.teardown(e.Path)
+	}
+	m.mu.Unlock()
+	if err != nil && m.onErr != nil {
+		m.onErr(e.Path, err)
+	}
+}
+
+// modemRemoved tears down the configurations of all bearers removed together with the modem, e.g. after a reset
+// of the modem, as ModemManager does not emit a disconnect for them
+func (m *Manager) modemRemoved() {
+	errs := make(map[dbus.ObjectPath]error)
+	m.mu.Lock()
+	for path := range m.setups {
+		bearer, err := mm.NewBearerWithConn(m.conn, path)
+		if err == nil {
+			_, err = bearer.GetInterface()
+		}
+		if !isUnknownObject(err) {
+			continue
+		}
+		if err := m.teardown(path); err != nil {
+			errs[path] = err
+		}
+	}
+	m.mu.Unlock()
+	if m.onErr != nil {
+		for path, err := range errs {
+			m.onErr(path, err)
+		}
+	}
+}
+
+// isUnknownObject returns true if the error reports a missing object or interface
+func isUnknownObject(err error) bool {
+	var name string
+	switch e := err.(type) {
+	case dbus.Error:
+		name = e.Name
+	case *dbus.Error:
+		name = e.Name
+	default:
+		return false
+	}
+	switch name {
+	case "org.freedesktop.DBus.Error.UnknownObject", "org.freedesktop.DBus.Error.UnknownInterface",
+		"org.freedesktop.DBus.Error.UnknownMethod":
+		return true
+	}
+	return false
+}
+
+const (
+	familyIp4 = 2  // AF_INET
+	familyIp6 = 10 // AF_INET6
+)
+
+func familyOf(c *mm.BearerIpConfig) int {
+	if ip := net.ParseIP(c.Address); ip != nil {
+		if ip.To4() != nil {
+			return familyIp4
+		}
+		return familyIp6
+	}
+	if c.IpFamily == mm.MmBearerIpFamilyIpv6 {
+		return familyIp6
+	}
+	return familyIp4
+}
+
+func ipBytes(ip net.IP, family int) []byte {
+	if family == familyIp4 {
+		return ip.To4()
+	}
+	return ip.To16()
+}
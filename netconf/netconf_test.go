@@ -0,0 +1,158 @@
+package netconf
+
+import (
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+func TestApplyConfigMissingInterface(t *testing.T) {
+	ip4 := &mm.BearerIpConfig{Method: mm.MmBearerIpMethodStatic, Address: "10.64.64.64", Prefix: 30}
+	if _, err := ApplyConfig("", ip4, nil, Config{}); err == nil {
+		t.Error("config applied without interface")
+	}
+	if _, err := ApplyConfig("gomm-missing0", ip4, nil, Config{}); err == nil {
+		t.Error("config applied to a missing interface")
+	}
+}
+
+func TestTeardownRemovedInterface(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "resolv.conf")
+	if err := ioutil.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dns := &ResolvConf{Path: path}
+	if err := dns.SetDns("gomm-missing0", 42, servers); err != nil {
+		t.Fatal(err)
+	}
+	// the interface of a reset modem is gone, only the DNS servers are reverted
+	s := &Setup{Interface: "gomm-missing0", cfg: Config{Dns: dns}, index: 42, mtu: 1500, up: true, dns: true}
+	if err := s.Teardown(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != original {
+		t.Errorf("content after teardown = %q, want %q", got, original)
+	}
+	if err := s.Teardown(); err != nil {
+		t.Errorf("second teardown returned %v", err)
+	}
+}
+
+// revertRecorder records the interface indexes of reverted DNS settings
+type revertRecorder chan int
+
+func (r revertRecorder) SetDns(ifname string, ifindex int, servers []net.IP) error { return nil }
+
+func (r revertRecorder) RevertDns(ifname string, ifindex int) error {
+	r <- ifindex
+	return nil
+}
+
+// startManager starts a manager which records the applied bearers instead of configuring an interface
+func startManager(t *testing.T, conn *dbus.Conn) (*Manager, revertRecorder, chan dbus.ObjectPath) {
+	t.Helper()
+	reverted := make(revertRecorder, 4)
+	applied := make(chan dbus.ObjectPath, 4)
+	m, err := NewManager(conn, Config{Dns: reverted}, func(bearer dbus.ObjectPath, err error) {
+		t.Errorf("error of bearer %s: %v", bearer, err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := 100
+	m.mu.Lock()
+	m.applyBearer = func(bearer mm.Bearer, cfg Config) (*Setup, error) {
+		index++
+		applied <- bearer.GetObjectPath()
+		return &Setup{Interface: "gomm-missing0", cfg: cfg, index: index, dns: true}, nil
+	}
+	m.mu.Unlock()
+	return m, reverted, applied
+}
+
+func connect(t *testing.T, modem mm.Modem, applied chan dbus.ObjectPath) mm.Bearer {
+	t.Helper()
+	simple, err := modem.GetSimpleModem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bearer, err := simple.Connect(mm.SimpleProperties{Apn: "internet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case path := <-applied:
+		if path != bearer.GetObjectPath() {
+			t.Fatalf("applied bearer %s, want %s", path, bearer.GetObjectPath())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration of the connected bearer not applied")
+	}
+	return bearer
+}
+
+func expectRevert(t *testing.T, reverted revertRecorder, index int) {
+	t.Helper()
+	select {
+	case got := <-reverted:
+		if got != index {
+			t.Errorf("reverted interface %d, want %d", got, index)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("configuration of interface %d not torn down", index)
+	}
+}
+
+func TestManagerDisconnect(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	m, reverted, applied := startManager(t, conn)
+	defer m.Close()
+
+	bearer := connect(t, modem, applied)
+	if err := bearer.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+	expectRevert(t, reverted, 101)
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case index := <-reverted:
+		t.Errorf("interface %d reverted twice", index)
+	default:
+	}
+}
+
+func TestManagerModemRemoved(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	removed, first := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	_, second := srv.AddModemT(t, conn, mmtest.ModemConfig{State: mm.MmModemStateRegistered})
+	m, reverted, applied := startManager(t, conn)
+	defer m.Close()
+
+	connect(t, first, applied)
+	connect(t, second, applied)
+	// the bearer disappears together with the modem without a disconnect
+	removed.Remove()
+	expectRevert(t, reverted, 101)
+	select {
+	case index := <-reverted:
+		t.Fatalf("interface %d of the remaining modem reverted", index)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expectRevert(t, reverted, 102)
+}
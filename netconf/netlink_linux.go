@@ -0,0 +1,177 @@
+package netconf
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+// rtnetlink constants, which are not defined by the syscall package
+const (
+	iflaMtu        = 4
+	rtTableMain    = 254
+	rtprotStatic   = 4
+	rtScopeLink    = 253
+	rtScopeUniv    = 0
+	rtnUnicast     = 1
+	rtaDst         = 1
+	rtaOif         = 4
+	rtaGateway     = 5
+	rtaPriority    = 6
+	ifaLocal       = 2
+	ifaAddress     = 1
+	sizeofRtMsg    = 12
+	sizeofIfAddr   = 8
+	sizeofIfInfo   = 16
+	netlinkBufSize = 8192
+)
+
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+var netlinkSeq uint32
+
+func setLink(index int, up bool, mtu uint32) error {
+	msg := make([]byte, sizeofIfInfo)
+	msg[0] = syscall.AF_UNSPEC
+	nativeEndian.PutUint32(msg[4:], uint32(index))
+	if up {
+		nativeEndian.PutUint32(msg[8:], syscall.IFF_UP)
+	}
+	nativeEndian.PutUint32(msg[12:], syscall.IFF_UP)
+	if mtu > 0 {
+		msg = appendAttr(msg, iflaMtu, uint32Bytes(mtu))
+	}
+	return netlinkRequest(syscall.RTM_NEWLINK, 0, msg)
+}
+
+func addressMsg(index int, family int, addr *net.IPNet) []byte {
+	ones, _ := addr.Mask.Size()
+	msg := make([]byte, sizeofIfAddr)
+	msg[0] = byte(family)
+	msg[1] = byte(ones)
+	msg[3] = rtScopeUniv
+	nativeEndian.PutUint32(msg[4:], uint32(index))
+	ip := ipBytes(addr.IP, family)
+	msg = appendAttr(msg, ifaLocal, ip)
+	return appendAttr(msg, ifaAddress, ip)
+}
+
+func addAddress(index int, family int, addr *net.IPNet) error {
+	return netlinkRequest(syscall.RTM_NEWADDR, syscall.NLM_F_CREATE|syscall.NLM_F_REPLACE, addressMsg(index, family, addr))
+}
+
+func deleteAddress(index int, family int, addr *net.IPNet) error {
+	return netlinkRequest(syscall.RTM_DELADDR, 0, addressMsg(index, family, addr))
+}
+
+func routeMsg(index int, r route) []byte {
+	msg := make([]byte, sizeofRtMsg)
+	msg[0] = byte(r.family)
+	msg[4] = rtTableMain
+	msg[5] = rtprotStatic
+	msg[6] = rtScopeUniv
+	msg[7] = rtnUnicast
+	if r.gateway != nil {
+		msg = appendAttr(msg, rtaGateway, ipBytes(r.gateway, r.family))
+	} else {
+		// point-to-point interface without gateway
+		msg[6] = rtScopeLink
+	}
+	msg = appendAttr(msg, rtaOif, uint32Bytes(uint32(index)))
+	if r.metric > 0 {
+		msg = appendAttr(msg, rtaPriority, uint32Bytes(r.metric))
+	}
+	return msg
+}
+
+func addDefaultRoute(index int, r route) error {
+	return netlinkRequest(syscall.RTM_NEWROUTE, syscall.NLM_F_CREATE|syscall.NLM_F_REPLACE, routeMsg(index, r))
+}
+
+func deleteDefaultRoute(index int, r route) error {
+	return netlinkRequest(syscall.RTM_DELROUTE, 0, routeMsg(index, r))
+}
+
+// ignoreMissing ignores errors of already removed addresses, routes or interfaces
+func ignoreMissing(err error) error {
+	if errors.Is(err, syscall.ESRCH) || errors.Is(err, syscall.EADDRNOTAVAIL) || errors.Is(err, syscall.ENODEV) ||
+		errors.Is(err, syscall.ENOENT) {
+		return nil
+	}
+	return err
+}
+
+func uint32Bytes(v uint32) []byte {
+	b := make([]byte, 4)
+	nativeEndian.PutUint32(b, v)
+	return b
+}
+
+func appendAttr(msg []byte, attrType uint16, data []byte) []byte {
+	l := syscall.SizeofRtAttr + len(data)
+	attr := make([]byte, rtaAlign(l))
+	nativeEndian.PutUint16(attr[0:], uint16(l))
+	nativeEndian.PutUint16(attr[2:], attrType)
+	copy(attr[syscall.SizeofRtAttr:], data)
+	return append(msg, attr...)
+}
+
+func rtaAlign(l int) int {
+	return (l + syscall.RTA_ALIGNTO - 1) & ^(syscall.RTA_ALIGNTO - 1)
+}
+
+// netlinkRequest sends a rtnetlink request and waits for its acknowledgement
+func netlinkRequest(msgType uint16, flags uint16, payload []byte) error {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_ROUTE)
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(fd)
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return err
+	}
+
+	seq := atomic.AddUint32(&netlinkSeq, 1)
+	msg := make([]byte, syscall.NLMSG_HDRLEN, syscall.NLMSG_HDRLEN+len(payload))
+	nativeEndian.PutUint32(msg[0:], uint32(syscall.NLMSG_HDRLEN+len(payload)))
+	nativeEndian.PutUint16(msg[4:], msgType)
+	nativeEndian.PutUint16(msg[6:], syscall.NLM_F_REQUEST|syscall.NLM_F_ACK|flags)
+	nativeEndian.PutUint32(msg[8:], seq)
+	msg = append(msg, payload...)
+	if err := syscall.Sendto(fd, msg, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return err
+	}
+
+	buf := make([]byte, netlinkBufSize)
+	for {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return err
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return err
+		}
+		for _, m := range msgs {
+			if m.Header.Seq != seq || m.Header.Type != syscall.NLMSG_ERROR {
+				continue
+			}
+			if len(m.Data) < 4 {
+				return errors.New("invalid netlink acknowledgement")
+			}
+			if code := int32(nativeEndian.Uint32(m.Data[0:4])); code != 0 {
+				return syscall.Errno(-code)
+			}
+			return nil
+		}
+	}
+}
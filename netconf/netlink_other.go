@@ -0,0 +1,35 @@
+//go:build !linux
+// +build !linux
+
+package netconf
+
+import (
+	"errors"
+	"net"
+)
+
+var errUnsupported = errors.New("netconf is only supported on linux")
+
+func setLink(index int, up bool, mtu uint32) error {
+	return errUnsupported
+}
+
+func addAddress(index int, family int, addr *net.IPNet) error {
+	return errUnsupported
+}
+
+func deleteAddress(index int, family int, addr *net.IPNet) error {
+	return errUnsupported
+}
+
+func addDefaultRoute(index int, r route) error {
+	return errUnsupported
+}
+
+func deleteDefaultRoute(index int, r route) error {
+	return errUnsupported
+}
+
+func ignoreMissing(err error) error {
+	return err
+}
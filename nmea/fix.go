@@ -0,0 +1,206 @@
+package nmea
+
+import (
+	"sort"
+	"time"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// Fix merges the sentences of one epoch, e.g. the NmeaSentences of a GpsNmeaLocation
+type Fix struct {
+	Time           time.Time   `json:"time"`            // UTC time of the fix, on 0000-01-01 if no RMC gives the date
+	Valid          bool        `json:"valid"`           // True if the receiver reports a position fix
+	Latitude       float64     `json:"latitude"`        // Latitude in decimal degrees, negative in the southern hemisphere
+	Longitude      float64     `json:"longitude"`       // Longitude in decimal degrees, negative in the western hemisphere
+	Altitude       float64     `json:"altitude"`        // Altitude above mean sea level in meters
+	Quality        FixQuality  `json:"quality"`         // The fix quality of GGA
+	Type           FixType     `json:"type"`            // The fix type of GSA
+	SatellitesUsed int         `json:"satellites_used"` // Number of satellites used for the fix
+	HDOP           float64     `json:"hdop"`            // Horizontal dilution of precision
+	PDOP           float64     `json:"pdop"`            // Position dilution of precision
+	VDOP           float64     `json:"vdop"`            // Vertical dilution of precision
+	SpeedKmh       float64     `json:"speed_kmh"`       // Speed over ground in km/h
+	Course         float64     `json:"course"`          // Course over ground in degrees true north
+	Satellites     []Satellite `json:"satellites"`      // The satellites in view, ordered by constellation and PRN
+	HasPosition    bool        `json:"has_position"`    // True if any sentence contained a position
+	HasSpeed       bool        `json:"has_speed"`       // True if any sentence contained speed and course
+	HasAltitude    bool        `json:"has_altitude"`    // True if the GGA or GNS sentence of the position contained the altitude
+}
+
+// knotsToKmh converts knots to km/h
+const knotsToKmh = 1.852
+
+// ParseFix parses the given sentences and merges them into a Fix. Sentences with an invalid checksum or syntax are
+// skipped, the first of these errors is returned along with the fix of the remaining sentences.
+func ParseFix(sentences []string) (Fix, error) {
+	var firstErr error
+	parsed := make([]Sentence, 0, len(sentences))
+	for _, raw := range sentences {
+		s, err := Parse(raw)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		parsed = append(parsed, s)
+	}
+	return NewFix(parsed), firstErr
+}
+
+// FromLocation parses the sentences of the GpsNmea location source into a Fix, see ParseFix
+func FromLocation(location mm.GpsNmeaLocation) (Fix, error) {
+	return ParseFix(location.NmeaSentences)
+}
+
+// NewFix merges the given sentences into a Fix. GGA and GNS give position and quality, RMC the date and a position
+// if no GGA or GNS is given, GSA the fix type and dilution of precision, VTG or else RMC the speed and course, GSV
+// the satellites in view.
+func NewFix(sentences []Sentence) Fix {
+	var f Fix
+	var timeOfDay time.Duration
+	var hasTime, hasVtg, hasGga bool
+	var gsas []*GSA
+	satellites := make(map[satelliteKey]Satellite)
+	for _, sentence := range sentences {
+		switch s := sentence.(type) {
+		case *GGA:
+			hasGga = true
+			timeOfDay, hasTime = s.Time, true
+			f.Quality = s.Quality
+			f.Valid = s.Quality != FixQualityInvalid
+			f.SatellitesUsed = s.SatellitesUsed
+			f.HDOP = s.HDOP
+			if f.Valid {
+				f.Latitude, f.Longitude, f.Altitude, f.HasPosition = s.Latitude, s.Longitude, s.Altitude, true
+				f.HasAltitude = s.HasAltitude
+			}
+		case *GNS:
+			if hasGga {
+				continue
+			}
+			timeOfDay, hasTime = s.Time, true
+			f.Valid = s.Valid()
+			f.SatellitesUsed = s.SatellitesUsed
+			f.HDOP = s.HDOP
+			if f.Valid {
+				f.Latitude, f.Longitude, f.Altitude, f.HasPosition = s.Latitude, s.Longitude, s.Altitude, true
+				f.HasAltitude = s.HasAltitude
+			}
+		case *RMC:
+			if !s.Time.IsZero() {
+				f.Time = s.Time
+			}
+			if !hasVtg && s.Valid {
+				f.SpeedKmh, f.Course, f.HasSpeed = s.SpeedKnots*knotsToKmh, s.Course, true
+			}
+			if !f.HasPosition && s.Valid {
+				f.Valid = true
+				f.Latitude, f.Longitude, f.HasPosition = s.Latitude, s.Longitude, true
+			}
+		case *VTG:
+			if s.Mode == ModeNotValid {
+				continue
+			}
+			hasVtg = true
+			f.SpeedKmh, f.Course, f.HasSpeed = s.SpeedKmh, s.Course, true
+			if s.SpeedKmh == 0 && s.SpeedKnots != 0 {
+				f.SpeedKmh = s.SpeedKnots * knotsToKmh
+			}
+		case *GSA:
+			gsas = append(gsas, s)
+			if s.Type > f.Type {
+				f.Type = s.Type
+			}
+			f.PDOP, f.VDOP = s.PDOP, s.VDOP
+			if f.HDOP == 0 {
+				f.HDOP = s.HDOP
+			}
+		case *GSV:
+			for _, satellite := range s.Satellites {
+				key := satelliteKey{satellite.System, satellite.PRN}
+				if key.system == ConstellationMultiple || key.system == ConstellationUnknown {
+					key.system = prnConstellation(satellite.PRN)
+					satellite.System = key.system
+				}
+				// the same satellite may be listed for several signals, keep the strongest one
+				if existing, ok := satellites[key]; !ok || satellite.SNR > existing.SNR {
+					satellites[key] = satellite
+				}
+			}
+		}
+	}
+	if hasTime {
+		if f.Time.IsZero() {
+			f.Time = time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)
+		}
+		year, month, day := f.Time.Date()
+		f.Time = time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Add(timeOfDay)
+	}
+	usedSatellites := 0
+	for _, gsa := range gsas {
+		for _, prn := range gsa.SatellitesUsed {
+			usedSatellites++
+			system := gsa.System
+			if system == ConstellationMultiple || system == ConstellationUnknown {
+				system = prnConstellation(prn)
+			}
+			key := satelliteKey{system, prn}
+			if satellite, ok := satellites[key]; ok {
+				satellite.Used = true
+				satellites[key] = satellite
+			}
+		}
+	}
+	if f.SatellitesUsed == 0 {
+		f.SatellitesUsed = usedSatellites
+	}
+	for _, satellite := range satellites {
+		f.Satellites = append(f.Satellites, satellite)
+	}
+	sort.Slice(f.Satellites, func(i, j int) bool {
+		if f.Satellites[i].System != f.Satellites[j].System {
+			return f.Satellites[i].System < f.Satellites[j].System
+		}
+		return f.Satellites[i].PRN < f.Satellites[j].PRN
+	})
+	return f
+}
+
+// SatellitesInView returns the number of satellites in view of the given constellation, or of all constellations
+// for ConstellationMultiple
+func (f Fix) SatellitesInView(system Constellation) int {
+	n := 0
+	for _, satellite := range f.Satellites {
+		if system == ConstellationMultiple || satellite.System == system {
+			n++
+		}
+	}
+	return n
+}
+
+type satelliteKey struct {
+	system Constellation
+	prn    int
+}
+
+// prnConstellation returns the constellation of a satellite by the PRN ranges of NMEA 4.00 and common receivers,
+// used for GN talkers without system id
+func prnConstellation(prn int) Constellation {
+	switch {
+	case prn >= 1 && prn <= 32:
+		return ConstellationGps
+	case prn >= 65 && prn <= 96:
+		return ConstellationGlonass
+	case prn >= 193 && prn <= 202:
+		return ConstellationQzss
+	case prn >= 203 && prn <= 263:
+		return ConstellationBeidou
+	case prn >= 301 && prn <= 336:
+		return ConstellationGalileo
+	case prn >= 401 && prn <= 437:
+		return ConstellationBeidou
+	}
+	return ConstellationUnknown
+}
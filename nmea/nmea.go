@@ -0,0 +1,311 @@
+// Package nmea parses the NMEA 0183 sentences of the GpsNmea location source: GGA, RMC, GSA, GSV, VTG and GNS,
+// sent by any talker, e.g. GP (GPS), GL (GLONASS), GA (Galileo), GB (BeiDou) or GN (multiple constellations).
+// Parse returns a typed sentence, ParseFix merges the sentences of a GpsNmeaLocation into a Fix with position,
+// fix quality, dilution of precision, speed, course and the satellites in view.
+package nmea
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrChecksum is returned for sentences with a wrong checksum
+	ErrChecksum = errors.New("checksum mismatch")
+	// ErrNoChecksum is returned for sentences without checksum
+	ErrNoChecksum = errors.New("missing checksum")
+)
+
+// Constellation is a satellite navigation system
+type Constellation int
+
+const (
+	ConstellationUnknown  Constellation = 0 // Unknown or proprietary talker.
+	ConstellationGps      Constellation = 1 // GPS, talker GP.
+	ConstellationGlonass  Constellation = 2 // GLONASS, talker GL.
+	ConstellationGalileo  Constellation = 3 // Galileo, talker GA.
+	ConstellationBeidou   Constellation = 4 // BeiDou, talker GB or BD.
+	ConstellationQzss     Constellation = 5 // QZSS, talker GQ or QZ.
+	ConstellationNavic    Constellation = 6 // NavIC (IRNSS), talker GI.
+	ConstellationMultiple Constellation = 7 // Combined solution of multiple constellations, talker GN.
+)
+
+func (c Constellation) String() string {
+	switch c {
+	case ConstellationGps:
+		return "GPS"
+	case ConstellationGlonass:
+		return "GLONASS"
+	case ConstellationGalileo:
+		return "Galileo"
+	case ConstellationBeidou:
+		return "BeiDou"
+	case ConstellationQzss:
+		return "QZSS"
+	case ConstellationNavic:
+		return "NavIC"
+	case ConstellationMultiple:
+		return "Multiple"
+	}
+	return "Unknown"
+}
+
+// TalkerConstellation returns the constellation of a talker id
+func TalkerConstellation(talker string) Constellation {
+	switch talker {
+	case "GP":
+		return ConstellationGps
+	case "GL":
+		return ConstellationGlonass
+	case "GA":
+		return ConstellationGalileo
+	case "GB", "BD":
+		return ConstellationBeidou
+	case "GQ", "QZ":
+		return ConstellationQzss
+	case "GI":
+		return ConstellationNavic
+	case "GN":
+		return ConstellationMultiple
+	}
+	return ConstellationUnknown
+}
+
+// systemConstellation returns the constellation of a NMEA 4.10 system id, as given in GSA and GSV
+func systemConstellation(id int) Constellation {
+	switch id {
+	case 1:
+		return ConstellationGps
+	case 2:
+		return ConstellationGlonass
+	case 3:
+		return ConstellationGalileo
+	case 4:
+		return ConstellationBeidou
+	case 5:
+		return ConstellationQzss
+	case 6:
+		return ConstellationNavic
+	}
+	return ConstellationUnknown
+}
+
+// Sentence is a parsed sentence, one of *GGA, *RMC, *GSA, *GSV, *VTG, *GNS or *Base for other sentence types
+type Sentence interface {
+	// GetTalker returns the talker id, e.g. GP
+	GetTalker() string
+	// GetType returns the sentence type, e.g. GGA
+	GetType() string
+}
+
+// Base holds the fields of a sentence
+type Base struct {
+	Talker string   // The talker id, e.g. GP, empty for proprietary sentences
+	Type   string   // The sentence type, e.g. GGA
+	Fields []string // The comma separated fields following the address
+	Raw    string   // The sentence as received
+}
+
+// GetTalker returns the talker id
+func (b *Base) GetTalker() string {
+	return b.Talker
+}
+
+// GetType returns the sentence type
+func (b *Base) GetType() string {
+	return b.Type
+}
+
+// Constellation returns the constellation of the talker
+func (b *Base) Constellation() Constellation {
+	return TalkerConstellation(b.Talker)
+}
+
+// Checksum returns the checksum of the sentence data between $ and *
+func Checksum(data string) byte {
+	var sum byte
+	for i := 0; i < len(data); i++ {
+		sum ^= data[i]
+	}
+	return sum
+}
+
+// parseBase validates the checksum and splits the sentence into its fields
+func parseBase(raw string) (*Base, error) {
+	sentence := strings.TrimSpace(raw)
+	if len(sentence) < 1 || (sentence[0] != '$' && sentence[0] != '!') {
+		return nil, fmt.Errorf("sentence does not start with $: %q", raw)
+	}
+	star := strings.LastIndexByte(sentence, '*')
+	if star < 0 {
+		return nil, ErrNoChecksum
+	}
+	data := sentence[1:star]
+	checksum, err := strconv.ParseUint(sentence[star+1:], 16, 8)
+	if err != nil || len(sentence)-star-1 != 2 {
+		return nil, fmt.Errorf("invalid checksum %q", sentence[star+1:])
+	}
+	if byte(checksum) != Checksum(data) {
+		return nil, ErrChecksum
+	}
+	fields := strings.Split(data, ",")
+	address := fields[0]
+	b := &Base{Fields: fields[1:], Raw: raw}
+	switch {
+	case strings.HasPrefix(address, "P"):
+		// proprietary sentences have no talker
+		b.Type = address
+	case len(address) == 5:
+		b.Talker, b.Type = address[:2], address[2:]
+	default:
+		return nil, fmt.Errorf("invalid address %q", address)
+	}
+	return b, nil
+}
+
+// Parse parses a sentence and validates its checksum. Sentence types without a parser are returned as *Base.
+func Parse(raw string) (Sentence, error) {
+	b, err := parseBase(raw)
+	if err != nil {
+		return nil, err
+	}
+	switch b.Type {
+	case "GGA":
+		return parseGGA(b)
+	case "RMC":
+		return parseRMC(b)
+	case "GSA":
+		return parseGSA(b)
+	case "GSV":
+		return parseGSV(b)
+	case "VTG":
+		return parseVTG(b)
+	case "GNS":
+		return parseGNS(b)
+	}
+	return b, nil
+}
+
+// fieldParser reads the fields of a sentence and keeps the first error
+type fieldParser struct {
+	b   *Base
+	err error
+}
+
+func (p *fieldParser) fail(i int, value string, what string) {
+	if p.err == nil {
+		p.err = fmt.Errorf("invalid %s %q in field %d of %s%s", what, value, i+1, p.b.Talker, p.b.Type)
+	}
+}
+
+// field returns the field i, or an empty string if the sentence has less fields
+func (p *fieldParser) field(i int) string {
+	if i < len(p.b.Fields) {
+		return p.b.Fields[i]
+	}
+	return ""
+}
+
+func (p *fieldParser) float(i int) float64 {
+	value := p.field(i)
+	if value == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		p.fail(i, value, "number")
+	}
+	return f
+}
+
+func (p *fieldParser) int(i int) int {
+	value := p.field(i)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		p.fail(i, value, "integer")
+	}
+	return n
+}
+
+// coordinate parses a latitude (ddmm.mmmm) or longitude (dddmm.mmmm) with its hemisphere in field i+1 as decimal
+// degrees, negative for south and west
+func (p *fieldParser) coordinate(i int) float64 {
+	value, hemisphere := p.field(i), p.field(i+1)
+	if value == "" {
+		return 0
+	}
+	dot := strings.IndexByte(value, '.')
+	if dot < 0 {
+		dot = len(value)
+	}
+	if dot < 3 {
+		p.fail(i, value, "coordinate")
+		return 0
+	}
+	degrees, err := strconv.ParseFloat(value[:dot-2], 64)
+	if err != nil {
+		p.fail(i, value, "coordinate")
+		return 0
+	}
+	minutes, err := strconv.ParseFloat(value[dot-2:], 64)
+	if err != nil {
+		p.fail(i, value, "coordinate")
+		return 0
+	}
+	coordinate := degrees + minutes/60
+	switch hemisphere {
+	case "S", "W":
+		return -coordinate
+	case "N", "E":
+		return coordinate
+	}
+	p.fail(i+1, hemisphere, "hemisphere")
+	return 0
+}
+
+// timeOfDay parses a UTC time hhmmss.sss as duration since midnight
+func (p *fieldParser) timeOfDay(i int) time.Duration {
+	value := p.field(i)
+	if value == "" {
+		return 0
+	}
+	if len(value) < 6 {
+		p.fail(i, value, "time")
+		return 0
+	}
+	hours, err1 := strconv.Atoi(value[0:2])
+	minutes, err2 := strconv.Atoi(value[2:4])
+	seconds, err3 := strconv.ParseFloat(value[4:], 64)
+	if err1 != nil || err2 != nil || err3 != nil || hours > 23 || minutes > 59 || seconds >= 61 {
+		p.fail(i, value, "time")
+		return 0
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)+0.5)
+}
+
+// date parses a date ddmmyy, years before 80 are in the 21st century
+func (p *fieldParser) date(i int) (year int, month time.Month, day int) {
+	value := p.field(i)
+	if value == "" {
+		return
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || len(value) != 6 {
+		p.fail(i, value, "date")
+		return
+	}
+	day, month, year = n/10000, time.Month(n/100%100), n%100
+	if year < 80 {
+		year += 2000
+	} else {
+		year += 1900
+	}
+	return
+}
@@ -0,0 +1,398 @@
+package nmea
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// sentences of the NMEA 0183 reference examples and captures of u-blox receivers, the multi constellation epoch is
+// composed in the NMEA 4.10 format of a u-blox M8 with GPS, GLONASS, Galileo and BeiDou enabled
+const (
+	referenceGGA = "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47"
+	referenceRMC = "$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6A"
+	referenceGSA = "$GPGSA,A,3,04,05,,09,12,,,24,,,,,2.5,1.3,2.1*39"
+	referenceGSV = "$GPGSV,2,1,08,01,40,083,46,02,17,308,41,12,07,344,39,14,22,228,45*75"
+	referenceVTG = "$GPVTG,054.7,T,034.4,M,005.5,N,010.2,K*48"
+
+	ubloxRMC = "$GNRMC,001031.00,A,4404.13993,N,12118.86023,W,0.146,,100117,,,A*7B"
+	ubloxGGA = "$GNGGA,001043.00,4404.14036,N,12118.85961,W,1,12,0.98,1113.0,M,-21.3,M,,*47"
+	ubloxGSV = "$GPGSV,4,1,13,02,02,213,,03,-3,000,,11,00,121,,14,13,172,05*67"
+	ubloxGLL = "$GNGLL,4404.14012,N,12118.85993,W,001037.00,A,A*67"
+	ubloxGNS = "$GNGNS,014035.00,4332.69262,S,17235.48549,E,RR,13,0.9,25.63,11.24,,*70"
+)
+
+var multiConstellationEpoch = []string{
+	"$GNRMC,094512.00,A,5230.81248,N,01323.59812,E,1.204,87.31,150321,,,A,V*38",
+	"$GNVTG,87.31,T,,M,1.204,N,2.230,K,A*1A",
+	"$GNGGA,094512.00,5230.81248,N,01323.59812,E,1,08,0.92,38.4,M,39.6,M,,*7E",
+	"$GNGSA,A,3,05,13,15,18,,,,,,,,,1.62,0.92,1.33,1*04",
+	"$GNGSA,A,3,65,72,,,,,,,,,,,1.62,0.92,1.33,2*0B",
+	"$GNGSA,A,3,07,11,,,,,,,,,,,1.62,0.92,1.33,3*0B",
+	"$GPGSV,2,1,06,05,63,289,43,13,44,104,38,15,32,057,35,18,20,151,31,1*64",
+	"$GPGSV,2,2,06,20,05,325,,29,12,208,22,1*63",
+	"$GPGSV,1,1,02,05,63,289,40,13,44,104,41,8*6A",
+	"$GLGSV,1,1,03,65,34,304,24,72,12,248,19,73,05,011,,1*4F",
+	"$GAGSV,1,1,03,07,43,154,39,11,27,053,31,12,10,270,,7*4C",
+	"$GBGSV,1,1,03,11,75,281,39,12,32,067,36,24,30,201,37,1*42",
+}
+
+func assertFloat(t *testing.T, name string, got float64, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-6 {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	tests := []struct {
+		sentence string
+		err      error
+		valid    bool
+	}{
+		{referenceGGA, nil, true},
+		{ubloxGLL + "\r\n", nil, true},
+		// the checksum is case insensitive
+		{"$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6a", nil, true},
+		{"$GNVTG,,T,,M,,N,,K,N*32", nil, true},
+		{"$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*46", ErrChecksum, false},
+		{"$GPGGA,123519,4807.038,N,01131.000,E,1,09,0.9,545.4,M,46.9,M,,*47", ErrChecksum, false},
+		{"$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,", ErrNoChecksum, false},
+		{"$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*4", nil, false},
+		{"$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*4G", nil, false},
+		{"GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47", nil, false},
+		{"", nil, false},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.sentence)
+		if test.valid != (err == nil) || (test.err != nil && err != test.err) {
+			t.Errorf("Parse(%q) error = %v, want valid %t or error %v", test.sentence, err, test.valid, test.err)
+		}
+	}
+	if got := Checksum("GPVTG,054.7,T,034.4,M,005.5,N,010.2,K"); got != 0x48 {
+		t.Errorf("Checksum = %02X, want 48", got)
+	}
+}
+
+func TestTalkers(t *testing.T) {
+	tests := []struct {
+		sentence string
+		talker   string
+		typ      string
+		system   Constellation
+	}{
+		{referenceGGA, "GP", "GGA", ConstellationGps},
+		{ubloxRMC, "GN", "RMC", ConstellationMultiple},
+		{multiConstellationEpoch[9], "GL", "GSV", ConstellationGlonass},
+		{multiConstellationEpoch[10], "GA", "GSV", ConstellationGalileo},
+		{multiConstellationEpoch[11], "GB", "GSV", ConstellationBeidou},
+		// sentences without parser are returned as Base
+		{ubloxGLL, "GN", "GLL", ConstellationMultiple},
+	}
+	for _, test := range tests {
+		s, err := Parse(test.sentence)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.sentence, err)
+			continue
+		}
+		if s.GetTalker() != test.talker || s.GetType() != test.typ || TalkerConstellation(s.GetTalker()) != test.system {
+			t.Errorf("Parse(%q) = %s%s of %s, want %s%s of %s", test.sentence, s.GetTalker(), s.GetType(),
+				TalkerConstellation(s.GetTalker()), test.talker, test.typ, test.system)
+		}
+	}
+	for talker, want := range map[string]Constellation{"BD": ConstellationBeidou, "GQ": ConstellationQzss, "GI": ConstellationNavic, "XX": ConstellationUnknown} {
+		if got := TalkerConstellation(talker); got != want {
+			t.Errorf("TalkerConstellation(%s) = %s, want %s", talker, got, want)
+		}
+	}
+
+	b := "PUBX,00,094512.00"
+	s, err := Parse("$" + b + "*" + string("0123456789ABCDEF"[Checksum(b)>>4]) + string("0123456789ABCDEF"[Checksum(b)&0x0f]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetTalker() != "" || s.GetType() != "PUBX" {
+		t.Errorf("proprietary sentence parsed as %s%s", s.GetTalker(), s.GetType())
+	}
+}
+
+func TestParseSentences(t *testing.T) {
+	s, err := Parse(referenceGGA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gga := s.(*GGA)
+	if gga.Time != 12*time.Hour+35*time.Minute+19*time.Second || gga.Quality != FixQualityGps || gga.SatellitesUsed != 8 {
+		t.Errorf("got GGA %+v", gga)
+	}
+	assertFloat(t, "latitude", gga.Latitude, 48+7.038/60)
+	assertFloat(t, "longitude", gga.Longitude, 11+31.0/60)
+	assertFloat(t, "altitude", gga.Altitude, 545.4)
+	assertFloat(t, "geoid separation", gga.GeoidSeparation, 46.9)
+
+	s, err = Parse(ubloxGGA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gga = s.(*GGA)
+	assertFloat(t, "western longitude", gga.Longitude, -(121 + 18.85961/60))
+	assertFloat(t, "negative geoid separation", gga.GeoidSeparation, -21.3)
+
+	s, err = Parse(referenceRMC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rmc := s.(*RMC)
+	if !rmc.Valid || !rmc.Time.Equal(time.Date(1994, time.March, 23, 12, 35, 19, 0, time.UTC)) {
+		t.Errorf("got RMC %+v", rmc)
+	}
+	assertFloat(t, "speed", rmc.SpeedKnots, 22.4)
+	assertFloat(t, "magnetic variation", rmc.MagneticVariation, -3.1)
+
+	s, err = Parse(ubloxRMC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rmc = s.(*RMC)
+	if !rmc.Time.Equal(time.Date(2017, time.January, 10, 0, 10, 31, 0, time.UTC)) || rmc.Mode != ModeAutonomous || rmc.Course != 0 {
+		t.Errorf("got RMC %+v", rmc)
+	}
+
+	s, err = Parse(referenceGSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gsa := s.(*GSA)
+	if !gsa.Automatic || gsa.Type != FixType3D || len(gsa.SatellitesUsed) != 5 || gsa.SatellitesUsed[4] != 24 ||
+		gsa.System != ConstellationGps {
+		t.Errorf("got GSA %+v", gsa)
+	}
+	assertFloat(t, "pdop", gsa.PDOP, 2.5)
+	assertFloat(t, "vdop", gsa.VDOP, 2.1)
+
+	s, err = Parse(referenceVTG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vtg := s.(*VTG)
+	assertFloat(t, "course", vtg.Course, 54.7)
+	assertFloat(t, "speed", vtg.SpeedKmh, 10.2)
+
+	s, err = Parse(ubloxGNS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gns := s.(*GNS)
+	if !gns.Valid() || gns.ModeOf(ConstellationGps) != ModeRtk || gns.ModeOf(ConstellationGalileo) != "" || gns.SatellitesUsed != 13 {
+		t.Errorf("got GNS %+v", gns)
+	}
+	assertFloat(t, "southern latitude", gns.Latitude, -(43 + 32.69262/60))
+
+	for _, sentence := range []string{
+		"$GPGGA,123519,4807.038,X,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*5D",
+		"$GPGGA,253519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*45",
+		"$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,2303,003.1,W*6A",
+	} {
+		if s, err := Parse(sentence); err == nil {
+			t.Errorf("Parse(%q) = %+v, want error", sentence, s)
+		}
+	}
+}
+
+func TestParseGSV(t *testing.T) {
+	tests := []struct {
+		name       string
+		sentence   string
+		signal     string
+		satellites []Satellite
+	}{
+		{
+			name:     "NMEA 4.00 without signal id",
+			sentence: referenceGSV,
+			satellites: []Satellite{
+				{System: ConstellationGps, PRN: 1, Elevation: 40, Azimuth: 83, SNR: 46},
+				{System: ConstellationGps, PRN: 2, Elevation: 17, Azimuth: 308, SNR: 41},
+				{System: ConstellationGps, PRN: 12, Elevation: 7, Azimuth: 344, SNR: 39},
+				{System: ConstellationGps, PRN: 14, Elevation: 22, Azimuth: 228, SNR: 45},
+			},
+		},
+		{
+			name:     "not tracked and below the horizon",
+			sentence: ubloxGSV,
+			satellites: []Satellite{
+				{System: ConstellationGps, PRN: 2, Elevation: 2, Azimuth: 213},
+				{System: ConstellationGps, PRN: 3, Elevation: -3},
+				{System: ConstellationGps, PRN: 11, Azimuth: 121},
+				{System: ConstellationGps, PRN: 14, Elevation: 13, Azimuth: 172, SNR: 5},
+			},
+		},
+		{
+			name:     "signal id after a full sentence",
+			sentence: multiConstellationEpoch[6],
+			signal:   "1",
+			satellites: []Satellite{
+				{System: ConstellationGps, PRN: 5, Elevation: 63, Azimuth: 289, SNR: 43},
+				{System: ConstellationGps, PRN: 13, Elevation: 44, Azimuth: 104, SNR: 38},
+				{System: ConstellationGps, PRN: 15, Elevation: 32, Azimuth: 57, SNR: 35},
+				{System: ConstellationGps, PRN: 18, Elevation: 20, Azimuth: 151, SNR: 31},
+			},
+		},
+		{
+			name:     "signal id after a partial sentence",
+			sentence: multiConstellationEpoch[7],
+			signal:   "1",
+			satellites: []Satellite{
+				{System: ConstellationGps, PRN: 20, Elevation: 5, Azimuth: 325},
+				{System: ConstellationGps, PRN: 29, Elevation: 12, Azimuth: 208, SNR: 22},
+			},
+		},
+		{
+			name:     "galileo E5b",
+			sentence: multiConstellationEpoch[10],
+			signal:   "7",
+			satellites: []Satellite{
+				{System: ConstellationGalileo, PRN: 7, Elevation: 43, Azimuth: 154, SNR: 39},
+				{System: ConstellationGalileo, PRN: 11, Elevation: 27, Azimuth: 53, SNR: 31},
+				{System: ConstellationGalileo, PRN: 12, Elevation: 10, Azimuth: 270},
+			},
+		},
+	}
+	for _, test := range tests {
+		s, err := Parse(test.sentence)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		gsv := s.(*GSV)
+		if gsv.Signal != test.signal {
+			t.Errorf("%s: signal = %q, want %q", test.name, gsv.Signal, test.signal)
+		}
+		if len(gsv.Satellites) != len(test.satellites) {
+			t.Errorf("%s: got satellites %+v, want %+v", test.name, gsv.Satellites, test.satellites)
+			continue
+		}
+		for i, satellite := range gsv.Satellites {
+			if satellite != test.satellites[i] {
+				t.Errorf("%s: satellite %d = %+v, want %+v", test.name, i, satellite, test.satellites[i])
+			}
+		}
+	}
+}
+
+func TestNewFix(t *testing.T) {
+	f, err := ParseFix([]string{referenceGGA, referenceRMC, referenceGSA, referenceGSV, referenceVTG})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Valid || !f.HasPosition || !f.HasSpeed || f.Quality != FixQualityGps || f.Type != FixType3D || f.SatellitesUsed != 8 {
+		t.Errorf("got fix %+v", f)
+	}
+	if !f.Time.Equal(time.Date(1994, time.March, 23, 12, 35, 19, 0, time.UTC)) {
+		t.Errorf("time = %s, want the date of RMC with the time of GGA", f.Time)
+	}
+	if !f.HasAltitude {
+		t.Error("altitude of GGA not reported")
+	}
+	assertFloat(t, "altitude", f.Altitude, 545.4)
+	// VTG takes precedence over the speed of RMC
+	assertFloat(t, "speed", f.SpeedKmh, 10.2)
+	assertFloat(t, "hdop", f.HDOP, 0.9)
+	assertFloat(t, "pdop", f.PDOP, 2.5)
+	// of the satellites of the first GSV only 12 is listed by GSA
+	if len(f.Satellites) != 4 || f.Satellites[0].Used || !f.Satellites[2].Used || f.Satellites[3].Used {
+		t.Errorf("got satellites %+v, want 12 used", f.Satellites)
+	}
+}
+
+func TestNewFixMultipleConstellations(t *testing.T) {
+	f, err := ParseFix(multiConstellationEpoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Valid || f.Type != FixType3D || f.SatellitesUsed != 8 {
+		t.Errorf("got fix %+v", f)
+	}
+	if !f.Time.Equal(time.Date(2021, time.March, 15, 9, 45, 12, 0, time.UTC)) {
+		t.Errorf("time = %s", f.Time)
+	}
+	assertFloat(t, "latitude", f.Latitude, 52+30.81248/60)
+	assertFloat(t, "speed", f.SpeedKmh, 2.23)
+	inView := map[Constellation]int{
+		ConstellationGps:      6,
+		ConstellationGlonass:  3,
+		ConstellationGalileo:  3,
+		ConstellationBeidou:   3,
+		ConstellationMultiple: 15,
+	}
+	for system, want := range inView {
+		if got := f.SatellitesInView(system); got != want {
+			t.Errorf("satellites in view of %s = %d, want %d", system, got, want)
+		}
+	}
+	used := make(map[Constellation]int)
+	for i, satellite := range f.Satellites {
+		if satellite.Used {
+			used[satellite.System]++
+		}
+		if i > 0 {
+			previous := f.Satellites[i-1]
+			if previous.System > satellite.System || (previous.System == satellite.System && previous.PRN >= satellite.PRN) {
+				t.Errorf("satellites not ordered: %+v before %+v", previous, satellite)
+			}
+		}
+		// the satellites listed for L1 and L2 keep the stronger signal
+		if satellite.System == ConstellationGps && satellite.PRN == 13 && satellite.SNR != 41 {
+			t.Errorf("got %+v, want the SNR of the stronger signal", satellite)
+		}
+		if satellite.System == ConstellationGps && satellite.PRN == 5 && satellite.SNR != 43 {
+			t.Errorf("got %+v, want the SNR of the stronger signal", satellite)
+		}
+	}
+	// the GSA system ids tell apart satellites with the same PRN, e.g. GPS and Galileo 07 and 11
+	want := map[Constellation]int{ConstellationGps: 4, ConstellationGlonass: 2, ConstellationGalileo: 2}
+	for system, n := range want {
+		if used[system] != n {
+			t.Errorf("used satellites of %s = %d, want %d", system, used[system], n)
+		}
+	}
+	if used[ConstellationBeidou] != 0 {
+		t.Errorf("got used BeiDou satellites without BeiDou GSA")
+	}
+}
+
+func TestNewFixWithoutPosition(t *testing.T) {
+	// the GGA of a receiver without fix is followed by a RMC of a later epoch with position
+	f, err := ParseFix([]string{
+		"$GPGGA,094513.00,,,,,0,00,99.99,,,,,,*6C",
+		"$GNVTG,,T,,M,,N,,K,N*32",
+		"$GPRMC,094513.00,A,5230.81250,N,01323.59815,E,0.500,90.00,150321,,,A*55",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Valid || !f.HasPosition || f.Quality != FixQualityInvalid || f.HasAltitude {
+		t.Errorf("got fix %+v, want the position of RMC", f)
+	}
+	// VTG without fix is ignored
+	assertFloat(t, "speed", f.SpeedKmh, 0.5*knotsToKmh)
+	assertFloat(t, "course", f.Course, 90)
+
+	f, err = ParseFix([]string{ubloxGNS})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Valid || !f.HasPosition || f.Time.Year() != 0 || f.Time.Hour() != 1 {
+		t.Errorf("got fix %+v of GNS without date", f)
+	}
+	assertFloat(t, "altitude", f.Altitude, 25.63)
+}
+
+func TestParseFixInvalidSentence(t *testing.T) {
+	f, err := ParseFix([]string{"$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*46", referenceRMC})
+	if err != ErrChecksum {
+		t.Errorf("got error %v, want ErrChecksum", err)
+	}
+	if !f.Valid || f.Quality != FixQualityInvalid {
+		t.Errorf("got fix %+v, want the fix of RMC", f)
+	}
+}
@@ -0,0 +1,311 @@
+package nmea
+
+import (
+	"fmt"
+	"time"
+)
+
+// FixQuality is the GPS quality indicator of GGA sentences
+type FixQuality int
+
+const (
+	FixQualityInvalid    FixQuality = 0 // Fix not available or invalid.
+	FixQualityGps        FixQuality = 1 // Autonomous GNSS fix.
+	FixQualityDgps       FixQuality = 2 // Differential GNSS fix.
+	FixQualityPps        FixQuality = 3 // Precise positioning service fix.
+	FixQualityRtk        FixQuality = 4 // Real time kinematic with fixed integers.
+	FixQualityFloatRtk   FixQuality = 5 // Real time kinematic with float integers.
+	FixQualityEstimated  FixQuality = 6 // Estimated (dead reckoning) fix.
+	FixQualityManual     FixQuality = 7 // Manual input mode.
+	FixQualitySimulation FixQuality = 8 // Simulation mode.
+)
+
+func (q FixQuality) String() string {
+	switch q {
+	case FixQualityInvalid:
+		return "Invalid"
+	case FixQualityGps:
+		return "Gps"
+	case FixQualityDgps:
+		return "Dgps"
+	case FixQualityPps:
+		return "Pps"
+	case FixQualityRtk:
+		return "Rtk"
+	case FixQualityFloatRtk:
+		return "FloatRtk"
+	case FixQualityEstimated:
+		return "Estimated"
+	case FixQualityManual:
+		return "Manual"
+	case FixQualitySimulation:
+		return "Simulation"
+	}
+	return fmt.Sprintf("FixQuality(%d)", int(q))
+}
+
+// FixType is the fix type of GSA sentences
+type FixType int
+
+const (
+	FixTypeUnknown FixType = 0 // Fix type not given.
+	FixTypeNone    FixType = 1 // No fix.
+	FixType2D      FixType = 2 // Two dimensional fix, without altitude.
+	FixType3D      FixType = 3 // Three dimensional fix.
+)
+
+func (t FixType) String() string {
+	switch t {
+	case FixTypeUnknown:
+		return "Unknown"
+	case FixTypeNone:
+		return "None"
+	case FixType2D:
+		return "2D"
+	case FixType3D:
+		return "3D"
+	}
+	return fmt.Sprintf("FixType(%d)", int(t))
+}
+
+// Mode indicators of RMC, VTG and GNS sentences
+const (
+	ModeAutonomous   = "A" // Autonomous fix.
+	ModeDifferential = "D" // Differential fix.
+	ModeEstimated    = "E" // Estimated (dead reckoning) fix.
+	ModeFloatRtk     = "F" // Real time kinematic with float integers.
+	ModeManual       = "M" // Manual input mode.
+	ModeNotValid     = "N" // No fix.
+	ModePrecise      = "P" // Precise fix.
+	ModeRtk          = "R" // Real time kinematic with fixed integers.
+	ModeSimulator    = "S" // Simulation mode.
+)
+
+// GGA is the time, position and fix quality
+type GGA struct {
+	*Base
+	Time            time.Duration // UTC time of the fix since midnight
+	Latitude        float64       // Latitude in decimal degrees, negative in the southern hemisphere
+	Longitude       float64       // Longitude in decimal degrees, negative in the western hemisphere
+	Quality         FixQuality    // The fix quality
+	SatellitesUsed  int           // Number of satellites used for the fix
+	HDOP            float64       // Horizontal dilution of precision
+	Altitude        float64       // Altitude above mean sea level in meters
+	HasAltitude     bool          // True if the altitude is given
+	GeoidSeparation float64       // Height of the geoid above the WGS84 ellipsoid in meters
+	DgpsAge         float64       // Age of the differential corrections in seconds
+	DgpsStation     string        // Id of the differential reference station
+}
+
+func parseGGA(b *Base) (*GGA, error) {
+	p := fieldParser{b: b}
+	s := &GGA{
+		Base:            b,
+		Time:            p.timeOfDay(0),
+		Latitude:        p.coordinate(1),
+		Longitude:       p.coordinate(3),
+		Quality:         FixQuality(p.int(5)),
+		SatellitesUsed:  p.int(6),
+		HDOP:            p.float(7),
+		Altitude:        p.float(8),
+		HasAltitude:     p.field(8) != "",
+		GeoidSeparation: p.float(10),
+		DgpsAge:         p.float(12),
+		DgpsStation:     p.field(13),
+	}
+	return s, p.err
+}
+
+// RMC is the recommended minimum data: time, date, position, speed and course
+type RMC struct {
+	*Base
+	Time              time.Time // UTC date and time of the fix, zero if the date is missing
+	Valid             bool      // True if the status is A (valid), false for V (warning)
+	Latitude          float64   // Latitude in decimal degrees, negative in the southern hemisphere
+	Longitude         float64   // Longitude in decimal degrees, negative in the western hemisphere
+	SpeedKnots        float64   // Speed over ground in knots
+	Course            float64   // Course over ground in degrees true north
+	MagneticVariation float64   // Magnetic variation in degrees, negative to the west
+	Mode              string    // Mode indicator since NMEA 2.3, e.g. ModeAutonomous
+}
+
+func parseRMC(b *Base) (*RMC, error) {
+	p := fieldParser{b: b}
+	s := &RMC{
+		Base:              b,
+		Valid:             p.field(1) == "A",
+		Latitude:          p.coordinate(2),
+		Longitude:         p.coordinate(4),
+		SpeedKnots:        p.float(6),
+		Course:            p.float(7),
+		MagneticVariation: p.float(9),
+		Mode:              p.field(11),
+	}
+	if p.field(10) == "W" {
+		s.MagneticVariation = -s.MagneticVariation
+	}
+	timeOfDay := p.timeOfDay(0)
+	if year, month, day := p.date(8); year > 0 {
+		s.Time = time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Add(timeOfDay)
+	}
+	return s, p.err
+}
+
+// GSA is the fix type, the satellites used and the dilution of precision
+type GSA struct {
+	*Base
+	Automatic      bool          // True if the receiver selects between 2D and 3D automatically
+	Type           FixType       // The fix type
+	SatellitesUsed []int         // PRN numbers of the satellites used for the fix
+	PDOP           float64       // Position dilution of precision
+	HDOP           float64       // Horizontal dilution of precision
+	VDOP           float64       // Vertical dilution of precision
+	System         Constellation // Constellation of the satellites, by system id since NMEA 4.10 or else by talker
+}
+
+func parseGSA(b *Base) (*GSA, error) {
+	p := fieldParser{b: b}
+	s := &GSA{
+		Base:      b,
+		Automatic: p.field(0) == "A",
+		Type:      FixType(p.int(1)),
+		PDOP:      p.float(14),
+		HDOP:      p.float(15),
+		VDOP:      p.float(16),
+		System:    systemConstellation(p.int(17)),
+	}
+	for i := 2; i < 14; i++ {
+		if p.field(i) != "" {
+			s.SatellitesUsed = append(s.SatellitesUsed, p.int(i))
+		}
+	}
+	if s.System == ConstellationUnknown {
+		s.System = b.Constellation()
+	}
+	return s, p.err
+}
+
+// Satellite is a satellite in view
+type Satellite struct {
+	System    Constellation `json:"system"`    // The constellation of the satellite
+	PRN       int           `json:"prn"`       // The PRN number of the satellite
+	Elevation int           `json:"elevation"` // Elevation in degrees, 0 to 90
+	Azimuth   int           `json:"azimuth"`   // Azimuth in degrees true north, 0 to 359
+	SNR       int           `json:"snr"`       // Signal to noise ratio in dB-Hz, 0 if not tracked
+	Used      bool          `json:"used"`      // True if the satellite is used for the fix, see GSA
+}
+
+// GSV is one of the sentences listing the satellites in view
+type GSV struct {
+	*Base
+	Total            int         // Total number of GSV sentences of this talker
+	Number           int         // 1-based number of this sentence
+	SatellitesInView int         // Total number of satellites in view
+	Satellites       []Satellite // Up to 4 satellites of this sentence
+	Signal           string      // Signal id since NMEA 4.10, e.g. 1 for GPS L1 C/A
+}
+
+func parseGSV(b *Base) (*GSV, error) {
+	p := fieldParser{b: b}
+	s := &GSV{
+		Base:             b,
+		Total:            p.int(0),
+		Number:           p.int(1),
+		SatellitesInView: p.int(2),
+	}
+	// each satellite has 4 fields, an odd number of remaining fields is the signal id
+	n := len(b.Fields) - 3
+	if n%4 == 1 {
+		s.Signal = p.field(len(b.Fields) - 1)
+	}
+	for i := 3; i+4 <= 3+n-n%4; i += 4 {
+		if p.field(i) == "" {
+			continue
+		}
+		s.Satellites = append(s.Satellites, Satellite{
+			System:    b.Constellation(),
+			PRN:       p.int(i),
+			Elevation: p.int(i + 1),
+			Azimuth:   p.int(i + 2),
+			SNR:       p.int(i + 3),
+		})
+	}
+	return s, p.err
+}
+
+// VTG is the course and speed over ground
+type VTG struct {
+	*Base
+	Course         float64 // Course over ground in degrees true north
+	CourseMagnetic float64 // Course over ground in degrees magnetic north
+	SpeedKnots     float64 // Speed over ground in knots
+	SpeedKmh       float64 // Speed over ground in km/h
+	Mode           string  // Mode indicator since NMEA 2.3, e.g. ModeAutonomous
+}
+
+func parseVTG(b *Base) (*VTG, error) {
+	p := fieldParser{b: b}
+	s := &VTG{
+		Base:           b,
+		Course:         p.float(0),
+		CourseMagnetic: p.float(2),
+		SpeedKnots:     p.float(4),
+		SpeedKmh:       p.float(6),
+		Mode:           p.field(8),
+	}
+	return s, p.err
+}
+
+// GNS is the time, position and fix data of a multi constellation receiver
+type GNS struct {
+	*Base
+	Time            time.Duration // UTC time of the fix since midnight
+	Latitude        float64       // Latitude in decimal degrees, negative in the southern hemisphere
+	Longitude       float64       // Longitude in decimal degrees, negative in the western hemisphere
+	Modes           string        // Mode indicator per constellation in the order GPS, GLONASS, Galileo, BeiDou, ...
+	SatellitesUsed  int           // Number of satellites used for the fix
+	HDOP            float64       // Horizontal dilution of precision
+	Altitude        float64       // Altitude above mean sea level in meters
+	HasAltitude     bool          // True if the altitude is given
+	GeoidSeparation float64       // Height of the geoid above the WGS84 ellipsoid in meters
+	DgpsAge         float64       // Age of the differential corrections in seconds
+	DgpsStation     string        // Id of the differential reference station
+}
+
+func parseGNS(b *Base) (*GNS, error) {
+	p := fieldParser{b: b}
+	s := &GNS{
+		Base:            b,
+		Time:            p.timeOfDay(0),
+		Latitude:        p.coordinate(1),
+		Longitude:       p.coordinate(3),
+		Modes:           p.field(5),
+		SatellitesUsed:  p.int(6),
+		HDOP:            p.float(7),
+		Altitude:        p.float(8),
+		HasAltitude:     p.field(8) != "",
+		GeoidSeparation: p.float(9),
+		DgpsAge:         p.float(10),
+		DgpsStation:     p.field(11),
+	}
+	return s, p.err
+}
+
+// Valid returns true if any constellation has a fix
+func (s *GNS) Valid() bool {
+	for _, mode := range s.Modes {
+		if mode != 'N' {
+			return true
+		}
+	}
+	return false
+}
+
+// ModeOf returns the mode indicator of the given constellation, or an empty string if not reported
+func (s *GNS) ModeOf(system Constellation) string {
+	i := int(system) - 1
+	if system == ConstellationUnknown || system == ConstellationMultiple || i >= len(s.Modes) {
+		return ""
+	}
+	return s.Modes[i : i+1]
+}
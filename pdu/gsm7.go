@@ -0,0 +1,236 @@
+package pdu
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Language is a national language of the GSM 7 bit alphabet as defined in 3GPP TS 23.038, section 6.2.1.2.4
+type Language byte
+
+const (
+	LanguageDefault    Language = 0 // The GSM 7 bit default alphabet and its extension table.
+	LanguageTurkish    Language = 1 // Turkish locking and single shift tables.
+	LanguageSpanish    Language = 2 // Spanish single shift table, Spanish has no locking shift table.
+	LanguagePortuguese Language = 3 // Portuguese locking and single shift tables.
+)
+
+func (l Language) String() string {
+	switch l {
+	case LanguageDefault:
+		return "Default"
+	case LanguageTurkish:
+		return "Turkish"
+	case LanguageSpanish:
+		return "Spanish"
+	case LanguagePortuguese:
+		return "Portuguese"
+	}
+	return fmt.Sprintf("Language(%d)", byte(l))
+}
+
+const (
+	gsm7Escape = 0x1b
+	gsm7Space  = 0x20
+)
+
+// locking shift tables, indexed by the septet, the escape code 0x1b is no character
+var lockingShiftTables = map[Language][]rune{
+	LanguageDefault: []rune("@£$¥èéùìòÇ\nØø\rÅå" +
+		"Δ_ΦΓΛΩΠΨΣΘΞ\x1bÆæßÉ" +
+		" !\"#¤%&'()*+,-./" +
+		"0123456789:;<=>?" +
+		"¡ABCDEFGHIJKLMNO" +
+		"PQRSTUVWXYZÄÖÑÜ§" +
+		"¿abcdefghijklmno" +
+		"pqrstuvwxyzäöñüà"),
+	LanguageTurkish: []rune("@£$¥€éùıòÇ\nĞğ\rÅå" +
+		"Δ_ΦΓΛΩΠΨΣΘΞ\x1bŞşßÉ" +
+		" !\"#¤%&'()*+,-./" +
+		"0123456789:;<=>?" +
+		"İABCDEFGHIJKLMNO" +
+		"PQRSTUVWXYZÄÖÑÜ§" +
+		"çabcdefghijklmno" +
+		"pqrstuvwxyzäöñüà"),
+	LanguagePortuguese: []rune("@£$¥êéúíóç\nÔô\rÁá" +
+		"Δ_ªÇÀ∞^\\€Ó|\x1bÂâÊÉ" +
+		" !\"#º%&'()*+,-./" +
+		"0123456789:;<=>?" +
+		"ÍABCDEFGHIJKLMNO" +
+		"PQRSTUVWXYZÃÕÚÜ§" +
+		"~abcdefghijklmno" +
+		"pqrstuvwxyzãõ`üà"),
+}
+
+// single shift tables, the characters following an escape code
+var singleShiftTables = map[Language]map[byte]rune{
+	LanguageDefault: {
+		0x0a: '\f', 0x14: '^', 0x28: '{', 0x29: '}', 0x2f: '\\', 0x3c: '[', 0x3d: '~', 0x3e: ']', 0x40: '|',
+		0x65: '€',
+	},
+	LanguageTurkish: {
+		0x0a: '\f', 0x14: '^', 0x28: '{', 0x29: '}', 0x2f: '\\', 0x3c: '[', 0x3d: '~', 0x3e: ']', 0x40: '|',
+		0x47: 'Ğ', 0x49: 'İ', 0x53: 'Ş', 0x63: 'ç', 0x65: '€', 0x67: 'ğ', 0x69: 'ı', 0x73: 'ş',
+	},
+	LanguageSpanish: {
+		0x09: 'ç', 0x0a: '\f', 0x14: '^', 0x28: '{', 0x29: '}', 0x2f: '\\', 0x3c: '[', 0x3d: '~', 0x3e: ']',
+		0x40: '|', 0x41: 'Á', 0x49: 'Í', 0x4f: 'Ó', 0x55: 'Ú', 0x61: 'á', 0x65: '€', 0x69: 'í', 0x6f: 'ó',
+		0x75: 'ú',
+	},
+	LanguagePortuguese: {
+		0x05: 'ê', 0x09: 'ç', 0x0a: '\f', 0x0b: 'Ô', 0x0c: 'ô', 0x0e: 'Á', 0x0f: 'á', 0x12: 'Φ', 0x13: 'Γ',
+		0x14: '^', 0x15: 'Ω', 0x16: 'Π', 0x17: 'Ψ', 0x18: 'Σ', 0x19: 'Θ', 0x1f: 'Ê', 0x28: '{', 0x29: '}',
+		0x2f: '\\', 0x3c: '[', 0x3d: '~', 0x3e: ']', 0x40: '|', 0x41: 'À', 0x49: 'Í', 0x4f: 'Ó', 0x55: 'Ú',
+		0x5b: 'Ã', 0x5c: 'Õ', 0x61: 'â', 0x65: '€', 0x69: 'í', 0x6f: 'ó', 0x75: 'ú', 0x7b: 'ã', 0x7c: 'õ',
+	},
+}
+
+// HasLockingShiftTable returns true if the language defines a locking shift table
+func (l Language) HasLockingShiftTable() bool {
+	_, ok := lockingShiftTables[l]
+	return ok
+}
+
+// HasSingleShiftTable returns true if the language defines a single shift table
+func (l Language) HasSingleShiftTable() bool {
+	_, ok := singleShiftTables[l]
+	return ok
+}
+
+// gsm7Charset maps the characters of a combination of a locking and a single shift table to septets
+type gsm7Charset struct {
+	locking     Language
+	single      Language
+	lockingMap  map[rune]byte
+	singleMap   map[rune]byte
+	lockingRune []rune
+	singleRune  map[byte]rune
+}
+
+func newGsm7Charset(locking Language, single Language) (*gsm7Charset, error) {
+	lockingTable, ok := lockingShiftTables[locking]
+	if !ok {
+		return nil, errors.New("no locking shift table for language " + locking.String())
+	}
+	singleTable, ok := singleShiftTables[single]
+	if !ok {
+		return nil, errors.New("no single shift table for language " + single.String())
+	}
+	cs := &gsm7Charset{
+		locking:     locking,
+		single:      single,
+		lockingMap:  make(map[rune]byte, len(lockingTable)),
+		singleMap:   make(map[rune]byte, len(singleTable)),
+		lockingRune: lockingTable,
+		singleRune:  singleTable,
+	}
+	for septet, r := range lockingTable {
+		if septet != gsm7Escape {
+			cs.lockingMap[r] = byte(septet)
+		}
+	}
+	for septet, r := range singleTable {
+		cs.singleMap[r] = septet
+	}
+	return cs, nil
+}
+
+// septets returns the number of septets of r, or 0 if r can't be encoded
+func (cs *gsm7Charset) septets(r rune) int {
+	if _, ok := cs.lockingMap[r]; ok {
+		return 1
+	}
+	if _, ok := cs.singleMap[r]; ok {
+		return 2
+	}
+	return 0
+}
+
+// encode returns the septets of text, characters of the single shift table are preceded by the escape code
+func (cs *gsm7Charset) encode(text string) ([]byte, error) {
+	septets := make([]byte, 0, len(text))
+	for _, r := range text {
+		if septet, ok := cs.lockingMap[r]; ok {
+			septets = append(septets, septet)
+		} else if septet, ok := cs.singleMap[r]; ok {
+			septets = append(septets, gsm7Escape, septet)
+		} else {
+			return nil, fmt.Errorf("character %q is not in the GSM 7 bit alphabet", r)
+		}
+	}
+	return septets, nil
+}
+
+// decode returns the text of the septets. Unknown characters of the single shift table are decoded as the
+// character of the locking shift table, as recommended by 3GPP TS 23.038.
+func (cs *gsm7Charset) decode(septets []byte) string {
+	runes := make([]rune, 0, len(septets))
+	for i := 0; i < len(septets); i++ {
+		septet := septets[i] & 0x7f
+		if septet != gsm7Escape {
+			runes = append(runes, cs.lockingRune[septet])
+			continue
+		}
+		if i+1 >= len(septets) {
+			// a trailing escape code is shown as space
+			runes = append(runes, ' ')
+			break
+		}
+		i++
+		septet = septets[i] & 0x7f
+		if r, ok := cs.singleRune[septet]; ok {
+			runes = append(runes, r)
+		} else if septet == gsm7Escape {
+			runes = append(runes, ' ')
+		} else {
+			runes = append(runes, cs.lockingRune[septet])
+		}
+	}
+	return string(runes)
+}
+
+var defaultCharset, _ = newGsm7Charset(LanguageDefault, LanguageDefault)
+
+// IsGsm7 returns true if text can be encoded with the GSM 7 bit default alphabet and its extension table
+func IsGsm7(text string) bool {
+	for _, r := range text {
+		if defaultCharset.septets(r) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// packSeptets packs the septets into octets, starting with fill zero bits
+func packSeptets(septets []byte, fill int) []byte {
+	bits := fill + len(septets)*7
+	octets := make([]byte, (bits+7)/8)
+	pos := fill
+	for _, septet := range septets {
+		septet &= 0x7f
+		octets[pos/8] |= septet << uint(pos%8)
+		if pos%8 > 1 {
+			octets[pos/8+1] |= septet >> uint(8-pos%8)
+		}
+		pos += 7
+	}
+	return octets
+}
+
+// unpackSeptets returns count septets of the octets, skipping fill bits
+func unpackSeptets(octets []byte, fill int, count int) ([]byte, error) {
+	if fill+count*7 > len(octets)*8 {
+		return nil, errors.New("user data shorter than its length")
+	}
+	septets := make([]byte, count)
+	pos := fill
+	for i := range septets {
+		septet := octets[pos/8] >> uint(pos%8)
+		if pos%8 > 1 {
+			septet |= octets[pos/8+1] << uint(8-pos%8)
+		}
+		septets[i] = septet & 0x7f
+		pos += 7
+	}
+	return septets, nil
+}
@@ -0,0 +1,381 @@
+package pdu
+
+import (
+	"errors"
+	"time"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+// Validity period formats of the first octet of a SMS-SUBMIT
+const (
+	validityNone     = 0x00
+	validityEnhanced = 0x08
+	validityRelative = 0x10
+	validityAbsolute = 0x18
+)
+
+// Submit is a SMS-SUBMIT, a message sent by the mobile
+type Submit struct {
+	SMSC                Address       // The service centre, empty to use the one stored in the SIM
+	RejectDuplicates    bool          // Reject a message with the same reference and destination still held by the service centre
+	ReplyPath           bool          // Request a reply path
+	StatusReportRequest bool          // Request a status report
+	MessageReference    byte          // The reference of the message, usually set by the modem
+	Destination         Address       // The recipient
+	ProtocolIdentifier  byte          // The TP-Protocol-Identifier, 0 for plain messages
+	Class               MessageClass  // The message class
+	ValidityPeriod      time.Duration // The relative validity period, rounded up to the next representable period, 0 if not given
+	ValidityTime        time.Time     // The absolute validity period, takes precedence over ValidityPeriod if not zero
+	UserData            UserData      // The content
+}
+
+// NewSubmits returns the SMS-SUBMIT PDUs needed to send text to destination, see SplitText
+func NewSubmits(destination string, text string, reference byte, languages ...Language) ([]*Submit, error) {
+	userData, err := SplitText(text, reference, languages...)
+	if err != nil {
+		return nil, err
+	}
+	submits := make([]*Submit, 0, len(userData))
+	for _, ud := range userData {
+		submits = append(submits, &Submit{Destination: NewAddress(destination), UserData: ud})
+	}
+	return submits, nil
+}
+
+// MessageType returns MessageTypeSubmit
+func (s *Submit) MessageType() MessageType {
+	return MessageTypeSubmit
+}
+
+// Encode returns the PDU including the leading SMSC address
+func (s *Submit) Encode() ([]byte, error) {
+	b, err := encodeSmscAddress(s.SMSC)
+	if err != nil {
+		return nil, err
+	}
+	firstOctet := byte(MessageTypeSubmit)
+	var validity []byte
+	if !s.ValidityTime.IsZero() {
+		firstOctet |= validityAbsolute
+		validity = encodeTimestamp(s.ValidityTime)
+	} else if s.ValidityPeriod > 0 {
+		firstOctet |= validityRelative
+		validity = []byte{encodeRelativeValidity(s.ValidityPeriod)}
+	}
+	if s.RejectDuplicates {
+		firstOctet |= 0x04
+	}
+	if s.StatusReportRequest {
+		firstOctet |= 0x20
+	}
+	if len(s.UserData.Header) > 0 {
+		firstOctet |= 0x40
+	}
+	if s.ReplyPath {
+		firstOctet |= 0x80
+	}
+	destination, err := s.Destination.encode()
+	if err != nil {
+		return nil, err
+	}
+	length, userData, err := s.UserData.encode()
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, firstOctet, s.MessageReference)
+	b = append(b, destination...)
+	b = append(b, s.ProtocolIdentifier, encodeDataCodingScheme(s.UserData.Encoding, s.Class))
+	b = append(b, validity...)
+	b = append(b, length)
+	return append(b, userData...), nil
+}
+
+func (s *Submit) decode(b []byte) (err error) {
+	if len(b) < 2 {
+		return errors.New("sms-submit too short")
+	}
+	firstOctet := b[0]
+	s.RejectDuplicates = firstOctet&0x04 != 0
+	s.StatusReportRequest = firstOctet&0x20 != 0
+	s.ReplyPath = firstOctet&0x80 != 0
+	s.MessageReference = b[1]
+	var n int
+	s.Destination, n, err = decodeAddress(b[2:])
+	if err != nil {
+		return err
+	}
+	b = b[2+n:]
+	if len(b) < 2 {
+		return errors.New("sms-submit too short")
+	}
+	s.ProtocolIdentifier = b[0]
+	encoding, class, err := decodeDataCodingScheme(b[1])
+	if err != nil {
+		return err
+	}
+	s.Class = class
+	b = b[2:]
+	switch firstOctet & 0x18 {
+	case validityRelative:
+		if len(b) < 1 {
+			return errors.New("sms-submit too short")
+		}
+		s.ValidityPeriod = decodeRelativeValidity(b[0])
+		b = b[1:]
+	case validityAbsolute:
+		if s.ValidityTime, err = decodeTimestamp(b); err != nil {
+			return err
+		}
+		b = b[7:]
+	case validityEnhanced:
+		// the enhanced format is skipped
+		if len(b) < 7 {
+			return errors.New("sms-submit too short")
+		}
+		b = b[7:]
+	}
+	if len(b) < 1 {
+		return errors.New("sms-submit without user data length")
+	}
+	s.UserData, err = decodeUserData(b[1:], int(b[0]), encoding, firstOctet&0x40 != 0)
+	return err
+}
+
+// Deliver is a SMS-DELIVER, a message received by the mobile
+type Deliver struct {
+	SMSC                   Address      // The service centre
+	MoreMessagesToSend     bool         // More messages are waiting in the service centre
+	LoopPrevention         bool         // The message was forwarded or is a spawned message
+	ReplyPath              bool         // A reply path is set
+	StatusReportIndication bool         // A status report was requested by the sender
+	Originator             Address      // The sender
+	ProtocolIdentifier     byte         // The TP-Protocol-Identifier
+	Class                  MessageClass // The message class
+	Timestamp              time.Time    // The time the service centre received the message
+	UserData               UserData     // The content
+}
+
+// MessageType returns MessageTypeDeliver
+func (d *Deliver) MessageType() MessageType {
+	return MessageTypeDeliver
+}
+
+// Encode returns the PDU including the leading SMSC address
+func (d *Deliver) Encode() ([]byte, error) {
+	b, err := encodeSmscAddress(d.SMSC)
+	if err != nil {
+		return nil, err
+	}
+	firstOctet := byte(MessageTypeDeliver)
+	// the bit is set if no more messages are waiting
+	if !d.MoreMessagesToSend {
+		firstOctet |= 0x04
+	}
+	if d.LoopPrevention {
+		firstOctet |= 0x08
+	}
+	if d.StatusReportIndication {
+		firstOctet |= 0x20
+	}
+	if len(d.UserData.Header) > 0 {
+		firstOctet |= 0x40
+	}
+	if d.ReplyPath {
+		firstOctet |= 0x80
+	}
+	originator, err := d.Originator.encode()
+	if err != nil {
+		return nil, err
+	}
+	length, userData, err := d.UserData.encode()
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, firstOctet)
+	b = append(b, originator...)
+	b = append(b, d.ProtocolIdentifier, encodeDataCodingScheme(d.UserData.Encoding, d.Class))
+	b = append(b, encodeTimestamp(d.Timestamp)...)
+	b = append(b, length)
+	return append(b, userData...), nil
+}
+
+func (d *Deliver) decode(b []byte) (err error) {
+	firstOctet := b[0]
+	d.MoreMessagesToSend = firstOctet&0x04 == 0
+	d.LoopPrevention = firstOctet&0x08 != 0
+	d.StatusReportIndication = firstOctet&0x20 != 0
+	d.ReplyPath = firstOctet&0x80 != 0
+	var n int
+	d.Originator, n, err = decodeAddress(b[1:])
+	if err != nil {
+		return err
+	}
+	b = b[1+n:]
+	if len(b) < 10 {
+		return errors.New("sms-deliver too short")
+	}
+	d.ProtocolIdentifier = b[0]
+	encoding, class, err := decodeDataCodingScheme(b[1])
+	if err != nil {
+		return err
+	}
+	d.Class = class
+	if d.Timestamp, err = decodeTimestamp(b[2:9]); err != nil {
+		return err
+	}
+	d.UserData, err = decodeUserData(b[10:], int(b[9]), encoding, firstOctet&0x40 != 0)
+	return err
+}
+
+// Parameter indicator bits of a SMS-STATUS-REPORT
+const (
+	parameterProtocolIdentifier = 0x01
+	parameterDataCodingScheme   = 0x02
+	parameterUserDataLength     = 0x04
+)
+
+// StatusReport is a SMS-STATUS-REPORT, the delivery report of a sent message
+type StatusReport struct {
+	SMSC                  Address               // The service centre
+	MoreMessagesToSend    bool                  // More messages are waiting in the service centre
+	LoopPrevention        bool                  // The report was forwarded or is a spawned report
+	StatusReportQualifier bool                  // The report is the result of a SMS-COMMAND instead of a SMS-SUBMIT
+	MessageReference      byte                  // The reference of the sent message
+	Recipient             Address               // The recipient of the sent message
+	Timestamp             time.Time             // The time the service centre received the sent message
+	DischargeTime         time.Time             // The time of the delivery, or of the last delivery attempt
+	Status                mm.MMSmsDeliveryState // The TP-Status, e.g. MmSmsDeliveryStateCompletedReceived
+	ProtocolIdentifier    byte                  // The optional TP-Protocol-Identifier
+	Class                 MessageClass          // The optional message class
+	UserData              UserData              // The optional content
+}
+
+// MessageType returns MessageTypeStatusReport
+func (sr *StatusReport) MessageType() MessageType {
+	return MessageTypeStatusReport
+}
+
+// Encode returns the PDU including the leading SMSC address
+func (sr *StatusReport) Encode() ([]byte, error) {
+	if sr.Status > 0xff {
+		return nil, errors.New("status " + sr.Status.String() + " is no TP-Status")
+	}
+	b, err := encodeSmscAddress(sr.SMSC)
+	if err != nil {
+		return nil, err
+	}
+	firstOctet := byte(MessageTypeStatusReport)
+	if !sr.MoreMessagesToSend {
+		firstOctet |= 0x04
+	}
+	if sr.LoopPrevention {
+		firstOctet |= 0x08
+	}
+	if sr.StatusReportQualifier {
+		firstOctet |= 0x20
+	}
+	if len(sr.UserData.Header) > 0 {
+		firstOctet |= 0x40
+	}
+	recipient, err := sr.Recipient.encode()
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, firstOctet, sr.MessageReference)
+	b = append(b, recipient...)
+	b = append(b, encodeTimestamp(sr.Timestamp)...)
+	b = append(b, encodeTimestamp(sr.DischargeTime)...)
+	b = append(b, byte(sr.Status))
+
+	// the optional parameters are only given if set
+	dcs := encodeDataCodingScheme(sr.UserData.Encoding, sr.Class)
+	hasUserData := len(sr.UserData.Header) > 0 || sr.UserData.Text != "" || len(sr.UserData.Data) > 0
+	var parameters byte
+	if sr.ProtocolIdentifier != 0 {
+		parameters |= parameterProtocolIdentifier
+	}
+	if dcs != 0 {
+		parameters |= parameterDataCodingScheme
+	}
+	if hasUserData {
+		parameters |= parameterUserDataLength
+	}
+	if parameters == 0 {
+		return b, nil
+	}
+	b = append(b, parameters)
+	if parameters&parameterProtocolIdentifier != 0 {
+		b = append(b, sr.ProtocolIdentifier)
+	}
+	if parameters&parameterDataCodingScheme != 0 {
+		b = append(b, dcs)
+	}
+	if hasUserData {
+		length, userData, err := sr.UserData.encode()
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, length)
+		b = append(b, userData...)
+	}
+	return b, nil
+}
+
+func (sr *StatusReport) decode(b []byte) (err error) {
+	if len(b) < 2 {
+		return errors.New("sms-status-report too short")
+	}
+	firstOctet := b[0]
+	sr.MoreMessagesToSend = firstOctet&0x04 == 0
+	sr.LoopPrevention = firstOctet&0x08 != 0
+	sr.StatusReportQualifier = firstOctet&0x20 != 0
+	sr.MessageReference = b[1]
+	var n int
+	sr.Recipient, n, err = decodeAddress(b[2:])
+	if err != nil {
+		return err
+	}
+	b = b[2+n:]
+	if len(b) < 15 {
+		return errors.New("sms-status-report too short")
+	}
+	if sr.Timestamp, err = decodeTimestamp(b[0:7]); err != nil {
+		return err
+	}
+	if sr.DischargeTime, err = decodeTimestamp(b[7:14]); err != nil {
+		return err
+	}
+	sr.Status = mm.MMSmsDeliveryState(b[14])
+	b = b[15:]
+	if len(b) == 0 {
+		return nil
+	}
+	parameters := b[0]
+	b = b[1:]
+	if parameters&parameterProtocolIdentifier != 0 {
+		if len(b) < 1 {
+			return errors.New("sms-status-report without protocol identifier")
+		}
+		sr.ProtocolIdentifier = b[0]
+		b = b[1:]
+	}
+	encoding := EncodingGsm7
+	if parameters&parameterDataCodingScheme != 0 {
+		if len(b) < 1 {
+			return errors.New("sms-status-report without data coding scheme")
+		}
+		if encoding, sr.Class, err = decodeDataCodingScheme(b[0]); err != nil {
+			return err
+		}
+		b = b[1:]
+	}
+	sr.UserData.Encoding = encoding
+	if parameters&parameterUserDataLength != 0 {
+		if len(b) < 1 {
+			return errors.New("sms-status-report without user data length")
+		}
+		sr.UserData, err = decodeUserData(b[1:], int(b[0]), encoding, firstOctet&0x40 != 0)
+	}
+	return err
+}
@@ -0,0 +1,394 @@
+// Package pdu encodes and decodes SMS PDUs as defined in 3GPP TS 23.040: SMS-SUBMIT, SMS-DELIVER and
+// SMS-STATUS-REPORT messages with the GSM 7 bit default alphabet and its extension table, 8 bit data and UCS-2,
+// including the user data header of concatenated messages. Of the national language shift tables of
+// 3GPP TS 23.038, the Turkish, Spanish and Portuguese tables are supported, messages using the tables of other
+// languages can't be decoded. The PDUs are given with the leading SMSC address, as used by the +CMGS and +CMGL AT
+// commands.
+//
+// CountSegments computes the number of segments a text needs before sending it, NewSubmits splits a text into
+// the PDUs of a concatenated message.
+package pdu
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MessageType is the TP-Message-Type-Indicator of a PDU
+type MessageType byte
+
+const (
+	MessageTypeDeliver      MessageType = 0 // SMS-DELIVER, sent from the service centre to the mobile.
+	MessageTypeSubmit       MessageType = 1 // SMS-SUBMIT, sent from the mobile to the service centre.
+	MessageTypeStatusReport MessageType = 2 // SMS-STATUS-REPORT, sent from the service centre to the mobile.
+)
+
+func (t MessageType) String() string {
+	switch t {
+	case MessageTypeDeliver:
+		return "Deliver"
+	case MessageTypeSubmit:
+		return "Submit"
+	case MessageTypeStatusReport:
+		return "StatusReport"
+	}
+	return fmt.Sprintf("MessageType(%d)", byte(t))
+}
+
+// Message is a decoded PDU, one of *Submit, *Deliver or *StatusReport
+type Message interface {
+	// MessageType returns the type of the message
+	MessageType() MessageType
+	// Encode returns the PDU including the leading SMSC address
+	Encode() ([]byte, error)
+}
+
+// Decode decodes a PDU including the leading SMSC address
+func Decode(pdu []byte) (Message, error) {
+	if len(pdu) < 1 {
+		return nil, errors.New("empty pdu")
+	}
+	smscLength := int(pdu[0])
+	if len(pdu) < 1+smscLength {
+		return nil, errors.New("pdu shorter than its smsc address")
+	}
+	smsc, err := decodeSmscAddress(pdu[1 : 1+smscLength])
+	if err != nil {
+		return nil, err
+	}
+	tpdu := pdu[1+smscLength:]
+	if len(tpdu) < 1 {
+		return nil, errors.New("pdu without message")
+	}
+	switch MessageType(tpdu[0] & 0x03) {
+	case MessageTypeDeliver:
+		d := &Deliver{SMSC: smsc}
+		return d, d.decode(tpdu)
+	case MessageTypeSubmit:
+		s := &Submit{SMSC: smsc}
+		return s, s.decode(tpdu)
+	case MessageTypeStatusReport:
+		sr := &StatusReport{SMSC: smsc}
+		return sr, sr.decode(tpdu)
+	}
+	return nil, fmt.Errorf("unsupported message type %d", tpdu[0]&0x03)
+}
+
+// DecodeHex decodes a PDU given as hex string, e.g. as returned by the +CMGL AT command
+func DecodeHex(pdu string) (Message, error) {
+	b, err := hex.DecodeString(strings.TrimSpace(pdu))
+	if err != nil {
+		return nil, err
+	}
+	return Decode(b)
+}
+
+// EncodeHex returns the PDU of the message as upper case hex string, e.g. for the +CMGS AT command
+func EncodeHex(m Message) (string, error) {
+	b, err := m.Encode()
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+// Type-of-address values of 3GPP TS 23.040, section 9.1.2.5
+const (
+	AddressTypeUnknown       byte = 0x81 // Unknown type of number, ISDN/telephone numbering plan.
+	AddressTypeInternational byte = 0x91 // International number, ISDN/telephone numbering plan.
+	AddressTypeNational      byte = 0xa1 // National number, ISDN/telephone numbering plan.
+	AddressTypeAlphanumeric  byte = 0xd0 // Alphanumeric, coded in the GSM 7 bit default alphabet.
+)
+
+// Address is a phone number or an alphanumeric sender of a message
+type Address struct {
+	Number string // The number, international numbers are prefixed with +
+	Type   byte   // The type-of-address, e.g. AddressTypeInternational
+}
+
+// NewAddress returns the address of number: international if prefixed with +, alphanumeric if number contains
+// characters other than digits, * and #, otherwise of unknown type
+func NewAddress(number string) Address {
+	if strings.HasPrefix(number, "+") {
+		return Address{Number: number, Type: AddressTypeInternational}
+	}
+	for _, r := range number {
+		if !strings.ContainsRune("0123456789*#", r) {
+			return Address{Number: number, Type: AddressTypeAlphanumeric}
+		}
+	}
+	return Address{Number: number, Type: AddressTypeUnknown}
+}
+
+func (a Address) String() string {
+	return a.Number
+}
+
+const bcdDigits = "0123456789*#abc"
+
+func (a Address) isAlphanumeric() bool {
+	return a.Type&0x70 == 0x50
+}
+
+// semiOctets returns the digits of the address as swapped semi octets, padded with 0xF
+func (a Address) semiOctets() ([]byte, int, error) {
+	digits := strings.TrimPrefix(a.Number, "+")
+	b := make([]byte, (len(digits)+1)/2)
+	for i, r := range digits {
+		v := strings.IndexRune(bcdDigits, r)
+		if v < 0 {
+			return nil, 0, fmt.Errorf("invalid character %q in number %s", r, a.Number)
+		}
+		if i%2 == 0 {
+			b[i/2] = 0xf0 | byte(v)
+		} else {
+			b[i/2] = b[i/2]&0x0f | byte(v)<<4
+		}
+	}
+	return b, len(digits), nil
+}
+
+func (a Address) addressType() byte {
+	if a.Type != 0 {
+		return a.Type
+	}
+	return NewAddress(a.Number).Type
+}
+
+// encode returns the address field of a TPDU, the length is given in semi octets
+func (a Address) encode() ([]byte, error) {
+	addressType := a.addressType()
+	if (Address{Type: addressType}).isAlphanumeric() {
+		septets, err := defaultCharset.encode(a.Number)
+		if err != nil {
+			return nil, err
+		}
+		if len(septets) > 11 {
+			return nil, errors.New("alphanumeric address longer than 11 characters")
+		}
+		packed := packSeptets(septets, 0)
+		return append([]byte{byte((len(septets)*7 + 3) / 4), addressType}, packed...), nil
+	}
+	b, digits, err := a.semiOctets()
+	if err != nil {
+		return nil, err
+	}
+	if digits > 20 {
+		return nil, errors.New("number longer than 20 digits")
+	}
+	return append([]byte{byte(digits), addressType}, b...), nil
+}
+
+// decodeAddress decodes the address field of a TPDU and returns the number of used octets
+func decodeAddress(b []byte) (Address, int, error) {
+	if len(b) < 2 {
+		return Address{}, 0, errors.New("address field too short")
+	}
+	length := int(b[0])
+	octets := (length + 1) / 2
+	if len(b) < 2+octets {
+		return Address{}, 0, errors.New("address field shorter than its length")
+	}
+	a := Address{Type: b[1]}
+	if a.isAlphanumeric() {
+		septets, err := unpackSeptets(b[2:2+octets], 0, octets*8/7)
+		if err != nil {
+			return Address{}, 0, err
+		}
+		a.Number = strings.TrimRight(defaultCharset.decode(septets), "@")
+	} else {
+		a.Number = decodeSemiOctets(b[2:2+octets], length)
+		if a.Type&0x70 == 0x10 {
+			a.Number = "+" + a.Number
+		}
+	}
+	return a, 2 + octets, nil
+}
+
+func decodeSemiOctets(b []byte, digits int) string {
+	var sb strings.Builder
+	for i := 0; i < digits; i++ {
+		v := b[i/2] & 0x0f
+		if i%2 == 1 {
+			v = b[i/2] >> 4
+		}
+		if int(v) < len(bcdDigits) {
+			sb.WriteByte(bcdDigits[v])
+		}
+	}
+	return sb.String()
+}
+
+// encodeSmscAddress returns the SMSC address with its length in octets, or a single zero octet if no SMSC is given
+// and the SMSC stored in the SIM is used
+func encodeSmscAddress(a Address) ([]byte, error) {
+	if a.Number == "" {
+		return []byte{0}, nil
+	}
+	b, _, err := a.semiOctets()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{byte(len(b) + 1), a.addressType()}, b...), nil
+}
+
+func decodeSmscAddress(b []byte) (Address, error) {
+	if len(b) == 0 {
+		return Address{}, nil
+	}
+	// the fill semi octet 0xF of an odd number of digits is skipped by decodeSemiOctets
+	a := Address{Type: b[0], Number: decodeSemiOctets(b[1:], (len(b)-1)*2)}
+	if a.Type&0x70 == 0x10 {
+		a.Number = "+" + a.Number
+	}
+	return a, nil
+}
+
+// Encoding is the alphabet of the user data
+type Encoding byte
+
+const (
+	EncodingGsm7  Encoding = 0 // GSM 7 bit default alphabet, with national language shift tables given in the header.
+	Encoding8Bit  Encoding = 1 // 8 bit data.
+	EncodingUcs2  Encoding = 2 // UCS-2, decoded as UTF-16 to support surrogate pairs.
+	encodingOther Encoding = 3
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingGsm7:
+		return "Gsm7"
+	case Encoding8Bit:
+		return "8Bit"
+	case EncodingUcs2:
+		return "Ucs2"
+	}
+	return fmt.Sprintf("Encoding(%d)", byte(e))
+}
+
+// MessageClass is the message class of the data coding scheme, the zero value means no class
+type MessageClass byte
+
+const (
+	ClassNone MessageClass = 0 // No message class.
+	Class0    MessageClass = 1 // Class 0, flash message displayed immediately.
+	Class1    MessageClass = 2 // Class 1, mobile equipment specific.
+	Class2    MessageClass = 3 // Class 2, SIM specific.
+	Class3    MessageClass = 4 // Class 3, terminal equipment specific.
+)
+
+// encodeDataCodingScheme returns the TP-Data-Coding-Scheme of the general data coding group
+func encodeDataCodingScheme(encoding Encoding, class MessageClass) byte {
+	dcs := byte(encoding) << 2
+	if class != ClassNone {
+		dcs |= 0x10 | byte(class-1)
+	}
+	return dcs
+}
+
+// decodeDataCodingScheme returns the alphabet and the message class of a TP-Data-Coding-Scheme
+func decodeDataCodingScheme(dcs byte) (Encoding, MessageClass, error) {
+	switch {
+	case dcs&0xc0 == 0x00 || dcs&0xc0 == 0x40:
+		// general data coding and automatic deletion group
+		if dcs&0x20 != 0 {
+			return 0, 0, errors.New("compressed user data is not supported")
+		}
+		class := ClassNone
+		if dcs&0x10 != 0 {
+			class = MessageClass(dcs&0x03) + 1
+		}
+		encoding := Encoding(dcs>>2) & 0x03
+		if encoding == encodingOther {
+			encoding = EncodingGsm7
+		}
+		return encoding, class, nil
+	case dcs&0xf0 == 0xc0 || dcs&0xf0 == 0xd0:
+		// message waiting indication, discard or store message
+		return EncodingGsm7, ClassNone, nil
+	case dcs&0xf0 == 0xe0:
+		// message waiting indication, store message in UCS-2
+		return EncodingUcs2, ClassNone, nil
+	case dcs&0xf0 == 0xf0:
+		// data coding and message class
+		encoding := EncodingGsm7
+		if dcs&0x04 != 0 {
+			encoding = Encoding8Bit
+		}
+		return encoding, MessageClass(dcs&0x03) + 1, nil
+	}
+	// reserved coding groups are assumed to be the default alphabet
+	return EncodingGsm7, ClassNone, nil
+}
+
+// encodeTimestamp returns the TP-Service-Centre-Time-Stamp of t, in the time zone of t
+func encodeTimestamp(t time.Time) []byte {
+	_, offset := t.Zone()
+	quarters := offset / 900
+	negative := quarters < 0
+	if negative {
+		quarters = -quarters
+	}
+	values := []int{t.Year() % 100, int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second(), quarters}
+	b := make([]byte, len(values))
+	for i, v := range values {
+		b[i] = byte(v/10) | byte(v%10)<<4
+	}
+	if negative {
+		b[6] |= 0x08
+	}
+	return b
+}
+
+// decodeTimestamp decodes a TP-Service-Centre-Time-Stamp
+func decodeTimestamp(b []byte) (time.Time, error) {
+	if len(b) < 7 {
+		return time.Time{}, errors.New("timestamp too short")
+	}
+	values := make([]int, 7)
+	for i := range values {
+		values[i] = int(b[i]&0x0f)*10 + int(b[i]>>4)
+	}
+	quarters := int(b[6]&0x07)*10 + int(b[6]>>4)
+	if b[6]&0x08 != 0 {
+		quarters = -quarters
+	}
+	location := time.FixedZone("", quarters*900)
+	return time.Date(2000+values[0], time.Month(values[1]), values[2], values[3], values[4], values[5], 0, location), nil
+}
+
+// encodeRelativeValidity returns the relative TP-Validity-Period, rounded up to the next representable period
+func encodeRelativeValidity(d time.Duration) byte {
+	minutes := int((d + time.Minute - 1) / time.Minute)
+	switch {
+	case minutes <= 12*60:
+		v := (minutes+4)/5 - 1
+		if v < 0 {
+			v = 0
+		}
+		return byte(v)
+	case minutes <= 24*60:
+		return byte(143 + (minutes-12*60+29)/30)
+	case minutes <= 30*24*60:
+		return byte(166 + (minutes+24*60-1)/(24*60))
+	case minutes <= 63*7*24*60:
+		return byte(192 + (minutes+7*24*60-1)/(7*24*60))
+	}
+	return 255
+}
+
+// decodeRelativeValidity decodes a relative TP-Validity-Period
+func decodeRelativeValidity(v byte) time.Duration {
+	switch {
+	case v <= 143:
+		return time.Duration(v+1) * 5 * time.Minute
+	case v <= 167:
+		return 12*time.Hour + time.Duration(v-143)*30*time.Minute
+	case v <= 196:
+		return time.Duration(v-166) * 24 * time.Hour
+	}
+	return time.Duration(v-192) * 7 * 24 * time.Hour
+}
@@ -0,0 +1,312 @@
+package pdu
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name      string
+		pdu       string
+		want      Message
+		roundTrip bool // false if the PDU uses a form the encoder does not produce
+	}{
+		{
+			name: "deliver",
+			// the time zone octet 08 is -0, encoded as +0
+			pdu: "07911326040000F0040B911346610089F60000208062917314080CC8F71D14969741F977FD07",
+			want: &Deliver{
+				SMSC:       Address{Number: "+31624000000", Type: AddressTypeInternational},
+				Originator: Address{Number: "+31641600986", Type: AddressTypeInternational},
+				Timestamp:  time.Date(2002, time.August, 26, 19, 37, 41, 0, time.UTC),
+				UserData:   UserData{Encoding: EncodingGsm7, Text: "How are you?"},
+			},
+		},
+		{
+			name: "deliver ucs2 surrogate pair",
+			pdu:  "0004" + "0B911346610089F6" + "0008" + "21309251619580" + "0A" + "00480069D83DDE000021",
+			want: &Deliver{
+				Originator: Address{Number: "+31641600986", Type: AddressTypeInternational},
+				Timestamp:  time.Date(2012, time.March, 29, 15, 16, 59, 0, time.FixedZone("", 2*3600)),
+				UserData:   UserData{Encoding: EncodingUcs2, Text: "Hi\U0001F600!"},
+			},
+			roundTrip: true,
+		},
+		{
+			name: "deliver alphanumeric originator",
+			pdu:  "00" + "04" + "14D0C8329BFD065DDF723619" + "0000" + "21309251619580" + "05" + "C8329BFD06",
+			want: &Deliver{
+				Originator: Address{Number: "Hello World", Type: AddressTypeAlphanumeric},
+				Timestamp:  time.Date(2012, time.March, 29, 15, 16, 59, 0, time.FixedZone("", 2*3600)),
+				UserData:   UserData{Encoding: EncodingGsm7, Text: "Hello"},
+			},
+		},
+		{
+			name: "submit",
+			pdu:  "0011000B916407281553F80000AA0AE8329BFD4697D9EC37",
+			want: &Submit{
+				Destination:    Address{Number: "+46708251358", Type: AddressTypeInternational},
+				ValidityPeriod: 4 * 24 * time.Hour,
+				UserData:       UserData{Encoding: EncodingGsm7, Text: "hellohello"},
+			},
+			roundTrip: true,
+		},
+		{
+			name: "status report",
+			pdu:  "07911326060032F0060D0B911326880736F4111011719551401110117195714000",
+			want: &StatusReport{
+				SMSC:             Address{Number: "+31626000230", Type: AddressTypeInternational},
+				MessageReference: 0x0d,
+				Recipient:        Address{Number: "+31628870634", Type: AddressTypeInternational},
+				Timestamp:        time.Date(2011, time.January, 11, 17, 59, 15, 0, time.FixedZone("", 3600)),
+				DischargeTime:    time.Date(2011, time.January, 11, 17, 59, 17, 0, time.FixedZone("", 3600)),
+				Status:           mm.MmSmsDeliveryStateCompletedReceived,
+				UserData:         UserData{Encoding: EncodingGsm7},
+			},
+			roundTrip: true,
+		},
+	}
+	for _, test := range tests {
+		m, err := DecodeHex(test.pdu)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if m.MessageType() != test.want.MessageType() {
+			t.Errorf("%s: type = %s, want %s", test.name, m.MessageType(), test.want.MessageType())
+			continue
+		}
+		if got, want := describe(m), describe(test.want); got != want {
+			t.Errorf("%s: decoded\n%s\nwant\n%s", test.name, got, want)
+		}
+		if !test.roundTrip {
+			continue
+		}
+		encoded, err := EncodeHex(m)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if encoded != test.pdu {
+			t.Errorf("%s: encoded %s, want %s", test.name, encoded, test.pdu)
+		}
+	}
+}
+
+// describe returns the fields of a message, with timestamps compared by instant and offset
+func describe(m Message) string {
+	format := func(t time.Time) string {
+		return t.Format(time.RFC3339)
+	}
+	var b strings.Builder
+	switch m := m.(type) {
+	case *Deliver:
+		b.WriteString(strings.Join([]string{m.SMSC.Number, m.Originator.Number, format(m.Timestamp)}, " "))
+		writeUserData(&b, m.UserData)
+		b.WriteString(" more:" + strconv.FormatBool(m.MoreMessagesToSend))
+	case *Submit:
+		b.WriteString(strings.Join([]string{m.SMSC.Number, m.Destination.Number, m.ValidityPeriod.String()}, " "))
+		writeUserData(&b, m.UserData)
+	case *StatusReport:
+		b.WriteString(strings.Join([]string{m.SMSC.Number, m.Recipient.Number, format(m.Timestamp), format(m.DischargeTime),
+			m.Status.String(), hex.EncodeToString([]byte{m.MessageReference})}, " "))
+		writeUserData(&b, m.UserData)
+	}
+	return b.String()
+}
+
+func writeUserData(b *strings.Builder, ud UserData) {
+	b.WriteString(" " + ud.Encoding.String() + " " + hex.EncodeToString(ud.Data) + " " + ud.Text)
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"07911326040000",
+		"00",
+		"0004",
+		"00040B911346610089F6000020806291731408",
+		"00040B911346610089F600002080629173140820C8F71D",
+		"0011000B916407281553F80020",
+		"0006D70B911326880736F4111011719551",
+		// national language shift tables without implementation: the locking and single shift tables of
+		// Language(5) and the locking shift table of Spanish, which has none
+		"00440B911346610089F600002080629173140806032501050802",
+		"00440B911346610089F600002080629173140806032401050802",
+		"00440B911346610089F600002080629173140806032501020802",
+	}
+	for _, pdu := range tests {
+		if m, err := DecodeHex(pdu); err == nil {
+			t.Errorf("DecodeHex(%q) = %#v, want error", pdu, m)
+		}
+	}
+}
+
+func TestGsm7Charset(t *testing.T) {
+	tests := []struct {
+		name    string
+		locking Language
+		single  Language
+		text    string
+		septets string
+	}{
+		{"default", LanguageDefault, LanguageDefault, "@£$", "000102"},
+		{"extension table", LanguageDefault, LanguageDefault, "^{}\\[~]|€\f", "1b141b281b291b2f1b3c1b3d1b3e1b401b651b0a"},
+		{"turkish locking", LanguageTurkish, LanguageDefault, "ĞğŞşİıç€", "0b0c1c1d40076004"},
+		{"turkish single", LanguageDefault, LanguageTurkish, "ĞİŞçğış", "1b471b491b531b631b671b691b73"},
+		{"spanish single", LanguageDefault, LanguageSpanish, "ÁÍÓÚáíóúç", "1b411b491b4f1b551b611b691b6f1b751b09"},
+		{"portuguese locking", LanguagePortuguese, LanguageDefault, "êúÔÁªÇÀ∞ÂãõÃ", "04060b0e12131415" + "1c7b7c5b"},
+		{"portuguese single", LanguageDefault, LanguagePortuguese, "ÊÁáÃõ", "1b1f1b0e1b0f1b5b1b7c"},
+		{"portuguese locking and single", LanguagePortuguese, LanguagePortuguese, "ΦΩ€", "1b121b1518"},
+	}
+	for _, test := range tests {
+		cs, err := newGsm7Charset(test.locking, test.single)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		septets, err := cs.encode(test.text)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := hex.EncodeToString(septets); got != test.septets {
+			t.Errorf("%s: septets = %s, want %s", test.name, got, test.septets)
+		}
+		if got := cs.decode(septets); got != test.text {
+			t.Errorf("%s: decoded %q, want %q", test.name, got, test.text)
+		}
+	}
+
+	if _, err := defaultCharset.encode("ş"); err == nil {
+		t.Error("turkish character encoded with the default alphabet")
+	}
+	if _, err := newGsm7Charset(LanguageSpanish, LanguageDefault); err == nil {
+		t.Error("got a spanish locking shift table")
+	}
+	// unknown characters of the single shift table fall back to the locking shift table, a trailing escape is a space
+	if got := defaultCharset.decode([]byte{0x1b, 0x41, 0x61, 0x1b}); got != "Aa " {
+		t.Errorf("decoded %q, want %q", got, "Aa ")
+	}
+}
+
+func TestPackSeptets(t *testing.T) {
+	tests := []struct {
+		text   string
+		fill   int
+		octets string
+	}{
+		{"hellohello", 0, "e8329bfd4697d9ec37"},
+		{"a{b", 0, "e10d4a0c"},
+		// the text after a concatenation header of 6 octets starts at the next septet boundary
+		{"A", 1, "82"},
+	}
+	for _, test := range tests {
+		septets, err := defaultCharset.encode(test.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packed := packSeptets(septets, test.fill)
+		if got := hex.EncodeToString(packed); got != test.octets {
+			t.Errorf("packSeptets(%q, %d) = %s, want %s", test.text, test.fill, got, test.octets)
+		}
+		unpacked, err := unpackSeptets(packed, test.fill, len(septets))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(unpacked, septets) {
+			t.Errorf("unpackSeptets(%s) = %x, want %x", test.octets, unpacked, septets)
+		}
+	}
+	if _, err := unpackSeptets([]byte{0xe8}, 0, 2); err == nil {
+		t.Error("unpacked more septets than given")
+	}
+}
+
+func TestCountSegments(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		languages []Language
+		segments  int
+		encoding  Encoding
+	}{
+		{"empty", "", nil, 1, EncodingGsm7},
+		{"gsm7 single", strings.Repeat("a", 160), nil, 1, EncodingGsm7},
+		{"gsm7 concatenated", strings.Repeat("a", 161), nil, 2, EncodingGsm7},
+		{"gsm7 two full segments", strings.Repeat("a", 306), nil, 2, EncodingGsm7},
+		{"gsm7 three segments", strings.Repeat("a", 307), nil, 3, EncodingGsm7},
+		{"extension characters count twice", strings.Repeat("€", 80), nil, 1, EncodingGsm7},
+		{"extension characters concatenated", strings.Repeat("€", 80) + "a", nil, 2, EncodingGsm7},
+		{"ucs2 single", strings.Repeat("я", 70), nil, 1, EncodingUcs2},
+		{"ucs2 concatenated", strings.Repeat("я", 71), nil, 2, EncodingUcs2},
+		{"ucs2 two full segments", strings.Repeat("я", 134), nil, 2, EncodingUcs2},
+		{"ucs2 three segments", strings.Repeat("я", 135), nil, 3, EncodingUcs2},
+		{"ucs2 surrogate pairs", strings.Repeat("\U0001F600", 35), nil, 1, EncodingUcs2},
+		{"ucs2 surrogate pairs concatenated", strings.Repeat("\U0001F600", 35) + "a", nil, 2, EncodingUcs2},
+		{"without language", "á" + strings.Repeat("a", 153), nil, 3, EncodingUcs2},
+		// a single shift table header of 4 octets leaves 155 septets
+		{"single shift header", "á" + strings.Repeat("a", 153), []Language{LanguageSpanish}, 1, EncodingGsm7},
+		{"single shift header full", "á" + strings.Repeat("a", 154), []Language{LanguageSpanish}, 2, EncodingGsm7},
+		// locking and single shift table headers of 7 octets leave 152 septets
+		{"locking and single shift header", "ª" + strings.Repeat("Φ", 75) + "a", []Language{LanguagePortuguese}, 1, EncodingGsm7},
+		{"locking and single shift header full", "ª" + strings.Repeat("Φ", 75) + "aa", []Language{LanguagePortuguese}, 2, EncodingGsm7},
+		{"locking shift header", "ş" + strings.Repeat("{", 76) + "a", []Language{LanguageTurkish}, 1, EncodingGsm7},
+		{"unneeded language", strings.Repeat("a", 160), []Language{LanguageTurkish}, 1, EncodingGsm7},
+	}
+	for _, test := range tests {
+		segments, encoding := CountSegments(test.text, test.languages...)
+		if segments != test.segments || encoding != test.encoding {
+			t.Errorf("%s: CountSegments = %d, %s, want %d, %s", test.name, segments, encoding, test.segments, test.encoding)
+		}
+	}
+}
+
+func TestNewSubmits(t *testing.T) {
+	text := "ª" + strings.Repeat("Φ", 80)
+	submits, err := NewSubmits("+491701234567", text, 42, LanguagePortuguese)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(submits) != 2 {
+		t.Fatalf("got %d submits, want 2", len(submits))
+	}
+	var decoded string
+	for i, submit := range submits {
+		pdu, err := submit.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := Decode(pdu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ud := m.(*Submit).UserData
+		reference, total, sequence, ok := ud.Header.Concatenation()
+		if !ok || reference != 42 || total != 2 || int(sequence) != i+1 {
+			t.Errorf("segment %d: concatenation %d %d/%d, want 42 %d/2", i, reference, sequence, total, i+1)
+		}
+		if locking, single := ud.Header.Languages(); locking != LanguagePortuguese || single != LanguagePortuguese {
+			t.Errorf("segment %d: languages %s/%s, want portuguese", i, locking, single)
+		}
+		decoded += ud.Text
+	}
+	if decoded != text {
+		t.Errorf("decoded %q, want %q", decoded, text)
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	submit := &Submit{Destination: NewAddress("+491701234567"), UserData: UserData{Text: strings.Repeat("a", 161)}}
+	if _, err := submit.Encode(); err == nil {
+		t.Error("encoded 161 septets in a single pdu")
+	}
+	submit.UserData = UserData{Encoding: EncodingUcs2, Text: strings.Repeat("я", 71)}
+	if _, err := submit.Encode(); err == nil {
+		t.Error("encoded 71 ucs2 characters in a single pdu")
+	}
+}
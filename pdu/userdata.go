@@ -0,0 +1,360 @@
+package pdu
+
+import (
+	"encoding/binary"
+	"errors"
+	"unicode/utf16"
+)
+
+// Information element identifiers of the user data header, 3GPP TS 23.040 section 9.2.3.24
+const (
+	IeiConcatenated8        byte = 0x00 // Concatenated short messages, 8 bit reference number.
+	IeiPorts8               byte = 0x04 // Application port addressing, 8 bit ports.
+	IeiPorts16              byte = 0x05 // Application port addressing, 16 bit ports.
+	IeiConcatenated16       byte = 0x08 // Concatenated short messages, 16 bit reference number.
+	IeiNationalSingleShift  byte = 0x24 // National language single shift table.
+	IeiNationalLockingShift byte = 0x25 // National language locking shift table.
+)
+
+const (
+	// maxUserDataOctets is the maximum length of the user data of a single PDU
+	maxUserDataOctets = 140
+	// maxUserDataSeptets is the maximum length of the GSM 7 bit user data of a single PDU
+	maxUserDataSeptets = maxUserDataOctets * 8 / 7
+)
+
+// InformationElement is an element of the user data header
+type InformationElement struct {
+	ID   byte   // The information element identifier, e.g. IeiConcatenated8
+	Data []byte // The data of the element
+}
+
+// Header is the user data header, a list of information elements
+type Header []InformationElement
+
+// Get returns the first element with the given identifier
+func (h Header) Get(id byte) (InformationElement, bool) {
+	for _, ie := range h {
+		if ie.ID == id {
+			return ie, true
+		}
+	}
+	return InformationElement{}, false
+}
+
+// Concatenation returns the reference number, the total number of segments and the 1-based sequence number of a
+// segment of a concatenated message
+func (h Header) Concatenation() (reference uint16, total byte, sequence byte, ok bool) {
+	for _, ie := range h {
+		switch {
+		case ie.ID == IeiConcatenated8 && len(ie.Data) == 3:
+			return uint16(ie.Data[0]), ie.Data[1], ie.Data[2], true
+		case ie.ID == IeiConcatenated16 && len(ie.Data) == 4:
+			return binary.BigEndian.Uint16(ie.Data), ie.Data[2], ie.Data[3], true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// Ports returns the destination and source application port
+func (h Header) Ports() (destination uint16, source uint16, ok bool) {
+	for _, ie := range h {
+		switch {
+		case ie.ID == IeiPorts8 && len(ie.Data) == 2:
+			return uint16(ie.Data[0]), uint16(ie.Data[1]), true
+		case ie.ID == IeiPorts16 && len(ie.Data) == 4:
+			return binary.BigEndian.Uint16(ie.Data), binary.BigEndian.Uint16(ie.Data[2:]), true
+		}
+	}
+	return 0, 0, false
+}
+
+// Languages returns the national languages of the locking and single shift table
+func (h Header) Languages() (locking Language, single Language) {
+	if ie, ok := h.Get(IeiNationalLockingShift); ok && len(ie.Data) == 1 {
+		locking = Language(ie.Data[0])
+	}
+	if ie, ok := h.Get(IeiNationalSingleShift); ok && len(ie.Data) == 1 {
+		single = Language(ie.Data[0])
+	}
+	return
+}
+
+// encode returns the header including its length, or nil if empty
+func (h Header) encode() ([]byte, error) {
+	if len(h) == 0 {
+		return nil, nil
+	}
+	b := []byte{0}
+	for _, ie := range h {
+		if len(ie.Data) > 255 {
+			return nil, errors.New("information element too long")
+		}
+		b = append(b, ie.ID, byte(len(ie.Data)))
+		b = append(b, ie.Data...)
+	}
+	if len(b)-1 > maxUserDataOctets-1 {
+		return nil, errors.New("user data header too long")
+	}
+	b[0] = byte(len(b) - 1)
+	return b, nil
+}
+
+// ParseHeader parses the user data header at the start of b, e.g. of the data of a received message, and returns
+// the number of octets of the header including its length octet
+func ParseHeader(b []byte) (Header, int, error) {
+	return decodeHeader(b)
+}
+
+// decodeHeader decodes the header of the user data and returns the number of octets including its length
+func decodeHeader(b []byte) (Header, int, error) {
+	if len(b) < 1 || len(b) < 1+int(b[0]) {
+		return nil, 0, errors.New("user data header shorter than its length")
+	}
+	length := 1 + int(b[0])
+	var h Header
+	for i := 1; i < length; {
+		if i+2 > length || i+2+int(b[i+1]) > length {
+			return nil, 0, errors.New("information element shorter than its length")
+		}
+		h = append(h, InformationElement{ID: b[i], Data: append([]byte(nil), b[i+2:i+2+int(b[i+1])]...)})
+		i += 2 + int(b[i+1])
+	}
+	return h, length, nil
+}
+
+// headerSeptets returns the number of septets occupied by a header of the given length including its length octet,
+// the text starts at the next septet boundary
+func headerSeptets(headerLength int) int {
+	return (headerLength*8 + 6) / 7
+}
+
+// UserData is the content of a PDU
+type UserData struct {
+	Header   Header   // The user data header, e.g. of a concatenated message
+	Encoding Encoding // The alphabet of the user data
+	Text     string   // The text of GSM 7 bit and UCS-2 encoded user data
+	Data     []byte   // The data of 8 bit encoded user data
+}
+
+// encode returns the TP-User-Data-Length and the TP-User-Data
+func (ud UserData) encode() (byte, []byte, error) {
+	header, err := ud.Header.encode()
+	if err != nil {
+		return 0, nil, err
+	}
+	switch ud.Encoding {
+	case EncodingGsm7:
+		cs, err := newGsm7Charset(ud.Header.Languages())
+		if err != nil {
+			return 0, nil, err
+		}
+		septets, err := cs.encode(ud.Text)
+		if err != nil {
+			return 0, nil, err
+		}
+		skip := headerSeptets(len(header))
+		if skip+len(septets) > maxUserDataSeptets {
+			return 0, nil, errors.New("text too long for a single pdu")
+		}
+		b := packSeptets(septets, skip*7)
+		copy(b, header)
+		return byte(skip + len(septets)), b, nil
+	case Encoding8Bit:
+		b := append(header, ud.Data...)
+		if len(b) > maxUserDataOctets {
+			return 0, nil, errors.New("data too long for a single pdu")
+		}
+		return byte(len(b)), b, nil
+	case EncodingUcs2:
+		b := append(header, encodeUcs2(ud.Text)...)
+		if len(b) > maxUserDataOctets {
+			return 0, nil, errors.New("text too long for a single pdu")
+		}
+		return byte(len(b)), b, nil
+	}
+	return 0, nil, errors.New("unsupported encoding " + ud.Encoding.String())
+}
+
+// decodeUserData decodes the TP-User-Data, with a header if hasHeader
+func decodeUserData(b []byte, length int, encoding Encoding, hasHeader bool) (ud UserData, err error) {
+	ud.Encoding = encoding
+	headerLength := 0
+	if hasHeader {
+		ud.Header, headerLength, err = decodeHeader(b)
+		if err != nil {
+			return
+		}
+	}
+	if encoding == EncodingGsm7 {
+		skip := headerSeptets(headerLength)
+		if length < skip {
+			return ud, errors.New("user data length shorter than its header")
+		}
+		// only the tables of Turkish, Spanish and Portuguese are implemented, decoding the text of another
+		// language with the default alphabet would return wrong characters
+		cs, err := newGsm7Charset(ud.Header.Languages())
+		if err != nil {
+			return ud, err
+		}
+		septets, err := unpackSeptets(b, skip*7, length-skip)
+		if err != nil {
+			return ud, err
+		}
+		ud.Text = cs.decode(septets)
+		return ud, nil
+	}
+	if length > len(b) || length < headerLength {
+		return ud, errors.New("user data shorter than its length")
+	}
+	data := b[headerLength:length]
+	if encoding == EncodingUcs2 {
+		ud.Text = decodeUcs2(data)
+	} else {
+		ud.Data = append([]byte(nil), data...)
+	}
+	return ud, nil
+}
+
+// encodeUcs2 returns text as big endian UTF-16, characters outside the basic multilingual plane are encoded as
+// surrogate pairs
+func encodeUcs2(text string) []byte {
+	units := utf16.Encode([]rune(text))
+	b := make([]byte, len(units)*2)
+	for i, unit := range units {
+		binary.BigEndian.PutUint16(b[i*2:], unit)
+	}
+	return b
+}
+
+func decodeUcs2(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.BigEndian.Uint16(b[i*2:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// segmentPlan is the encoding of a text and its split into segments
+type segmentPlan struct {
+	encoding Encoding
+	locking  Language
+	single   Language
+	parts    []string
+}
+
+// split splits the runes with the given costs into parts of at most single (if one part) or multi units
+func split(runes []rune, costs []int, single int, multi int) []string {
+	total := 0
+	for _, cost := range costs {
+		total += cost
+	}
+	if total <= single {
+		return []string{string(runes)}
+	}
+	var parts []string
+	start, used := 0, 0
+	for i, cost := range costs {
+		if used+cost > multi {
+			parts = append(parts, string(runes[start:i]))
+			start, used = i, 0
+		}
+		used += cost
+	}
+	return append(parts, string(runes[start:]))
+}
+
+// planSegments selects the encoding with the fewest segments: the GSM 7 bit alphabet with the default or one of
+// the given national language tables, or UCS-2 if the text contains other characters
+func planSegments(text string, languages []Language) segmentPlan {
+	runes := []rune(text)
+	lockings := []Language{LanguageDefault}
+	singles := []Language{LanguageDefault}
+	for _, language := range languages {
+		if language != LanguageDefault && language.HasLockingShiftTable() {
+			lockings = append(lockings, language)
+		}
+		if language != LanguageDefault && language.HasSingleShiftTable() {
+			singles = append(singles, language)
+		}
+	}
+	var best *segmentPlan
+	costs := make([]int, len(runes))
+	for _, locking := range lockings {
+	candidates:
+		for _, single := range singles {
+			cs, err := newGsm7Charset(locking, single)
+			if err != nil {
+				continue
+			}
+			for i, r := range runes {
+				costs[i] = cs.septets(r)
+				if costs[i] == 0 {
+					continue candidates
+				}
+			}
+			// each national language table adds an information element of 3 octets
+			languageOctets := 0
+			if locking != LanguageDefault {
+				languageOctets += 3
+			}
+			if single != LanguageDefault {
+				languageOctets += 3
+			}
+			singleCapacity := maxUserDataSeptets
+			if languageOctets > 0 {
+				singleCapacity -= headerSeptets(1 + languageOctets)
+			}
+			multiCapacity := maxUserDataSeptets - headerSeptets(1+languageOctets+5)
+			parts := split(runes, costs, singleCapacity, multiCapacity)
+			if best == nil || len(parts) < len(best.parts) {
+				best = &segmentPlan{encoding: EncodingGsm7, locking: locking, single: single, parts: parts}
+			}
+		}
+	}
+	if best != nil {
+		return *best
+	}
+	for i, r := range runes {
+		costs[i] = len(utf16.Encode([]rune{r})) * 2
+	}
+	return segmentPlan{
+		encoding: EncodingUcs2,
+		parts:    split(runes, costs, maxUserDataOctets, maxUserDataOctets-6),
+	}
+}
+
+// CountSegments returns the number of PDUs needed to send text and the selected encoding. The GSM 7 bit alphabet is
+// used if possible, with the national language tables of the given languages if they save segments or are needed
+// to encode the text, otherwise UCS-2.
+func CountSegments(text string, languages ...Language) (segments int, encoding Encoding) {
+	plan := planSegments(text, languages)
+	return len(plan.parts), plan.encoding
+}
+
+// SplitText returns the user data of the segments needed to send text, see CountSegments for the selected encoding.
+// Segments of a concatenated message get the given reference number, which should differ for each message sent to
+// the same recipient.
+func SplitText(text string, reference byte, languages ...Language) ([]UserData, error) {
+	plan := planSegments(text, languages)
+	if len(plan.parts) > 255 {
+		return nil, errors.New("text too long for a concatenated message")
+	}
+	var languageHeader Header
+	if plan.locking != LanguageDefault {
+		languageHeader = append(languageHeader, InformationElement{ID: IeiNationalLockingShift, Data: []byte{byte(plan.locking)}})
+	}
+	if plan.single != LanguageDefault {
+		languageHeader = append(languageHeader, InformationElement{ID: IeiNationalSingleShift, Data: []byte{byte(plan.single)}})
+	}
+	userData := make([]UserData, 0, len(plan.parts))
+	for i, part := range plan.parts {
+		var header Header
+		if len(plan.parts) > 1 {
+			header = Header{{ID: IeiConcatenated8, Data: []byte{reference, byte(len(plan.parts)), byte(i + 1)}}}
+		}
+		header = append(header, languageHeader...)
+		userData = append(userData, UserData{Header: header, Encoding: plan.encoding, Text: part})
+	}
+	return userData, nil
+}
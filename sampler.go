@@ -0,0 +1,383 @@
+package modemmanager
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// TrafficSample is a measurement of the data traffic of all bearers of a modem
+type TrafficSample struct {
+	Time      time.Time // The time of the measurement
+	Connected bool      // Whether a bearer of the modem was connected
+	RxBytes   uint64    // Received bytes of the accounting period, accumulated across reconnects
+	TxBytes   uint64    // Transmitted bytes of the accounting period, accumulated across reconnects
+	RxRate    float64   // Receive throughput since the previous sample, in bytes per second
+	TxRate    float64   // Transmit throughput since the previous sample, in bytes per second
+}
+
+func (ts TrafficSample) String() string {
+	return returnString(ts)
+}
+
+// DataCap is a data budget, e.g. the monthly volume of a metered SIM
+type DataCap struct {
+	Limit      uint64    // The budget of received and transmitted bytes
+	Thresholds []float64 // Fractions of Limit which raise an alert when reached, e.g. 0.8 and 1, defaults to 1
+}
+
+// DataCapAlert is raised once per threshold of the DataCap within an accounting period
+type DataCapAlert struct {
+	Time      time.Time // The time of the sample which reached the threshold
+	Threshold float64   // The reached fraction of the limit
+	Used      uint64    // The received and transmitted bytes of the accounting period
+	Limit     uint64    // The limit of the DataCap
+}
+
+func (a DataCapAlert) String() string {
+	return returnString(a)
+}
+
+// TrafficSampler polls the stats of all bearers of a modem, computes the throughput and accumulates the traffic of
+// an accounting period across reconnects and recreated bearers. The traffic before the first sample is not counted,
+// use SetUsage to continue a previous accounting period. The Stats property changes of the sampled bearers are
+// followed, so the traffic of a bearer removed between two samples is accounted up to its last announced stats.
+type TrafficSampler struct {
+	modem Modem
+
+	// Interval is the polling interval, defaults to 10s
+	Interval time.Duration
+	// HistorySize is the number of samples kept in the time series, defaults to 360
+	HistorySize int
+
+	mu          sync.Mutex
+	baseline    bool
+	bearers     map[dbus.ObjectPath]BearerStats // the accounted stats per bearer
+	latest      map[dbus.ObjectPath]BearerStats // the latest stats announced by the bearers
+	watched     map[dbus.ObjectPath]Bearer      // the bearers whose stats changes are followed
+	removed     map[dbus.ObjectPath]bool        // bearers removed before the last sample, kept for late stats changes
+	rxBytes     uint64
+	txBytes     uint64
+	samples     []TrafficSample
+	dataCap     DataCap
+	raised      int
+	onSample    []func(TrafficSample)
+	onAlert     []func(DataCapAlert)
+	cancel      context.CancelFunc
+	stopped     chan struct{}
+	sampleMutex sync.Mutex
+}
+
+// NewTrafficSampler returns a new TrafficSampler of the given modem
+func NewTrafficSampler(modem Modem) (*TrafficSampler, error) {
+	if modem == nil {
+		return nil, errors.New("no modem given")
+	}
+	return &TrafficSampler{
+		modem:       modem,
+		Interval:    10 * time.Second,
+		HistorySize: 360,
+		bearers:     make(map[dbus.ObjectPath]BearerStats),
+		latest:      make(map[dbus.ObjectPath]BearerStats),
+		watched:     make(map[dbus.ObjectPath]Bearer),
+		removed:     make(map[dbus.ObjectPath]bool),
+	}, nil
+}
+
+// OnSample adds a callback, which is called for every sample. Callbacks are called from the sampler goroutine and
+// should not block.
+func (s *TrafficSampler) OnSample(f func(sample TrafficSample)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onSample = append(s.onSample, f)
+}
+
+// OnDataCapAlert adds a callback, which is called when a threshold of the DataCap is reached. Callbacks are called
+// from the sampler goroutine and should not block.
+func (s *TrafficSampler) OnDataCapAlert(f func(alert DataCapAlert)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onAlert = append(s.onAlert, f)
+}
+
+// SetDataCap sets the data budget. Thresholds which are already reached are raised with the next sample.
+func (s *TrafficSampler) SetDataCap(dataCap DataCap) {
+	thresholds := append([]float64(nil), dataCap.Thresholds...)
+	if len(thresholds) == 0 {
+		thresholds = []float64{1}
+	}
+	sort.Float64s(thresholds)
+	dataCap.Thresholds = thresholds
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dataCap = dataCap
+	s.raised = 0
+}
+
+// Usage returns the received and transmitted bytes of the accounting period
+func (s *TrafficSampler) Usage() (rx uint64, tx uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rxBytes, s.txBytes
+}
+
+// SetUsage sets the received and transmitted bytes of the accounting period, e.g. to continue a persisted period
+// after a restart. Thresholds of the DataCap which are already reached are raised with the next sample.
+func (s *TrafficSampler) SetUsage(rx uint64, tx uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rxBytes, s.txBytes = rx, tx
+	s.raised = 0
+}
+
+// ResetUsage starts a new accounting period, e.g. at the beginning of a month
+func (s *TrafficSampler) ResetUsage() {
+	s.SetUsage(0, 0)
+}
+
+// Samples returns the time series of the latest samples, oldest first
+func (s *TrafficSampler) Samples() []TrafficSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]TrafficSample(nil), s.samples...)
+}
+
+// Sample polls the stats of all bearers of the modem, adds the sample to the time series and raises the alerts of the
+// DataCap. The first sample only takes the baseline of the bearer stats.
+func (s *TrafficSampler) Sample() (TrafficSample, error) {
+	// samples are taken one at a time, so the deltas are computed against consistent stats
+	s.sampleMutex.Lock()
+	defer s.sampleMutex.Unlock()
+	bearers, err := s.modem.GetBearers()
+	if err != nil {
+		return TrafficSample{}, err
+	}
+	sample := TrafficSample{Time: time.Now()}
+	current := make(map[dbus.ObjectPath]BearerStats)
+	for _, bearer := range bearers {
+		s.watch(bearer)
+		stats, err := bearer.GetStats()
+		if err != nil {
+			// the bearer was removed in between
+			continue
+		}
+		current[bearer.GetObjectPath()] = stats
+		if connected, err := bearer.GetConnected(); err == nil && connected {
+			sample.Connected = true
+		}
+	}
+
+	s.mu.Lock()
+	var rx, tx uint64
+	account := func(path dbus.ObjectPath, stats BearerStats) {
+		last, known := s.bearers[path]
+		if s.baseline {
+			bearerRx, bearerTx := trafficDelta(last, stats, known)
+			rx += bearerRx
+			tx += bearerTx
+		}
+		s.bearers[path] = stats
+	}
+	for path, stats := range current {
+		account(path, stats)
+		delete(s.removed, path)
+	}
+	var unwatch []Bearer
+	for path, last := range s.bearers {
+		if _, ok := current[path]; ok {
+			continue
+		}
+		// the final reading of a removed bearer are its last announced stats
+		if final, ok := s.latest[path]; ok && statsAfter(last, final) {
+			account(path, final)
+		}
+		if !s.removed[path] {
+			// stats announced just before the removal may still be delivered until the next sample
+			s.removed[path] = true
+			continue
+		}
+		delete(s.bearers, path)
+		delete(s.latest, path)
+		delete(s.removed, path)
+		if bearer, ok := s.watched[path]; ok {
+			unwatch = append(unwatch, bearer)
+			delete(s.watched, path)
+		}
+	}
+	s.baseline = true
+	s.rxBytes += rx
+	s.txBytes += tx
+	sample.RxBytes, sample.TxBytes = s.rxBytes, s.txBytes
+	if len(s.samples) > 0 {
+		elapsed := sample.Time.Sub(s.samples[len(s.samples)-1].Time).Seconds()
+		if elapsed > 0 {
+			sample.RxRate = float64(rx) / elapsed
+			sample.TxRate = float64(tx) / elapsed
+		}
+	}
+	s.samples = append(s.samples, sample)
+	if s.HistorySize > 0 && len(s.samples) > s.HistorySize {
+		s.samples = append([]TrafficSample(nil), s.samples[len(s.samples)-s.HistorySize:]...)
+	}
+	var alerts []DataCapAlert
+	used := s.rxBytes + s.txBytes
+	for s.dataCap.Limit > 0 && s.raised < len(s.dataCap.Thresholds) {
+		threshold := s.dataCap.Thresholds[s.raised]
+		if float64(used) < threshold*float64(s.dataCap.Limit) {
+			break
+		}
+		alerts = append(alerts, DataCapAlert{Time: sample.Time, Threshold: threshold, Used: used, Limit: s.dataCap.Limit})
+		s.raised++
+	}
+	onSample, onAlert := s.onSample, s.onAlert
+	s.mu.Unlock()
+
+	for _, bearer := range unwatch {
+		bearer.Unsubscribe()
+	}
+	for _, f := range onSample {
+		f(sample)
+	}
+	for _, alert := range alerts {
+		for _, f := range onAlert {
+			f(alert)
+		}
+	}
+	return sample, nil
+}
+
+// watch follows the stats changes of the bearer, if it is not followed yet
+func (s *TrafficSampler) watch(bearer Bearer) {
+	path := bearer.GetObjectPath()
+	s.mu.Lock()
+	_, watched := s.watched[path]
+	if !watched {
+		s.watched[path] = bearer
+	}
+	s.mu.Unlock()
+	if watched {
+		return
+	}
+	changes := bearer.SubscribePropertiesChanged()
+	go func() {
+		for sig := range changes {
+			_, changed, _, err := bearer.ParsePropertiesChanged(sig)
+			if err != nil {
+				continue
+			}
+			tmpMap, ok := changed["Stats"].Value().(map[string]dbus.Variant)
+			if !ok {
+				continue
+			}
+			stats := parseBearerStats(tmpMap)
+			s.mu.Lock()
+			if latest, ok := s.latest[path]; !ok || statsAfter(latest, stats) {
+				s.latest[path] = stats
+			}
+			s.mu.Unlock()
+		}
+	}()
+}
+
+// unwatchAll stops following the stats changes of all bearers
+func (s *TrafficSampler) unwatchAll() {
+	s.mu.Lock()
+	watched := s.watched
+	s.watched = make(map[dbus.ObjectPath]Bearer)
+	s.mu.Unlock()
+	for _, bearer := range watched {
+		bearer.Unsubscribe()
+	}
+}
+
+// statsAfter reports whether the stats of a bearer were taken after the last stats, signals may be delivered after
+// a newer poll of the stats
+func statsAfter(last BearerStats, current BearerStats) bool {
+	if last.TotalRxBytes > 0 || last.TotalTxBytes > 0 || current.TotalRxBytes > 0 || current.TotalTxBytes > 0 {
+		return current.TotalRxBytes+current.TotalTxBytes > last.TotalRxBytes+last.TotalTxBytes
+	}
+	if current.StartDate != last.StartDate {
+		return current.StartDate > last.StartDate
+	}
+	return current.RxBytes+current.TxBytes > last.RxBytes+last.TxBytes
+}
+
+// trafficDelta returns the traffic of a bearer since the last stats. The stats of a bearer are reset on reconnect,
+// so the total counters are used if the modem reports them, otherwise a reset is detected by decreasing counters
+// or a changed start date.
+func trafficDelta(last BearerStats, current BearerStats, known bool) (rx uint64, tx uint64) {
+	hasTotals := current.TotalRxBytes > 0 || current.TotalTxBytes > 0
+	if !known {
+		if hasTotals {
+			return current.TotalRxBytes, current.TotalTxBytes
+		}
+		return current.RxBytes, current.TxBytes
+	}
+	if hasTotals && current.TotalRxBytes >= last.TotalRxBytes && current.TotalTxBytes >= last.TotalTxBytes {
+		return current.TotalRxBytes - last.TotalRxBytes, current.TotalTxBytes - last.TotalTxBytes
+	}
+	if current.RxBytes < last.RxBytes || current.TxBytes < last.TxBytes || current.Duration < last.Duration ||
+		current.StartDate != last.StartDate {
+		return current.RxBytes, current.TxBytes
+	}
+	return current.RxBytes - last.RxBytes, current.TxBytes - last.TxBytes
+}
+
+// Start takes the first sample and starts polling in a new goroutine. Failed samples are skipped, their traffic is
+// accounted by the next successful sample.
+func (s *TrafficSampler) Start() error {
+	s.mu.Lock()
+	started := s.cancel != nil
+	interval := s.Interval
+	s.mu.Unlock()
+	if started {
+		return errors.New("sampler already started")
+	}
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	if _, err := s.Sample(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return errors.New("sampler already started")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.stopped = make(chan struct{})
+	go s.run(ctx, interval, s.stopped)
+	return nil
+}
+
+// Stop stops polling, waits for the sampler goroutine and stops following the stats changes of the bearers
+func (s *TrafficSampler) Stop() {
+	s.mu.Lock()
+	cancel, stopped := s.cancel, s.stopped
+	s.cancel, s.stopped = nil, nil
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-stopped
+	}
+	s.unwatchAll()
+}
+
+func (s *TrafficSampler) run(ctx context.Context, interval time.Duration, stopped chan struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = s.Sample()
+		}
+	}
+}
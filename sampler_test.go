@@ -0,0 +1,164 @@
+package modemmanager_test
+
+import (
+	"testing"
+	"time"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+// newSampler connects the modem by ModemSimple.Connect and returns a sampler after its baseline sample
+func newSampler(t *testing.T, modem mm.Modem) *mm.TrafficSampler {
+	t.Helper()
+	simple, err := modem.GetSimpleModem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := simple.Connect(mm.SimpleProperties{Apn: "internet"}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := mm.NewTrafficSampler(modem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample, err := s.Sample()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sample.Connected || sample.RxBytes != 0 || sample.TxBytes != 0 {
+		t.Fatalf("baseline sample %s, want connected without traffic", sample)
+	}
+	return s
+}
+
+func assertUsage(t *testing.T, s *mm.TrafficSampler, wantRx uint64, wantTx uint64) {
+	t.Helper()
+	if rx, tx := s.Usage(); rx != wantRx || tx != wantTx {
+		t.Errorf("usage = %d/%d, want %d/%d", rx, tx, wantRx, wantTx)
+	}
+}
+
+func TestTrafficSamplerReconnect(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	s := newSampler(t, modem)
+	defer s.Stop()
+
+	fake.Bearers()[0].AddTraffic(1000, 100)
+	sample, err := s.Sample()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sample.RxBytes != 1000 || sample.TxBytes != 100 || sample.RxRate <= 0 || sample.TxRate <= 0 {
+		t.Errorf("sample %s, want 1000/100 bytes with rates", sample)
+	}
+
+	// the counters of the bearer are reset by the reconnect
+	fake.Bearers()[0].Drop()
+	bearers, err := modem.GetBearers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bearers[0].Connect(); err != nil {
+		t.Fatal(err)
+	}
+	fake.Bearers()[0].AddTraffic(500, 50)
+	if _, err := s.Sample(); err != nil {
+		t.Fatal(err)
+	}
+	assertUsage(t, s, 1500, 150)
+	if samples := s.Samples(); len(samples) != 3 {
+		t.Errorf("got %d samples, want 3", len(samples))
+	}
+}
+
+func TestTrafficSamplerBearerRemoved(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	s := newSampler(t, modem)
+	defer s.Stop()
+
+	bearers, err := modem.GetBearers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := bearers[0].SubscribePropertiesChanged()
+	defer bearers[0].Unsubscribe()
+	fake.Bearers()[0].AddTraffic(1000, 200)
+	nextSignal(t, changed)
+	// the traffic after the last sample is only known by the stats change of the removed bearer
+	if err := modem.DeleteBearer(bearers[0]); err != nil {
+		t.Fatal(err)
+	}
+	sample, err := s.Sample()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sample.Connected {
+		t.Error("sample connected without bearer")
+	}
+	if rx, _ := s.Usage(); rx == 0 {
+		// the stats change may be delivered late, it is accounted by the next sample
+		time.Sleep(100 * time.Millisecond)
+		if _, err := s.Sample(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertUsage(t, s, 1000, 200)
+	if _, err := s.Sample(); err != nil {
+		t.Fatal(err)
+	}
+	assertUsage(t, s, 1000, 200)
+}
+
+func TestTrafficSamplerDataCap(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	s := newSampler(t, modem)
+	defer s.Stop()
+	s.HistorySize = 2
+	s.SetDataCap(mm.DataCap{Limit: 1000, Thresholds: []float64{1, 0.5}})
+	var alerts []mm.DataCapAlert
+	s.OnDataCapAlert(func(alert mm.DataCapAlert) {
+		alerts = append(alerts, alert)
+	})
+
+	fake.Bearers()[0].AddTraffic(400, 200)
+	if _, err := s.Sample(); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].Threshold != 0.5 || alerts[0].Used != 600 {
+		t.Fatalf("got alerts %v, want the alert of 0.5 at 600 bytes", alerts)
+	}
+	fake.Bearers()[0].AddTraffic(300, 0)
+	if _, err := s.Sample(); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("got alerts %v, want no alert below the limit", alerts)
+	}
+	fake.Bearers()[0].AddTraffic(100, 0)
+	if _, err := s.Sample(); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 2 || alerts[1].Threshold != 1 || alerts[1].Used != 1000 {
+		t.Fatalf("got alerts %v, want the alert of 1 at 1000 bytes", alerts)
+	}
+	if samples := s.Samples(); len(samples) != 2 || samples[1].RxBytes != 800 {
+		t.Errorf("got samples %v, want the last 2 samples", samples)
+	}
+
+	// a new accounting period raises the alerts again
+	s.ResetUsage()
+	fake.Bearers()[0].AddTraffic(600, 0)
+	if _, err := s.Sample(); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 3 || alerts[2].Threshold != 0.5 {
+		t.Errorf("got alerts %v, want the alert of 0.5 of the new period", alerts)
+	}
+}
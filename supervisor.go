@@ -0,0 +1,679 @@
+package modemmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// ConnectionStatus describes the current step of a ConnectionSupervisor
+type ConnectionStatus int
+
+const (
+	ConnectionStatusStopped         ConnectionStatus = iota // The supervisor is not running.
+	ConnectionStatusWaitingForModem                         // No modem matching the profile is available.
+	ConnectionStatusUnlocking                               // The SIM of the modem is being unlocked.
+	ConnectionStatusEnabling                                // The modem is being enabled.
+	ConnectionStatusRegistering                             // Waiting for the modem to register with a network.
+	ConnectionStatusConnecting                              // The data bearer is being connected.
+	ConnectionStatusConnected                               // The data bearer is connected.
+	ConnectionStatusBackoff                                 // The last attempt failed or the connection was lost, waiting for the next attempt.
+)
+
+var connectionStatusNames = []string{"Stopped", "WaitingForModem", "Unlocking", "Enabling", "Registering", "Connecting", "Connected", "Backoff"}
+
+func (s ConnectionStatus) String() string {
+	if s < 0 || int(s) >= len(connectionStatusNames) {
+		return fmt.Sprintf("ConnectionStatus(%d)", int(s))
+	}
+	return connectionStatusNames[s]
+}
+
+// ConnectionProfile defines the connection which is maintained by a ConnectionSupervisor
+type ConnectionProfile struct {
+	DeviceIdentifier    string           // Selects the modem by its DeviceIdentifier, optional
+	EquipmentIdentifier string           // Selects the modem by its EquipmentIdentifier (e.g. IMEI), optional. If both identifiers are empty the first modem is used
+	Properties          SimpleProperties // The properties of ModemSimple.Connect, e.g. the Apn. Properties.Pin is used to unlock the SIM
+}
+
+// Backoff defines the delays between two connection attempts, starting with Initial and multiplied by Multiplier
+// after each failed attempt up to Max
+type Backoff struct {
+	Initial    time.Duration // The delay after the first failure, defaults to 1s
+	Max        time.Duration // The maximum delay, defaults to 5m
+	Multiplier float64       // The factor of the delay after each failure, defaults to 2
+}
+
+func (b Backoff) withDefaults() Backoff {
+	if b.Initial <= 0 {
+		b.Initial = time.Second
+	}
+	if b.Max <= 0 {
+		b.Max = 5 * time.Minute
+	}
+	if b.Max < b.Initial {
+		b.Max = b.Initial
+	}
+	if b.Multiplier < 1 {
+		b.Multiplier = 2
+	}
+	return b
+}
+
+func (b Backoff) next(delay time.Duration) time.Duration {
+	delay = time.Duration(float64(delay) * b.Multiplier)
+	if delay > b.Max {
+		return b.Max
+	}
+	return delay
+}
+
+// ConnectionState is the current state of a ConnectionSupervisor
+type ConnectionState struct {
+	Status    ConnectionStatus // The current step
+	Modem     dbus.ObjectPath  // The path of the supervised modem, empty if none is available
+	Bearer    dbus.ObjectPath  // The path of the connected bearer, empty if not connected
+	Lock      MMModemLock      // The lock of the modem found by the current or last failed attempt, e.g. MmModemLockSimPuk
+	Attempts  int              // Failed attempts since the last successful connection
+	LastError error            // The error of the last failed attempt or the reason of the lost connection
+	Since     time.Time        // The time of the last status change
+}
+
+func (cs ConnectionState) String() string {
+	return returnString(cs)
+}
+
+// ErrConnectionLost is reported as LastError if a connected bearer gets disconnected
+var ErrConnectionLost = errors.New("connection lost")
+
+// ErrModemRemoved is reported as LastError if the supervised modem disappears, e.g. due to a reset
+var ErrModemRemoved = errors.New("modem removed")
+
+// ErrEventsClosed is reported as LastError if the events of the bus stopped, e.g. because the connection was lost
+var ErrEventsClosed = errors.New("event channels closed")
+
+// ConnectionSupervisor keeps a data connection up: it waits for a modem matching the profile, unlocks the SIM,
+// enables the modem, waits for the network registration and connects by ModemSimple.Connect. If the bearer gets
+// disconnected, an attempt fails or the modem is re-enumerated, it reconnects with an exponential backoff.
+type ConnectionSupervisor struct {
+	conn    *dbus.Conn
+	profile ConnectionProfile
+	backoff Backoff
+
+	// RegistrationTimeout is the maximum time to wait for the network registration or for a connection
+	// attempt of another client, defaults to 2m
+	RegistrationTimeout time.Duration
+	// CheckInterval is the interval of polling the bearer state in addition to the signals, defaults to 30s
+	CheckInterval time.Duration
+	// Dial returns a new connection if the connection to the bus was lost. It defaults to a new private system
+	// bus connection for NewConnectionSupervisor and to nil for NewConnectionSupervisorWithConn, which keeps
+	// using the given connection.
+	Dial func() (*dbus.Conn, error)
+
+	mu             sync.Mutex
+	state          ConnectionState
+	onStatus       []func(ConnectionState)
+	onEvent        []func(Event)
+	cancel         context.CancelFunc
+	stopped        chan struct{}
+	watcher        *ModemWatcher
+	dispatcher     *EventDispatcher
+	subscription   *EventSubscription
+	modemEvents    <-chan Event
+	supervisedPath dbus.ObjectPath
+	bearerPath     dbus.ObjectPath
+	eventsClosed   bool
+	dialed         bool // conn was returned by Dial and is closed by Stop
+}
+
+// NewConnectionSupervisor returns a new ConnectionSupervisor using the system bus
+func NewConnectionSupervisor(profile ConnectionProfile, backoff Backoff) (*ConnectionSupervisor, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewConnectionSupervisorWithConn(conn, profile, backoff)
+	if err != nil {
+		return nil, err
+	}
+	s.Dial = dialSystemBus
+	return s, nil
+}
+
+// dialSystemBus returns a new private connection to the system bus, the shared connection of dbus.SystemBus
+// is not replaced after it was closed
+func dialSystemBus() (*dbus.Conn, error) {
+	conn, err := dbus.SystemBusPrivate()
+	if err != nil {
+		return nil, err
+	}
+	if err = conn.Auth(nil); err == nil {
+		err = conn.Hello()
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// NewConnectionSupervisorWithConn returns a new ConnectionSupervisor using the given dbus connection
+func NewConnectionSupervisorWithConn(conn *dbus.Conn, profile ConnectionProfile, backoff Backoff) (*ConnectionSupervisor, error) {
+	if conn == nil {
+		return nil, errors.New("no dbus connection given")
+	}
+	return &ConnectionSupervisor{
+		conn:                conn,
+		profile:             profile,
+		backoff:             backoff.withDefaults(),
+		RegistrationTimeout: 2 * time.Minute,
+		CheckInterval:       30 * time.Second,
+		state:               ConnectionState{Status: ConnectionStatusStopped, Since: time.Now()},
+	}, nil
+}
+
+// OnStatusChanged adds a callback, which is called on every status change. Callbacks are called from the
+// supervisor goroutine and should not block.
+func (s *ConnectionSupervisor) OnStatusChanged(f func(state ConnectionState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onStatus = append(s.onStatus, f)
+}
+
+// OnEvent adds a callback, which is called for all events of the supervised modem and its bearer, including
+// ModemAddedEvent and ModemRemovedEvent. Callbacks are called from the supervisor goroutine and should not block.
+func (s *ConnectionSupervisor) OnEvent(f func(e Event)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onEvent = append(s.onEvent, f)
+}
+
+// Status returns the current state
+func (s *ConnectionSupervisor) Status() ConnectionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// Start starts the supervision in a new goroutine
+func (s *ConnectionSupervisor) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return errors.New("supervisor already started")
+	}
+	conn, err := s.connection()
+	if err != nil {
+		return err
+	}
+	if err := s.subscribe(conn); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.stopped = make(chan struct{})
+	go s.run(ctx)
+	return nil
+}
+
+// Stop stops the supervision and waits for the supervisor goroutine. An established connection is not disconnected.
+func (s *ConnectionSupervisor) Stop() error {
+	s.mu.Lock()
+	cancel, stopped := s.cancel, s.stopped
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	<-stopped
+
+	s.mu.Lock()
+	err := s.unsubscribe()
+	if s.dialed {
+		_ = s.conn.Close()
+	}
+	s.cancel, s.stopped = nil, nil
+	s.mu.Unlock()
+	s.setStatus(ConnectionStatusStopped, nil)
+	return err
+}
+
+// subscribe starts the watcher and the dispatcher on conn, s.mu must be held
+func (s *ConnectionSupervisor) subscribe(conn *dbus.Conn) error {
+	watcher, err := NewModemWatcherWithConn(conn)
+	if err != nil {
+		return err
+	}
+	dispatcher, err := NewEventDispatcherWithConn(conn)
+	if err != nil {
+		_ = watcher.Close()
+		return err
+	}
+	s.watcher = watcher
+	s.dispatcher = dispatcher
+	s.subscription = dispatcher.Subscribe(EventFilter{}, 64)
+	s.modemEvents = watcher.Events()
+	s.eventsClosed = false
+	return nil
+}
+
+// unsubscribe stops the watcher and the dispatcher, s.mu must be held
+func (s *ConnectionSupervisor) unsubscribe() error {
+	if s.watcher == nil {
+		return nil
+	}
+	err := s.dispatcher.Close()
+	if werr := s.watcher.Close(); err == nil {
+		err = werr
+	}
+	s.watcher, s.dispatcher, s.subscription, s.modemEvents = nil, nil, nil, nil
+	return err
+}
+
+// resubscribe replaces the watcher and the dispatcher after their events were closed
+func (s *ConnectionSupervisor) resubscribe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.eventsClosed {
+		return nil
+	}
+	_ = s.unsubscribe()
+	conn, err := s.connection()
+	if err != nil {
+		return err
+	}
+	return s.subscribe(conn)
+}
+
+// connection returns the connection to the bus, a closed connection is replaced by Dial if set. s.mu must be held.
+func (s *ConnectionSupervisor) connection() (*dbus.Conn, error) {
+	if s.Dial == nil || s.conn.Context().Err() == nil {
+		return s.conn, nil
+	}
+	conn, err := s.Dial()
+	if err != nil {
+		return nil, err
+	}
+	s.conn, s.dialed = conn, true
+	return conn, nil
+}
+
+func (s *ConnectionSupervisor) run(ctx context.Context) {
+	defer close(s.stopped)
+	delay := s.backoff.Initial
+	for {
+		// reconnect to the bus if the events were closed
+		err := s.resubscribe()
+		var modem Modem
+		if err == nil {
+			modem, err = s.waitForModem(ctx)
+		}
+		if err == nil {
+			err = s.connect(ctx, modem)
+			if err == nil {
+				delay = s.backoff.Initial
+				s.mu.Lock()
+				s.state.Attempts = 0
+				s.mu.Unlock()
+				s.setStatus(ConnectionStatusConnected, nil)
+				err = s.waitForDisconnect(ctx)
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		s.mu.Lock()
+		s.bearerPath = ""
+		s.state.Attempts++
+		s.mu.Unlock()
+		s.setStatus(ConnectionStatusBackoff, err)
+		if err == ErrModemRemoved {
+			// the modem is expected to come back soon, the backoff is applied while waiting for it
+			s.setSupervised("")
+		}
+		if s.sleep(ctx, delay) != nil {
+			return
+		}
+		delay = s.backoff.next(delay)
+	}
+}
+
+// waitForModem returns the modem matching the profile, or an error if the context is done or the events were closed
+func (s *ConnectionSupervisor) waitForModem(ctx context.Context) (Modem, error) {
+	for {
+		if modem := s.findModem(); modem != nil {
+			s.setSupervised(modem.GetObjectPath())
+			return modem, nil
+		}
+		s.setSupervised("")
+		s.setStatus(ConnectionStatusWaitingForModem, s.Status().LastError)
+		if _, err := s.nextEvent(ctx, 0); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *ConnectionSupervisor) findModem() Modem {
+	var modem Modem
+	var err error
+	switch {
+	case s.profile.DeviceIdentifier != "":
+		modem, err = s.watcher.GetModemByDeviceIdentifier(s.profile.DeviceIdentifier)
+	case s.profile.EquipmentIdentifier != "":
+		modem, err = s.watcher.GetModemByEquipmentIdentifier(s.profile.EquipmentIdentifier)
+	default:
+		modems := s.watcher.Modems()
+		if len(modems) == 0 {
+			return nil
+		}
+		modem = modems[0]
+	}
+	if err != nil {
+		return nil
+	}
+	return modem
+}
+
+// connect brings the modem from any state to connected
+func (s *ConnectionSupervisor) connect(ctx context.Context, modem Modem) error {
+	timeout := time.NewTimer(s.RegistrationTimeout)
+	defer timeout.Stop()
+	s.setLock(MmModemLockNone)
+	for {
+		state, err := modem.GetState()
+		if err != nil {
+			return err
+		}
+		switch {
+		case state == MmModemStateFailed:
+			reason, _ := modem.GetStateFailedReason()
+			return fmt.Errorf("modem failed: %s", reason)
+
+		case state == MmModemStateLocked:
+			lock, err := modem.GetUnlockRequired()
+			if err != nil {
+				return err
+			}
+			s.setLock(lock)
+			s.setStatus(ConnectionStatusUnlocking, nil)
+			if err := s.unlock(ctx, modem, lock); err != nil {
+				return err
+			}
+
+		case state == MmModemStateDisabled:
+			s.setStatus(ConnectionStatusEnabling, nil)
+			if err := modem.EnableWithContext(ctx); err != nil {
+				return err
+			}
+
+		case state == MmModemStateConnected:
+			// connected before the supervisor was started or by another client
+			return s.adoptBearer(modem)
+
+		case state == MmModemStateRegistered:
+			s.setStatus(ConnectionStatusConnecting, nil)
+			return s.connectBearer(ctx, modem)
+
+		case state == MmModemStateConnecting:
+			// another client is connecting, wait for its result
+			s.setStatus(ConnectionStatusConnecting, nil)
+			if err := s.waitForStateChange(ctx, timeout.C, state); err != nil {
+				return err
+			}
+
+		default:
+			// initializing, enabling, enabled, searching or disconnecting
+			s.setStatus(ConnectionStatusRegistering, nil)
+			if err := s.waitForStateChange(ctx, timeout.C, state); err != nil {
+				return err
+			}
+		}
+		if s.isRemoved() {
+			return ErrModemRemoved
+		}
+	}
+}
+
+// waitForStateChange waits for the next event or at the latest one second, it returns an error after the timeout
+func (s *ConnectionSupervisor) waitForStateChange(ctx context.Context, timeout <-chan time.Time, state MMModemState) error {
+	select {
+	case <-timeout:
+		if state == MmModemStateConnecting {
+			return fmt.Errorf("modem not connected within %s", s.RegistrationTimeout)
+		}
+		return fmt.Errorf("modem not registered within %s (state: %s)", s.RegistrationTimeout, state)
+	default:
+	}
+	_, err := s.nextEvent(ctx, time.Second)
+	return err
+}
+
+func (s *ConnectionSupervisor) unlock(ctx context.Context, modem Modem, lock MMModemLock) error {
+	if lock != MmModemLockSimPin {
+		return fmt.Errorf("sim locked: %s", lock)
+	}
+	if s.profile.Properties.Pin == "" {
+		return errors.New("sim locked, but no pin given")
+	}
+	sim, err := modem.GetSim()
+	if err != nil {
+		return err
+	}
+	return sim.SendPinWithContext(ctx, s.profile.Properties.Pin)
+}
+
+func (s *ConnectionSupervisor) connectBearer(ctx context.Context, modem Modem) error {
+	simple, err := modem.GetSimpleModem()
+	if err != nil {
+		return err
+	}
+	bearer, err := simple.ConnectWithContext(ctx, s.profile.Properties)
+	if err != nil {
+		return err
+	}
+	s.setBearer(bearer.GetObjectPath())
+	return nil
+}
+
+// adoptBearer supervises the connected bearer of a modem, which was connected without the supervisor
+func (s *ConnectionSupervisor) adoptBearer(modem Modem) error {
+	bearers, err := modem.GetBearers()
+	if err != nil {
+		return err
+	}
+	for _, bearer := range bearers {
+		connected, err := bearer.GetConnected()
+		if err != nil {
+			return err
+		}
+		if connected {
+			s.setBearer(bearer.GetObjectPath())
+			return nil
+		}
+	}
+	return errors.New("modem connected, but no connected bearer found")
+}
+
+func (s *ConnectionSupervisor) setBearer(path dbus.ObjectPath) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bearerPath = path
+	s.state.Bearer = path
+}
+
+// waitForDisconnect returns if the bearer gets disconnected, the modem is removed or the context is done
+func (s *ConnectionSupervisor) waitForDisconnect(ctx context.Context) error {
+	s.mu.Lock()
+	bearerPath := s.bearerPath
+	s.mu.Unlock()
+	bearer, err := NewBearerWithConn(s.conn, bearerPath)
+	if err != nil {
+		return err
+	}
+	lastCheck := time.Now()
+	for {
+		e, err := s.nextEvent(ctx, s.CheckInterval)
+		if err != nil {
+			return err
+		}
+		if s.isRemoved() {
+			return ErrModemRemoved
+		}
+		if e, ok := e.(BearerConnectedEvent); ok && e.Path == bearerPath && !e.Connected {
+			return ErrConnectionLost
+		}
+		if time.Since(lastCheck) >= s.CheckInterval {
+			lastCheck = time.Now()
+			connected, err := bearer.GetConnected()
+			if err != nil {
+				return err
+			}
+			if !connected {
+				return ErrConnectionLost
+			}
+		}
+	}
+}
+
+// nextEvent waits for the next event of the supervised modem or its bearer and for added or removed modems.
+// It returns a nil event after the timeout, a timeout of 0 waits without limit. ErrEventsClosed is returned
+// if the event channels were closed.
+func (s *ConnectionSupervisor) nextEvent(ctx context.Context, timeout time.Duration) (Event, error) {
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+	s.mu.Lock()
+	closed := s.eventsClosed
+	s.mu.Unlock()
+	if closed {
+		return nil, ErrEventsClosed
+	}
+	for {
+		var e Event
+		ok := true
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer:
+			return nil, nil
+		case e, ok = <-s.modemEvents:
+			if removed, isRemoved := e.(ModemRemovedEvent); isRemoved && removed.Path == s.supervised() {
+				s.mu.Lock()
+				s.state.Modem = ""
+				s.mu.Unlock()
+			}
+		case e, ok = <-s.subscription.Events():
+			if ok && !s.isRelevant(e) {
+				continue
+			}
+		}
+		if !ok {
+			s.mu.Lock()
+			s.eventsClosed = true
+			s.mu.Unlock()
+			return nil, ErrEventsClosed
+		}
+		s.mu.Lock()
+		callbacks := s.onEvent
+		s.mu.Unlock()
+		for _, f := range callbacks {
+			f(e)
+		}
+		return e, nil
+	}
+}
+
+func (s *ConnectionSupervisor) isRelevant(e Event) bool {
+	switch e.(type) {
+	case ModemAddedEvent, ModemRemovedEvent:
+		// reported by the watcher with the identifiers of the modem
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := e.GetObjectPath()
+	return path != "" && (path == s.supervisedPath || path == s.bearerPath)
+}
+
+func (s *ConnectionSupervisor) isRemoved() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.supervisedPath != "" && s.state.Modem == ""
+}
+
+func (s *ConnectionSupervisor) supervised() dbus.ObjectPath {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.supervisedPath
+}
+
+func (s *ConnectionSupervisor) setSupervised(path dbus.ObjectPath) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.supervisedPath = path
+	s.state.Modem = path
+}
+
+func (s *ConnectionSupervisor) sleep(ctx context.Context, delay time.Duration) error {
+	deadline := time.Now().Add(delay)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil
+		}
+		// keep receiving events, so the callbacks are called and the modem list stays up to date
+		_, err := s.nextEvent(ctx, remaining)
+		if err == ErrEventsClosed {
+			return sleepContext(ctx, remaining)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func (s *ConnectionSupervisor) setLock(lock MMModemLock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Lock = lock
+}
+
+func (s *ConnectionSupervisor) setStatus(status ConnectionStatus, err error) {
+	s.mu.Lock()
+	if s.state.Status == status && s.state.LastError == err {
+		s.mu.Unlock()
+		return
+	}
+	s.state.Status = status
+	s.state.Since = time.Now()
+	if err != nil || status == ConnectionStatusConnected {
+		s.state.LastError = err
+	}
+	if status != ConnectionStatusConnected {
+		s.state.Bearer = ""
+	}
+	state := s.state
+	callbacks := s.onStatus
+	s.mu.Unlock()
+	for _, f := range callbacks {
+		f(state)
+	}
+}
@@ -0,0 +1,215 @@
+package modemmanager_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+// startSupervisor starts a supervisor and returns a channel of its status changes
+func startSupervisor(t *testing.T, conn *dbus.Conn, profile mm.ConnectionProfile, setup func(s *mm.ConnectionSupervisor)) (*mm.ConnectionSupervisor, <-chan mm.ConnectionState) {
+	t.Helper()
+	s, err := mm.NewConnectionSupervisorWithConn(conn, profile, mm.Backoff{Initial: 10 * time.Millisecond, Max: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if setup != nil {
+		setup(s)
+	}
+	states := make(chan mm.ConnectionState, 100)
+	s.OnStatusChanged(func(state mm.ConnectionState) {
+		select {
+		case states <- state:
+		default:
+		}
+	})
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return s, states
+}
+
+// waitForStatus returns the first state matching f, it fails the test after a timeout
+func waitForStatus(t *testing.T, states <-chan mm.ConnectionState, f func(state mm.ConnectionState) bool) mm.ConnectionState {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case state := <-states:
+			if f(state) {
+				return state
+			}
+		case <-timeout:
+			t.Fatal("timeout waiting for status")
+		}
+	}
+}
+
+func isConnected(state mm.ConnectionState) bool {
+	return state.Status == mm.ConnectionStatusConnected
+}
+
+func countCalls(srv *mmtest.Server, method string) (n int) {
+	for _, call := range srv.Calls() {
+		if call.Method == method {
+			n++
+		}
+	}
+	return
+}
+
+func TestConnectionSupervisorConnect(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	fake, _ := srv.AddModemT(t, conn, mmtest.ModemConfig{Sim: mmtest.SimConfig{Locked: true}})
+	s, states := startSupervisor(t, conn, mm.ConnectionProfile{Properties: mm.SimpleProperties{Apn: "internet", Pin: "1234"}}, nil)
+	defer s.Stop()
+
+	unlocking := waitForStatus(t, states, func(state mm.ConnectionState) bool {
+		return state.Status == mm.ConnectionStatusUnlocking
+	})
+	if unlocking.Lock != mm.MmModemLockSimPin || unlocking.Modem != fake.GetObjectPath() {
+		t.Errorf("got %s, want sim-pin lock of %s", unlocking, fake.GetObjectPath())
+	}
+	connected := waitForStatus(t, states, isConnected)
+	if len(fake.Bearers()) != 1 || connected.Bearer != fake.Bearers()[0].GetObjectPath() {
+		t.Errorf("got %s, want the bearer of the fake", connected)
+	}
+
+	fake.Bearers()[0].Drop()
+	lost := waitForStatus(t, states, func(state mm.ConnectionState) bool {
+		return state.Status == mm.ConnectionStatusBackoff
+	})
+	if lost.LastError != mm.ErrConnectionLost || lost.Attempts != 1 {
+		t.Errorf("got %s, want a lost connection", lost)
+	}
+	waitForStatus(t, states, isConnected)
+}
+
+func TestConnectionSupervisorLockType(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	srv.AddModemT(t, conn, mmtest.ModemConfig{Sim: mmtest.SimConfig{Locked: true, PinRetries: 1}})
+	s, states := startSupervisor(t, conn, mm.ConnectionProfile{Properties: mm.SimpleProperties{Pin: "0000"}}, nil)
+	defer s.Stop()
+
+	// the wrong pin blocks the sim, the next attempt reports the puk lock
+	state := waitForStatus(t, states, func(state mm.ConnectionState) bool {
+		return state.Status == mm.ConnectionStatusBackoff && state.Lock == mm.MmModemLockSimPuk
+	})
+	if state.LastError == nil || !strings.Contains(state.LastError.Error(), mm.MmModemLockSimPuk.String()) {
+		t.Errorf("last error = %v, want the sim-puk lock", state.LastError)
+	}
+}
+
+func TestConnectionSupervisorAlreadyConnected(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	_, modem := srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	simple, err := modem.GetSimpleModem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bearer, err := simple.Connect(mm.SimpleProperties{Apn: "internet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, states := startSupervisor(t, conn, mm.ConnectionProfile{Properties: mm.SimpleProperties{Apn: "internet"}}, nil)
+	defer s.Stop()
+
+	connected := waitForStatus(t, states, isConnected)
+	if connected.Bearer != bearer.GetObjectPath() {
+		t.Errorf("got %s, want the connected bearer %s", connected, bearer.GetObjectPath())
+	}
+	if n := countCalls(srv, mm.ModemSimpleConnect); n != 1 {
+		t.Errorf("got %d calls of Connect, want only the connect before the supervisor was started", n)
+	}
+}
+
+func TestConnectionSupervisorEventsClosed(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	srv.AddModemT(t, conn, mmtest.ModemConfig{})
+	supervisorConn, err := srv.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, states := startSupervisor(t, supervisorConn, mm.ConnectionProfile{Properties: mm.SimpleProperties{Apn: "internet"}},
+		func(s *mm.ConnectionSupervisor) { s.Dial = srv.Connect })
+	defer s.Stop()
+	connected := waitForStatus(t, states, isConnected)
+
+	// losing the connection to the bus closes the events, the supervisor reconnects and keeps the bearer
+	_ = supervisorConn.Close()
+	waitForStatus(t, states, func(state mm.ConnectionState) bool {
+		return state.Status == mm.ConnectionStatusBackoff && state.LastError == mm.ErrEventsClosed
+	})
+	reconnected := waitForStatus(t, states, isConnected)
+	if reconnected.Bearer != connected.Bearer {
+		t.Errorf("got %s, want the bearer %s", reconnected, connected.Bearer)
+	}
+	if n := countCalls(srv, mm.ModemSimpleConnect); n != 1 {
+		t.Errorf("got %d calls of Connect, want 1", n)
+	}
+}
+
+func TestConnectionSupervisorReenumeration(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	cfg := mmtest.ModemConfig{EquipmentIdentifier: "490154203237518", DeviceIdentifier: "a1b2c3d4e5f6"}
+	// another modem is listed first, the supervisor selects the modem by its identifier
+	srv.AddModemT(t, conn, mmtest.ModemConfig{EquipmentIdentifier: "358240051111110", DeviceIdentifier: "0f0f0f0f"})
+	fake, _ := srv.AddModemT(t, conn, cfg)
+	var added []mm.ModemAddedEvent
+	var removed []mm.ModemRemovedEvent
+	s, states := startSupervisor(t, conn, mm.ConnectionProfile{DeviceIdentifier: cfg.DeviceIdentifier, Properties: mm.SimpleProperties{Apn: "internet"}},
+		func(s *mm.ConnectionSupervisor) {
+			s.OnEvent(func(e mm.Event) {
+				switch e := e.(type) {
+				case mm.ModemAddedEvent:
+					added = append(added, e)
+				case mm.ModemRemovedEvent:
+					removed = append(removed, e)
+				}
+			})
+		})
+	defer s.Stop()
+	connected := waitForStatus(t, states, isConnected)
+	if connected.Modem != fake.GetObjectPath() {
+		t.Fatalf("got %s, want the modem %s", connected, fake.GetObjectPath())
+	}
+
+	// a reset modem disappears without disconnecting its bearer and comes back under a new path
+	fake.Remove()
+	waitForStatus(t, states, func(state mm.ConnectionState) bool {
+		return state.Status == mm.ConnectionStatusBackoff && state.LastError == mm.ErrModemRemoved
+	})
+	reenumerated, err := srv.AddModem(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reenumerated.GetObjectPath() == fake.GetObjectPath() {
+		t.Fatal("modem added under the same path")
+	}
+	reconnected := waitForStatus(t, states, isConnected)
+	if reconnected.Modem != reenumerated.GetObjectPath() {
+		t.Errorf("got %s, want the modem bound to its new path %s", reconnected, reenumerated.GetObjectPath())
+	}
+	if len(reenumerated.Bearers()) != 1 || reconnected.Bearer != reenumerated.Bearers()[0].GetObjectPath() {
+		t.Errorf("got %s, want the bearer of the re-enumerated modem", reconnected)
+	}
+	if n := countCalls(srv, mm.ModemSimpleConnect); n != 2 {
+		t.Errorf("got %d calls of Connect, want a reconnect after the re-enumeration", n)
+	}
+	s.Stop()
+	if len(removed) != 1 || removed[0].Path != fake.GetObjectPath() || removed[0].EquipmentIdentifier != cfg.EquipmentIdentifier {
+		t.Errorf("got removed events %v, want the removal of %s", removed, fake.GetObjectPath())
+	}
+	if len(added) == 0 || added[len(added)-1].Path != reenumerated.GetObjectPath() || added[len(added)-1].PreviousPath != fake.GetObjectPath() {
+		t.Errorf("got added events %v, want the re-enumeration of %s", added, fake.GetObjectPath())
+	}
+}
@@ -23,6 +23,7 @@ package modemmanager
 */
 
 import (
+	"context"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
@@ -85,31 +86,50 @@ func (p Pair) MarshalJSON() ([]byte, error) {
 type dbusBase struct {
 	conn *dbus.Conn
 	obj  dbus.BusObject
+	sig  *signalSubscription
 }
 
 func (d *dbusBase) init(iface string, objectPath dbus.ObjectPath) error {
-	var err error
-
-	d.conn, err = dbus.SystemBus()
+	conn, err := dbus.SystemBus()
 	if err != nil {
 		return err
 	}
+	return d.initWithConn(conn, iface, objectPath)
+}
 
+func (d *dbusBase) initWithConn(conn *dbus.Conn, iface string, objectPath dbus.ObjectPath) error {
+	if conn == nil {
+		return errors.New("no dbus connection given")
+	}
+	d.conn = conn
 	d.obj = d.conn.Object(iface, objectPath)
+	d.sig = &signalSubscription{}
 
 	return nil
 }
 
 func (d *dbusBase) call(method string, args ...interface{}) error {
-	return d.obj.Call(method, 0, args...).Err
+	return d.callContext(context.Background(), method, args...)
 }
 
 func (d *dbusBase) callWithReturn(ret interface{}, method string, args ...interface{}) error {
-	return d.obj.Call(method, 0, args...).Store(ret)
+	return d.callWithReturnContext(context.Background(), ret, method, args...)
 }
 
 func (d *dbusBase) callWithReturn2(ret1 interface{}, ret2 interface{}, method string, args ...interface{}) error {
-	return d.obj.Call(method, 0, args...).Store(ret1, ret2)
+	return d.callWithReturn2Context(context.Background(), ret1, ret2, method, args...)
+}
+
+func (d *dbusBase) callContext(ctx context.Context, method string, args ...interface{}) error {
+	return parseError(d.obj.CallWithContext(ctx, method, 0, args...).Err)
+}
+
+func (d *dbusBase) callWithReturnContext(ctx context.Context, ret interface{}, method string, args ...interface{}) error {
+	return parseError(d.obj.CallWithContext(ctx, method, 0, args...).Store(ret))
+}
+
+func (d *dbusBase) callWithReturn2Context(ctx context.Context, ret1 interface{}, ret2 interface{}, method string, args ...interface{}) error {
+	return parseError(d.obj.CallWithContext(ctx, method, 0, args...).Store(ret1, ret2))
 }
 
 func (d *dbusBase) subscribe(iface, member string) {
@@ -147,12 +167,11 @@ func (d *dbusBase) parsePropertiesChanged(v *dbus.Signal) (interfaceName string,
 
 func (d *dbusBase) getProperty(iface string) (interface{}, error) {
 	variant, err := d.obj.GetProperty(iface)
-	return variant.Value(), err
+	return variant.Value(), parseError(err)
 }
 
 func (d *dbusBase) setProperty(iface string, value interface{}) error {
-	err := d.obj.SetProperty(iface, dbus.MakeVariant(value))
-	return err
+	return parseError(d.obj.SetProperty(iface, dbus.MakeVariant(value)))
 }
 
 func (d *dbusBase) getObjectProperty(iface string) (value dbus.ObjectPath, err error) {
@@ -0,0 +1,46 @@
+package modemmanager_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+// deniedProperties answers every property access with a ModemManager error, like a daemon denying access by polkit
+type deniedProperties struct{}
+
+func (deniedProperties) Get(iface string, property string) (dbus.Variant, *dbus.Error) {
+	return dbus.Variant{}, dbus.NewError(mmtest.ErrorUnauthorized, []interface{}{"not authorized to read " + property})
+}
+
+func (deniedProperties) GetAll(iface string) (map[string]dbus.Variant, *dbus.Error) {
+	return nil, dbus.NewError(mmtest.ErrorUnauthorized, []interface{}{"not authorized"})
+}
+
+func (deniedProperties) Set(iface string, property string, value dbus.Variant) *dbus.Error {
+	return dbus.NewError(mmtest.ErrorUnauthorized, []interface{}{"not authorized to write " + property})
+}
+
+func TestPropertyError(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	path := dbus.ObjectPath(mm.ModemManagerObjectPath + "/Modem/99")
+	if err := srv.Conn().Export(deniedProperties{}, path, "org.freedesktop.DBus.Properties"); err != nil {
+		t.Fatal(err)
+	}
+	modem, err := mm.NewModemWithConn(conn, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = modem.GetState()
+	if !errors.Is(err, mm.MmCoreErrorUnauthorized) {
+		t.Errorf("got error %v, want MmCoreErrorUnauthorized", err)
+	}
+	var mmErr *mm.ModemManagerError
+	if !errors.As(err, &mmErr) || mmErr.Message != "not authorized to read State" {
+		t.Errorf("got error %#v, want the ModemManagerError with the message of the daemon", err)
+	}
+}
@@ -0,0 +1,193 @@
+package modemmanager
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// ErrModemNotFound is returned if no modem matches the given identifier
+var ErrModemNotFound = errors.New("modem not found")
+
+// ModemWatcher follows modems appearing and disappearing on the bus, e.g. if a USB modem is reset and
+// re-enumerated by ModemManager under a new object path. It sends a ModemAddedEvent for each modem
+// available when the watcher is started, followed by ModemAddedEvent and ModemRemovedEvent events.
+// A re-enumerated modem is recognized by its DeviceIdentifier or EquipmentIdentifier and the
+// PreviousPath of its ModemAddedEvent is set to the path of the removed modem.
+type ModemWatcher struct {
+	conn         *dbus.Conn
+	dispatcher   *EventDispatcher
+	subscription *EventSubscription
+	events       chan Event
+	done         chan struct{}
+	closeOnce    sync.Once
+	wg           sync.WaitGroup
+
+	mu      sync.Mutex
+	modems  map[dbus.ObjectPath]ModemAddedEvent
+	removed map[string]dbus.ObjectPath // last path of removed modems by device and equipment identifier
+}
+
+// NewModemWatcher returns a new ModemWatcher using the system bus
+func NewModemWatcher() (*ModemWatcher, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return nil, err
+	}
+	return NewModemWatcherWithConn(conn)
+}
+
+// NewModemWatcherWithConn returns a new ModemWatcher using the given dbus connection
+func NewModemWatcherWithConn(conn *dbus.Conn) (*ModemWatcher, error) {
+	dispatcher, err := NewEventDispatcherWithConn(conn)
+	if err != nil {
+		return nil, err
+	}
+	w := &ModemWatcher{
+		conn:       conn,
+		dispatcher: dispatcher,
+		events:     make(chan Event, 10),
+		done:       make(chan struct{}),
+		modems:     make(map[dbus.ObjectPath]ModemAddedEvent),
+		removed:    make(map[string]dbus.ObjectPath),
+	}
+	// subscribe before reading the current modems, so no modem gets lost in between
+	w.subscription = dispatcher.Subscribe(EventFilter{Interface: ModemInterface}, 64)
+	var initial []ModemAddedEvent
+	managedObjects := make(map[dbus.ObjectPath]map[string]map[string]dbus.Variant)
+	err = conn.Object(ModemManagerInterface, ModemManagerObjectPath).Call(dbusMethodManagedObjects, 0).Store(&managedObjects)
+	if err != nil {
+		_ = dispatcher.Close()
+		return nil, parseError(err)
+	}
+	for path, interfaces := range managedObjects {
+		if e, ok := parseModemAdded(conn, path, interfaces); ok {
+			initial = append(initial, e)
+		}
+	}
+	sort.Slice(initial, func(i, j int) bool { return initial[i].Path < initial[j].Path })
+	w.wg.Add(1)
+	go w.run(initial)
+	return w, nil
+}
+
+// Events returns the channel of ModemAddedEvent and ModemRemovedEvent events, which is closed after Close
+func (w *ModemWatcher) Events() <-chan Event {
+	return w.events
+}
+
+// Modems returns the currently available modems
+func (w *ModemWatcher) Modems() []Modem {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	paths := make([]string, 0, len(w.modems))
+	for path := range w.modems {
+		paths = append(paths, string(path))
+	}
+	sort.Strings(paths)
+	modems := make([]Modem, 0, len(paths))
+	for _, path := range paths {
+		modems = append(modems, w.modems[dbus.ObjectPath(path)].Modem)
+	}
+	return modems
+}
+
+// GetModemByDeviceIdentifier returns the available modem with the given DeviceIdentifier
+func (w *ModemWatcher) GetModemByDeviceIdentifier(deviceIdentifier string) (Modem, error) {
+	return w.find(func(e ModemAddedEvent) bool { return e.DeviceIdentifier == deviceIdentifier })
+}
+
+// GetModemByEquipmentIdentifier returns the available modem with the given EquipmentIdentifier, e.g. the IMEI
+func (w *ModemWatcher) GetModemByEquipmentIdentifier(equipmentIdentifier string) (Modem, error) {
+	return w.find(func(e ModemAddedEvent) bool { return e.EquipmentIdentifier == equipmentIdentifier })
+}
+
+func (w *ModemWatcher) find(match func(e ModemAddedEvent) bool) (Modem, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, e := range w.modems {
+		if match(e) {
+			return e.Modem, nil
+		}
+	}
+	return nil, ErrModemNotFound
+}
+
+// Close stops the watcher and closes the event channel, the connection is not closed
+func (w *ModemWatcher) Close() (err error) {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.dispatcher.Close()
+		w.wg.Wait()
+	})
+	return
+}
+
+func (w *ModemWatcher) run(initial []ModemAddedEvent) {
+	defer w.wg.Done()
+	defer close(w.events)
+	for _, e := range initial {
+		if !w.handle(e) {
+			return
+		}
+	}
+	for e := range w.subscription.Events() {
+		if !w.handle(e) {
+			return
+		}
+	}
+}
+
+// handle updates the known modems and forwards the event, it returns false if the watcher was closed
+func (w *ModemWatcher) handle(e Event) bool {
+	var out Event
+	w.mu.Lock()
+	switch e := e.(type) {
+	case ModemAddedEvent:
+		if _, ok := w.modems[e.Path]; ok {
+			break
+		}
+		for _, key := range modemKeys(e.DeviceIdentifier, e.EquipmentIdentifier) {
+			if path, ok := w.removed[key]; ok {
+				e.PreviousPath = path
+				delete(w.removed, key)
+			}
+		}
+		w.modems[e.Path] = e
+		out = e
+	case ModemRemovedEvent:
+		added, ok := w.modems[e.Path]
+		if !ok {
+			break
+		}
+		delete(w.modems, e.Path)
+		e.DeviceIdentifier = added.DeviceIdentifier
+		e.EquipmentIdentifier = added.EquipmentIdentifier
+		for _, key := range modemKeys(e.DeviceIdentifier, e.EquipmentIdentifier) {
+			w.removed[key] = e.Path
+		}
+		out = e
+	}
+	w.mu.Unlock()
+	if out == nil {
+		return true
+	}
+	select {
+	case w.events <- out:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+func modemKeys(deviceIdentifier string, equipmentIdentifier string) (keys []string) {
+	if deviceIdentifier != "" {
+		keys = append(keys, "device:"+deviceIdentifier)
+	}
+	if equipmentIdentifier != "" {
+		keys = append(keys, "equipment:"+equipmentIdentifier)
+	}
+	return
+}
@@ -0,0 +1,74 @@
+package modemmanager_test
+
+import (
+	"testing"
+
+	mm "github.com/maltegrosse/go-modemmanager"
+	"github.com/maltegrosse/go-modemmanager/mmtest"
+)
+
+func TestModemWatcher(t *testing.T) {
+	srv, conn, stop := mmtest.StartT(t)
+	defer stop()
+	first, _ := srv.AddModemT(t, conn, mmtest.ModemConfig{EquipmentIdentifier: "861234567890123", DeviceIdentifier: "usb-1"})
+	w, err := mm.NewModemWatcherWithConn(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	all := func(e mm.Event) bool { return true }
+
+	added := nextEvent(t, w.Events(), all).(mm.ModemAddedEvent)
+	if added.Path != first.GetObjectPath() || added.PreviousPath != "" || added.DeviceIdentifier != "usb-1" {
+		t.Errorf("got %s, want initial modem %s", added, first.GetObjectPath())
+	}
+	second, err := srv.AddModem(mmtest.ModemConfig{EquipmentIdentifier: "861234567890456"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	added = nextEvent(t, w.Events(), all).(mm.ModemAddedEvent)
+	if added.Path != second.GetObjectPath() || added.EquipmentIdentifier != "861234567890456" {
+		t.Errorf("got %s, want added modem %s", added, second.GetObjectPath())
+	}
+	if modems := w.Modems(); len(modems) != 2 {
+		t.Errorf("got %d modems, want 2", len(modems))
+	}
+	modem, err := w.GetModemByEquipmentIdentifier("861234567890456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modem.GetObjectPath() != second.GetObjectPath() {
+		t.Errorf("got modem %s, want %s", modem.GetObjectPath(), second.GetObjectPath())
+	}
+
+	// a reset modem is re-enumerated under a new path with the same identifiers
+	first.Remove()
+	removed := nextEvent(t, w.Events(), all).(mm.ModemRemovedEvent)
+	if removed.Path != first.GetObjectPath() || removed.DeviceIdentifier != "usb-1" || removed.EquipmentIdentifier != "861234567890123" {
+		t.Errorf("got %s, want removed modem %s with its identifiers", removed, first.GetObjectPath())
+	}
+	if _, err := w.GetModemByDeviceIdentifier("usb-1"); err != mm.ErrModemNotFound {
+		t.Errorf("removed modem found, error %v", err)
+	}
+	reset, err := srv.AddModem(mmtest.ModemConfig{EquipmentIdentifier: "861234567890123", DeviceIdentifier: "usb-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	added = nextEvent(t, w.Events(), all).(mm.ModemAddedEvent)
+	if added.Path != reset.GetObjectPath() || added.PreviousPath != first.GetObjectPath() {
+		t.Errorf("got %s, want modem %s previously %s", added, reset.GetObjectPath(), first.GetObjectPath())
+	}
+	modem, err = w.GetModemByDeviceIdentifier("usb-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modem.GetObjectPath() != reset.GetObjectPath() {
+		t.Errorf("got modem %s, want %s", modem.GetObjectPath(), reset.GetObjectPath())
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for range w.Events() {
+	}
+}